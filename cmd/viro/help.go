@@ -9,7 +9,11 @@ USAGE:
     viro [OPTIONS] [FILE [ARGS...]]
     viro [OPTIONS] -- [ARGS...]
     viro -c EXPRESSION
-    viro --check FILE
+    viro --check [--json] FILE
+    viro fmt [--check | --write] [PATH...]
+    viro lsp
+    viro test [--run REGEXP] [--fail-fast] [--format FORMAT] [PATH...]
+    viro doc [--output DIR | --check] [PATH...]
     viro --version
     viro --help
 
@@ -18,33 +22,82 @@ MODES:
     FILE [ARGS...]      Execute script file with arguments
     -- [ARGS...]        Start REPL with arguments in system.args
     -c EXPRESSION       Evaluate expression and print result
-    --check FILE        Check syntax without executing
+    --check FILE        Check syntax and lint without executing
+    fmt [PATH...]       Format source files (standard input if none given)
+    lsp                 Run the language server on standard input and output
+    test [PATH...]      Run test cases in *_test.viro files (sandbox root if none given)
+    doc [PATH...]       Generate the function reference, with the documented functions
+                        of the scripts given, and check its examples
 
 GLOBAL OPTIONS:
     --sandbox-root PATH        Sandbox root for file operations (default: current directory)
     --allow-insecure-tls       Disable TLS certificate verification (warning: security risk)
+    --allow-exec               Allow scripts to run external programs with call
+    --compile                  Compile blocks before evaluating them (faster loops)
+    --profile                  Show a call-tree profile (calls, self and total time) on exit
+    --profile-output FILE      Also write the profile to FILE: pprof for .pprof/.pb.gz,
+                               JSON for .json, collapsed stacks (for flamegraphs) otherwise
+    --profile-allocs           Count series and frame allocations per function in the
+                               profile; stats --allocations reads them while running
+    --trace                    Enable tracing (events go to stderr)
+    --trace-file FILE          Write trace events to FILE instead (rotated at 50MB)
+    --trace-format FORMAT      Trace as json (default), tree (indented calls) or
+                               chrome (trace-event JSON for chrome://tracing, Perfetto)
+    --trace-buffer N           Keep only the last N trace events; write them out when
+                               the script fails
     --quiet                    Suppress non-error output
     --verbose                  Enable verbose output
     --help                     Show this help message
     --version                  Show version information
 
 SCRIPT OPTIONS:
-    --profile                  Enable profiling and show execution statistics
+    --coverage FILE            Record line and expression coverage; write LCOV to FILE,
+                               or HTML when FILE ends in .html (also for viro test)
+    --record FILE              Record input, file, port and URL reads and writes, file
+                               times, processes, the environment and secure random
+                               numbers to FILE (also for -c)
+    --replay FILE              Replay a recording without reading or changing stdin,
+                               files, the network or the environment, then step back
+                               and forth through its trace
 
 EVAL OPTIONS:
     --stdin                    Read additional input from stdin
     --no-print                 Don't print result of evaluation
 
+CHECK OPTIONS:
+    --json                     Report diagnostics as a JSON document
+                               Lint errors exit 1; warnings alone exit 0
+
+FMT OPTIONS:
+    --check                    List files whose formatting differs; exit 1 if any
+    --write                    Rewrite files in place instead of printing them
+                               Directories are searched for .viro files
+
+TEST OPTIONS:
+    --run REGEXP               Run only tests whose name matches
+    --fail-fast                Stop after the first failing test
+    --format FORMAT            Report as text (default), tap or junit
+    --verbose                  List passing tests and their output
+    --coverage FILE            Record coverage of the test files (see SCRIPT OPTIONS)
+                               Any failing test exits 1
+
+DOC OPTIONS:
+    --output DIR               Write the Markdown and HTML reference to DIR (default: reference)
+    --check                    Only check the examples; write nothing
+                               Examples annotated with "; =>" run as doctests; any
+                               mismatch exits 1
+
 REPL OPTIONS:
     --no-history               Disable command history
     --history-file PATH        History file location
     --prompt STRING            Custom REPL prompt
     --no-welcome               Skip welcome message
-    --trace                    Start REPL with tracing enabled
 
 ENVIRONMENT VARIABLES:
     VIRO_SANDBOX_ROOT          Default sandbox root directory
     VIRO_ALLOW_INSECURE_TLS    Allow insecure TLS (set to "1" or "true")
+    VIRO_ALLOW_EXEC            Allow call to run subprocesses (set to "1" or "true")
+    VIRO_COMPILE               Compile blocks by default (set to "1" or "true")
     VIRO_HISTORY_FILE          REPL history file location
 
 EXIT CODES:
@@ -66,8 +119,25 @@ EXAMPLES:
     # Execute script with arguments
     viro script.viro arg1 arg2
 
-    # Check syntax
+    # Check syntax and lint, or emit diagnostics for an editor
     viro --check script.viro
+    viro --check --json script.viro
+
+    # Format scripts in place, or verify formatting in CI
+    viro fmt --write src/
+    viro fmt --check src/
+
+    # Run tests, or produce a JUnit report for CI
+    viro test
+    viro test --run '^parse' --fail-fast
+    viro test --format junit tests/ > report.xml
+
+    # Generate the reference for the natives and a library, or only check examples
+    viro doc --output site lib/
+    viro doc --check
+
+    # Language server for editors (diagnostics, hover, completion, ...)
+    viro lsp
 
     # Evaluate expression
     viro -c "3 + 4"
@@ -83,8 +153,22 @@ EXAMPLES:
     >> print ["Email:" first system.args]
     >> print ["Role:" last system.args]
 
-    # Profile script execution
+    # Profile script execution; open the pprof file with go tool pprof
     viro --profile script.viro
+    viro --profile-output cpu.pprof script.viro
+    viro --profile-allocs script.viro
+
+    # Keep the last 1000 trace events and show them as a call tree on a crash
+    viro --trace-buffer 1000 --trace-format tree script.viro
+    viro --trace-format chrome --trace-file trace.json script.viro
+
+    # Record a failing run, then replay it and walk back from the error
+    viro --record run.json script.viro
+    viro --replay run.json
+
+    # Coverage as LCOV for CI tools, or as a browsable HTML page
+    viro --coverage coverage.info script.viro
+    viro test --coverage coverage.html
 
 For more information, visit: https://github.com/marcin-radoszewski/viro
 `
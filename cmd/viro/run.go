@@ -47,6 +47,16 @@ func executeModeWithContext(cfg *config.Config, ctx *api.RuntimeContext) int {
 		return runREPLWithContext(cfg, ctx)
 	case config.ModeScript, config.ModeEval, config.ModeCheck:
 		return api.RunExecutionWithContext(cfg, mode, ctx)
+	case config.ModeFmt:
+		return api.RunFormatWithContext(cfg, ctx)
+	case config.ModeLSP:
+		return api.RunLanguageServerWithContext(cfg, ctx)
+	case config.ModeTest:
+		return api.RunTestsWithContext(cfg, ctx)
+	case config.ModeReplay:
+		return api.RunReplayWithContext(cfg, ctx)
+	case config.ModeDoc:
+		return api.RunDocWithContext(cfg, ctx)
 	case config.ModeVersion:
 		fmt.Fprintf(ctx.Stdout, "%s\n", getVersionString())
 		return api.ExitSuccess
@@ -65,12 +75,21 @@ func runREPLWithContext(cfg *config.Config, ctx *api.RuntimeContext) int {
 	}
 
 	opts := &repl.Options{
-		Prompt:      cfg.Prompt,
-		NoWelcome:   cfg.NoWelcome,
-		NoHistory:   cfg.NoHistory,
-		HistoryFile: cfg.HistoryFile,
-		TraceOn:     cfg.TraceOn,
-		Args:        cfg.Args,
+		Prompt:        cfg.Prompt,
+		NoWelcome:     cfg.NoWelcome,
+		NoHistory:     cfg.NoHistory,
+		HistoryFile:   cfg.HistoryFile,
+		TraceOn:       cfg.TraceOn,
+		TraceFile:     cfg.TraceFile,
+		TraceFormat:   cfg.TraceFormat,
+		TraceBuffer:   cfg.TraceBuffer,
+		AllowExec:     cfg.AllowExec,
+		Compile:       cfg.Compile,
+		SandboxRoot:   cfg.SandboxRoot,
+		Args:          cfg.Args,
+		Profile:       cfg.Profile,
+		ProfileOutput: cfg.ProfileOutput,
+		ProfileAllocs: cfg.ProfileAllocs,
 	}
 
 	r, err := repl.NewREPLWithOptions(opts)
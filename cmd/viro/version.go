@@ -1,17 +1,18 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/marcin-radoszewski/viro/internal/version"
+)
 
 const (
-	Version   = "0.1.0"
-	BuildDate = ""
+	Version   = version.Version
+	BuildDate = version.BuildDate
 )
 
 func getVersionString() string {
-	if BuildDate != "" {
-		return fmt.Sprintf("Viro %s (built %s)", Version, BuildDate)
-	}
-	return fmt.Sprintf("Viro %s", Version)
+	return version.String()
 }
 
 func printVersion() {
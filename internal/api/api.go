@@ -5,19 +5,21 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/marcin-radoszewski/viro/internal/bootstrap"
 	"github.com/marcin-radoszewski/viro/internal/config"
 	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/coverage"
 	"github.com/marcin-radoszewski/viro/internal/debug"
 	"github.com/marcin-radoszewski/viro/internal/eval"
-	"github.com/marcin-radoszewski/viro/internal/frame"
 	"github.com/marcin-radoszewski/viro/internal/native"
 	"github.com/marcin-radoszewski/viro/internal/parse"
 	"github.com/marcin-radoszewski/viro/internal/profile"
 	"github.com/marcin-radoszewski/viro/internal/trace"
 	"github.com/marcin-radoszewski/viro/internal/value"
 	"github.com/marcin-radoszewski/viro/internal/verror"
+	"github.com/marcin-radoszewski/viro/internal/version"
 )
 
 type RuntimeContext struct {
@@ -36,6 +38,11 @@ const (
 	ModeCheck   = config.ModeCheck
 	ModeVersion = config.ModeVersion
 	ModeHelp    = config.ModeHelp
+	ModeFmt     = config.ModeFmt
+	ModeLSP     = config.ModeLSP
+	ModeTest    = config.ModeTest
+	ModeReplay  = config.ModeReplay
+	ModeDoc     = config.ModeDoc
 )
 
 type Config = config.Config
@@ -129,8 +136,18 @@ func Run(ctx *RuntimeContext, cfg *Config) int {
 		return ExitError
 	case ModeScript, ModeEval, ModeCheck:
 		return RunExecutionWithContext(cfg, mode, ctx)
+	case ModeFmt:
+		return RunFormatWithContext(cfg, ctx)
+	case ModeLSP:
+		return RunLanguageServerWithContext(cfg, ctx)
+	case ModeTest:
+		return RunTestsWithContext(cfg, ctx)
+	case ModeReplay:
+		return RunReplayWithContext(cfg, ctx)
+	case ModeDoc:
+		return RunDocWithContext(cfg, ctx)
 	case ModeVersion:
-		fmt.Fprintf(ctx.Stdout, "%s\n", "Viro 0.1.0")
+		fmt.Fprintf(ctx.Stdout, "%s\n", version.String())
 		return ExitSuccess
 	case ModeHelp:
 		fmt.Fprintf(ctx.Stdout, "%s", "Viro help text")
@@ -148,6 +165,18 @@ func detectMode(cfg *Config) Mode {
 	if cfg.ShowHelp {
 		return ModeHelp
 	}
+	if cfg.Command == config.CommandFmt {
+		return ModeFmt
+	}
+	if cfg.Command == config.CommandLSP {
+		return ModeLSP
+	}
+	if cfg.Command == config.CommandTest {
+		return ModeTest
+	}
+	if cfg.Command == config.CommandDoc {
+		return ModeDoc
+	}
 	if cfg.EvalExpr != "" {
 		return ModeEval
 	}
@@ -157,33 +186,23 @@ func detectMode(cfg *Config) Mode {
 	if cfg.ScriptFile != "" {
 		return ModeScript
 	}
+	if cfg.Replay != "" {
+		return ModeReplay
+	}
 	return ModeREPL
 }
 
 func RunExecutionWithContext(cfg *Config, mode Mode, ctx *RuntimeContext) int {
-	if cfg.Profile && mode == ModeEval {
-		fmt.Fprintf(ctx.Stderr, "Error: --profile flag requires a script file, not -c expression\n")
+	if cfg.Coverage != "" && mode != ModeScript {
+		fmt.Fprintf(ctx.Stderr, "Error: --coverage flag requires a script file\n")
 		return ExitUsage
 	}
 
-	var err error
-	if cfg.Profile {
-		err = bootstrap.InitTrace(true)
-	} else {
-		err = bootstrap.InitTrace(false)
-	}
-
-	if err != nil {
+	if err := bootstrap.InitTraceWithFormat(cfg.TraceFile, cfg.TraceFormat, cfg.TraceBuffer); err != nil {
 		fmt.Fprintf(ctx.Stderr, "Error initializing trace: %v\n", err)
 		return ExitInternal
 	}
 
-	var profiler *profile.Profiler
-	if cfg.Profile && trace.GlobalTraceSession != nil {
-		profiler = profile.NewProfiler()
-		profile.EnableProfilingWithTrace(trace.GlobalTraceSession, profiler)
-	}
-
 	var input InputSource
 
 	switch mode {
@@ -207,14 +226,6 @@ func RunExecutionWithContext(cfg *Config, mode Mode, ctx *RuntimeContext) int {
 
 	exitCode := executeViroCodeWithContext(cfg, input, args, printResult, parseOnly, ctx)
 
-	if profiler != nil {
-		profiler.Disable()
-		if !cfg.Quiet {
-			report := profiler.GetReport()
-			report.FormatText(ctx.Stderr)
-		}
-	}
-
 	if trace.GlobalTraceSession != nil {
 		trace.GlobalTraceSession.Close()
 	}
@@ -222,7 +233,7 @@ func RunExecutionWithContext(cfg *Config, mode Mode, ctx *RuntimeContext) int {
 	return exitCode
 }
 
-func executeViroCodeWithContext(cfg *Config, input InputSource, args []string, printResult bool, parseOnly bool, ctx *RuntimeContext) int {
+func executeViroCodeWithContext(cfg *Config, input InputSource, args []string, printResult bool, parseOnly bool, ctx *RuntimeContext) (exitCode int) {
 	content, err := input.Load()
 	if err != nil {
 		fmt.Fprintf(ctx.Stderr, "Error loading input: %v\n", err)
@@ -230,30 +241,110 @@ func executeViroCodeWithContext(cfg *Config, input InputSource, args []string, p
 	}
 
 	sourceName := input.SourceName()
+	if parseOnly {
+		return checkSource(cfg, content, sourceName, ctx)
+	}
+
 	values, locations, err := parse.ParseWithSource(content, sourceName)
 	if err != nil {
 		printErrorToWriter(err, "Parse", ctx.Stderr)
 		return ExitSyntax
 	}
 
-	if parseOnly {
-		if cfg.Verbose {
-			fmt.Fprintf(ctx.Stdout, "✓ Syntax valid\n")
-			fmt.Fprintf(ctx.Stdout, "  Parsed %d expressions\n", len(values))
-		}
-		return ExitSuccess
+	if err := eval.InitSandbox(cfg.SandboxRoot); err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error initializing sandbox: %v\n", err)
+		return ExitAccess
 	}
+	native.SandboxRoot = eval.SandboxRoot
+	native.AllowExec = cfg.AllowExec
 
 	evaluator := setupEvaluatorWithContext(cfg, ctx)
-	initializeSystemObjectInEvaluator(evaluator, args)
+
+	if cfg.Coverage != "" {
+		recorder := coverage.NewRecorder()
+		recorder.AddFile(sourceName, content, values, locations, evaluator.GetFrameByIndex(0))
+		evaluator.SetCoverageHook(recorder.Hit)
+		defer func() {
+			if code := writeCoverage(cfg, ctx, recorder); exitCode == ExitSuccess {
+				exitCode = code
+			}
+		}()
+	}
+
+	replayInput, replaying := input.(*ReplayInput)
+	_, isFile := input.(*FileInput)
+	if replaying {
+		isFile = replayInput.Recording.Script
+	}
+
+	info := bootstrap.SystemInfo{Args: args, Options: cfg}
+	if replaying {
+		system := replayInput.Recording.System
+		info.Cwd, info.OS, info.Arch = system.Cwd, system.OS, system.Arch
+	}
+	info = info.WithDefaults()
+	if isFile {
+		info.ScriptPath = sourceName
+
+		var header *value.BlockValue
+		header, values, locations = splitScriptHeader(values, locations)
+		if header != nil {
+			headerObj, err := native.Object([]core.Value{header}, nil, evaluator)
+			if err != nil {
+				printErrorToWriter(err, "Runtime", ctx.Stderr)
+				return HandleErrorWithContext(err)
+			}
+			info.ScriptHeader = headerObj
+		}
+	}
+	bootstrap.InjectSystem(evaluator, info)
+
+	if cfg.Profile {
+		tree := profile.NewCallTree("(top level)")
+		evaluator.SetCallObserver(tree)
+		evaluator.SetAllocationAccounting(cfg.ProfileAllocs)
+		tree.Start()
+		defer func() {
+			tree.Stop()
+			evaluator.SetAllocationAccounting(false)
+			if code := writeProfile(cfg, ctx, tree); exitCode == ExitSuccess {
+				exitCode = code
+			}
+		}()
+	}
+
+	if cfg.TraceOn {
+		trace.GlobalTraceSession.Enable(trace.TraceFilters{})
+		evaluator.UpdateTraceCache()
+	}
+
+	if replaying {
+		startReplay(evaluator, replayInput)
+	} else if cfg.Record != "" {
+		recorder := startRecording(evaluator, content, sourceName, isFile, printResult, info)
+		defer func() {
+			if err := recorder.Finish(exitCode).Save(cfg.Record); err != nil {
+				fmt.Fprintf(ctx.Stderr, "Error writing recording: %v\n", err)
+				if exitCode == ExitSuccess {
+					exitCode = ExitError
+				}
+			}
+		}()
+	}
 
 	result, err := evaluator.DoBlock(values, locations)
 	if err != nil {
 		if returnSig, ok := err.(*eval.ReturnSignal); ok {
 			result = returnSig.Value()
 			err = nil
+		} else if exitSig, ok := err.(*eval.ExitSignal); ok {
+			return exitSig.Code()
 		} else {
 			err = verror.ConvertLoopControlSignal(err)
+			// A ring-buffer trace holds the events leading up to the error.
+			if dumpErr := trace.GlobalTraceSession.Dump(); dumpErr != nil {
+				fmt.Fprintf(ctx.Stderr, "Error writing trace: %v\n", dumpErr)
+			}
 			printErrorToWriter(err, "Runtime", ctx.Stderr)
 			return HandleErrorWithContext(err)
 		}
@@ -268,6 +359,7 @@ func executeViroCodeWithContext(cfg *Config, input InputSource, args []string, p
 
 func setupEvaluatorWithContext(cfg *Config, ctx *RuntimeContext) *eval.Evaluator {
 	evaluator := eval.NewEvaluator()
+	evaluator.SetCompile(cfg.Compile)
 
 	if cfg.Quiet {
 		evaluator.SetOutputWriter(io.Discard)
@@ -292,21 +384,24 @@ func setupEvaluatorWithContext(cfg *Config, ctx *RuntimeContext) *eval.Evaluator
 	return evaluator
 }
 
-func initializeSystemObjectInEvaluator(evaluator *eval.Evaluator, args []string) {
-	viroArgs := make([]core.Value, len(args))
-	for i, arg := range args {
-		viroArgs[i] = value.NewStringValue(arg)
+// splitScriptHeader detects an optional `Viro [...]` header at the start of a
+// script and returns the header block along with the remaining code.
+func splitScriptHeader(values []core.Value, locations []core.SourceLocation) (*value.BlockValue, []core.Value, []core.SourceLocation) {
+	if len(values) < 2 {
+		return nil, values, locations
 	}
-
-	argsBlock := value.NewBlockValue(viroArgs)
-
-	ownedFrame := frame.NewFrame(frame.FrameObject, -1)
-	ownedFrame.Bind("args", argsBlock)
-
-	systemObj := value.NewObject(ownedFrame)
-
-	rootFrame := evaluator.GetFrameByIndex(0)
-	rootFrame.Bind("system", systemObj)
+	word, ok := value.AsWordValue(values[0])
+	if !ok || values[0].GetType() != value.TypeWord || !strings.EqualFold(word, "viro") {
+		return nil, values, locations
+	}
+	header, ok := value.AsBlockValue(values[1])
+	if !ok || values[1].GetType() != value.TypeBlock {
+		return nil, values, locations
+	}
+	if len(locations) >= 2 {
+		locations = locations[2:]
+	}
+	return header, values[2:], locations
 }
 
 func HandleErrorWithContext(err error) int {
@@ -314,6 +409,10 @@ func HandleErrorWithContext(err error) int {
 		return ExitSuccess
 	}
 
+	if exitSig, ok := err.(*eval.ExitSignal); ok {
+		return exitSig.Code()
+	}
+
 	if vErr, ok := err.(*verror.Error); ok {
 		return verror.ToExitCode(vErr.Category)
 	}
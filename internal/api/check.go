@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/lint"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+)
+
+// checkReport is the JSON document `viro --check --json` prints.
+type checkReport struct {
+	File        string            `json:"file"`
+	Valid       bool              `json:"valid"`
+	Errors      int               `json:"errors"`
+	Warnings    int               `json:"warnings"`
+	Diagnostics []lint.Diagnostic `json:"diagnostics"`
+}
+
+// checkSource implements --check: it parses the script and runs the lint
+// pass over it without executing anything. Syntax errors exit with
+// ExitSyntax, lint errors with ExitError; warnings alone still succeed.
+func checkSource(cfg *Config, content, sourceName string, ctx *RuntimeContext) int {
+	values, locations, err := parse.ParseWithSource(content, sourceName)
+	if err != nil {
+		if cfg.CheckJSON {
+			writeCheckReport(ctx.Stdout, sourceName, []lint.Diagnostic{lint.SyntaxDiagnostic(err, sourceName)})
+		} else {
+			printErrorToWriter(err, "Parse", ctx.Stderr)
+		}
+		return ExitSyntax
+	}
+
+	evaluator := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+	bootstrap.InjectSystem(evaluator, bootstrap.SystemInfo{Options: cfg})
+	diagnostics := lint.Check(values, locations, evaluator.GetFrameByIndex(0))
+
+	if cfg.CheckJSON {
+		writeCheckReport(ctx.Stdout, sourceName, diagnostics)
+	} else {
+		for _, d := range diagnostics {
+			fmt.Fprintln(ctx.Stdout, d.String())
+		}
+		if cfg.Verbose && !lint.HasErrors(diagnostics) {
+			fmt.Fprintf(ctx.Stdout, "✓ Syntax valid\n")
+			fmt.Fprintf(ctx.Stdout, "  Parsed %d expressions\n", len(values))
+		}
+	}
+
+	if lint.HasErrors(diagnostics) {
+		return ExitError
+	}
+	return ExitSuccess
+}
+
+func writeCheckReport(w io.Writer, sourceName string, diagnostics []lint.Diagnostic) {
+	report := checkReport{File: sourceName, Valid: !lint.HasErrors(diagnostics), Diagnostics: diagnostics}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []lint.Diagnostic{}
+	}
+	for _, d := range diagnostics {
+		if d.Severity == lint.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	data, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Fprintln(w, string(data))
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/coverage"
+)
+
+// writeCoverage writes the --coverage report, as HTML when the file name
+// ends in .html or .htm and as LCOV otherwise, and prints a summary to
+// standard error.
+func writeCoverage(cfg *Config, ctx *RuntimeContext, recorder *coverage.Recorder) int {
+	report := recorder.Report()
+
+	out, err := os.Create(cfg.Coverage)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error writing coverage: %v\n", err)
+		return ExitError
+	}
+	switch strings.ToLower(filepath.Ext(cfg.Coverage)) {
+	case ".html", ".htm":
+		err = report.WriteHTML(out)
+	default:
+		err = report.WriteLCOV(out)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error writing coverage: %v\n", err)
+		return ExitError
+	}
+
+	if !cfg.Quiet {
+		report.WriteText(ctx.Stderr)
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/docgen"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/trace"
+	"github.com/marcin-radoszewski/viro/internal/value"
+)
+
+// docTitle is the title of the generated reference.
+const docTitle = "Viro Reference"
+
+// docScript is a script given to `viro doc` for its user functions.
+type docScript struct {
+	file    sourceFile
+	content string
+}
+
+// RunDocWithContext implements `viro doc`. It documents every native, and
+// the documented functions of the scripts given, as a Markdown and HTML
+// reference in --output. The scripts are not run to find their functions.
+// Every example annotated with `; =>` runs as a doctest, in a fresh evaluator
+// with an empty temporary sandbox; a mismatch exits with ExitError. --check
+// runs the doctests without writing the site.
+func RunDocWithContext(cfg *Config, ctx *RuntimeContext) int {
+	if err := eval.InitSandbox(cfg.SandboxRoot); err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error initializing sandbox: %v\n", err)
+		return ExitAccess
+	}
+	native.SandboxRoot = eval.SandboxRoot
+	trace.InitTraceSilent()
+
+	entries := docgen.Collect(docEvaluator(cfg).GetFrameByIndex(0), nil)
+	scripts := make(map[string]docScript)
+	if len(cfg.Args) > 0 {
+		files, err := collectSourceFiles(cfg, cfg.Args, isViroFile)
+		if err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error: %v\n", err)
+			return ExitError
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file.path)
+			if err != nil {
+				fmt.Fprintf(ctx.Stderr, "Error loading input: %v\n", err)
+				return ExitError
+			}
+			script := docScript{file: file, content: string(content)}
+			values, _, err := parse.ParseWithSource(script.content, file.name)
+			if err != nil {
+				printErrorToWriter(err, "Parse", ctx.Stderr)
+				return HandleErrorWithContext(err)
+			}
+			for _, e := range scriptEntries(values) {
+				if e.Category == "" {
+					e.Category = strings.TrimSuffix(filepath.Base(file.name), ".viro")
+				}
+				e.Source = file.name
+				entries = append(entries, e)
+			}
+			scripts[file.name] = script
+		}
+		docgen.SortEntries(entries)
+	}
+
+	if !cfg.Quiet {
+		for _, ref := range docgen.UnknownSeeAlso(entries) {
+			fmt.Fprintf(ctx.Stderr, "Warning: see also of an undocumented function: %s\n", ref)
+		}
+	}
+
+	run, failed, skipped := 0, 0, 0
+	for _, e := range entries {
+		for i, example := range e.Doc.Examples {
+			if checks, _ := docgen.ParseExample(example); len(checks) == 0 {
+				skipped++
+				continue
+			}
+			mismatch, err := runDoctest(cfg, e, i+1, example, scripts[e.Source])
+			if err != nil {
+				fmt.Fprintf(ctx.Stderr, "Error: %v\n", err)
+				return ExitError
+			}
+			run++
+			if mismatch != nil {
+				failed++
+				fmt.Fprintf(ctx.Stderr, "FAIL %s\n", mismatch)
+			}
+		}
+	}
+
+	if !cfg.DocCheck {
+		if err := docgen.WriteSite(cfg.DocOutput, docTitle, entries); err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error writing reference: %v\n", err)
+			return ExitError
+		}
+	}
+	if !cfg.Quiet || failed > 0 {
+		fmt.Fprintf(ctx.Stderr, "%d functions, %d examples checked, %d failed, %d without results\n", len(entries), run, failed, skipped)
+		if !cfg.DocCheck {
+			fmt.Fprintf(ctx.Stderr, "wrote %s\n", filepath.Join(cfg.DocOutput, "index.html"))
+		}
+	}
+	if failed > 0 {
+		return ExitError
+	}
+	return ExitSuccess
+}
+
+// docEvaluator returns an evaluator with no input whose output is
+// discarded.
+func docEvaluator(cfg *Config) *eval.Evaluator {
+	return setupEvaluatorWithContext(cfg, &RuntimeContext{Stdin: strings.NewReader(""), Stdout: io.Discard, Stderr: io.Discard})
+}
+
+// scriptEntries returns the documented functions that a script defines as
+// `name: fn [spec] [body]`, at its top level or in an object made there with
+// `name: object [...]` or `name: context [...]`. It reads the parsed script
+// without running it.
+func scriptEntries(values []core.Value) []docgen.Entry {
+	var entries []docgen.Entry
+	var walk func(prefix string, values []core.Value)
+	walk = func(prefix string, values []core.Value) {
+		for i := 0; i+2 < len(values); i++ {
+			if values[i].GetType() != value.TypeSetWord || values[i+1].GetType() != value.TypeWord || values[i+2].GetType() != value.TypeBlock {
+				continue
+			}
+			name, _ := value.AsWordValue(values[i])
+			maker, _ := value.AsWordValue(values[i+1])
+			block, _ := value.AsBlockValue(values[i+2])
+			switch {
+			case maker == "fn":
+				if doc := native.ParseFuncDoc(block); doc.HasDoc() {
+					entries = append(entries, docgen.Entry{Name: prefix + name, Category: doc.Category, Doc: doc})
+				}
+			case (maker == "object" || maker == "context") && prefix == "":
+				walk(name+".", block.Elements)
+			}
+		}
+	}
+	walk("", values)
+	return entries
+}
+
+// loadDocScript evaluates a script in a new evaluator, defining its
+// functions for a doctest. A script that ends with exit or return is loaded
+// up to there.
+func loadDocScript(cfg *Config, script docScript) (*eval.Evaluator, error) {
+	values, locations, err := parse.ParseWithSource(script.content, script.file.name)
+	if err != nil {
+		return nil, err
+	}
+	evaluator := docEvaluator(cfg)
+	info := bootstrap.SystemInfo{Args: []string{}, Options: cfg, ScriptPath: script.file.path}
+	var header *value.BlockValue
+	header, values, locations = splitScriptHeader(values, locations)
+	if header != nil {
+		headerObj, err := native.Object([]core.Value{header}, nil, evaluator)
+		if err != nil {
+			return nil, err
+		}
+		info.ScriptHeader = headerObj
+	}
+	bootstrap.InjectSystem(evaluator, info)
+
+	if _, err := evaluator.DoBlock(values, locations); err != nil {
+		switch err.(type) {
+		case *eval.ReturnSignal, *eval.ExitSignal:
+		default:
+			return nil, err
+		}
+	}
+	return evaluator, nil
+}
+
+// runDoctest runs one example in a temporary sandbox, which is removed
+// afterwards. Examples of user functions run after their script is loaded
+// there.
+func runDoctest(cfg *Config, e docgen.Entry, index int, example string, script docScript) (*docgen.Mismatch, error) {
+	dir, err := os.MkdirTemp("", "viro-doctest-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := eval.InitSandbox(dir); err != nil {
+		return nil, err
+	}
+	native.SandboxRoot = eval.SandboxRoot
+
+	var evaluator *eval.Evaluator
+	if e.Source == "" {
+		evaluator = docEvaluator(cfg)
+	} else if evaluator, err = loadDocScript(cfg, script); err != nil {
+		return &docgen.Mismatch{Function: e.Name, Example: index, Code: "(loading " + e.Source + ")", Err: err}, nil
+	}
+	mismatch, _ := docgen.RunExample(e.Name, index, example, evaluator)
+	return mismatch, nil
+}
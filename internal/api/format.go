@@ -0,0 +1,135 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/marcin-radoszewski/viro/internal/format"
+)
+
+// RunFormatWithContext implements `viro fmt`. With no files it formats
+// standard input to standard output. Directories are searched for .viro
+// files. --check lists the files whose formatting differs and exits with
+// ExitError; --write rewrites them in place.
+func RunFormatWithContext(cfg *Config, ctx *RuntimeContext) int {
+	if len(cfg.Args) == 0 {
+		if cfg.FormatWrite {
+			fmt.Fprintf(ctx.Stderr, "Error: fmt --write requires at least one file\n")
+			return ExitUsage
+		}
+		data, err := io.ReadAll(ctx.Stdin)
+		if err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error loading input: %v\n", err)
+			return ExitError
+		}
+		return formatOne(cfg, ctx, "(stdin)", string(data), nil)
+	}
+
+	files, err := collectSourceFiles(cfg, cfg.Args, isViroFile)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+
+	exitCode := ExitSuccess
+	for _, file := range files {
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error loading input: %v\n", err)
+			exitCode = max(exitCode, ExitError)
+			continue
+		}
+		exitCode = max(exitCode, formatOne(cfg, ctx, file.name, string(data), &file))
+	}
+	return exitCode
+}
+
+type sourceFile struct {
+	// name is the path as shown to the user; path is the resolved path.
+	name string
+	path string
+	mode fs.FileMode
+}
+
+func isViroFile(path string) bool {
+	return filepath.Ext(path) == ".viro"
+}
+
+// collectSourceFiles expands the command-line arguments into files,
+// walking directories for the files accepted by match. Files named
+// explicitly are always included.
+func collectSourceFiles(cfg *Config, args []string, match func(path string) bool) ([]sourceFile, error) {
+	var files []sourceFile
+	for _, arg := range args {
+		path := arg
+		if !filepath.IsAbs(path) && cfg.SandboxRoot != "" {
+			path = filepath.Join(cfg.SandboxRoot, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, sourceFile{name: arg, path: path, mode: info.Mode().Perm()})
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !match(p) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(path, p)
+			files = append(files, sourceFile{name: filepath.Join(arg, rel), path: p, mode: info.Mode().Perm()})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// formatOne formats one input and reports or writes the result. file is nil
+// for standard input.
+func formatOne(cfg *Config, ctx *RuntimeContext, name, content string, file *sourceFile) int {
+	formatted, err := format.Source(content, name)
+	if err != nil {
+		printErrorToWriter(err, "Format", ctx.Stderr)
+		if HandleErrorWithContext(err) == ExitSyntax {
+			return ExitSyntax
+		}
+		return ExitInternal
+	}
+
+	changed := formatted != content
+	switch {
+	case cfg.FormatCheck:
+		if !changed {
+			return ExitSuccess
+		}
+		if !cfg.Quiet {
+			fmt.Fprintln(ctx.Stdout, name)
+		}
+		return ExitError
+	case cfg.FormatWrite:
+		if !changed {
+			return ExitSuccess
+		}
+		if err := os.WriteFile(file.path, []byte(formatted), file.mode); err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error writing %s: %v\n", name, err)
+			return ExitError
+		}
+		return ExitSuccess
+	}
+	fmt.Fprint(ctx.Stdout, formatted)
+	return ExitSuccess
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/lsp"
+)
+
+// RunLanguageServerWithContext implements `viro lsp`: it serves the
+// Language Server Protocol on standard input and output until the client
+// exits. Documents are only analysed, never executed.
+func RunLanguageServerWithContext(cfg *Config, ctx *RuntimeContext) int {
+	evaluator := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+	bootstrap.InjectSystem(evaluator, bootstrap.SystemInfo{Options: cfg})
+
+	server := lsp.NewServer(evaluator.GetFrameByIndex(0))
+	if err := server.Serve(ctx.Stdin, ctx.Stdout); err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/marcin-radoszewski/viro/internal/profile"
+)
+
+// writeProfile prints the --profile report to standard error and, with
+// --profile-output, writes the profile to that file.
+func writeProfile(cfg *Config, ctx *RuntimeContext, tree *profile.CallTree) int {
+	if !cfg.Quiet {
+		tree.FormatText(ctx.Stderr)
+	}
+	if cfg.ProfileOutput != "" {
+		if err := tree.WriteFile(cfg.ProfileOutput); err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error writing profile: %v\n", err)
+			return ExitError
+		}
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/replay"
+	"github.com/marcin-radoszewski/viro/internal/trace"
+)
+
+// ReplayInput is the program stored in a recording.
+type ReplayInput struct {
+	Recording *replay.Recording
+	player    *replay.Player
+	baseline  map[string]string // words bound before the program ran
+}
+
+func (r *ReplayInput) Load() (string, error) {
+	return r.Recording.Source, nil
+}
+
+func (r *ReplayInput) SourceName() string {
+	return r.Recording.SourceName
+}
+
+// RunReplayWithContext implements --replay. It reruns the recorded program
+// with every recorded native answered from the recording and the random
+// generator reseeded, tracing each step with its frame, then lets the user
+// move back and forth through the steps.
+func RunReplayWithContext(cfg *Config, ctx *RuntimeContext) int {
+	rec, err := replay.Load(cfg.Replay)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error loading recording: %v\n", err)
+		return ExitError
+	}
+
+	trace.InitTraceSilent()
+	events := trace.NewMemorySink()
+	trace.GlobalTraceSession.SetSink(events)
+
+	input := &ReplayInput{Recording: rec}
+	exitCode := executeViroCodeWithContext(cfg, input, rec.Args, rec.PrintResult, false, ctx)
+	trace.GlobalTraceSession.Disable()
+
+	if exitCode != rec.ExitCode {
+		fmt.Fprintf(ctx.Stderr, "Warning: replay exited with %d, the recorded run with %d\n", exitCode, rec.ExitCode)
+	}
+	if input.player != nil && input.player.Remaining() > 0 {
+		fmt.Fprintf(ctx.Stderr, "Warning: %d recorded calls were not replayed\n", input.player.Remaining())
+	}
+
+	replay.NewBrowser(events.Events(), input.baseline, ctx.Stdout).Run(ctx.Stdin)
+	trace.GlobalTraceSession.Close()
+	return exitCode
+}
+
+// startReplay answers the recorded natives from the recording and traces
+// every step of the run.
+func startReplay(evaluator *eval.Evaluator, input *ReplayInput) {
+	input.player = replay.NewPlayer(input.Recording)
+	input.player.Install(evaluator.GetFrameByIndex(0))
+	evaluator.SeedRandom(input.Recording.Seed)
+
+	bindings := evaluator.GetFrameByIndex(0).GetAll()
+	input.baseline = make(map[string]string, len(bindings))
+	for _, binding := range bindings {
+		input.baseline[binding.Symbol] = binding.Value.Form()
+	}
+
+	trace.GlobalTraceSession.ResetStepCounter()
+	trace.GlobalTraceSession.Enable(trace.TraceFilters{Verbose: true, StepLevel: 1, IncludeArgs: true})
+	evaluator.UpdateTraceCache()
+}
+
+// startRecording records the recorded natives' results, the system object's
+// working directory and platform, and the random seed so the run can be
+// replayed with --replay.
+func startRecording(evaluator *eval.Evaluator, content, sourceName string, script, printResult bool, info bootstrap.SystemInfo) *replay.Recorder {
+	seed := rand.Uint64()
+	evaluator.SeedRandom(seed)
+	system := replay.System{Cwd: info.Cwd, OS: info.OS, Arch: info.Arch}
+	recorder := replay.NewRecorder(content, sourceName, script, printResult, info.Args, system, seed)
+	recorder.Install(evaluator.GetFrameByIndex(0))
+	return recorder
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/coverage"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/testrun"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func isTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.viro")
+}
+
+// RunTestsWithContext implements `viro test`. It finds *_test.viro files
+// under the sandbox root, or under the paths given, loads each one in its
+// own evaluator and runs the `test` cases it declares, each in a fresh
+// frame. It exits with ExitError when any case fails.
+func RunTestsWithContext(cfg *Config, ctx *RuntimeContext) int {
+	if err := eval.InitSandbox(cfg.SandboxRoot); err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error initializing sandbox: %v\n", err)
+		return ExitAccess
+	}
+	native.SandboxRoot = eval.SandboxRoot
+	native.AllowExec = cfg.AllowExec
+
+	paths := cfg.Args
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := collectSourceFiles(cfg, paths, isTestFile)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	if len(files) == 0 {
+		if !cfg.Quiet {
+			fmt.Fprintf(ctx.Stderr, "no test files found\n")
+		}
+		return ExitSuccess
+	}
+
+	filter, err := regexp.Compile(cfg.TestRun)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Error: invalid --run pattern: %v\n", err)
+		return ExitUsage
+	}
+
+	var recorder *coverage.Recorder
+	if cfg.Coverage != "" {
+		recorder = coverage.NewRecorder()
+	}
+
+	report := &testrun.Report{}
+	for _, file := range files {
+		result := runTestFile(cfg, ctx, file, filter, recorder)
+		report.Files = append(report.Files, result)
+		if cfg.TestFailFast && fileFailed(result) {
+			break
+		}
+	}
+
+	_, failed := report.Counts()
+	format := cfg.TestFormat
+	if format == "" {
+		format = testrun.FormatText
+	}
+	if !(cfg.Quiet && failed == 0 && format == testrun.FormatText) {
+		if err := report.Write(ctx.Stdout, format, cfg.Verbose); err != nil {
+			fmt.Fprintf(ctx.Stderr, "Error writing report: %v\n", err)
+			return ExitError
+		}
+	}
+	if recorder != nil {
+		if code := writeCoverage(cfg, ctx, recorder); code != ExitSuccess {
+			return code
+		}
+	}
+	if failed > 0 {
+		return ExitError
+	}
+	return ExitSuccess
+}
+
+func fileFailed(f testrun.File) bool {
+	if f.LoadError != nil {
+		return true
+	}
+	for _, c := range f.Cases {
+		if c.Failure != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// testCase is a case collected while its file loads.
+type testCase struct {
+	name string
+	body *value.BlockValue
+	loc  core.SourceLocation
+}
+
+// runTestFile loads one test file and runs the cases it declares. Loading
+// evaluates the top level with `test` rebound to a collector, so helpers
+// and fixtures defined there are visible to every case. recorder, when not
+// nil, records the file's coverage.
+func runTestFile(cfg *Config, ctx *RuntimeContext, file sourceFile, filter *regexp.Regexp, recorder *coverage.Recorder) testrun.File {
+	start := time.Now()
+	result := testrun.File{Path: file.name}
+	defer func() { result.Duration = time.Since(start) }()
+
+	content, err := (&FileInput{Config: cfg, Path: file.path}).Load()
+	if err != nil {
+		result.LoadError = &testrun.Failure{Kind: "load", Message: err.Error()}
+		return result
+	}
+
+	values, locations, err := parse.ParseWithSource(content, file.name)
+	if err != nil {
+		result.LoadError = testFailure(err)
+		return result
+	}
+
+	var output bytes.Buffer
+	evaluator := setupEvaluatorWithContext(cfg, &RuntimeContext{Stdin: ctx.Stdin, Stdout: &output, Stderr: &output})
+	if recorder != nil {
+		recorder.AddFile(file.name, content, values, locations, evaluator.GetFrameByIndex(0))
+		evaluator.SetCoverageHook(recorder.Hit)
+	}
+
+	info := bootstrap.SystemInfo{Args: []string{}, Options: cfg, ScriptPath: file.path}
+	var header *value.BlockValue
+	header, values, locations = splitScriptHeader(values, locations)
+	if header != nil {
+		headerObj, err := native.Object([]core.Value{header}, nil, evaluator)
+		if err != nil {
+			result.LoadError = testFailure(err)
+			return result
+		}
+		info.ScriptHeader = headerObj
+	}
+	bootstrap.InjectSystem(evaluator, info)
+
+	var cases []testCase
+	collect := func(args []core.Value, refValues map[string]core.Value, _ core.Evaluator) (core.Value, error) {
+		name, ok := value.AsStringValue(args[0])
+		if !ok {
+			return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"test", "string", value.TypeToString(args[0].GetType())})
+		}
+		body, ok := value.AsBlockValue(args[1])
+		if !ok {
+			return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"test", "block", value.TypeToString(args[1].GetType())})
+		}
+		cases = append(cases, testCase{name: name.String(), body: body, loc: testLocation(values, locations, body)})
+		return value.NewNoneVal(), nil
+	}
+	evaluator.GetFrameByIndex(0).Bind("test", value.NewNativeFunction("test", []value.ParamSpec{
+		value.NewParamSpec("name", true),
+		value.NewParamSpec("body", false),
+	}, collect, false, nil))
+
+	if _, err := evaluator.DoBlock(values, locations); err != nil {
+		if _, ok := err.(*eval.ReturnSignal); !ok {
+			result.LoadError = testFailure(err)
+			return result
+		}
+	}
+
+	for _, tc := range cases {
+		if !filter.MatchString(tc.name) {
+			continue
+		}
+		c := runTestCase(evaluator, &output, tc)
+		c.File = file.name
+		result.Cases = append(result.Cases, c)
+		if cfg.TestFailFast && c.Failure != nil {
+			break
+		}
+	}
+	return result
+}
+
+func runTestCase(evaluator *eval.Evaluator, output *bytes.Buffer, tc testCase) testrun.Case {
+	output.Reset()
+	start := time.Now()
+	_, err := native.DoInNewFrame(evaluator, tc.body, "test "+tc.name)
+	c := testrun.Case{Name: tc.name, Line: tc.loc.Line, Duration: time.Since(start), Output: output.String()}
+	if err != nil {
+		if _, ok := err.(*eval.ReturnSignal); !ok {
+			c.Failure = testFailure(err)
+		}
+	}
+	return c
+}
+
+// testLocation finds where a case is declared: the `test` word of a
+// top-level call, or else the first line of its body.
+func testLocation(values []core.Value, locations []core.SourceLocation, body *value.BlockValue) core.SourceLocation {
+	for i, v := range values {
+		if v == core.Value(body) && i >= 2 && i-2 < len(locations) {
+			return locations[i-2]
+		}
+	}
+	if locs := body.Locations(); len(locs) > 0 {
+		return locs[0]
+	}
+	return core.SourceLocation{}
+}
+
+func testFailure(err error) *testrun.Failure {
+	if exitSig, ok := err.(*eval.ExitSignal); ok {
+		return &testrun.Failure{Kind: "exit", Message: fmt.Sprintf("exit called with code %d", exitSig.Code())}
+	}
+	err = verror.ConvertLoopControlSignal(err)
+	vErr, ok := err.(*verror.Error)
+	if !ok {
+		return &testrun.Failure{Message: err.Error()}
+	}
+	return &testrun.Failure{Kind: vErr.ID, Message: vErr.Message, File: vErr.File, Line: vErr.Line, Column: vErr.Column}
+}
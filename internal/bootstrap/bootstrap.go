@@ -6,10 +6,8 @@ import (
 	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/debug"
 	"github.com/marcin-radoszewski/viro/internal/eval"
-	"github.com/marcin-radoszewski/viro/internal/frame"
 	"github.com/marcin-radoszewski/viro/internal/native"
 	"github.com/marcin-radoszewski/viro/internal/trace"
-	"github.com/marcin-radoszewski/viro/internal/value"
 )
 
 // InitTrace initializes the global trace session.
@@ -27,6 +25,13 @@ func InitTraceWithOutput(profile bool, output string) error {
 	return trace.InitTrace(output, 50) // default 50MB max size
 }
 
+// InitTraceWithFormat initializes the global trace session writing format
+// to output, or to stderr when output is empty. When buffer is positive only
+// the last buffer events are kept until the session is dumped.
+func InitTraceWithFormat(output, format string, buffer int) error {
+	return trace.InitTraceWithFormat(output, 50, format, buffer) // default 50MB max size
+}
+
 // InitDebugger initializes the global debugger.
 // This should be called once at application startup.
 func InitDebugger() {
@@ -64,20 +69,7 @@ func NewEvaluatorWithNatives(stdout, stderr io.Writer, stdin io.Reader, quiet bo
 }
 
 // InjectSystemArgs creates and injects the system object with command-line arguments
-// into the evaluator's root frame.
+// into the evaluator's root frame. All other system fields get their defaults.
 func InjectSystemArgs(evaluator core.Evaluator, args []string) {
-	viroArgs := make([]core.Value, len(args))
-	for i, arg := range args {
-		viroArgs[i] = value.NewStringValue(arg)
-	}
-
-	argsBlock := value.NewBlockValue(viroArgs)
-
-	ownedFrame := frame.NewFrame(frame.FrameObject, -1)
-	ownedFrame.Bind("args", argsBlock)
-
-	systemObj := value.NewObject(ownedFrame)
-
-	rootFrame := evaluator.GetFrameByIndex(0)
-	rootFrame.Bind("system", systemObj)
+	InjectSystem(evaluator, SystemInfo{Args: args})
 }
@@ -0,0 +1,146 @@
+package bootstrap
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/marcin-radoszewski/viro/internal/config"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/version"
+)
+
+// SystemInfo describes the runtime environment exposed through the system object.
+type SystemInfo struct {
+	Args []string
+
+	// ScriptPath is the path of the running script; empty in the REPL and for -c.
+	ScriptPath string
+
+	// ScriptHeader is the object built from the script's Viro [...] header, or nil.
+	ScriptHeader core.Value
+
+	// Options is the active configuration; nil means defaults.
+	Options *config.Config
+
+	// Cwd, OS and Arch are the working directory and platform; empty means
+	// the interpreter process's own. A replay sets them to the recorded ones.
+	Cwd  string
+	OS   string
+	Arch string
+}
+
+// WithDefaults fills the empty Cwd, OS and Arch from the interpreter process.
+// Cwd stays empty when the working directory cannot be determined.
+func (info SystemInfo) WithDefaults() SystemInfo {
+	if info.Cwd == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			info.Cwd = cwd
+		}
+	}
+	if info.OS == "" {
+		info.OS = runtime.GOOS
+	}
+	if info.Arch == "" {
+		info.Arch = runtime.GOARCH
+	}
+	return info
+}
+
+// InjectSystem creates the system object and binds it in the evaluator's root frame.
+//
+// Fields:
+//   - args: block of command-line argument strings
+//   - version: interpreter version string
+//   - platform: object with os and arch strings (Go GOOS/GOARCH names)
+//   - script: object with path, dir and header, or none outside script mode
+//   - options: object with sandbox-root, quiet, verbose, trace, profile and allow-exec
+//   - cwd: working directory of the interpreter process
+func InjectSystem(evaluator core.Evaluator, info SystemInfo) {
+	info = info.WithDefaults()
+	viroArgs := make([]core.Value, len(info.Args))
+	for i, arg := range info.Args {
+		viroArgs[i] = value.NewStringValue(arg)
+	}
+
+	ownedFrame := frame.NewFrame(frame.FrameObject, -1)
+	ownedFrame.Bind("args", value.NewBlockValue(viroArgs))
+	ownedFrame.Bind("version", value.NewStrVal(version.Version))
+	ownedFrame.Bind("platform", newPlatformObject(info))
+	ownedFrame.Bind("script", newScriptObject(info))
+	ownedFrame.Bind("options", newOptionsObject(info.Options))
+
+	if info.Cwd == "" {
+		ownedFrame.Bind("cwd", value.NewNoneVal())
+	} else {
+		ownedFrame.Bind("cwd", value.NewStrVal(info.Cwd))
+	}
+
+	systemObj := value.NewObject(ownedFrame)
+
+	rootFrame := evaluator.GetFrameByIndex(0)
+	rootFrame.Bind("system", systemObj)
+}
+
+func newPlatformObject(info SystemInfo) core.Value {
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	objFrame.Bind("os", value.NewStrVal(info.OS))
+	objFrame.Bind("arch", value.NewStrVal(info.Arch))
+	return value.NewObject(objFrame)
+}
+
+func newScriptObject(info SystemInfo) core.Value {
+	if info.ScriptPath == "" {
+		return value.NewNoneVal()
+	}
+
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	if info.ScriptPath == "-" {
+		objFrame.Bind("path", value.NewStrVal("-"))
+		objFrame.Bind("dir", value.NewNoneVal())
+	} else {
+		path := info.ScriptPath
+		if !filepath.IsAbs(path) && info.Cwd != "" {
+			path = filepath.Join(info.Cwd, path)
+		} else if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		objFrame.Bind("path", value.NewStrVal(path))
+		objFrame.Bind("dir", value.NewStrVal(filepath.Dir(path)))
+	}
+
+	if info.ScriptHeader != nil {
+		objFrame.Bind("header", info.ScriptHeader)
+	} else {
+		objFrame.Bind("header", value.NewNoneVal())
+	}
+	return value.NewObject(objFrame)
+}
+
+func newOptionsObject(cfg *config.Config) core.Value {
+	if cfg == nil {
+		cfg = config.NewConfig()
+	}
+
+	sandboxRoot := eval.SandboxRoot
+	if sandboxRoot == "" {
+		sandboxRoot = cfg.SandboxRoot
+	}
+
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	if sandboxRoot == "" {
+		objFrame.Bind("sandbox-root", value.NewNoneVal())
+	} else {
+		objFrame.Bind("sandbox-root", value.NewStrVal(sandboxRoot))
+	}
+	objFrame.Bind("quiet", value.NewLogicVal(cfg.Quiet))
+	objFrame.Bind("verbose", value.NewLogicVal(cfg.Verbose))
+	objFrame.Bind("trace", value.NewLogicVal(cfg.TraceOn))
+	objFrame.Bind("profile", value.NewLogicVal(cfg.Profile))
+	objFrame.Bind("allow-exec", value.NewLogicVal(cfg.AllowExec))
+	objFrame.Bind("compile", value.NewLogicVal(cfg.Compile))
+	return value.NewObject(objFrame)
+}
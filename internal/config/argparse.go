@@ -3,10 +3,17 @@ package config
 import "strings"
 
 var flagsWithValues = map[string]bool{
-	"-c":             true,
-	"--sandbox-root": true,
-	"--history-file": true,
-	"--prompt":       true,
+	"-c":               true,
+	"--sandbox-root":   true,
+	"--history-file":   true,
+	"--prompt":         true,
+	"--coverage":       true,
+	"--profile-output": true,
+	"--trace-file":     true,
+	"--trace-format":   true,
+	"--trace-buffer":   true,
+	"--record":         true,
+	"--replay":         true,
 }
 
 type ParsedArgs struct {
@@ -4,11 +4,15 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 )
 
 type Config struct {
 	SandboxRoot      string
 	AllowInsecureTLS bool
+	AllowExec        bool
+	Compile          bool
 	Quiet            bool
 	Verbose          bool
 
@@ -16,6 +20,7 @@ type Config struct {
 	ShowHelp    bool
 	EvalExpr    string
 	CheckOnly   bool
+	CheckJSON   bool
 	ScriptFile  string
 	Args        []string
 
@@ -24,10 +29,46 @@ type Config struct {
 	Prompt      string
 	NoWelcome   bool
 	TraceOn     bool
+	// TraceFile, TraceFormat and TraceBuffer configure where trace events
+	// go: a file instead of stderr, the json, tree or chrome format, and
+	// a ring buffer of that many events written out only on error. Each
+	// implies TraceOn.
+	TraceFile   string
+	TraceFormat string
+	TraceBuffer int
 
 	NoPrint   bool
 	ReadStdin bool
 	Profile   bool
+	// ProfileOutput is the file the call-tree profile is written to; its
+	// extension picks pprof, JSON or collapsed stacks. Setting it implies
+	// Profile.
+	ProfileOutput string
+	// ProfileAllocs turns on allocation accounting, reported with the
+	// profile and by `stats --allocations`. Setting it implies Profile.
+	ProfileAllocs bool
+	// Coverage is the file the coverage report is written to; its
+	// extension picks LCOV or HTML.
+	Coverage string
+	// Record is the file a recording of the run's outside inputs is
+	// written to. Replay is a recording to replay and browse step by step.
+	Record string
+	Replay string
+
+	// Command is the subcommand given as the first argument, such as "fmt".
+	// Its file arguments are stored in Args.
+	Command     string
+	FormatCheck bool
+	FormatWrite bool
+
+	TestRun      string
+	TestFailFast bool
+	TestFormat   string
+
+	// DocOutput is the directory `viro doc` writes the reference site to.
+	// DocCheck only runs the doctests.
+	DocOutput string
+	DocCheck  bool
 }
 
 func NewConfig() *Config {
@@ -47,6 +88,14 @@ func (c *Config) LoadFromEnv() error {
 		c.AllowInsecureTLS = true
 	}
 
+	if exec := os.Getenv("VIRO_ALLOW_EXEC"); exec == "1" || exec == "true" {
+		c.AllowExec = true
+	}
+
+	if compile := os.Getenv("VIRO_COMPILE"); compile == "1" || compile == "true" {
+		c.Compile = true
+	}
+
 	if history := os.Getenv("VIRO_HISTORY_FILE"); history != "" {
 		c.HistoryFile = history
 	}
@@ -59,10 +108,25 @@ func (c *Config) LoadFromFlags() error {
 }
 
 func (c *Config) LoadFromFlagsWithArgs(args []string) error {
+	if len(args) > 0 && args[0] == CommandFmt {
+		return c.loadFmtArgs(args[1:])
+	}
+	if len(args) > 0 && args[0] == CommandLSP {
+		return c.loadLSPArgs(args[1:])
+	}
+	if len(args) > 0 && args[0] == CommandTest {
+		return c.loadTestArgs(args[1:])
+	}
+	if len(args) > 0 && args[0] == CommandDoc {
+		return c.loadDocArgs(args[1:])
+	}
+
 	fs := flag.NewFlagSet("viro", flag.ContinueOnError)
 
 	sandboxRoot := fs.String("sandbox-root", "", "Sandbox root directory for file operations (default: current directory)")
 	allowInsecureTLS := fs.Bool("allow-insecure-tls", false, "Allow insecure TLS connections globally (warning: disables certificate verification)")
+	allowExec := fs.Bool("allow-exec", false, "Allow scripts to run external programs with call")
+	compile := fs.Bool("compile", false, "Compile blocks before evaluating them")
 	quiet := fs.Bool("quiet", false, "Suppress non-error output")
 	verbose := fs.Bool("verbose", false, "Enable verbose output")
 
@@ -70,16 +134,25 @@ func (c *Config) LoadFromFlagsWithArgs(args []string) error {
 	help := fs.Bool("help", false, "Show help information")
 	evalExpr := fs.String("c", "", "Evaluate expression and print result")
 	check := fs.Bool("check", false, "Check syntax only (don't execute)")
+	checkJSON := fs.Bool("json", false, "Report --check diagnostics as JSON")
 
 	noHistory := fs.Bool("no-history", false, "Disable command history in REPL")
 	historyFile := fs.String("history-file", "", "History file location")
 	prompt := fs.String("prompt", "", "Custom REPL prompt")
 	noWelcome := fs.Bool("no-welcome", false, "Skip welcome message in REPL")
-	traceOn := fs.Bool("trace", false, "Start REPL with tracing enabled")
+	traceOn := fs.Bool("trace", false, "Enable tracing for the REPL or script")
+	traceFile := fs.String("trace-file", "", "Write trace events to the file instead of stderr; implies --trace")
+	traceFormat := fs.String("trace-format", "json", "Trace output format: json, tree or chrome; implies --trace when set")
+	traceBuffer := fs.Int("trace-buffer", 0, "Keep only the last N trace events and write them out on error; implies --trace")
 
 	noPrint := fs.Bool("no-print", false, "Don't print result of evaluation")
 	stdin := fs.Bool("stdin", false, "Read additional input from stdin")
-	profileFlag := fs.Bool("profile", false, "Show execution profile after script execution")
+	profileFlag := fs.Bool("profile", false, "Show a call-tree execution profile after evaluation")
+	profileOutput := fs.String("profile-output", "", "Write the profile to the file as collapsed stacks (pprof for .pprof/.pb.gz, JSON for .json); implies --profile")
+	profileAllocs := fs.Bool("profile-allocs", false, "Count series and frame allocations per function in the profile; implies --profile")
+	coverage := fs.String("coverage", "", "Record coverage and write an LCOV (or .html) report to the file")
+	record := fs.String("record", "", "Record input, file and port access, time and secure random numbers to the file for --replay")
+	replay := fs.String("replay", "", "Replay a recording deterministically and browse its steps")
 
 	parsed := splitCommandLineArgs(args)
 
@@ -104,6 +177,8 @@ func (c *Config) LoadFromFlagsWithArgs(args []string) error {
 		c.SandboxRoot = *sandboxRoot
 	}
 	c.AllowInsecureTLS = c.AllowInsecureTLS || *allowInsecureTLS
+	c.AllowExec = c.AllowExec || *allowExec
+	c.Compile = c.Compile || *compile
 	c.Quiet = *quiet
 	c.Verbose = *verbose
 
@@ -111,6 +186,7 @@ func (c *Config) LoadFromFlagsWithArgs(args []string) error {
 	c.ShowHelp = *help
 	c.EvalExpr = *evalExpr
 	c.CheckOnly = *check
+	c.CheckJSON = *checkJSON
 
 	c.NoHistory = *noHistory
 	if *historyFile != "" {
@@ -120,11 +196,19 @@ func (c *Config) LoadFromFlagsWithArgs(args []string) error {
 		c.Prompt = *prompt
 	}
 	c.NoWelcome = *noWelcome
-	c.TraceOn = *traceOn
+	c.TraceFile = *traceFile
+	c.TraceFormat = *traceFormat
+	c.TraceBuffer = *traceBuffer
+	c.TraceOn = *traceOn || *traceFile != "" || *traceBuffer != 0 || flagSet(fs, "trace-format")
 
 	c.NoPrint = *noPrint
 	c.ReadStdin = *stdin
-	c.Profile = *profileFlag
+	c.Profile = *profileFlag || *profileOutput != "" || *profileAllocs
+	c.ProfileOutput = *profileOutput
+	c.ProfileAllocs = *profileAllocs
+	c.Coverage = *coverage
+	c.Record = *record
+	c.Replay = *replay
 
 	if parsed.ReplArgsIdx < 0 && len(parsed.ScriptArgs) > 0 {
 		c.ScriptFile = parsed.ScriptArgs[0]
@@ -134,6 +218,131 @@ func (c *Config) LoadFromFlagsWithArgs(args []string) error {
 	return nil
 }
 
+// Trace output formats accepted by --trace-format.
+var traceFormats = []string{"json", "tree", "chrome"}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// CommandFmt is the subcommand that formats source files.
+const CommandFmt = "fmt"
+
+func (c *Config) loadFmtArgs(args []string) error {
+	fs := flag.NewFlagSet("viro fmt", flag.ContinueOnError)
+
+	check := fs.Bool("check", false, "List files whose formatting differs and exit with status 1")
+	write := fs.Bool("write", false, "Write the result back to the files instead of standard output")
+	quiet := fs.Bool("quiet", false, "Suppress non-error output")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c.Command = CommandFmt
+	c.FormatCheck = *check
+	c.FormatWrite = *write
+	c.Quiet = *quiet
+	c.Args = fs.Args()
+	return nil
+}
+
+// CommandLSP is the subcommand that runs the language server.
+const CommandLSP = "lsp"
+
+// loadLSPArgs accepts --stdio, which editors pass to select the only
+// transport the server has.
+func (c *Config) loadLSPArgs(args []string) error {
+	fs := flag.NewFlagSet("viro lsp", flag.ContinueOnError)
+	fs.Bool("stdio", true, "Communicate over standard input and output")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("lsp: unexpected argument %q", fs.Arg(0))
+	}
+
+	c.Command = CommandLSP
+	return nil
+}
+
+// CommandTest is the subcommand that runs *_test.viro files.
+const CommandTest = "test"
+
+// Test report formats accepted by --format.
+var testFormats = []string{"text", "tap", "junit"}
+
+func (c *Config) loadTestArgs(args []string) error {
+	fs := flag.NewFlagSet("viro test", flag.ContinueOnError)
+
+	run := fs.String("run", "", "Run only tests whose name matches the regular expression")
+	failFast := fs.Bool("fail-fast", false, "Stop after the first failing test")
+	format := fs.String("format", "text", "Report format: text, tap or junit")
+	verbose := fs.Bool("verbose", false, "List passing tests and their output")
+	quiet := fs.Bool("quiet", false, "Suppress non-error output")
+	sandboxRoot := fs.String("sandbox-root", "", "Sandbox root directory, searched for tests (default: current directory)")
+	allowExec := fs.Bool("allow-exec", false, "Allow tests to run external programs with call")
+	coverage := fs.String("coverage", "", "Record coverage of the test files and write an LCOV (or .html) report to the file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := regexp.Compile(*run); err != nil {
+		return fmt.Errorf("test: invalid --run pattern: %w", err)
+	}
+	if !slices.Contains(testFormats, *format) {
+		return fmt.Errorf("test: unknown --format %q (want text, tap or junit)", *format)
+	}
+
+	c.Command = CommandTest
+	c.TestRun = *run
+	c.TestFailFast = *failFast
+	c.TestFormat = *format
+	c.Verbose = *verbose
+	c.Quiet = *quiet
+	if *sandboxRoot != "" {
+		c.SandboxRoot = *sandboxRoot
+	}
+	c.AllowExec = c.AllowExec || *allowExec
+	c.Coverage = *coverage
+	c.Args = fs.Args()
+	return nil
+}
+
+// CommandDoc is the subcommand that generates the reference site.
+const CommandDoc = "doc"
+
+func (c *Config) loadDocArgs(args []string) error {
+	fs := flag.NewFlagSet("viro doc", flag.ContinueOnError)
+
+	output := fs.String("output", "reference", "Directory to write the Markdown and HTML reference to")
+	check := fs.Bool("check", false, "Only run the doctests; write nothing")
+	quiet := fs.Bool("quiet", false, "Suppress non-error output")
+	sandboxRoot := fs.String("sandbox-root", "", "Sandbox root directory, searched for the script files given (default: current directory)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c.Command = CommandDoc
+	c.DocOutput = *output
+	c.DocCheck = *check
+	c.Quiet = *quiet
+	if *sandboxRoot != "" {
+		c.SandboxRoot = *sandboxRoot
+	}
+	c.Args = fs.Args()
+	return nil
+}
+
 func (c *Config) ApplyDefaults() error {
 	if c.SandboxRoot == "" {
 		cwd, err := os.Getwd()
@@ -149,14 +358,35 @@ func (c *Config) Validate() error {
 	if c.CheckOnly && c.ScriptFile == "" {
 		return fmt.Errorf("--check flag requires a script file")
 	}
+	if c.CheckJSON && !c.CheckOnly {
+		return fmt.Errorf("--json flag requires --check")
+	}
 	if c.ReadStdin && c.EvalExpr == "" {
 		return fmt.Errorf("--stdin flag requires -c flag")
 	}
 	if c.NoPrint && c.EvalExpr == "" {
 		return fmt.Errorf("--no-print flag requires -c flag")
 	}
-	if c.Profile && c.ScriptFile == "" {
-		return fmt.Errorf("--profile flag requires a script file")
+	if c.TraceFormat != "" && !slices.Contains(traceFormats, c.TraceFormat) {
+		return fmt.Errorf("unknown --trace-format %q (want json, tree or chrome)", c.TraceFormat)
+	}
+	if c.TraceBuffer < 0 {
+		return fmt.Errorf("--trace-buffer must not be negative")
+	}
+	if c.Coverage != "" && c.ScriptFile == "" && c.Command != CommandTest {
+		return fmt.Errorf("--coverage flag requires a script file")
+	}
+	if c.Record != "" && c.ScriptFile == "" && c.EvalExpr == "" {
+		return fmt.Errorf("--record flag requires a script file or -c")
+	}
+	if c.Record != "" && c.Replay != "" {
+		return fmt.Errorf("use only one of --record or --replay")
+	}
+	if c.FormatCheck && c.FormatWrite {
+		return fmt.Errorf("fmt: use only one of --check or --write")
+	}
+	if c.FormatWrite && len(c.Args) == 0 {
+		return fmt.Errorf("fmt --write requires at least one file")
 	}
 	return nil
 }
@@ -170,6 +400,11 @@ const (
 	ModeCheck
 	ModeVersion
 	ModeHelp
+	ModeFmt
+	ModeLSP
+	ModeTest
+	ModeReplay
+	ModeDoc
 )
 
 func (m Mode) String() string {
@@ -186,6 +421,16 @@ func (m Mode) String() string {
 		return "Version"
 	case ModeHelp:
 		return "Help"
+	case ModeFmt:
+		return "Fmt"
+	case ModeLSP:
+		return "LSP"
+	case ModeTest:
+		return "Test"
+	case ModeReplay:
+		return "Replay"
+	case ModeDoc:
+		return "Doc"
 	default:
 		return "Unknown"
 	}
@@ -198,9 +443,14 @@ func (c *Config) DetectMode() (Mode, error) {
 	}{
 		{c.ShowVersion, ModeVersion},
 		{c.ShowHelp, ModeHelp},
+		{c.Command == CommandFmt, ModeFmt},
+		{c.Command == CommandLSP, ModeLSP},
+		{c.Command == CommandTest, ModeTest},
+		{c.Command == CommandDoc, ModeDoc},
 		{c.EvalExpr != "", ModeEval},
 		{c.CheckOnly, ModeCheck},
 		{!c.CheckOnly && c.ScriptFile != "", ModeScript},
+		{c.Replay != "", ModeReplay},
 	}
 
 	var detectedMode Mode
@@ -214,7 +464,7 @@ func (c *Config) DetectMode() (Mode, error) {
 	}
 
 	if modeCount > 1 {
-		return ModeREPL, fmt.Errorf("multiple modes specified; use only one of: --version, --help, -c, or script file")
+		return ModeREPL, fmt.Errorf("multiple modes specified; use only one of: --version, --help, -c, --replay, or script file")
 	}
 
 	if modeCount == 0 {
@@ -230,24 +480,60 @@ func ParseSimple(args []string) (*Config, error) {
 		SandboxRoot: cwd,
 	}
 
+	if len(args) > 0 && args[0] == CommandFmt {
+		if err := cfg.loadFmtArgs(args[1:]); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+	if len(args) > 0 && args[0] == CommandLSP {
+		if err := cfg.loadLSPArgs(args[1:]); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+	if len(args) > 0 && args[0] == CommandTest {
+		if err := cfg.loadTestArgs(args[1:]); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+	if len(args) > 0 && args[0] == CommandDoc {
+		if err := cfg.loadDocArgs(args[1:]); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+
 	fs := flag.NewFlagSet("viro", flag.ContinueOnError)
 
 	sandboxRoot := fs.String("sandbox-root", "", "")
 	allowInsecureTLS := fs.Bool("allow-insecure-tls", false, "")
+	allowExec := fs.Bool("allow-exec", false, "")
+	compile := fs.Bool("compile", false, "")
 	quiet := fs.Bool("quiet", false, "")
 	verbose := fs.Bool("verbose", false, "")
 	version := fs.Bool("version", false, "")
 	help := fs.Bool("help", false, "")
 	evalExpr := fs.String("c", "", "")
 	check := fs.Bool("check", false, "")
+	checkJSON := fs.Bool("json", false, "")
 	noHistory := fs.Bool("no-history", false, "")
 	historyFile := fs.String("history-file", "", "")
 	prompt := fs.String("prompt", "", "")
 	noWelcome := fs.Bool("no-welcome", false, "")
 	traceOn := fs.Bool("trace", false, "")
+	traceFile := fs.String("trace-file", "", "")
+	traceFormat := fs.String("trace-format", "json", "")
+	traceBuffer := fs.Int("trace-buffer", 0, "")
 	noPrint := fs.Bool("no-print", false, "")
 	stdin := fs.Bool("stdin", false, "")
 	profileFlag := fs.Bool("profile", false, "")
+	profileOutput := fs.String("profile-output", "", "")
+	profileAllocs := fs.Bool("profile-allocs", false, "")
+	coverage := fs.String("coverage", "", "")
+	record := fs.String("record", "", "")
+	replay := fs.String("replay", "", "")
 
 	if err := fs.Parse(args); err != nil {
 		return nil, err
@@ -257,12 +543,15 @@ func ParseSimple(args []string) (*Config, error) {
 		cfg.SandboxRoot = *sandboxRoot
 	}
 	cfg.AllowInsecureTLS = *allowInsecureTLS
+	cfg.AllowExec = *allowExec
+	cfg.Compile = *compile
 	cfg.Quiet = *quiet
 	cfg.Verbose = *verbose
 	cfg.ShowVersion = *version
 	cfg.ShowHelp = *help
 	cfg.EvalExpr = *evalExpr
 	cfg.CheckOnly = *check
+	cfg.CheckJSON = *checkJSON
 	cfg.NoHistory = *noHistory
 	if *historyFile != "" {
 		cfg.HistoryFile = *historyFile
@@ -271,10 +560,18 @@ func ParseSimple(args []string) (*Config, error) {
 		cfg.Prompt = *prompt
 	}
 	cfg.NoWelcome = *noWelcome
-	cfg.TraceOn = *traceOn
+	cfg.TraceFile = *traceFile
+	cfg.TraceFormat = *traceFormat
+	cfg.TraceBuffer = *traceBuffer
+	cfg.TraceOn = *traceOn || *traceFile != "" || *traceBuffer != 0 || flagSet(fs, "trace-format")
 	cfg.NoPrint = *noPrint
 	cfg.ReadStdin = *stdin
-	cfg.Profile = *profileFlag
+	cfg.Profile = *profileFlag || *profileOutput != "" || *profileAllocs
+	cfg.ProfileOutput = *profileOutput
+	cfg.ProfileAllocs = *profileAllocs
+	cfg.Coverage = *coverage
+	cfg.Record = *record
+	cfg.Replay = *replay
 
 	positionalArgs := fs.Args()
 	if len(positionalArgs) > 0 {
@@ -178,6 +178,23 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "check with json",
+			cfg: &Config{
+				CheckOnly:  true,
+				CheckJSON:  true,
+				ScriptFile: "test.viro",
+			},
+			wantErr: false,
+		},
+		{
+			name: "json without check",
+			cfg: &Config{
+				CheckJSON:  true,
+				ScriptFile: "test.viro",
+			},
+			wantErr: true,
+		},
 		{
 			name: "script only",
 			cfg: &Config{
@@ -232,11 +249,11 @@ func TestConfigValidate(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "profile without script",
+			name: "profile in repl",
 			cfg: &Config{
 				Profile: true,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "profile with script",
@@ -252,6 +269,30 @@ func TestConfigValidate(t *testing.T) {
 				Profile:  true,
 				EvalExpr: "3 + 4",
 			},
+			wantErr: false,
+		},
+		{
+			name: "coverage with script",
+			cfg: &Config{
+				Coverage:   "out.info",
+				ScriptFile: "test.viro",
+			},
+			wantErr: false,
+		},
+		{
+			name: "coverage with test command",
+			cfg: &Config{
+				Coverage: "out.info",
+				Command:  CommandTest,
+			},
+			wantErr: false,
+		},
+		{
+			name: "coverage with eval",
+			cfg: &Config{
+				Coverage: "out.info",
+				EvalExpr: "3 + 4",
+			},
 			wantErr: true,
 		},
 	}
@@ -431,3 +472,283 @@ func TestScriptArgumentsNoScriptMode(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowExecFlag(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.AllowExec {
+		t.Errorf("AllowExec = true, want false by default")
+	}
+
+	cfg = NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"--allow-exec", "script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if !cfg.AllowExec {
+		t.Errorf("AllowExec = false, want true with --allow-exec")
+	}
+
+	simple, err := ParseSimple([]string{"--allow-exec", "script.viro"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if !simple.AllowExec {
+		t.Errorf("ParseSimple AllowExec = false, want true with --allow-exec")
+	}
+
+	t.Setenv("VIRO_ALLOW_EXEC", "1")
+	cfg = NewConfig()
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if err := cfg.LoadFromFlagsWithArgs([]string{"script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if !cfg.AllowExec {
+		t.Errorf("AllowExec = false, want true with VIRO_ALLOW_EXEC=1")
+	}
+}
+
+func TestCompileFlag(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.Compile {
+		t.Errorf("Compile = true, want false by default")
+	}
+
+	cfg = NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"--compile", "script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if !cfg.Compile {
+		t.Errorf("Compile = false, want true with --compile")
+	}
+
+	t.Setenv("VIRO_COMPILE", "1")
+	cfg = NewConfig()
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() error = %v", err)
+	}
+	if err := cfg.LoadFromFlagsWithArgs([]string{"script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if !cfg.Compile {
+		t.Errorf("Compile = false, want true with VIRO_COMPILE=1")
+	}
+}
+
+func TestProfileAllocsFlag(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"--profile-allocs", "script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if !cfg.ProfileAllocs || !cfg.Profile {
+		t.Errorf("ProfileAllocs = %v, Profile = %v; want both true with --profile-allocs", cfg.ProfileAllocs, cfg.Profile)
+	}
+
+	simple, err := ParseSimple([]string{"--profile-allocs", "--profile-output", "out.json", "script.viro"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if !simple.ProfileAllocs || !simple.Profile || simple.ProfileOutput != "out.json" {
+		t.Errorf("ParseSimple = %+v, want allocation profile written to out.json", simple)
+	}
+	if simple.ScriptFile != "script.viro" {
+		t.Errorf("ScriptFile = %q, want script.viro", simple.ScriptFile)
+	}
+}
+
+func TestTraceFlags(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"--trace-buffer", "1000", "--trace-format", "tree", "script.viro"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if !cfg.TraceOn || cfg.TraceBuffer != 1000 || cfg.TraceFormat != "tree" {
+		t.Errorf("TraceOn = %v, TraceBuffer = %d, TraceFormat = %q", cfg.TraceOn, cfg.TraceBuffer, cfg.TraceFormat)
+	}
+	if cfg.ScriptFile != "script.viro" {
+		t.Errorf("ScriptFile = %q, want script.viro", cfg.ScriptFile)
+	}
+
+	simple, err := ParseSimple([]string{"--trace-file", "trace.json", "script.viro"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if !simple.TraceOn || simple.TraceFile != "trace.json" || simple.TraceFormat != "json" {
+		t.Errorf("ParseSimple = %+v, want JSON trace written to trace.json", simple)
+	}
+
+	plain := NewConfig()
+	if err := plain.LoadFromFlagsWithArgs([]string{"script.viro"}); err != nil {
+		t.Fatal(err)
+	}
+	if plain.TraceOn {
+		t.Error("TraceOn set without any trace flag")
+	}
+
+	bad := NewConfig()
+	if err := bad.LoadFromFlagsWithArgs([]string{"--trace-format", "xml", "script.viro"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown --trace-format")
+	}
+}
+
+func TestRecordReplayFlags(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"--record", "run.json", "script.viro", "a"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.Record != "run.json" || cfg.ScriptFile != "script.viro" || len(cfg.Args) != 1 {
+		t.Errorf("Record = %q, ScriptFile = %q, Args = %v", cfg.Record, cfg.ScriptFile, cfg.Args)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	replay := NewConfig()
+	if err := replay.LoadFromFlagsWithArgs([]string{"--replay", "run.json"}); err != nil {
+		t.Fatal(err)
+	}
+	if mode, err := replay.DetectMode(); err != nil || mode != ModeReplay {
+		t.Errorf("DetectMode() = %v, %v; want Replay", mode, err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"record without a program", []string{"--record", "run.json"}},
+		{"record and replay", []string{"--record", "a.json", "--replay", "b.json", "-c", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			if err := cfg.LoadFromFlagsWithArgs(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.Validate(); err == nil {
+				t.Errorf("Validate() accepted %v", tt.args)
+			}
+		})
+	}
+}
+
+func TestFmtCommand(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"fmt", "--write", "a.viro", "dir"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.Command != CommandFmt || !cfg.FormatWrite || cfg.FormatCheck {
+		t.Errorf("Command = %q, FormatWrite = %v, FormatCheck = %v", cfg.Command, cfg.FormatWrite, cfg.FormatCheck)
+	}
+	if len(cfg.Args) != 2 || cfg.Args[0] != "a.viro" || cfg.Args[1] != "dir" {
+		t.Errorf("Args = %v, want [a.viro dir]", cfg.Args)
+	}
+	if cfg.ScriptFile != "" {
+		t.Errorf("ScriptFile = %q, want empty", cfg.ScriptFile)
+	}
+
+	simple, err := ParseSimple([]string{"fmt", "--check"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if simple.Command != CommandFmt || !simple.FormatCheck {
+		t.Errorf("ParseSimple Command = %q, FormatCheck = %v", simple.Command, simple.FormatCheck)
+	}
+
+	invalid := []struct {
+		name string
+		args []string
+	}{
+		{"check and write", []string{"fmt", "--check", "--write", "a.viro"}},
+		{"write without files", []string{"fmt", "--write"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			if err := cfg.LoadFromFlagsWithArgs(tt.args); err != nil {
+				t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+			}
+			if err := cfg.Validate(); err == nil {
+				t.Error("Validate() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLSPCommand(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"lsp", "--stdio"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.Command != CommandLSP || cfg.ScriptFile != "" {
+		t.Errorf("Command = %q, ScriptFile = %q", cfg.Command, cfg.ScriptFile)
+	}
+
+	simple, err := ParseSimple([]string{"lsp"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if simple.Command != CommandLSP {
+		t.Errorf("ParseSimple Command = %q", simple.Command)
+	}
+
+	if err := NewConfig().LoadFromFlagsWithArgs([]string{"lsp", "script.viro"}); err == nil {
+		t.Error("expected error for a file argument")
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"test", "--run", "^parse", "--fail-fast", "--format", "tap", "tests"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.Command != CommandTest || cfg.TestRun != "^parse" || !cfg.TestFailFast || cfg.TestFormat != "tap" {
+		t.Errorf("got Command = %q, TestRun = %q, TestFailFast = %v, TestFormat = %q", cfg.Command, cfg.TestRun, cfg.TestFailFast, cfg.TestFormat)
+	}
+	if len(cfg.Args) != 1 || cfg.Args[0] != "tests" {
+		t.Errorf("Args = %v, want [tests]", cfg.Args)
+	}
+
+	simple, err := ParseSimple([]string{"test"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if simple.Command != CommandTest || simple.TestFormat != "text" {
+		t.Errorf("ParseSimple Command = %q, TestFormat = %q", simple.Command, simple.TestFormat)
+	}
+
+	if err := NewConfig().LoadFromFlagsWithArgs([]string{"test", "--format", "xml"}); err == nil {
+		t.Error("expected error for an unknown format")
+	}
+	if err := NewConfig().LoadFromFlagsWithArgs([]string{"test", "--run", "("}); err == nil {
+		t.Error("expected error for an invalid --run pattern")
+	}
+}
+
+func TestDocCommand(t *testing.T) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromFlagsWithArgs([]string{"doc", "--output", "site", "--check", "lib"}); err != nil {
+		t.Fatalf("LoadFromFlagsWithArgs() error = %v", err)
+	}
+	if cfg.Command != CommandDoc || cfg.DocOutput != "site" || !cfg.DocCheck {
+		t.Errorf("got Command = %q, DocOutput = %q, DocCheck = %v", cfg.Command, cfg.DocOutput, cfg.DocCheck)
+	}
+	if len(cfg.Args) != 1 || cfg.Args[0] != "lib" {
+		t.Errorf("Args = %v, want [lib]", cfg.Args)
+	}
+
+	simple, err := ParseSimple([]string{"doc"})
+	if err != nil {
+		t.Fatalf("ParseSimple() error = %v", err)
+	}
+	if simple.Command != CommandDoc || simple.DocOutput != "reference" || simple.DocCheck {
+		t.Errorf("ParseSimple Command = %q, DocOutput = %q, DocCheck = %v", simple.Command, simple.DocOutput, simple.DocCheck)
+	}
+}
@@ -13,6 +13,11 @@ func TestModeString(t *testing.T) {
 		{ModeCheck, "Check"},
 		{ModeVersion, "Version"},
 		{ModeHelp, "Help"},
+		{ModeFmt, "Fmt"},
+		{ModeLSP, "LSP"},
+		{ModeTest, "Test"},
+		{ModeReplay, "Replay"},
+		{ModeDoc, "Doc"},
 	}
 
 	for _, tt := range tests {
@@ -81,6 +86,35 @@ func TestDetectMode(t *testing.T) {
 			},
 			want: ModeScript,
 		},
+		{
+			name: "fmt command",
+			cfg: &Config{
+				Command: CommandFmt,
+				Args:    []string{"test.viro"},
+			},
+			want: ModeFmt,
+		},
+		{
+			name: "lsp command",
+			cfg: &Config{
+				Command: CommandLSP,
+			},
+			want: ModeLSP,
+		},
+		{
+			name: "test command",
+			cfg: &Config{
+				Command: CommandTest,
+			},
+			want: ModeTest,
+		},
+		{
+			name: "doc command",
+			cfg: &Config{
+				Command: CommandDoc,
+			},
+			want: ModeDoc,
+		},
 		{
 			name: "multiple modes - version and help",
 			cfg: &Config{
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AllocKind is what an allocation reported through CountAllocation created.
+type AllocKind uint8
+
+const (
+	AllocBlock  AllocKind = iota // a new block! or paren! series
+	AllocString                  // a new string! series
+	AllocBinary                  // a new binary! series
+	AllocGrowth                  // elements added to a series by append or insert
+	AllocFrame                   // a frame entering an evaluator's frame store
+)
+
+// AllocStats counts allocations by kind.
+type AllocStats struct {
+	Blocks   int64
+	Strings  int64
+	Binaries int64
+	Growth   int64
+	Frames   int64
+}
+
+// Add counts n allocations of kind.
+func (s *AllocStats) Add(kind AllocKind, n int) {
+	switch kind {
+	case AllocBlock:
+		s.Blocks += int64(n)
+	case AllocString:
+		s.Strings += int64(n)
+	case AllocBinary:
+		s.Binaries += int64(n)
+	case AllocGrowth:
+		s.Growth += int64(n)
+	case AllocFrame:
+		s.Frames += int64(n)
+	}
+}
+
+// Merge adds the counts of o.
+func (s *AllocStats) Merge(o AllocStats) {
+	s.Blocks += o.Blocks
+	s.Strings += o.Strings
+	s.Binaries += o.Binaries
+	s.Growth += o.Growth
+	s.Frames += o.Frames
+}
+
+// Total returns the number of series and frames created. Growth counts
+// elements rather than allocations and is left out.
+func (s AllocStats) Total() int64 {
+	return s.Blocks + s.Strings + s.Binaries + s.Frames
+}
+
+// IsZero reports whether nothing was counted.
+func (s AllocStats) IsZero() bool {
+	return s == AllocStats{}
+}
+
+// ownedHook is an allocation hook together with whoever installed it.
+type ownedHook struct {
+	owner any
+	hook  func(AllocKind, int)
+}
+
+var (
+	// allocationHooks holds the installed hooks, most recent last. Only
+	// the most recent one receives allocations.
+	allocationHooks   []ownedHook
+	allocationHooksMu sync.Mutex
+
+	// allocationHook is the most recent hook. It is read on every series
+	// creation, so it is an atomic pointer rather than guarded by the mutex.
+	allocationHook atomic.Pointer[func(AllocKind, int)]
+)
+
+// SetAllocationHook installs the function CountAllocation reports to on
+// behalf of owner, or removes owner's hook when hook is nil. Series are
+// created without knowing their evaluator, so allocations go to the most
+// recently installed hook; removing it hands them back to the one installed
+// before. Removing never affects the hooks of other owners.
+func SetAllocationHook(owner any, hook func(kind AllocKind, n int)) {
+	allocationHooksMu.Lock()
+	defer allocationHooksMu.Unlock()
+
+	hooks := allocationHooks[:0]
+	for _, h := range allocationHooks {
+		if h.owner != owner {
+			hooks = append(hooks, h)
+		}
+	}
+	clear(allocationHooks[len(hooks):])
+	if hook != nil {
+		hooks = append(hooks, ownedHook{owner, hook})
+	}
+	allocationHooks = hooks
+
+	if len(hooks) == 0 {
+		allocationHook.Store(nil)
+		return
+	}
+	current := hooks[len(hooks)-1].hook
+	allocationHook.Store(&current)
+}
+
+// CountAllocation reports n allocations of kind to the installed hook. It
+// costs an atomic load when no hook is installed.
+func CountAllocation(kind AllocKind, n int) {
+	if hook := allocationHook.Load(); hook != nil {
+		(*hook)(kind, n)
+	}
+}
@@ -1,6 +1,9 @@
 package core
 
-import "io"
+import (
+	"io"
+	"math/rand/v2"
+)
 
 type ValueType uint8
 
@@ -28,6 +31,27 @@ type Binding struct {
 
 type FrameType uint8
 
+// FrameStats describes the evaluator's frame store.
+type FrameStats struct {
+	Slots     int // Slots in the frame store, used or free
+	Live      int // Frames that are still reachable
+	Active    int // Frames on the evaluator's frame stack
+	Free      int // Slots available for reuse
+	Reclaimed int // Slots reclaimed since the evaluator was created
+	Sweeps    int // Number of sweeps performed
+}
+
+// DecimalContext holds the settings used by decimal! arithmetic.
+type DecimalContext struct {
+	Precision int    // Significant digits kept in results
+	Rounding  string // Rounding mode name, e.g. "half-even"
+	Traps     bool   // Raise an error instead of rounding a result
+}
+
+// DefaultDecimalContext is the context every evaluator starts with:
+// decimal128 precision with banker's rounding.
+var DefaultDecimalContext = DecimalContext{Precision: 34, Rounding: "half-even"}
+
 type Frame interface {
 	GetType() FrameType
 	ChangeType(newType FrameType)
@@ -64,4 +88,12 @@ type Evaluator interface {
 	GetInputReader() io.Reader
 	UpdateTraceCache()
 	NewReturnSignal(val Value) error
+	NewExitSignal(code int) error
+	CallFunction(fn Value, args []Value) (Value, error)
+	FrameStats() FrameStats
+	RecycleFrames() FrameStats
+	DecimalContext() DecimalContext
+	SetDecimalContext(ctx DecimalContext)
+	Random() *rand.Rand
+	SeedRandom(seed uint64)
 }
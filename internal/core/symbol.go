@@ -0,0 +1,63 @@
+package core
+
+import "sync"
+
+// Symbol is the interned identity of a word name. Two words with the same
+// spelling always share a Symbol, so frames and the evaluator can compare and
+// index words by integer instead of by string.
+type Symbol int32
+
+// NoSymbol is never returned by Intern.
+const NoSymbol Symbol = -1
+
+// symbolIDs maps names to symbols. It is read on every string-based lookup and
+// written only when a new spelling appears, the access pattern sync.Map is built for.
+var symbolIDs sync.Map
+
+var symbolNames = struct {
+	sync.RWMutex
+	names []string
+}{names: make([]string, 0, 512)}
+
+// Intern returns the Symbol for name, allocating a new one on first use.
+// Symbols are never released; the table only grows with distinct spellings.
+func Intern(name string) Symbol {
+	if sym, ok := symbolIDs.Load(name); ok {
+		return sym.(Symbol)
+	}
+
+	symbolNames.Lock()
+	defer symbolNames.Unlock()
+	if sym, ok := symbolIDs.Load(name); ok {
+		return sym.(Symbol)
+	}
+	sym := Symbol(len(symbolNames.names))
+	symbolNames.names = append(symbolNames.names, name)
+	symbolIDs.Store(name, sym)
+	return sym
+}
+
+// LookupSymbol returns the Symbol for name without interning it.
+func LookupSymbol(name string) (Symbol, bool) {
+	if sym, ok := symbolIDs.Load(name); ok {
+		return sym.(Symbol), true
+	}
+	return NoSymbol, false
+}
+
+// Name returns the spelling of an interned symbol.
+func (s Symbol) Name() string {
+	symbolNames.RLock()
+	defer symbolNames.RUnlock()
+	if s < 0 || int(s) >= len(symbolNames.names) {
+		return ""
+	}
+	return symbolNames.names[s]
+}
+
+// SymbolCount reports how many distinct symbols have been interned.
+func SymbolCount() int {
+	symbolNames.RLock()
+	defer symbolNames.RUnlock()
+	return len(symbolNames.names)
+}
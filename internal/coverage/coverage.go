@@ -0,0 +1,178 @@
+// Package coverage records which expressions of a script are evaluated and
+// reports line and expression coverage as text, LCOV or HTML.
+//
+// The expressions that can be covered come from a static walk of each file
+// (lint.Expressions), which only descends into blocks known to be code.
+// The evaluator reports every value it evaluates through a hook, and the
+// recorder counts those that start one of the known expressions. Nothing
+// goes through the trace session, so recording costs a map lookup per
+// evaluated value.
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/lint"
+)
+
+// Recorder collects coverage for the files added to it. It is not safe
+// for concurrent use.
+type Recorder struct {
+	files  []*file
+	byName map[string]*file
+	counts map[core.SourceLocation]int
+}
+
+type file struct {
+	name        string
+	source      string
+	expressions []core.SourceLocation
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{byName: make(map[string]*file), counts: make(map[core.SourceLocation]int)}
+}
+
+// AddFile registers a parsed file, finding its expressions with the words
+// bound in root. name must be the source name the file was parsed with.
+// Adding a file twice has no effect.
+func (r *Recorder) AddFile(name, source string, values []core.Value, locations []core.SourceLocation, root core.Frame) {
+	if _, ok := r.byName[name]; ok {
+		return
+	}
+	f := &file{name: name, source: source}
+	for _, loc := range lint.Expressions(values, locations, root) {
+		if _, seen := r.counts[loc]; seen {
+			continue
+		}
+		r.counts[loc] = 0
+		f.expressions = append(f.expressions, loc)
+	}
+	r.files = append(r.files, f)
+	r.byName[name] = f
+}
+
+// Hit records that the value at loc was evaluated. It has the signature
+// of eval.CoverageHook.
+func (r *Recorder) Hit(loc core.SourceLocation) {
+	if n, ok := r.counts[loc]; ok {
+		r.counts[loc] = n + 1
+	}
+}
+
+// Line is the coverage of one source line that starts an expression.
+// Count is the most times any of those expressions ran.
+type Line struct {
+	Number int
+	Count  int
+}
+
+// File is the coverage of one file.
+type File struct {
+	Name   string
+	Source string
+	// Lines holds the lines that start an expression, in order.
+	Lines          []Line
+	Expressions    int
+	ExpressionsHit int
+}
+
+// LinesHit returns how many of the lines ran.
+func (f *File) LinesHit() int {
+	n := 0
+	for _, l := range f.Lines {
+		if l.Count > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Report is the coverage of all recorded files.
+type Report struct {
+	Files []File
+}
+
+// Report summarises what has been recorded so far.
+func (r *Recorder) Report() *Report {
+	report := &Report{}
+	for _, f := range r.files {
+		fr := File{Name: f.name, Source: f.source, Expressions: len(f.expressions)}
+		lines := make(map[int]int)
+		for _, loc := range f.expressions {
+			count := r.counts[loc]
+			if count > 0 {
+				fr.ExpressionsHit++
+			}
+			if prev, ok := lines[loc.Line]; !ok || count > prev {
+				lines[loc.Line] = count
+			}
+		}
+		for number, count := range lines {
+			fr.Lines = append(fr.Lines, Line{Number: number, Count: count})
+		}
+		sort.Slice(fr.Lines, func(i, j int) bool { return fr.Lines[i].Number < fr.Lines[j].Number })
+		report.Files = append(report.Files, fr)
+	}
+	return report
+}
+
+// Totals returns line and expression counts over all files.
+func (r *Report) Totals() (lines, linesHit, expressions, expressionsHit int) {
+	for i := range r.Files {
+		f := &r.Files[i]
+		lines += len(f.Lines)
+		linesHit += f.LinesHit()
+		expressions += f.Expressions
+		expressionsHit += f.ExpressionsHit
+	}
+	return
+}
+
+func percent(hit, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(hit) / float64(total)
+}
+
+// WriteText writes a summary line followed by one line per file.
+func (r *Report) WriteText(w io.Writer) error {
+	lines, linesHit, exprs, exprsHit := r.Totals()
+	var b strings.Builder
+	fmt.Fprintf(&b, "coverage: %.1f%% of lines, %.1f%% of expressions\n", percent(linesHit, lines), percent(exprsHit, exprs))
+
+	width := 0
+	for _, f := range r.Files {
+		width = max(width, len(f.Name))
+	}
+	for i := range r.Files {
+		f := &r.Files[i]
+		fmt.Fprintf(&b, "  %-*s  %5.1f%% lines (%d/%d)  %5.1f%% expressions (%d/%d)\n", width, f.Name,
+			percent(f.LinesHit(), len(f.Lines)), f.LinesHit(), len(f.Lines),
+			percent(f.ExpressionsHit, f.Expressions), f.ExpressionsHit, f.Expressions)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteLCOV writes the report as an LCOV tracefile with line records.
+func (r *Report) WriteLCOV(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("TN:\n")
+	for i := range r.Files {
+		f := &r.Files[i]
+		fmt.Fprintf(&b, "SF:%s\n", f.Name)
+		for _, l := range f.Lines {
+			fmt.Fprintf(&b, "DA:%d,%d\n", l.Number, l.Count)
+		}
+		fmt.Fprintf(&b, "LF:%d\nLH:%d\nend_of_record\n", len(f.Lines), f.LinesHit())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
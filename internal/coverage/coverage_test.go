@@ -0,0 +1,101 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+)
+
+const script = `sign: fn [n] [
+    if n > 0 [
+        "positive"
+    ] [
+        "other"
+    ]
+]
+unused: fn [] [
+    print "never"
+]
+sign 5
+`
+
+func record(t *testing.T, compile bool) *Report {
+	t.Helper()
+	values, locations, err := parse.ParseWithSource(script, "s.viro")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	e := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+	e.SetCompile(compile)
+	r := NewRecorder()
+	r.AddFile("s.viro", script, values, locations, e.GetFrameByIndex(0))
+	e.SetCoverageHook(r.Hit)
+	if _, err := e.DoBlock(values, locations); err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+	return r.Report()
+}
+
+func TestRecorder(t *testing.T) {
+	for _, compile := range []bool{false, true} {
+		report := record(t, compile)
+		if len(report.Files) != 1 {
+			t.Fatalf("files = %d, want 1", len(report.Files))
+		}
+		f := report.Files[0]
+		var lines []string
+		for _, l := range f.Lines {
+			lines = append(lines, fmt.Sprintf("%d:%d", l.Number, l.Count))
+		}
+		// Line 5 (the else branch) and line 9 (the body of unused) never
+		// ran.
+		if got, want := strings.Join(lines, " "), "1:1 2:1 3:1 5:0 8:1 9:0 11:1"; got != want {
+			t.Errorf("compile=%v: lines = %s, want %s", compile, got, want)
+		}
+		if f.LinesHit() != 5 || f.Expressions != 12 || f.ExpressionsHit != 9 {
+			t.Errorf("compile=%v: hit %d lines, %d/%d expressions", compile, f.LinesHit(), f.ExpressionsHit, f.Expressions)
+		}
+	}
+}
+
+func TestWriteLCOV(t *testing.T) {
+	var b strings.Builder
+	if err := record(t, false).WriteLCOV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "TN:\nSF:s.viro\nDA:1,1\nDA:2,1\nDA:3,1\nDA:5,0\nDA:8,1\nDA:9,0\nDA:11,1\nLF:7\nLH:5\nend_of_record\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var b strings.Builder
+	record(t, false).WriteText(&b)
+	want := "coverage: 71.4% of lines, 75.0% of expressions\n  s.viro   71.4% lines (5/7)   75.0% expressions (9/12)\n"
+	if b.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", b.String(), want)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b strings.Builder
+	if err := record(t, false).WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<tr class="hit"><td class="num">3</td><td class="count">1</td><td class="code">        &#34;positive&#34;</td></tr>`,
+		`<tr class="miss"><td class="num">9</td><td class="count">0</td><td class="code">    print &#34;never&#34;</td></tr>`,
+		`<tr class=""><td class="num">4</td>`,
+		`71.4% (5/7)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+type htmlLine struct {
+	Number int
+	Text   string
+	// Class is "hit", "miss" or "" for lines that start no expression.
+	Class string
+	Count string
+}
+
+type htmlFile struct {
+	ID          string
+	Name        string
+	Lines       string
+	Expressions string
+	Source      []htmlLine
+}
+
+type htmlPage struct {
+	Summary string
+	Files   []htmlFile
+}
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Viro coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table.summary { border-collapse: collapse; margin-bottom: 2em; }
+table.summary td, table.summary th { padding: 0.2em 1em; text-align: left; }
+table.source { border-collapse: collapse; font-family: monospace; width: 100%; }
+table.source td { padding: 0 0.5em; white-space: pre; }
+td.num, td.count { color: #888; text-align: right; width: 1%; }
+tr.hit td.code { background: #dfd; }
+tr.miss td.code { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Viro coverage</h1>
+<p>{{.Summary}}</p>
+<table class="summary">
+<tr><th>File</th><th>Lines</th><th>Expressions</th></tr>
+{{range .Files}}<tr><td><a href="#{{.ID}}">{{.Name}}</a></td><td>{{.Lines}}</td><td>{{.Expressions}}</td></tr>
+{{end}}</table>
+{{range .Files}}<h2 id="{{.ID}}">{{.Name}}</h2>
+<table class="source">
+{{range .Source}}<tr class="{{.Class}}"><td class="num">{{.Number}}</td><td class="count">{{.Count}}</td><td class="code">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained page with a summary table and each
+// file's source, lines shaded by whether they ran.
+func (r *Report) WriteHTML(w io.Writer) error {
+	lines, linesHit, exprs, exprsHit := r.Totals()
+	page := htmlPage{Summary: fmt.Sprintf("%.1f%% of lines, %.1f%% of expressions", percent(linesHit, lines), percent(exprsHit, exprs))}
+
+	for i := range r.Files {
+		f := &r.Files[i]
+		counts := make(map[int]int, len(f.Lines))
+		for _, l := range f.Lines {
+			counts[l.Number] = l.Count
+		}
+		hf := htmlFile{
+			ID:          fmt.Sprintf("file%d", i+1),
+			Name:        f.Name,
+			Lines:       fmt.Sprintf("%.1f%% (%d/%d)", percent(f.LinesHit(), len(f.Lines)), f.LinesHit(), len(f.Lines)),
+			Expressions: fmt.Sprintf("%.1f%% (%d/%d)", percent(f.ExpressionsHit, f.Expressions), f.ExpressionsHit, f.Expressions),
+		}
+		for n, text := range strings.Split(strings.TrimSuffix(f.Source, "\n"), "\n") {
+			line := htmlLine{Number: n + 1, Text: text}
+			if count, ok := counts[n+1]; ok {
+				line.Class = "miss"
+				if count > 0 {
+					line.Class = "hit"
+				}
+				line.Count = fmt.Sprintf("%d", count)
+			}
+			hf.Source = append(hf.Source, line)
+		}
+		page.Files = append(page.Files, hf)
+	}
+	return htmlTemplate.Execute(w, page)
+}
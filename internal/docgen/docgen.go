@@ -0,0 +1,172 @@
+// Package docgen builds a reference site from function documentation and
+// checks the examples in it.
+//
+// Natives carry a docmodel.FuncDoc written in Go; user functions get one
+// from the docstrings in their fn spec. Collect gathers the documented
+// functions of a frame, WriteSite renders them as Markdown and HTML pages,
+// one per category, with a search index, and RunExample runs an example as
+// a doctest against its `; =>` annotations.
+package docgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/docmodel"
+	"github.com/marcin-radoszewski/viro/internal/value"
+)
+
+// Entry is a documented function.
+type Entry struct {
+	Name     string
+	Aliases  []string // other names bound to the same function documentation
+	Category string
+	Doc      *docmodel.FuncDoc
+	Native   bool
+	Source   string // file defining a user function, "" for natives
+}
+
+// Collect returns the documented functions bound in f, including those in
+// objects bound in f, such as bit.and. Names sharing one documentation are
+// one entry. keep, when not nil, selects the functions to collect.
+func Collect(f core.Frame, keep func(name string, fn *value.FunctionValue) bool) []Entry {
+	byDoc := make(map[*docmodel.FuncDoc]*Entry)
+	var order []*docmodel.FuncDoc
+	add := func(name string, v core.Value) {
+		fn, ok := value.AsFunctionValue(v)
+		if !ok || !fn.Doc.HasDoc() || (keep != nil && !keep(name, fn)) {
+			return
+		}
+		if e, ok := byDoc[fn.Doc]; ok {
+			e.Aliases = append(e.Aliases, name)
+			return
+		}
+		byDoc[fn.Doc] = &Entry{Name: name, Category: fn.Doc.Category, Doc: fn.Doc, Native: fn.Type == value.FuncNative}
+		order = append(order, fn.Doc)
+	}
+
+	for _, binding := range f.GetAll() {
+		if binding.Value.GetType() == value.TypeObject {
+			obj, _ := value.AsObject(binding.Value)
+			for _, field := range obj.GetAllFieldsWithProto() {
+				add(binding.Symbol+"."+field.Symbol, field.Value)
+			}
+			continue
+		}
+		add(binding.Symbol, binding.Value)
+	}
+
+	entries := make([]Entry, 0, len(order))
+	for _, doc := range order {
+		e := byDoc[doc]
+		e.pickName()
+		entries = append(entries, *e)
+	}
+	SortEntries(entries)
+	return entries
+}
+
+// pickName makes the most readable name the entry's name: one with letters
+// over an operator, so bit.and is documented with & as its alias.
+func (e *Entry) pickName() {
+	names := append([]string{e.Name}, e.Aliases...)
+	sort.SliceStable(names, func(i, j int) bool {
+		if hasLetter(names[i]) != hasLetter(names[j]) {
+			return hasLetter(names[i])
+		}
+		return names[i] < names[j]
+	})
+	e.Name, e.Aliases = names[0], names[1:]
+}
+
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// SortEntries orders entries by category and then by name.
+func SortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Category != entries[j].Category {
+			return entries[i].Category < entries[j].Category
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// Usage returns the call pattern, such as `copy series --part length`.
+func (e Entry) Usage() string {
+	parts := []string{e.Name}
+	for _, p := range e.Doc.Parameters {
+		parts = append(parts, p.Name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// pageName turns a category into a file name without extension: "I/O"
+// becomes "i-o".
+func pageName(category string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(category) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" || name == "index" {
+		name = "category-" + name
+	}
+	return name
+}
+
+// anchor turns a function name into an HTML id. Characters other than
+// letters, digits, '.' and '-' are spelled as -xx hex codes so operator
+// names stay distinct: "+" becomes "-2b" and "exists?" "exists-3f".
+func anchor(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "-%x", r)
+		}
+	}
+	return b.String()
+}
+
+// index maps each function name and alias to the entry documenting it.
+type index map[string]*Entry
+
+func newIndex(entries []Entry) index {
+	idx := make(index, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		idx[e.Name] = e
+		for _, alias := range e.Aliases {
+			idx[alias] = e
+		}
+	}
+	return idx
+}
+
+// UnknownSeeAlso lists see-also references to functions that are not
+// documented, as "name: target" pairs.
+func UnknownSeeAlso(entries []Entry) []string {
+	idx := newIndex(entries)
+	var unknown []string
+	for _, e := range entries {
+		for _, ref := range e.Doc.SeeAlso {
+			if _, ok := idx[ref]; !ok {
+				unknown = append(unknown, e.Name+": "+ref)
+			}
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,176 @@
+package docgen
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/docmodel"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/trace"
+)
+
+func TestParseExample(t *testing.T) {
+	checks, rest := ParseExample("x: 2\ny: x * 3  ; => 6\nx + y  ; => 8 (sum)\nprint y")
+	if len(checks) != 2 {
+		t.Fatalf("checks = %+v, want 2", checks)
+	}
+	if checks[0].Code != "x: 2\ny: x * 3" || checks[0].Want != "6" {
+		t.Errorf("first check = %+v", checks[0])
+	}
+	if checks[1].Code != "x + y" || checks[1].Want != "8 (sum)" {
+		t.Errorf("second check = %+v", checks[1])
+	}
+	if rest != "print y" {
+		t.Errorf("rest = %q, want print y", rest)
+	}
+
+	if checks, _ := ParseExample("print 1  ; prints: 1"); len(checks) != 0 {
+		t.Errorf("expected no checks without an annotation, got %+v", checks)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		want, got string
+		match     bool
+	}{
+		{"6", "6", true},
+		{"#{00} (overflow lost)", "#{00}", true},
+		{"#{00} (overflow lost)", "#{01}", false},
+		{"[1 2] (index at 2)", "[1 2]", true},
+		{"3.5", "3", false},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.want, tt.got); got != tt.match {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.want, tt.got, got, tt.match)
+		}
+	}
+}
+
+func TestRunExample(t *testing.T) {
+	e := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, strings.NewReader(""), true)
+	if m, ran := RunExample("+", 1, "1 + 2  ; => 3", e); m != nil || !ran {
+		t.Errorf("RunExample() = %v, %v; want a passing run", m, ran)
+	}
+	m, _ := RunExample("+", 2, "1 + 2  ; => 4", e)
+	if m == nil || m.Got != "3" || !strings.Contains(m.String(), "want: 4") {
+		t.Errorf("expected a mismatch, got %v", m)
+	}
+	m, _ = RunExample("+", 3, "1 + missing  ; => 4", e)
+	if m == nil || m.Err == nil {
+		t.Errorf("expected an error, got %v", m)
+	}
+	if _, ran := RunExample("print", 1, "print 1", e); ran {
+		t.Error("expected an example without annotations not to run")
+	}
+}
+
+func TestCollect(t *testing.T) {
+	e := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+	entries := Collect(e.GetFrameByIndex(0), nil)
+
+	var and *Entry
+	for i := range entries {
+		if entries[i].Name == "&" || entries[i].Name == "bit.and" {
+			and = &entries[i]
+		}
+		if i > 0 && entries[i-1].Category > entries[i].Category {
+			t.Fatalf("entries not sorted by category: %q after %q", entries[i].Category, entries[i-1].Category)
+		}
+	}
+	if and == nil || and.Name != "bit.and" || len(and.Aliases) != 1 || and.Aliases[0] != "&" {
+		t.Errorf("bit.and entry = %+v, want & as its alias", and)
+	}
+	if unknown := UnknownSeeAlso(entries); len(unknown) > 0 {
+		t.Errorf("see also of undocumented functions: %v", unknown)
+	}
+}
+
+func TestNames(t *testing.T) {
+	for in, want := range map[string]string{"I/O": "i-o", "Series": "series", "Index": "category-index", "": "category-"} {
+		if got := pageName(in); got != want {
+			t.Errorf("pageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{"bit.and": "bit.and", "+": "-2b", "exists?": "exists-3f", "to-string": "to-string"} {
+		if got := anchor(in); got != want {
+			t.Errorf("anchor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWriteSite(t *testing.T) {
+	entries := []Entry{
+		{Name: "area", Category: "Geometry", Source: "geometry.viro", Doc: &docmodel.FuncDoc{
+			Summary:    "Returns the area",
+			Parameters: []docmodel.ParamDoc{{Name: "w", Type: "any-type!", Description: "a | b"}},
+			Examples:   []string{"area 3 4  ; => 12"},
+			SeeAlso:    []string{"+", "missing"},
+		}},
+		{Name: "+", Category: "Math", Native: true, Doc: &docmodel.FuncDoc{Summary: "Adds <numbers>"}},
+	}
+	SortEntries(entries)
+	dir := t.TempDir()
+	if err := WriteSite(dir, "Test Reference", entries); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	md := read("geometry.md")
+	for _, want := range []string{"## `area`", "[`+`](math.md#-2b)", "`missing`", `a \| b`, "Defined in `geometry.viro`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("geometry.md lacks %q:\n%s", want, md)
+		}
+	}
+	html := read("geometry.html")
+	if !strings.Contains(html, `<a href="math.html#-2b">`) {
+		t.Errorf("geometry.html lacks the see-also link:\n%s", html)
+	}
+	if !strings.Contains(read("math.html"), "Adds &lt;numbers&gt;") {
+		t.Error("math.html does not escape the summary")
+	}
+	if !strings.Contains(read("index.md"), "[Geometry](geometry.md)") {
+		t.Error("index.md lacks the category link")
+	}
+
+	var search []SearchEntry
+	if err := json.Unmarshal([]byte(read("search-index.json")), &search); err != nil {
+		t.Fatal(err)
+	}
+	if len(search) != 2 || search[0].URL != "geometry.html#area" {
+		t.Errorf("search index = %+v", search)
+	}
+}
+
+// TestNativeExamples runs every annotated example of the natives, so their
+// documentation cannot drift from their behavior.
+func TestNativeExamples(t *testing.T) {
+	trace.InitTraceSilent()
+	defer func(root string) { native.SandboxRoot = root }(native.SandboxRoot)
+
+	entries := Collect(bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true).GetFrameByIndex(0), nil)
+	for _, e := range entries {
+		for i, example := range e.Doc.Examples {
+			if err := eval.InitSandbox(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			native.SandboxRoot = eval.SandboxRoot
+			evaluator := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, strings.NewReader(""), true)
+			if m, _ := RunExample(e.Name, i+1, example, evaluator); m != nil {
+				t.Errorf("%s", m)
+			}
+		}
+	}
+}
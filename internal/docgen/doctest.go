@@ -0,0 +1,103 @@
+package docgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+)
+
+// expectMarker introduces an expected result in an example.
+const expectMarker = "; =>"
+
+// Check is one checked step of an example: the code up to and including a
+// line annotated with `; =>`, and the result it should mold to.
+type Check struct {
+	Code string
+	Want string
+}
+
+// ParseExample splits an example into checks. Lines without an annotation
+// run as part of the next check; trailing ones are returned as rest so they
+// still run.
+func ParseExample(example string) (checks []Check, rest string) {
+	var pending []string
+	for _, line := range strings.Split(example, "\n") {
+		code, want, found := strings.Cut(line, expectMarker)
+		if !found {
+			pending = append(pending, line)
+			continue
+		}
+		pending = append(pending, strings.TrimRight(code, " \t"))
+		checks = append(checks, Check{Code: strings.Join(pending, "\n"), Want: strings.TrimSpace(want)})
+		pending = nil
+	}
+	return checks, strings.TrimSpace(strings.Join(pending, "\n"))
+}
+
+// Matches reports whether got, a molded result, is what want expects. A
+// parenthesized note after the expected value is ignored, as in
+// `; => #{00} (overflow lost)`.
+func Matches(want, got string) bool {
+	if want == got {
+		return true
+	}
+	note, ok := strings.CutPrefix(want, got+" (")
+	return ok && strings.HasSuffix(note, ")")
+}
+
+// Mismatch is an example whose result differs from its annotation, or that
+// failed to run.
+type Mismatch struct {
+	Function string
+	Example  int // 1-based index into Examples
+	Code     string
+	Want     string
+	Got      string
+	Err      error
+}
+
+func (m Mismatch) String() string {
+	code := strings.ReplaceAll(m.Code, "\n", " ")
+	if m.Err != nil {
+		msg, _, _ := strings.Cut(m.Err.Error(), "\n")
+		return fmt.Sprintf("%s example %d: %s\n    error: %s", m.Function, m.Example, code, msg)
+	}
+	return fmt.Sprintf("%s example %d: %s\n    want: %s\n    got:  %s", m.Function, m.Example, code, m.Want, m.Got)
+}
+
+// RunExample evaluates an example in eval and returns its first mismatch, or
+// nil. Examples without annotations are not run, since many of them read
+// files, wait or print and only illustrate usage; ran reports whether the
+// example was run.
+func RunExample(function string, index int, example string, eval core.Evaluator) (mismatch *Mismatch, ran bool) {
+	checks, rest := ParseExample(example)
+	if len(checks) == 0 {
+		return nil, false
+	}
+	source := fmt.Sprintf("%s example %d", function, index)
+	for _, c := range checks {
+		result, err := evalCode(c.Code, source, eval)
+		if err != nil {
+			return &Mismatch{Function: function, Example: index, Code: c.Code, Want: c.Want, Err: err}, true
+		}
+		if got := result.Mold(); !Matches(c.Want, got) {
+			return &Mismatch{Function: function, Example: index, Code: c.Code, Want: c.Want, Got: got}, true
+		}
+	}
+	if rest != "" {
+		if _, err := evalCode(rest, source, eval); err != nil {
+			return &Mismatch{Function: function, Example: index, Code: rest, Err: err}, true
+		}
+	}
+	return nil, true
+}
+
+func evalCode(code, source string, eval core.Evaluator) (core.Value, error) {
+	values, locations, err := parse.ParseWithSource(code, source)
+	if err != nil {
+		return nil, err
+	}
+	return eval.DoBlock(values, locations)
+}
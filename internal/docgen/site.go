@@ -0,0 +1,305 @@
+package docgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchEntry is one function in search-index.json.
+type SearchEntry struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Category string   `json:"category"`
+	Summary  string   `json:"summary"`
+	Tags     []string `json:"tags,omitempty"`
+	URL      string   `json:"url"` // HTML page and anchor
+}
+
+// category is a page of the site.
+type category struct {
+	Name    string
+	Page    string
+	Entries []Entry
+}
+
+func categories(entries []Entry) []category {
+	var cats []category
+	for _, e := range entries {
+		if len(cats) == 0 || cats[len(cats)-1].Name != e.Category {
+			cats = append(cats, category{Name: e.Category, Page: pageName(e.Category)})
+		}
+		cats[len(cats)-1].Entries = append(cats[len(cats)-1].Entries, e)
+	}
+	return cats
+}
+
+// WriteSite writes the reference to dir: index.md and index.html listing
+// the categories, a Markdown and an HTML page per category, and
+// search-index.json. entries must be sorted with SortEntries.
+func WriteSite(dir, title string, entries []Entry) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	cats := categories(entries)
+	idx := newIndex(entries)
+
+	search := make([]SearchEntry, 0, len(entries))
+	for _, e := range entries {
+		search = append(search, SearchEntry{
+			Name:     e.Name,
+			Aliases:  e.Aliases,
+			Category: e.Category,
+			Summary:  e.Doc.Summary,
+			Tags:     e.Doc.Tags,
+			URL:      pageName(e.Category) + ".html#" + anchor(e.Name),
+		})
+	}
+	searchJSON, err := json.MarshalIndent(search, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"index.md":          indexMarkdown(title, cats),
+		"search-index.json": string(searchJSON) + "\n",
+	}
+	for _, cat := range cats {
+		files[cat.Page+".md"] = categoryMarkdown(title, cat, idx)
+	}
+
+	var b strings.Builder
+	page := htmlPage{Title: title, Categories: cats, Search: template.JS(searchJSON)}
+	if err := htmlTemplate.ExecuteTemplate(&b, "index", page); err != nil {
+		return err
+	}
+	files["index.html"] = b.String()
+	for _, cat := range cats {
+		b.Reset()
+		page.Current = &htmlCategory{Name: cat.Name, Functions: htmlFunctions(cat, idx)}
+		if err := htmlTemplate.ExecuteTemplate(&b, "category", page); err != nil {
+			return err
+		}
+		files[cat.Page+".html"] = b.String()
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// link returns the page and anchor documenting name, for pages with the
+// given extension, or "" when name is not documented.
+func (idx index) link(name, ext string) string {
+	e, ok := idx[name]
+	if !ok {
+		return ""
+	}
+	return pageName(e.Category) + ext + "#" + anchor(e.Name)
+}
+
+func indexMarkdown(title string, cats []category) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	b.WriteString("| Category | Functions |\n|---|---|\n")
+	for _, cat := range cats {
+		fmt.Fprintf(&b, "| [%s](%s.md) | %d |\n", cat.Name, cat.Page, len(cat.Entries))
+	}
+	for _, cat := range cats {
+		fmt.Fprintf(&b, "\n## %s\n\n", cat.Name)
+		for _, e := range cat.Entries {
+			fmt.Fprintf(&b, "- [`%s`](%s.md#%s) - %s\n", e.Name, cat.Page, anchor(e.Name), e.Doc.Summary)
+		}
+	}
+	return b.String()
+}
+
+func categoryMarkdown(title string, cat category, idx index) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n[%s](index.md)\n", cat.Name, title)
+	for _, e := range cat.Entries {
+		doc := e.Doc
+		fmt.Fprintf(&b, "\n<a id=\"%s\"></a>\n\n## `%s`\n\n%s\n\n", anchor(e.Name), e.Name, doc.Summary)
+		if len(e.Aliases) > 0 {
+			fmt.Fprintf(&b, "Also bound to %s.\n\n", codeList(e.Aliases))
+		}
+		if e.Source != "" {
+			fmt.Fprintf(&b, "Defined in `%s`.\n\n", e.Source)
+		}
+		fmt.Fprintf(&b, "```viro\n%s\n```\n\n", e.Usage())
+		if len(doc.Parameters) > 0 {
+			b.WriteString("| Parameter | Type | Description |\n|---|---|---|\n")
+			for _, p := range doc.Parameters {
+				desc := p.Description
+				if p.Optional {
+					desc += " (optional)"
+				}
+				fmt.Fprintf(&b, "| `%s` | `%s` | %s |\n", p.Name, p.Type, tableCell(desc))
+			}
+			b.WriteString("\n")
+		}
+		if doc.Returns != "" {
+			fmt.Fprintf(&b, "**Returns:** %s\n\n", doc.Returns)
+		}
+		if doc.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(doc.Description))
+		}
+		if len(doc.Examples) > 0 {
+			b.WriteString("**Examples:**\n\n```viro\n")
+			b.WriteString(strings.Join(doc.Examples, "\n"))
+			b.WriteString("\n```\n\n")
+		}
+		if len(doc.SeeAlso) > 0 {
+			refs := make([]string, len(doc.SeeAlso))
+			for i, ref := range doc.SeeAlso {
+				if target := idx.link(ref, ".md"); target != "" {
+					refs[i] = fmt.Sprintf("[`%s`](%s)", ref, target)
+				} else {
+					refs[i] = "`" + ref + "`"
+				}
+			}
+			fmt.Fprintf(&b, "**See also:** %s\n\n", strings.Join(refs, ", "))
+		}
+		if len(doc.Tags) > 0 {
+			fmt.Fprintf(&b, "**Tags:** %s\n", strings.Join(doc.Tags, ", "))
+		}
+	}
+	return b.String()
+}
+
+func codeList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// tableCell keeps text on one row of a Markdown table.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+type htmlPage struct {
+	Title      string
+	Categories []category
+	Current    *htmlCategory
+	Search     template.JS
+}
+
+type htmlCategory struct {
+	Name      string
+	Functions []htmlFunction
+}
+
+type htmlFunction struct {
+	Entry
+	Anchor  string
+	SeeAlso []htmlLink
+}
+
+type htmlLink struct {
+	Name string
+	URL  string // "" when the function is not documented
+}
+
+func htmlFunctions(cat category, idx index) []htmlFunction {
+	fns := make([]htmlFunction, len(cat.Entries))
+	for i, e := range cat.Entries {
+		fns[i] = htmlFunction{Entry: e, Anchor: anchor(e.Name)}
+		for _, ref := range e.Doc.SeeAlso {
+			fns[i].SeeAlso = append(fns[i].SeeAlso, htmlLink{Name: ref, URL: idx.link(ref, ".html")})
+		}
+	}
+	return fns
+}
+
+var htmlTemplate = template.Must(template.New("site").Funcs(template.FuncMap{
+	"anchor": anchor,
+	"join":   strings.Join,
+	"code":   func(examples []string) string { return strings.Join(examples, "\n") },
+}).Parse(`{{define "head"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .Current}}{{.Current.Name}} - {{end}}{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; color: #222; display: flex; }
+nav { width: 14em; padding: 1em; background: #f4f4f4; min-height: 100vh; }
+nav a { display: block; color: #225; text-decoration: none; padding: 0.1em 0; }
+main { padding: 1em 2em; max-width: 50em; }
+input { width: 100%; box-sizing: border-box; margin-bottom: 1em; }
+#results a { display: block; }
+pre { background: #f6f6f6; padding: 0.5em; overflow-x: auto; }
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 0.8em; text-align: left; vertical-align: top; }
+h2 { border-top: 1px solid #ddd; padding-top: 1em; }
+.meta { color: #666; }
+.desc { white-space: pre-line; }
+</style>
+</head>
+<body>
+<nav>
+<input id="search" type="search" placeholder="Search" autocomplete="off">
+<div id="results"></div>
+<a href="index.html"><strong>{{.Title}}</strong></a>
+{{range .Categories}}<a href="{{.Page}}.html">{{.Name}}</a>
+{{end}}</nav>
+<main>
+{{end}}
+{{define "foot"}}</main>
+<script>
+const index = {{.Search}};
+const box = document.getElementById("search");
+const results = document.getElementById("results");
+box.addEventListener("input", () => {
+  const q = box.value.trim().toLowerCase();
+  results.replaceChildren();
+  if (!q) return;
+  const hits = index.filter(f => [f.name, f.summary, f.category, ...(f.aliases || []), ...(f.tags || [])]
+    .some(s => s.toLowerCase().includes(q)));
+  for (const f of hits.slice(0, 20)) {
+    const a = document.createElement("a");
+    a.href = f.url;
+    a.textContent = f.name + " - " + f.summary;
+    results.append(a);
+  }
+});
+</script>
+</body>
+</html>
+{{end}}
+{{define "index"}}{{template "head" .}}<h1>{{.Title}}</h1>
+<table>
+<tr><th>Category</th><th>Functions</th></tr>
+{{range .Categories}}<tr><td><a href="{{.Page}}.html">{{.Name}}</a></td><td>{{len .Entries}}</td></tr>
+{{end}}</table>
+{{range .Categories}}<h2>{{.Name}}</h2>
+<table>
+{{$page := .Page}}{{range .Entries}}<tr><td><a href="{{$page}}.html#{{anchor .Name}}"><code>{{.Name}}</code></a></td><td>{{.Doc.Summary}}</td></tr>
+{{end}}</table>
+{{end}}{{template "foot" .}}{{end}}
+{{define "category"}}{{template "head" .}}<h1>{{.Current.Name}}</h1>
+{{range .Current.Functions}}<h2 id="{{.Anchor}}"><code>{{.Name}}</code></h2>
+<p>{{.Doc.Summary}}</p>
+{{if .Aliases}}<p class="meta">Also bound to {{range $i, $a := .Aliases}}{{if $i}}, {{end}}<code>{{$a}}</code>{{end}}.</p>
+{{end}}{{if .Source}}<p class="meta">Defined in <code>{{.Source}}</code>.</p>
+{{end}}<pre>{{.Usage}}</pre>
+{{if .Doc.Parameters}}<table>
+<tr><th>Parameter</th><th>Type</th><th>Description</th></tr>
+{{range .Doc.Parameters}}<tr><td><code>{{.Name}}</code></td><td><code>{{.Type}}</code></td><td>{{.Description}}{{if .Optional}} (optional){{end}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Doc.Returns}}<p><strong>Returns:</strong> {{.Doc.Returns}}</p>
+{{end}}{{if .Doc.Description}}<p class="desc">{{.Doc.Description}}</p>
+{{end}}{{if .Doc.Examples}}<pre>{{code .Doc.Examples}}</pre>
+{{end}}{{if .SeeAlso}}<p><strong>See also:</strong> {{range $i, $l := .SeeAlso}}{{if $i}}, {{end}}{{if $l.URL}}<a href="{{$l.URL}}"><code>{{$l.Name}}</code></a>{{else}}<code>{{$l.Name}}</code>{{end}}{{end}}</p>
+{{end}}{{if .Doc.Tags}}<p class="meta">Tags: {{join .Doc.Tags ", "}}</p>
+{{end}}{{end}}{{template "foot" .}}{{end}}
+`))
@@ -0,0 +1,61 @@
+package eval
+
+import "github.com/marcin-radoszewski/viro/internal/core"
+
+// AllocationObserver is implemented by call observers that also want the
+// allocations counted while accounting is on. Allocated is called after the
+// evaluator has attributed the allocation, inside the current call.
+type AllocationObserver interface {
+	Allocated(kind core.AllocKind, n int)
+}
+
+// SetAllocationAccounting turns allocation accounting on or off. While it
+// is on the evaluator installs its own allocation hook (see
+// core.SetAllocationHook): series creation, series growth in append and
+// insert, and frames entering the frame store are counted against the
+// innermost user-defined function on the call stack, or "(top level)".
+// Turning it on starts from zero; turning it off removes only this
+// evaluator's hook.
+func (e *Evaluator) SetAllocationAccounting(on bool) {
+	if !on {
+		e.allocs = nil
+		core.SetAllocationHook(e, nil)
+		return
+	}
+	e.allocs = make(map[string]*core.AllocStats)
+	core.SetAllocationHook(e, e.countAllocation)
+}
+
+// AllocationAccounting reports whether allocation accounting is on.
+func (e *Evaluator) AllocationAccounting() bool {
+	return e.allocs != nil
+}
+
+// Allocations returns the counts per function since accounting was turned
+// on, or nil when it is off.
+func (e *Evaluator) Allocations() map[string]core.AllocStats {
+	if e.allocs == nil {
+		return nil
+	}
+	counts := make(map[string]core.AllocStats, len(e.allocs))
+	for name, s := range e.allocs {
+		counts[name] = *s
+	}
+	return counts
+}
+
+func (e *Evaluator) countAllocation(kind core.AllocKind, n int) {
+	if e.allocs == nil {
+		return
+	}
+	owner := e.callOwners[len(e.callOwners)-1]
+	s, ok := e.allocs[owner]
+	if !ok {
+		s = &core.AllocStats{}
+		e.allocs[owner] = s
+	}
+	s.Add(kind, n)
+	if o, ok := e.calls.(AllocationObserver); ok {
+		o.Allocated(kind, n)
+	}
+}
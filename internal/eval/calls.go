@@ -0,0 +1,20 @@
+package eval
+
+// CallObserver is told when the evaluator enters and leaves a function,
+// natives included. Calls nest: every EnterCall is matched by an ExitCall,
+// also when the call fails. Names are those of the call stack shown in
+// error locations.
+type CallObserver interface {
+	EnterCall(name string)
+	ExitCall()
+}
+
+// SetCallObserver installs a call observer, or removes it when o is nil.
+func (e *Evaluator) SetCallObserver(o CallObserver) {
+	e.calls = o
+}
+
+// CallObserver returns the installed call observer, or nil.
+func (e *Evaluator) CallObserver() CallObserver {
+	return e.calls
+}
@@ -0,0 +1,415 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Block compilation.
+//
+// The interpreter rediscovers the shape of every expression each time a block
+// runs: it resolves words, splits the function's parameters into positional
+// and refinement specs, allocates bookkeeping maps and checks each token for
+// refinement syntax. With compilation enabled, DoBlock first turns the block
+// into a compiledBlock holding one site per position. A site records what the
+// token is (word, set-word, refinement, other) and, for words that resolved to
+// a function, that function and its parameter layout (arity, refinements).
+//
+// Sites are a cache, not a commitment: every execution still looks the word up
+// and the layout is reused only while the word resolves to the same function.
+// Rebinding a word to another function (and so possibly another arity or infix
+// flag) recompiles that site. A block edited in place is detected per site by
+// comparing the token's type and symbol; a block whose length changed is
+// recompiled whole. Anything that is not a word or set-word, and every
+// expression evaluated while tracing or debugging, goes through the
+// interpreter, so results and error locations are the same on both paths.
+
+// maxCompiledBlocks bounds the compiled block cache. Blocks built at run time
+// (compose, reduce, load) would otherwise keep it growing.
+const maxCompiledBlocks = 4096
+
+type siteKind uint8
+
+const (
+	siteOther siteKind = iota
+	siteWord
+	siteSetWord
+)
+
+type compiledBlock struct {
+	elems []core.Value
+	sites []callSite
+}
+
+type callSite struct {
+	kind       siteKind
+	typ        core.ValueType
+	sym        core.Symbol
+	name       string
+	refinement bool // word spelled --name
+	fn         *value.FunctionValue
+	layout     *paramLayout
+}
+
+// paramLayout is the result of separateParameters for one function value.
+type paramLayout struct {
+	positional []value.ParamSpec
+	refSpecs   map[string]value.ParamSpec
+}
+
+// SetCompile switches block compilation on or off. Turning it off drops the
+// compiled block cache.
+func (e *Evaluator) SetCompile(enabled bool) {
+	e.compileEnabled = enabled
+	if !enabled {
+		e.compiled = nil
+	}
+}
+
+// CompileEnabled reports whether DoBlock compiles blocks before evaluating them.
+func (e *Evaluator) CompileEnabled() bool {
+	return e.compileEnabled
+}
+
+// compiledBlockFor returns the cached compilation of vals, compiling it if the
+// block is new or its length changed.
+func (e *Evaluator) compiledBlockFor(vals []core.Value) *compiledBlock {
+	key := &vals[0]
+	if cb, ok := e.compiled[key]; ok && len(cb.elems) == len(vals) {
+		return cb
+	}
+	if e.compiled == nil || len(e.compiled) >= maxCompiledBlocks {
+		e.compiled = make(map[*core.Value]*compiledBlock)
+	}
+	cb := &compiledBlock{elems: vals, sites: make([]callSite, len(vals))}
+	for i := range vals {
+		cb.sites[i] = compileSite(vals[i])
+	}
+	e.compiled[key] = cb
+	return cb
+}
+
+func compileSite(elem core.Value) callSite {
+	site := callSite{kind: siteOther, typ: elem.GetType()}
+	switch site.typ {
+	case value.TypeWord:
+		site.kind = siteWord
+		site.name, _ = value.AsWordValue(elem)
+		site.sym, _ = value.AsWordSymbol(elem)
+		site.refinement = strings.HasPrefix(site.name, "--")
+	case value.TypeSetWord:
+		site.kind = siteSetWord
+		site.name, _ = value.AsWordValue(elem)
+		site.sym, _ = value.AsWordSymbol(elem)
+	}
+	return site
+}
+
+// site returns the site for position, recompiling it if the block was edited
+// in place since it was compiled.
+func (cb *compiledBlock) site(position int) *callSite {
+	site := &cb.sites[position]
+	elem := cb.elems[position]
+	if elem.GetType() != site.typ {
+		*site = compileSite(elem)
+	} else if site.kind != siteOther {
+		if sym, _ := value.AsWordSymbol(elem); sym != site.sym {
+			*site = compileSite(elem)
+		}
+	}
+	return site
+}
+
+// resolveCall reports the function a word site currently resolves to, reusing
+// the cached parameter layout when the binding has not changed.
+func (site *callSite) resolveCall(e *Evaluator, fn *value.FunctionValue) *paramLayout {
+	if site.fn != fn || site.layout == nil {
+		positional, refSpecs := e.separateParameters(fn)
+		site.fn = fn
+		site.layout = &paramLayout{positional: positional, refSpecs: refSpecs}
+	}
+	return site.layout
+}
+
+// interpreterOnly reports whether expressions must take the interpreter path
+// because they are being traced or their coverage recorded. Breakpoints only
+// report through the trace session, so an untraced expression cannot observe
+// them.
+func (e *Evaluator) interpreterOnly() bool {
+	return e.traceEnabled || e.traceShouldTraceExpr || e.coverage != nil
+}
+
+func (e *Evaluator) doCompiledBlock(cb *compiledBlock, locations []core.SourceLocation) (core.Value, error) {
+	position := 0
+	lastResult := value.NewNoneVal()
+
+	for position < len(cb.elems) {
+		newPos, result, err := e.evalCompiledExpression(cb, locations, position)
+		if err != nil {
+			return value.NewNoneVal(), e.annotateError(err, cb.elems, locations, position)
+		}
+		position = newPos
+		lastResult = result
+	}
+
+	return lastResult, nil
+}
+
+// evalCompiledExpression is EvaluateExpression over a compiled block.
+func (e *Evaluator) evalCompiledExpression(cb *compiledBlock, locations []core.SourceLocation, position int) (int, core.Value, error) {
+	if e.interpreterOnly() {
+		return e.EvaluateExpression(cb.elems, locations, position)
+	}
+
+	newPos, result, err := e.evalCompiledElement(cb, locations, position)
+	if err != nil {
+		return position, value.NewNoneVal(), err
+	}
+
+	for newPos < len(cb.elems) {
+		fn, layout, ok := e.compiledInfixAt(cb, newPos)
+		if !ok {
+			break
+		}
+
+		nextPos, nextResult, err := e.consumeCompiledInfix(cb, locations, newPos, fn, layout, result)
+		if err != nil {
+			return position, value.NewNoneVal(), err
+		}
+
+		newPos = nextPos
+		result = nextResult
+	}
+
+	return newPos, result, nil
+}
+
+// evalCompiledElement is evaluateElement over a compiled block. Only words and
+// set-words have compiled forms.
+func (e *Evaluator) evalCompiledElement(cb *compiledBlock, locations []core.SourceLocation, position int) (int, core.Value, error) {
+	if position >= len(cb.elems) {
+		return e.evaluateElement(cb.elems, locations, position)
+	}
+
+	site := cb.site(position)
+	switch site.kind {
+	case siteWord:
+		resolved, found := e.LookupSymbol(site.sym)
+		if !found {
+			return position, value.NewNoneVal(), verror.NewScriptError(verror.ErrIDNoValue, [3]string{site.name, "", ""})
+		}
+		fn, ok := value.AsFunctionValue(resolved)
+		if !ok {
+			return position + 1, resolved, nil
+		}
+		return e.invokeCompiledCall(cb, locations, position, fn, site.resolveCall(e, fn))
+	case siteSetWord:
+		return e.evalCompiledSetWord(cb, locations, position, site)
+	default:
+		return e.evaluateElement(cb.elems, locations, position)
+	}
+}
+
+func (e *Evaluator) evalCompiledSetWord(cb *compiledBlock, locations []core.SourceLocation, position int, site *callSite) (int, core.Value, error) {
+	if position+1 >= len(cb.elems) {
+		return position, value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDNoValue,
+			[3]string{site.name, "set-word-without-value", site.name},
+		)
+	}
+
+	newPos, result, err := e.evalCompiledExpression(cb, locations, position+1)
+	if err != nil {
+		return position, value.NewNoneVal(), e.annotateError(err, cb.elems, locations, position)
+	}
+
+	if fnVal, ok := value.AsFunctionValue(result); ok && fnVal.Name == "" {
+		fnVal.Name = site.name
+	}
+
+	currentFrame := e.currentFrame()
+	if f, ok := currentFrame.(*frame.Frame); ok {
+		f.BindSymbol(site.sym, site.name, result)
+	} else {
+		currentFrame.Bind(site.name, result)
+	}
+
+	return newPos, result, nil
+}
+
+func (e *Evaluator) compiledInfixAt(cb *compiledBlock, position int) (*value.FunctionValue, *paramLayout, bool) {
+	site := cb.site(position)
+	if site.kind != siteWord {
+		return nil, nil, false
+	}
+	resolved, found := e.LookupSymbol(site.sym)
+	if !found {
+		return nil, nil, false
+	}
+	fn, ok := value.AsFunctionValue(resolved)
+	if !ok || !fn.Infix {
+		return nil, nil, false
+	}
+	return fn, site.resolveCall(e, fn), true
+}
+
+func (e *Evaluator) invokeCompiledCall(cb *compiledBlock, locations []core.SourceLocation, position int, fn *value.FunctionValue, layout *paramLayout) (int, core.Value, error) {
+	name := functionDisplayName(fn)
+	e.pushCall(name, fn.Type == value.FuncNative)
+	defer e.popCall()
+
+	posArgs, refValues, newPos, err := e.collectCompiledArgs(fn, layout, cb, locations, position+1, 0, false)
+	if err != nil {
+		return position, value.NewNoneVal(), e.annotateError(err, cb.elems, locations, position)
+	}
+
+	var result core.Value
+	if fn.Type == value.FuncNative {
+		result, err = e.callNativeFunction(fn, posArgs, refValues, name, position, time.Time{})
+	} else {
+		result, err = e.callUserDefinedFunction(fn, posArgs, refValues, name, position, time.Time{})
+	}
+	if err != nil {
+		return position, value.NewNoneVal(), err
+	}
+
+	return newPos, result, nil
+}
+
+func (e *Evaluator) consumeCompiledInfix(cb *compiledBlock, locations []core.SourceLocation, position int, fn *value.FunctionValue, layout *paramLayout, leftOperand core.Value) (int, core.Value, error) {
+	name := functionDisplayName(fn)
+	e.pushCall(name, fn.Type == value.FuncNative)
+	defer e.popCall()
+
+	if len(layout.positional) == 0 {
+		return position, value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDArgCount,
+			[3]string{name, "0", "1 (infix requires at least one parameter)"},
+		)
+	}
+
+	posArgs, refValues, newPos, err := e.collectCompiledArgs(fn, layout, cb, locations, position+1, 1, true)
+	if err != nil {
+		return position, value.NewNoneVal(), e.annotateError(err, cb.elems, locations, position)
+	}
+
+	posArgs[0] = leftOperand
+
+	if fn.Type == value.FuncNative {
+		result, err := e.callNative(fn, posArgs, refValues)
+		if err != nil {
+			return position, value.NewNoneVal(), e.annotateError(err, cb.elems, locations, position)
+		}
+		return newPos, result, nil
+	}
+
+	result, err := e.executeFunction(fn, posArgs, refValues)
+	if err != nil {
+		return position, value.NewNoneVal(), err
+	}
+	return newPos, result, nil
+}
+
+// collectCompiledArgs is collectFunctionArgs using a cached layout. Functions
+// without refinements get a nil refinement map (reads behave as on an empty
+// one), and the bookkeeping map is only allocated when a refinement is present.
+func (e *Evaluator) collectCompiledArgs(fn *value.FunctionValue, layout *paramLayout, cb *compiledBlock, locations []core.SourceLocation, startPosition int, startParamIndex int, useElementEval bool) ([]core.Value, map[string]core.Value, int, error) {
+	var refValues map[string]core.Value
+	if len(layout.refSpecs) > 0 {
+		refValues = e.initializeRefinements(layout.refSpecs)
+	}
+	var refProvided map[string]bool
+
+	positional := layout.positional
+	posArgs := make([]core.Value, len(positional))
+	position := startPosition
+	var err error
+
+	for paramIndex := startParamIndex; paramIndex < len(positional); paramIndex++ {
+		position, err = e.readCompiledRefinements(cb, locations, position, layout.refSpecs, refValues, &refProvided)
+		if err != nil {
+			return nil, nil, position, err
+		}
+
+		if position >= len(cb.elems) {
+			for i := paramIndex; i < len(positional); i++ {
+				if !positional[i].Optional {
+					return nil, nil, position, verror.NewScriptError(
+						verror.ErrIDArgCount,
+						[3]string{functionDisplayName(fn), strconv.Itoa(len(positional)), strconv.Itoa(paramIndex)},
+					)
+				}
+				posArgs[i] = value.NewNoneVal()
+			}
+			break
+		}
+
+		switch {
+		case !positional[paramIndex].Eval:
+			posArgs[paramIndex] = cb.elems[position]
+			position++
+		case useElementEval:
+			position, posArgs[paramIndex], err = e.evalCompiledElement(cb, locations, position)
+		default:
+			position, posArgs[paramIndex], err = e.evalCompiledExpression(cb, locations, position)
+		}
+		if err != nil {
+			return nil, nil, position, err
+		}
+	}
+
+	position, err = e.readCompiledRefinements(cb, locations, position, layout.refSpecs, refValues, &refProvided)
+	if err != nil {
+		return nil, nil, position, err
+	}
+
+	return posArgs, refValues, position, nil
+}
+
+func (e *Evaluator) readCompiledRefinements(cb *compiledBlock, locations []core.SourceLocation, pos int, refSpecs map[string]value.ParamSpec, refValues map[string]core.Value, refProvided *map[string]bool) (int, error) {
+	for pos < len(cb.elems) {
+		site := cb.site(pos)
+		if site.kind != siteWord || !site.refinement {
+			break
+		}
+		refName := site.name[2:]
+
+		spec, exists := refSpecs[refName]
+		if !exists {
+			return pos, refinementError("unknown", refName)
+		}
+
+		if *refProvided == nil {
+			*refProvided = make(map[string]bool)
+		}
+		if (*refProvided)[refName] {
+			return pos, refinementError("duplicate", refName)
+		}
+
+		if spec.TakesValue {
+			if pos+1 >= len(cb.elems) {
+				return pos, refinementError("missing-value", refName)
+			}
+			var arg core.Value
+			var err error
+			pos, arg, err = e.evalCompiledExpression(cb, locations, pos+1)
+			if err != nil {
+				return pos, err
+			}
+			refValues[refName] = arg
+		} else {
+			refValues[refName] = value.NewLogicVal(true)
+			pos++
+		}
+
+		(*refProvided)[refName] = true
+	}
+
+	return pos, nil
+}
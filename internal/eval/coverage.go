@@ -0,0 +1,14 @@
+package eval
+
+import "github.com/marcin-radoszewski/viro/internal/core"
+
+// CoverageHook is told the source location of every value the evaluator
+// evaluates. Locations of values inside data blocks are never reported.
+type CoverageHook func(loc core.SourceLocation)
+
+// SetCoverageHook installs a coverage hook, or removes it when hook is nil.
+// While a hook is installed blocks are interpreted even with compilation
+// on, since compiled calls skip the per-value evaluation the hook observes.
+func (e *Evaluator) SetCoverageHook(hook CoverageHook) {
+	e.coverage = hook
+}
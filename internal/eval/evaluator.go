@@ -3,10 +3,12 @@ package eval
 import (
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+	"weak"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/debug"
@@ -18,11 +20,17 @@ import (
 )
 
 type Evaluator struct {
-	Stack        *stack.Stack
-	Frames       []core.Frame
-	frameStore   []core.Frame
-	captured     map[int]bool
-	callStack    []string
+	Stack      *stack.Stack
+	Frames     []core.Frame
+	frameStore []core.Frame
+	weakFrames []weak.Pointer[frame.Frame]
+	freeSlots  []int
+	storeStats frameStoreStats
+	captured   map[int]bool
+	callStack  []string
+	// callOwners holds, for each callStack entry, the innermost user-defined
+	// function at that depth; allocations are attributed to it.
+	callOwners   []string
 	OutputWriter io.Writer
 	ErrorWriter  io.Writer
 	InputReader  io.Reader
@@ -33,6 +41,42 @@ type Evaluator struct {
 	// or modifying trace filters) to ensure cache consistency.
 	traceEnabled         bool
 	traceShouldTraceExpr bool
+
+	// bindCache remembers where each symbol was last resolved, indexed by symbol.
+	bindCache []bindingCacheEntry
+
+	// compiled caches block compilations keyed by the block's first element;
+	// see compile.go.
+	compileEnabled bool
+	compiled       map[*core.Value]*compiledBlock
+
+	// decimalContext is the active decimal! context; with-decimal-context
+	// replaces it for the duration of a block.
+	decimalContext core.DecimalContext
+
+	// randomSource drives random; it starts from an unpredictable seed and
+	// random --seed reseeds it, so seeded sequences repeat per evaluator.
+	randomSource *rand.PCG
+	random       *rand.Rand
+
+	// coverage, when set, records evaluated locations; see coverage.go.
+	coverage CoverageHook
+	// calls, when set, observes function calls; see calls.go.
+	calls CallObserver
+	// allocs holds allocation counts per function while accounting is on;
+	// see alloc.go.
+	allocs map[string]*core.AllocStats
+}
+
+// bindingCacheEntry records that a lookup of a symbol starting in frame start
+// found it in slot of frame found. The entry is valid while the binding epoch
+// is unchanged: frames never drop bindings and parents never change, so only a
+// new word in a frame between start and found can alter the result.
+type bindingCacheEntry struct {
+	start *frame.Frame
+	found *frame.Frame
+	slot  int
+	epoch uint64
 }
 
 func NewEvaluator() *Evaluator {
@@ -43,12 +87,19 @@ func NewEvaluator() *Evaluator {
 		Stack:        stack.NewStack(1024),
 		Frames:       []core.Frame{global},
 		frameStore:   []core.Frame{global},
+		weakFrames:   make([]weak.Pointer[frame.Frame], 1),
+		storeStats:   frameStoreStats{nextSweep: minSweepThreshold},
 		captured:     make(map[int]bool),
 		callStack:    []string{"(top level)"},
+		callOwners:   []string{"(top level)"},
 		OutputWriter: os.Stdout,
 		ErrorWriter:  os.Stderr,
 		InputReader:  os.Stdin,
+
+		decimalContext: core.DefaultDecimalContext,
+		randomSource:   rand.NewPCG(rand.Uint64(), rand.Uint64()),
 	}
+	e.random = rand.New(e.randomSource)
 	e.captured[0] = true
 
 	frame.InitTypeFrames()
@@ -94,6 +145,22 @@ func (e *Evaluator) GetInputReader() io.Reader {
 	return e.InputReader
 }
 
+func (e *Evaluator) DecimalContext() core.DecimalContext {
+	return e.decimalContext
+}
+
+func (e *Evaluator) SetDecimalContext(ctx core.DecimalContext) {
+	e.decimalContext = ctx
+}
+
+func (e *Evaluator) Random() *rand.Rand {
+	return e.random
+}
+
+func (e *Evaluator) SeedRandom(seed uint64) {
+	e.randomSource.Seed(seed, seed)
+}
+
 func (e *Evaluator) UpdateTraceCache() {
 	if trace.GlobalTraceSession == nil {
 		e.traceEnabled = false
@@ -126,19 +193,26 @@ func (e *Evaluator) popFrame() int {
 	frm := e.Frames[len(e.Frames)-1]
 	e.Frames = e.Frames[:len(e.Frames)-1]
 	idx := frm.GetIndex()
-	if !e.captured[idx] {
-		e.frameStore[idx] = nil
-	} else if frm.GetType() != frame.FrameClosure {
+	if e.captured[idx] && frm.GetType() != frame.FrameClosure {
 		frm.ChangeType(frame.FrameClosure)
 	}
+	e.releaseFrame(idx, frm)
 	return idx
 }
 
-func (e *Evaluator) pushCall(name string) {
+func (e *Evaluator) pushCall(name string, native bool) {
 	if name == "" {
 		name = "(anonymous)"
 	}
 	e.callStack = append(e.callStack, name)
+	owner := name
+	if native {
+		owner = e.callOwners[len(e.callOwners)-1]
+	}
+	e.callOwners = append(e.callOwners, owner)
+	if e.calls != nil {
+		e.calls.EnterCall(name)
+	}
 }
 
 func (e *Evaluator) popCall() {
@@ -146,6 +220,10 @@ func (e *Evaluator) popCall() {
 		return
 	}
 	e.callStack = e.callStack[:len(e.callStack)-1]
+	e.callOwners = e.callOwners[:len(e.callOwners)-1]
+	if e.calls != nil {
+		e.calls.ExitCall()
+	}
 }
 
 func (e *Evaluator) captureCallStack() []string {
@@ -194,6 +272,9 @@ func functionDisplayName(fn *value.FunctionValue) string {
 func (e *Evaluator) MarkFrameCaptured(idx int) {
 	if idx >= 0 {
 		e.captured[idx] = true
+		if f, ok := e.GetFrameByIndex(idx).(*frame.Frame); ok {
+			f.MarkReferenced()
+		}
 	}
 }
 
@@ -202,29 +283,34 @@ func (e *Evaluator) CurrentFrameIndex() int {
 }
 
 func (e *Evaluator) RegisterFrame(f core.Frame) int {
-	if f.GetIndex() >= 0 {
+	if e.isStored(f) {
 		return f.GetIndex()
 	}
-
-	idx := len(e.frameStore)
-	e.frameStore = append(e.frameStore, f)
-	f.SetIndex(idx)
-	return idx
+	return e.storeFrame(f)
 }
 
 func (e *Evaluator) GetFrameByIndex(idx int) core.Frame {
 	if idx < 0 || idx >= len(e.frameStore) {
 		return nil
 	}
-	return e.frameStore[idx]
+	if f := e.frameStore[idx]; f != nil {
+		return f
+	}
+	if f := e.weakFrames[idx].Value(); f != nil {
+		return f
+	}
+	return nil
 }
 
 func (e *Evaluator) PushFrameContext(f core.Frame) int {
 	idx := f.GetIndex()
-	if idx < 0 {
-		idx = len(e.frameStore)
-		e.frameStore = append(e.frameStore, f)
-		f.SetIndex(idx)
+	if !e.isStored(f) {
+		idx = e.storeFrame(f)
+	} else {
+		e.pinFrame(idx, f)
+	}
+	if fp, ok := f.(*frame.Frame); ok {
+		fp.Activate()
 	}
 	e.Frames = append(e.Frames, f)
 	return idx
@@ -235,15 +321,70 @@ func (e *Evaluator) PopFrameContext() {
 }
 
 func (e *Evaluator) Lookup(symbol string) (core.Value, bool) {
-	frame := e.currentFrame()
-	for frame != nil {
-		if val, ok := frame.Get(symbol); ok {
+	sym, ok := core.LookupSymbol(symbol)
+	if !ok {
+		return value.NewNoneVal(), false
+	}
+	return e.LookupSymbol(sym)
+}
+
+// LookupSymbol resolves an interned word through the current frame chain,
+// consulting and refreshing the binding cache.
+func (e *Evaluator) LookupSymbol(sym core.Symbol) (core.Value, bool) {
+	start, ok := e.currentFrame().(*frame.Frame)
+	if !ok {
+		return e.lookupUncached(sym)
+	}
+
+	epoch := frame.BindingEpoch()
+	if int(sym) < len(e.bindCache) {
+		entry := &e.bindCache[sym]
+		if entry.start == start && entry.epoch == epoch {
+			return entry.found.ValueAt(entry.slot), true
+		}
+	}
+
+	var current core.Frame = start
+	for current != nil {
+		f, ok := current.(*frame.Frame)
+		if !ok {
+			return e.lookupUncached(sym)
+		}
+		if slot := f.Slot(sym); slot >= 0 {
+			e.cacheBinding(sym, bindingCacheEntry{start: start, found: f, slot: slot, epoch: epoch})
+			return f.ValueAt(slot), true
+		}
+		f.MarkObserved()
+		if f.GetParent() == -1 {
+			break
+		}
+		current = e.GetFrameByIndex(f.GetParent())
+	}
+	return value.NewNoneVal(), false
+}
+
+func (e *Evaluator) cacheBinding(sym core.Symbol, entry bindingCacheEntry) {
+	if int(sym) >= len(e.bindCache) {
+		grown := make([]bindingCacheEntry, int(sym)+1, 2*(int(sym)+1))
+		copy(grown, e.bindCache)
+		e.bindCache = grown
+	}
+	e.bindCache[sym] = entry
+}
+
+// lookupUncached walks the frame chain without the binding cache. Used for
+// frames that are not *frame.Frame.
+func (e *Evaluator) lookupUncached(sym core.Symbol) (core.Value, bool) {
+	name := sym.Name()
+	current := e.currentFrame()
+	for current != nil {
+		if val, ok := current.Get(name); ok {
 			return val, true
 		}
-		if frame.GetParent() == -1 {
+		if current.GetParent() == -1 {
 			break
 		}
-		frame = e.GetFrameByIndex(frame.GetParent())
+		current = e.GetFrameByIndex(current.GetParent())
 	}
 	return value.NewNoneVal(), false
 }
@@ -275,6 +416,10 @@ func (e *Evaluator) DoBlock(vals []core.Value, locations []core.SourceLocation)
 		return value.NewNoneVal(), nil
 	}
 
+	if e.compileEnabled && !e.traceEnabled {
+		return e.doCompiledBlock(e.compiledBlockFor(vals), locations)
+	}
+
 	position := 0
 	lastResult := value.NewNoneVal()
 
@@ -307,12 +452,12 @@ func (e *Evaluator) isNextInfixOperator(block []core.Value, position int) bool {
 		return false
 	}
 
-	word, ok := value.AsWordValue(nextElement)
+	sym, ok := value.AsWordSymbol(nextElement)
 	if !ok {
 		return false
 	}
 
-	resolved, found := e.Lookup(word)
+	resolved, found := e.LookupSymbol(sym)
 	if !found {
 		return false
 	}
@@ -322,13 +467,12 @@ func (e *Evaluator) isNextInfixOperator(block []core.Value, position int) bool {
 }
 
 func (e *Evaluator) consumeInfixOperator(block []core.Value, locations []core.SourceLocation, position int, leftOperand core.Value) (int, core.Value, error) {
-	wordElement := block[position]
-	word, _ := value.AsWordValue(wordElement)
-	resolved, _ := e.Lookup(word)
+	sym, _ := value.AsWordSymbol(block[position])
+	resolved, _ := e.LookupSymbol(sym)
 	fn, _ := value.AsFunctionValue(resolved)
 
 	name := functionDisplayName(fn)
-	e.pushCall(name)
+	e.pushCall(name, fn.Type == value.FuncNative)
 	defer e.popCall()
 
 	positional, _ := e.separateParameters(fn)
@@ -390,7 +534,12 @@ func (e *Evaluator) evaluateSetWord(block []core.Value, locations []core.SourceL
 	}
 
 	currentFrame := e.currentFrame()
-	currentFrame.Bind(wordStr, result)
+	if f, ok := currentFrame.(*frame.Frame); ok {
+		sym, _ := value.AsWordSymbol(element)
+		f.BindSymbol(sym, wordStr, result)
+	} else {
+		currentFrame.Bind(wordStr, result)
+	}
 
 	if shouldTraceExpr {
 		e.emitTraceResult("eval", wordStr, fmt.Sprintf("%s:", wordStr), result, position, traceStart, nil)
@@ -406,7 +555,8 @@ func (e *Evaluator) evaluateWord(block []core.Value, locations []core.SourceLoca
 		debug.GlobalDebugger.HandleBreakpoint(wordStr, position, len(e.callStack)-1)
 	}
 
-	resolved, found := e.Lookup(wordStr)
+	sym, _ := value.AsWordSymbol(element)
+	resolved, found := e.LookupSymbol(sym)
 	if !found {
 		err := verror.NewScriptError(verror.ErrIDNoValue, [3]string{wordStr, "", ""})
 		if shouldTraceExpr {
@@ -458,6 +608,10 @@ func (e *Evaluator) evaluateElement(block []core.Value, locations []core.SourceL
 
 	element := block[position]
 
+	if e.coverage != nil && position < len(locations) {
+		e.coverage(locations[position])
+	}
+
 	var traceStart time.Time
 	if e.traceEnabled {
 		traceStart = time.Now()
@@ -467,7 +621,7 @@ func (e *Evaluator) evaluateElement(block []core.Value, locations []core.SourceL
 
 	switch element.GetType() {
 	case value.TypeInteger, value.TypeLogic,
-		value.TypeNone, value.TypeDecimal, value.TypeObject,
+		value.TypeNone, value.TypeDecimal, value.TypeFloat, value.TypeBigInt, value.TypeObject,
 		value.TypePort, value.TypeDatatype,
 		value.TypeFunction:
 		if shouldTraceExpr {
@@ -530,7 +684,8 @@ func (e *Evaluator) evaluateElement(block []core.Value, locations []core.SourceL
 
 	case value.TypeGetWord:
 		wordStr, _ := value.AsWordValue(element)
-		result, ok := e.Lookup(wordStr)
+		sym, _ := value.AsWordSymbol(element)
+		result, ok := e.LookupSymbol(sym)
 		if !ok {
 			err := verror.NewScriptError(verror.ErrIDNoValue, [3]string{wordStr, "", ""})
 			if shouldTraceExpr {
@@ -619,12 +774,12 @@ func (e *Evaluator) evalSetPathValue(block []core.Value, locations []core.Source
 	return newPos, result, nil
 }
 
-func (e *Evaluator) setupFunctionCallTracing(name string, position int, posArgs []core.Value, refValues map[string]core.Value) (time.Time, map[string]string) {
+func (e *Evaluator) setupFunctionCallTracing(fn *value.FunctionValue, name string, position int, posArgs []core.Value, refValues map[string]core.Value) (time.Time, map[string]string) {
 	var traceStart time.Time
 	var args map[string]string
 	if e.traceEnabled {
 		traceStart = time.Now()
-		args = e.captureFunctionArgs(nil, posArgs, refValues) // fn is not needed for arg capture
+		args = e.captureFunctionArgs(fn, posArgs, refValues)
 		event := trace.TraceEvent{
 			Timestamp:  traceStart,
 			Value:      "",
@@ -673,7 +828,7 @@ func (e *Evaluator) callUserDefinedFunction(fn *value.FunctionValue, posArgs []c
 
 func (e *Evaluator) invokeFunctionExpression(block []core.Value, locations []core.SourceLocation, position int, fn *value.FunctionValue) (int, core.Value, error) {
 	name := functionDisplayName(fn)
-	e.pushCall(name)
+	e.pushCall(name, fn.Type == value.FuncNative)
 	defer e.popCall()
 
 	posArgs, refValues, newPos, err := e.collectFunctionArgs(fn, block, locations, position+1, 0, false)
@@ -681,7 +836,7 @@ func (e *Evaluator) invokeFunctionExpression(block []core.Value, locations []cor
 		return position, value.NewNoneVal(), e.annotateError(err, block, locations, position)
 	}
 
-	traceStart, _ := e.setupFunctionCallTracing(name, position, posArgs, refValues)
+	traceStart, _ := e.setupFunctionCallTracing(fn, name, position, posArgs, refValues)
 
 	var result core.Value
 	if fn.Type == value.FuncNative {
@@ -701,6 +856,50 @@ func (e *Evaluator) invokeFunctionExpression(block []core.Value, locations []cor
 	return newPos, result, nil
 }
 
+// CallFunction invokes a function value with already-evaluated positional
+// arguments, for natives that take callbacks. Refinements keep their inactive
+// defaults; the argument count must match the function's positional parameters.
+func (e *Evaluator) CallFunction(fnVal core.Value, args []core.Value) (core.Value, error) {
+	fn, ok := value.AsFunctionValue(fnVal)
+	if !ok {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDTypeMismatch,
+			[3]string{"function!", value.TypeToString(fnVal.GetType()), ""},
+		)
+	}
+
+	name := functionDisplayName(fn)
+	positional, refSpecs := e.separateParameters(fn)
+	if len(args) != len(positional) {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDArgCount,
+			[3]string{name, strconv.Itoa(len(positional)), strconv.Itoa(len(args))},
+		)
+	}
+	refValues := e.initializeRefinements(refSpecs)
+
+	e.pushCall(name, fn.Type == value.FuncNative)
+	defer e.popCall()
+
+	traceStart, _ := e.setupFunctionCallTracing(fn, name, -1, args, refValues)
+
+	var result core.Value
+	var err error
+	if fn.Type == value.FuncNative {
+		result, err = e.callNativeFunction(fn, args, refValues, name, -1, traceStart)
+	} else {
+		result, err = e.callUserDefinedFunction(fn, args, refValues, name, -1, traceStart)
+	}
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	if e.traceEnabled {
+		e.emitTraceResult("return", name, name, result, -1, traceStart, nil)
+	}
+	return result, nil
+}
+
 func (e *Evaluator) collectParameter(block []core.Value, locations []core.SourceLocation, position int, paramSpec value.ParamSpec, useElementEval bool) (int, core.Value, error) {
 	if position >= len(block) {
 		return position, value.NewNoneVal(), verror.NewScriptError(
@@ -832,6 +1031,9 @@ func (e *Evaluator) callNative(fn *value.FunctionValue, posArgs []core.Value, re
 	if _, ok := err.(*ReturnSignal); ok {
 		return result, err
 	}
+	if _, ok := err.(*ExitSignal); ok {
+		return result, err
+	}
 	if verr, ok := err.(*verror.Error); ok {
 		return result, verr
 	}
@@ -1347,7 +1549,9 @@ func (e *Evaluator) captureFunctionArgs(fn *value.FunctionValue, posArgs []core.
 }
 
 func (e *Evaluator) emitTraceResult(eventType string, word string, expr string, result core.Value, position int, traceStart time.Time, err error) {
-	if !e.traceEnabled || trace.GlobalTraceSession == nil {
+	// A zero start means tracing was switched on mid-expression, as for the
+	// trace --on call itself; there is no start to measure from.
+	if !e.traceEnabled || trace.GlobalTraceSession == nil || traceStart.IsZero() {
 		return
 	}
 
@@ -1384,3 +1588,7 @@ func (e *Evaluator) emitTraceResult(eventType string, word string, expr string,
 func (e *Evaluator) NewReturnSignal(val core.Value) error {
 	return NewReturnSignal(val)
 }
+
+func (e *Evaluator) NewExitSignal(code int) error {
+	return NewExitSignal(code)
+}
@@ -0,0 +1,22 @@
+package eval
+
+import "fmt"
+
+// ExitSignal is raised by exit/quit to stop evaluation and terminate the
+// program with the given status code. It passes through functions and loops
+// untouched; the CLI maps it to the process exit code.
+type ExitSignal struct {
+	code int
+}
+
+func NewExitSignal(code int) *ExitSignal {
+	return &ExitSignal{code: code}
+}
+
+func (s *ExitSignal) Error() string {
+	return fmt.Sprintf("exit signal (status %d)", s.code)
+}
+
+func (s *ExitSignal) Code() int {
+	return s.code
+}
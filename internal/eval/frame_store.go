@@ -0,0 +1,165 @@
+package eval
+
+import (
+	"runtime"
+	"weak"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+)
+
+// Frame store reclamation.
+//
+// Frames are addressed by their index in frameStore. While a frame is on the
+// frame stack (or is the root frame) the store holds it strongly. When it is
+// popped:
+//
+//   - a frame nothing depends on has its slot freed immediately;
+//   - a frame that was captured by a closure, or is the lexical parent of
+//     another frame, is held only through a weak pointer. Function values and
+//     child frames keep strong references to their parents, so Go's garbage
+//     collector decides reachability, including values only held by natives.
+//
+// A sweep turns slots whose weak frame has been collected into free slots.
+// Sweeps run when the store would otherwise grow past twice its live size,
+// and on demand through RecycleFrames.
+
+// minSweepThreshold is the store size below which no automatic sweep happens.
+const minSweepThreshold = 1024
+
+type frameStoreStats struct {
+	nextSweep int
+	reclaimed int
+	sweeps    int
+}
+
+// isStored reports whether f currently owns the slot at its index. A frame
+// whose slot was freed and reused by another frame must be stored again.
+func (e *Evaluator) isStored(f core.Frame) bool {
+	idx := f.GetIndex()
+	if idx < 0 || idx >= len(e.frameStore) {
+		return false
+	}
+	if e.frameStore[idx] == f {
+		return true
+	}
+	fp, ok := f.(*frame.Frame)
+	return ok && e.weakFrames[idx].Value() == fp
+}
+
+// storeFrame puts f in a free slot (or a new one) and links it to its parent.
+func (e *Evaluator) storeFrame(f core.Frame) int {
+	if len(e.freeSlots) == 0 && len(e.frameStore) >= e.storeStats.nextSweep {
+		e.sweepFrames()
+	}
+
+	var idx int
+	if n := len(e.freeSlots); n > 0 {
+		idx = e.freeSlots[n-1]
+		e.freeSlots = e.freeSlots[:n-1]
+		e.frameStore[idx] = f
+	} else {
+		idx = len(e.frameStore)
+		e.frameStore = append(e.frameStore, f)
+		e.weakFrames = append(e.weakFrames, weak.Pointer[frame.Frame]{})
+	}
+	f.SetIndex(idx)
+	if e.allocs != nil {
+		e.countAllocation(core.AllocFrame, 1)
+	}
+
+	if fp, ok := f.(*frame.Frame); ok {
+		if parent, ok := e.GetFrameByIndex(f.GetParent()).(*frame.Frame); ok {
+			fp.LinkParent(parent)
+		}
+	}
+	return idx
+}
+
+// pinFrame makes a stored frame strongly held again when it is re-entered.
+func (e *Evaluator) pinFrame(idx int, f core.Frame) {
+	if e.frameStore[idx] == nil {
+		e.frameStore[idx] = f
+		e.weakFrames[idx] = weak.Pointer[frame.Frame]{}
+	}
+}
+
+// releaseFrame is called when f leaves the frame stack.
+func (e *Evaluator) releaseFrame(idx int, f core.Frame) {
+	if idx <= 0 || idx >= len(e.frameStore) || e.frameStore[idx] != f {
+		return
+	}
+
+	fp, ok := f.(*frame.Frame)
+	if !ok {
+		if !e.captured[idx] {
+			e.frameStore[idx] = nil
+		}
+		return
+	}
+	if fp.Deactivate() {
+		return
+	}
+
+	e.frameStore[idx] = nil
+	if e.captured[idx] || fp.Referenced() {
+		e.weakFrames[idx] = weak.Make(fp)
+		return
+	}
+	e.freeSlot(idx)
+}
+
+func (e *Evaluator) freeSlot(idx int) {
+	delete(e.captured, idx)
+	e.frameStore[idx] = nil
+	e.weakFrames[idx] = weak.Pointer[frame.Frame]{}
+	e.freeSlots = append(e.freeSlots, idx)
+	e.storeStats.reclaimed++
+}
+
+// sweepFrames frees the slots of weakly held frames that have been collected.
+func (e *Evaluator) sweepFrames() {
+	empty := weak.Pointer[frame.Frame]{}
+	for idx, w := range e.weakFrames {
+		if e.frameStore[idx] == nil && w != empty && w.Value() == nil {
+			e.freeSlot(idx)
+		}
+	}
+	e.storeStats.sweeps++
+
+	live := len(e.frameStore) - len(e.freeSlots)
+	e.storeStats.nextSweep = max(minSweepThreshold, 2*live)
+}
+
+// FrameStats reports the state of the frame store without collecting.
+func (e *Evaluator) FrameStats() core.FrameStats {
+	return core.FrameStats{
+		Slots:     len(e.frameStore),
+		Live:      len(e.frameStore) - len(e.freeSlots) - e.deadWeakFrames(),
+		Active:    len(e.Frames),
+		Free:      len(e.freeSlots),
+		Reclaimed: e.storeStats.reclaimed,
+		Sweeps:    e.storeStats.sweeps,
+	}
+}
+
+func (e *Evaluator) deadWeakFrames() int {
+	dead := 0
+	empty := weak.Pointer[frame.Frame]{}
+	for idx, w := range e.weakFrames {
+		if e.frameStore[idx] == nil && w != empty && w.Value() == nil {
+			dead++
+		}
+	}
+	return dead
+}
+
+// RecycleFrames runs a full collection: it drops the binding cache (which
+// holds frames strongly), runs Go's garbage collector so unreachable frames
+// are cleared from their weak pointers, and sweeps the store.
+func (e *Evaluator) RecycleFrames() core.FrameStats {
+	clear(e.bindCache)
+	runtime.GC()
+	e.sweepFrames()
+	return e.FrameStats()
+}
@@ -1,16 +1,34 @@
 package eval
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/frame"
 	"github.com/marcin-radoszewski/viro/internal/value"
 )
 
+// rootFrameSize approximates the number of natives bound in the root frame.
+const rootFrameSize = 160
+
+// newPopulatedEvaluator returns an evaluator whose root frame holds as many
+// bindings as a fully bootstrapped one, with "+" bound last (the worst case for
+// a linear scan). The eval package cannot import native, so stand-in values are used.
+func newPopulatedEvaluator() *Evaluator {
+	e := NewEvaluator()
+	root := e.GetFrameByIndex(0)
+	for i := range rootFrameSize - 1 {
+		root.Bind(fmt.Sprintf("native-%d", i), value.NewIntVal(int64(i)))
+	}
+	root.Bind("+", value.NewIntVal(0))
+	return e
+}
+
 // BenchmarkLookupNative measures native function lookup from the top level.
 // Natives are stored in the root frame, so this tests root frame lookup performance.
 func BenchmarkLookupNative(b *testing.B) {
-	e := NewEvaluator()
+	e := newPopulatedEvaluator()
 
 	b.ReportAllocs()
 
@@ -25,7 +43,7 @@ func BenchmarkLookupNative(b *testing.B) {
 // BenchmarkLookupNativeFromNested measures native function lookup from a 3-level nested scope.
 // This tests frame chain traversal performance when looking up a native from deep in the call stack.
 func BenchmarkLookupNativeFromNested(b *testing.B) {
-	e := NewEvaluator()
+	e := newPopulatedEvaluator()
 
 	// Create 3 nested frames
 	frame1 := frame.NewFrameWithCapacity(frame.FrameFunctionArgs, 0, 5)
@@ -102,3 +120,61 @@ func BenchmarkLookupUserDefinedFromParent(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkLookupSymbolNativeFromNested is BenchmarkLookupNativeFromNested using
+// the interned symbol, as the evaluator does for words from parsed code.
+func BenchmarkLookupSymbolNativeFromNested(b *testing.B) {
+	e := newPopulatedEvaluator()
+
+	parent := 0
+	for level := range 3 {
+		f := frame.NewFrameWithCapacity(frame.FrameFunctionArgs, parent, 5)
+		f.Bind(fmt.Sprintf("local-%d", level), value.NewIntVal(int64(level)))
+		parent = e.PushFrameContext(f)
+	}
+	sym := core.Intern("+")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, ok := e.LookupSymbol(sym); !ok {
+			b.Fatal("native + not found from nested scope")
+		}
+	}
+}
+
+// BenchmarkLookupSymbolInvalidated measures lookups when every iteration adds a
+// shadowing candidate to an intermediate frame, forcing a cache miss.
+func BenchmarkLookupSymbolInvalidated(b *testing.B) {
+	e := newPopulatedEvaluator()
+
+	parentFrame := frame.NewFrameWithCapacity(frame.FrameFunctionArgs, 0, 10)
+	parentIdx := e.PushFrameContext(parentFrame)
+	childFrame := frame.NewFrameWithCapacity(frame.FrameFunctionArgs, parentIdx, 5)
+	e.PushFrameContext(childFrame)
+	sym := core.Intern("+")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, ok := e.LookupSymbol(sym); !ok {
+			b.Fatal("native + not found")
+		}
+		frame.InvalidateBindings()
+	}
+}
+
+// BenchmarkFrameGetLarge measures a direct lookup in a frame the size of the root frame.
+func BenchmarkFrameGetLarge(b *testing.B) {
+	e := newPopulatedEvaluator()
+	root := e.GetFrameByIndex(0).(*frame.Frame)
+	sym := core.Intern("+")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, ok := root.GetSymbol(sym); !ok {
+			b.Fatal("+ not found in root frame")
+		}
+	}
+}
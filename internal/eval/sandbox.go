@@ -1,7 +1,9 @@
 package eval
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,8 +17,8 @@ var SandboxRoot string
 // Enforces sandbox restrictions per research.md security considerations:
 // - Cleans path to normalize separators and remove ".." sequences
 // - Joins with sandbox root to create absolute path
-// - Evaluates symlinks to detect escape attempts
-// - Verifies final path has sandbox root prefix
+// - Evaluates symlinks of the longest existing ancestor to detect escape attempts
+// - Verifies final path is the sandbox root or lies below it
 //
 // Returns absolute path within sandbox, or error if path escapes sandbox.
 func ResolveSandboxPath(userPath string) (string, error) {
@@ -36,32 +38,117 @@ func ResolveSandboxPath(userPath string) (string, error) {
 		candidate = filepath.Join(SandboxRoot, cleaned)
 	}
 
-	// Evaluate symlinks to detect escape attempts
-	resolved, err := filepath.EvalSymlinks(candidate)
+	// Evaluate symlinks to detect escape attempts. Paths that don't exist yet
+	// are resolved through their deepest existing ancestor, and dangling
+	// symlinks through their targets, so creating new files and directories
+	// below or through a symlink cannot leave the sandbox either.
+	resolved, err := EvalSymlinksPartial(candidate)
 	if err != nil {
-		// Path doesn't exist yet - check parent directory chain
-		// This allows creating new files within sandbox
-		dir := filepath.Dir(candidate)
-		resolvedDir, err := filepath.EvalSymlinks(dir)
-		if err != nil {
-			// Parent doesn't exist either - verify candidate path is within sandbox
-			if !strings.HasPrefix(candidate, SandboxRoot) {
-				return "", fmt.Errorf("path escapes sandbox: %s", userPath)
-			}
-			return candidate, nil
-		}
-		// Reconstruct with resolved parent + filename
-		resolved = filepath.Join(resolvedDir, filepath.Base(candidate))
+		return "", fmt.Errorf("failed to resolve %s: %w", userPath, err)
 	}
 
 	// Verify resolved path is within sandbox root
-	if !strings.HasPrefix(resolved, SandboxRoot) {
+	if !withinSandbox(resolved) {
 		return "", fmt.Errorf("path escapes sandbox: %s resolves to %s", userPath, resolved)
 	}
 
 	return resolved, nil
 }
 
+// ResolveSandboxEntry is like ResolveSandboxPath, but leaves a symlink in the
+// final component unresolved: only the parent directory is resolved and
+// checked, so the result names the directory entry itself. Operations on
+// entries, such as delete and rename, then act on a link rather than on its
+// target.
+func ResolveSandboxEntry(userPath string) (string, error) {
+	if SandboxRoot == "" {
+		return "", fmt.Errorf("sandbox root not configured")
+	}
+
+	candidate := filepath.Clean(userPath)
+	if !filepath.IsAbs(candidate) {
+		candidate = filepath.Join(SandboxRoot, candidate)
+	}
+	if candidate == filepath.Clean(SandboxRoot) {
+		return candidate, nil
+	}
+
+	parent, err := EvalSymlinksPartial(filepath.Dir(candidate))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", userPath, err)
+	}
+	if !withinSandbox(parent) {
+		return "", fmt.Errorf("path escapes sandbox: %s resolves to %s", userPath, parent)
+	}
+	return filepath.Join(parent, filepath.Base(candidate)), nil
+}
+
+// withinSandbox reports whether path is the sandbox root or one of its descendants.
+// Comparing against root+separator keeps /tmp/sandbox-other out of /tmp/sandbox;
+// a root that already ends in a separator, such as /, is used as is.
+func withinSandbox(path string) bool {
+	root := filepath.Clean(SandboxRoot)
+	prefix := root
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return path == root || strings.HasPrefix(path, prefix)
+}
+
+// maxSymlinks limits the dangling symlinks followed while resolving one
+// path, as the kernel limits symlink chains.
+const maxSymlinks = 40
+
+// EvalSymlinksPartial evaluates symlinks in the longest existing prefix of path
+// and re-appends the remaining, not yet existing, components. A dangling
+// symlink on the way is replaced by its target, since creating the path
+// would create that target.
+func EvalSymlinksPartial(path string) (string, error) {
+	for links := 0; ; links++ {
+		resolved, link, err := resolveExisting(path)
+		if err != nil || link == "" {
+			return resolved, err
+		}
+		if links == maxSymlinks {
+			return "", fmt.Errorf("too many levels of symbolic links in %s", path)
+		}
+		path = resolved
+	}
+}
+
+// resolveExisting resolves the longest existing prefix of path. When a
+// missing component turns out to be a dangling symlink, it returns the path
+// with that link replaced by its target, and the link, for another pass.
+func resolveExisting(path string) (resolved, link string, err error) {
+	existing := path
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), "", nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+		if info, lerr := os.Lstat(existing); lerr == nil && info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(existing)
+			if err != nil {
+				return "", "", err
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(existing), target)
+			}
+			return filepath.Join(append([]string{target}, rest...)...), existing, nil
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", "", err
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+}
+
 // InitSandbox initializes the sandbox root directory.
 // Called during REPL initialization with the value from --sandbox-root flag.
 func InitSandbox(root string) error {
@@ -88,6 +175,11 @@ func InitSandbox(root string) error {
 		return fmt.Errorf("sandbox root is not a directory: %s", abs)
 	}
 
+	// Resolve symlinks so comparisons against resolved paths are meaningful
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
 	SandboxRoot = abs
 	return nil
 }
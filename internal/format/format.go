@@ -0,0 +1,454 @@
+// Package format implements the canonical source formatter behind `viro fmt`.
+//
+// The formatter works on tokens rather than parsed values so it can keep
+// comments and write every literal exactly as the author did. Line breaks
+// between values are kept (runs of blank lines collapse to one); everything
+// else is canonical: single spaces between values, four-space indentation
+// for blocks that span lines, long blocks wrapped at MaxWidth, and aligned
+// field values in object specs.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/tokenize"
+)
+
+// MaxWidth is the column limit beyond which blocks written on one line are
+// wrapped onto several.
+const MaxWidth = 80
+
+const indentUnit = "    "
+
+type nodeKind int
+
+const (
+	nodeAtom nodeKind = iota
+	nodeComment
+	nodeGroup
+)
+
+// node is one value or comment. Groups (blocks and parens) hold children.
+type node struct {
+	kind nodeKind
+	text string
+	// breaks is the number of line breaks between the previous token and
+	// this node in the source.
+	breaks   int
+	open     string
+	close    string
+	children []*node
+	// closeBreaks is the number of line breaks before a group's closing
+	// bracket.
+	closeBreaks int
+	// object marks the spec block of object, context or make.
+	object bool
+}
+
+// Source formats a whole source file. The source name is used in syntax
+// errors. The result is checked by parsing it again: if its values differ
+// from the input's, an error is returned instead.
+func Source(input, source string) (string, error) {
+	original, _, err := parse.ParseWithSource(input, source)
+	if err != nil {
+		return "", err
+	}
+
+	tokenizer := tokenize.NewTokenizer(input)
+	tokenizer.SetSource(source)
+	tokenizer.SetKeepComments(true)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		return "", err
+	}
+
+	b := &builder{tokens: tokens}
+	nodes := b.nodesUntil(tokenize.TokenEOF)
+
+	p := &printer{}
+	p.body(nodes, 0, false, false)
+	output := p.out.String()
+	if output != "" {
+		output += "\n"
+	}
+
+	formatted, _, err := parse.ParseWithSource(output, source)
+	if err != nil || !sameValues(original, formatted) {
+		return "", fmt.Errorf("formatting %s would change its meaning; please report this as a bug", sourceLabel(source))
+	}
+	return output, nil
+}
+
+func sourceLabel(source string) string {
+	if source == "" {
+		return "input"
+	}
+	return source
+}
+
+// sameValues reports whether two parsed value trees are equal.
+func sameValues(a, b []core.Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].GetType() != b[i].GetType() || a[i].Mold() != b[i].Mold() {
+			return false
+		}
+	}
+	return true
+}
+
+// builder turns the token stream into a node tree. The tokens have already
+// been parsed successfully, so brackets are known to balance.
+type builder struct {
+	tokens  []tokenize.Token
+	pos     int
+	endLine int
+}
+
+func (b *builder) breaksBefore(tok tokenize.Token) int {
+	if b.endLine == 0 {
+		return 0
+	}
+	return tok.Line - b.endLine
+}
+
+func (b *builder) consumed(tok tokenize.Token) {
+	b.endLine = tok.Line + strings.Count(tok.Raw, "\n")
+}
+
+func (b *builder) nodesUntil(closing tokenize.TokenType) []*node {
+	var nodes []*node
+	for b.pos < len(b.tokens) {
+		tok := b.tokens[b.pos]
+		if tok.Type == closing || tok.Type == tokenize.TokenEOF {
+			return nodes
+		}
+		b.pos++
+		n := &node{breaks: b.breaksBefore(tok)}
+		b.consumed(tok)
+
+		switch tok.Type {
+		case tokenize.TokenLBracket, tokenize.TokenLParen:
+			n.kind = nodeGroup
+			n.open = tok.Raw
+			end := tokenize.TokenRBracket
+			n.close = "]"
+			if tok.Type == tokenize.TokenLParen {
+				end = tokenize.TokenRParen
+				n.close = ")"
+			}
+			n.object = n.open == "[" && isObjectSpec(nodes)
+			n.children = b.nodesUntil(end)
+			closeTok := b.tokens[b.pos]
+			b.pos++
+			n.closeBreaks = b.breaksBefore(closeTok)
+			b.consumed(closeTok)
+		case tokenize.TokenComment:
+			n.kind = nodeComment
+			n.text = strings.TrimRight(tok.Raw, " \t\r")
+		default:
+			n.kind = nodeAtom
+			n.text = tok.Raw
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// isObjectSpec reports whether a block following the given siblings is an
+// object spec: `object [...]`, `context [...]` or `make proto [...]`.
+func isObjectSpec(before []*node) bool {
+	atomAt := func(i int) string {
+		if i < 0 || before[i].kind != nodeAtom {
+			return ""
+		}
+		return before[i].text
+	}
+	last := len(before) - 1
+	switch atomAt(last) {
+	case "object", "context":
+		return true
+	}
+	return atomAt(last-1) == "make" && atomAt(last) != ""
+}
+
+// multiline reports whether a group, or a group nested in it, was written
+// across several lines. Such groups keep their line structure and are
+// printed indented.
+func (n *node) multiline() bool {
+	if n.kind != nodeGroup || len(n.children) == 0 {
+		return false
+	}
+	if n.closeBreaks > 0 {
+		return true
+	}
+	for _, child := range n.children {
+		if child.breaks > 0 || child.multiline() {
+			return true
+		}
+	}
+	return false
+}
+
+// flatWidth is the width of the node printed on one line. ok is false when
+// it cannot be printed on one line.
+func (n *node) flatWidth() (int, bool) {
+	switch n.kind {
+	case nodeComment:
+		return 0, false
+	case nodeAtom:
+		if strings.Contains(n.text, "\n") {
+			return 0, false
+		}
+		return utf8.RuneCountInString(n.text), true
+	}
+	if n.multiline() {
+		return 0, false
+	}
+	width, ok := lineWidth(n.children)
+	return width + 2, ok
+}
+
+// lineWidth is the width of nodes printed on one line separated by spaces.
+func lineWidth(nodes []*node) (int, bool) {
+	width := 0
+	for i, n := range nodes {
+		w, ok := n.flatWidth()
+		if !ok && !(n.kind == nodeComment && i == len(nodes)-1) {
+			return 0, false
+		}
+		if n.kind == nodeComment {
+			w = utf8.RuneCountInString(n.text)
+		}
+		if i > 0 {
+			width++
+		}
+		width += w
+	}
+	return width, true
+}
+
+// isSetWord reports whether an atom is a set-word or set-path.
+func (n *node) isSetWord() bool {
+	return n.kind == nodeAtom && len(n.text) > 1 &&
+		strings.HasSuffix(n.text, ":") && !strings.HasPrefix(n.text, ":")
+}
+
+// line is a run of nodes the source has on one line.
+type line struct {
+	nodes []*node
+	blank bool
+	// pad is the number of extra spaces after the first node, used to
+	// align object fields.
+	pad int
+}
+
+func splitLines(nodes []*node) []*line {
+	var lines []*line
+	for i, n := range nodes {
+		if i == 0 || n.breaks > 0 {
+			lines = append(lines, &line{blank: i > 0 && n.breaks > 1})
+		}
+		cur := lines[len(lines)-1]
+		cur.nodes = append(cur.nodes, n)
+	}
+	return lines
+}
+
+// isField reports whether a line is a set-word followed by a value that
+// fits on one line when started at column.
+func (l *line) isField(column int) bool {
+	if len(l.nodes) < 2 || !l.nodes[0].isSetWord() || l.nodes[1].kind == nodeComment {
+		return false
+	}
+	width, ok := lineWidth(l.nodes)
+	return ok && column+width <= MaxWidth
+}
+
+// alignFields pads the set-words of consecutive field lines in an object
+// spec to a common width, when every code line starts with a set-word.
+func alignFields(lines []*line, column int) {
+	for _, l := range lines {
+		if l.nodes[0].kind != nodeComment && !l.nodes[0].isSetWord() {
+			return
+		}
+	}
+
+	var run []*line
+	for _, l := range lines {
+		field := l.isField(column)
+		if !field || l.blank {
+			alignRun(run, column)
+			run = nil
+		}
+		if field {
+			run = append(run, l)
+		}
+	}
+	alignRun(run, column)
+}
+
+// alignRun aligns a run of field lines. Lines that would overflow MaxWidth
+// once padded are left alone and split the run, so alignment never causes
+// wrapping.
+func alignRun(run []*line, column int) {
+	if len(run) < 2 {
+		return
+	}
+	key := 0
+	for _, l := range run {
+		key = max(key, utf8.RuneCountInString(l.nodes[0].text))
+	}
+	start := 0
+	split := false
+	for i, l := range run {
+		width, _ := lineWidth(l.nodes)
+		if column+width+key-utf8.RuneCountInString(l.nodes[0].text) > MaxWidth {
+			alignRun(run[start:i], column)
+			start = i + 1
+			split = true
+		}
+	}
+	if split {
+		alignRun(run[start:], column)
+		return
+	}
+	for _, l := range run {
+		l.pad = key - utf8.RuneCountInString(l.nodes[0].text)
+	}
+}
+
+type printer struct {
+	out    strings.Builder
+	column int
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.column = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.column += utf8.RuneCountInString(s)
+	}
+}
+
+func (p *printer) newline(depth int) {
+	p.write("\n" + strings.Repeat(indentUnit, depth))
+}
+
+// body prints nodes line by line at the given depth. When leadingBreak is
+// set, the first line starts on a new line too.
+func (p *printer) body(nodes []*node, depth int, leadingBreak, object bool) {
+	lines := splitLines(nodes)
+	if object {
+		alignFields(lines, depth*len(indentUnit))
+	}
+	for i, l := range lines {
+		if i > 0 || leadingBreak {
+			if l.blank {
+				p.write("\n")
+			}
+			p.newline(depth)
+		}
+		p.line(l, depth)
+	}
+}
+
+// line prints one source line. A line too long for MaxWidth continues on a
+// new line at the same depth before the first value that would overflow.
+func (p *printer) line(l *line, depth int) {
+	for i, n := range l.nodes {
+		if i > 0 {
+			if p.wraps(l.nodes, i, depth) {
+				p.newline(depth)
+			} else {
+				p.write(" ")
+			}
+		}
+		p.node(n, depth)
+		if i == 0 && l.pad > 0 {
+			p.write(strings.Repeat(" ", l.pad))
+		}
+	}
+}
+
+// wraps reports whether the i-th node of a line moves to a new line to keep
+// within MaxWidth. A set-word moves together with its value and a value never
+// leaves its set-word. Nodes that cannot be printed flat or would not fit on
+// a fresh line either stay put; long blocks then wrap themselves.
+func (p *printer) wraps(nodes []*node, i, depth int) bool {
+	if nodes[i-1].isSetWord() {
+		return false
+	}
+	w, ok := nodes[i].flatWidth()
+	if ok && nodes[i].isSetWord() && i+1 < len(nodes) {
+		if vw, vok := nodes[i+1].flatWidth(); vok {
+			w += 1 + vw
+		}
+	}
+	return ok && p.column+1+w > MaxWidth && depth*len(indentUnit)+w <= MaxWidth
+}
+
+func (p *printer) node(n *node, depth int) {
+	if n.kind != nodeGroup {
+		p.write(n.text)
+		return
+	}
+	if len(n.children) == 0 {
+		p.write(n.open + n.close)
+		return
+	}
+
+	if n.multiline() {
+		p.write(n.open)
+		children := n.children
+		// A comment right after the opening bracket stays on its line.
+		if first := children[0]; first.kind == nodeComment && first.breaks == 0 {
+			p.write(" " + first.text)
+			children = children[1:]
+		}
+		p.body(children, depth+1, true, n.object)
+		p.newline(depth)
+		p.write(n.close)
+		return
+	}
+
+	width, ok := n.flatWidth()
+	if !ok || n.open != "[" || len(n.children) < 2 || p.column+width <= MaxWidth {
+		p.write(n.open)
+		for i, child := range n.children {
+			if i > 0 {
+				p.write(" ")
+			}
+			p.node(child, depth)
+		}
+		p.write(n.close)
+		return
+	}
+
+	// Too long for one line: fill lines up to MaxWidth.
+	p.write(n.open)
+	p.newline(depth + 1)
+	lineStart := true
+	for _, child := range n.children {
+		if !lineStart {
+			w, ok := child.flatWidth()
+			if !ok || p.column+1+w > MaxWidth {
+				p.newline(depth + 1)
+				lineStart = true
+			} else {
+				p.write(" ")
+			}
+		}
+		p.node(child, depth+1)
+		lineStart = false
+	}
+	p.newline(depth)
+	p.write(n.close)
+}
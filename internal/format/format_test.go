@@ -0,0 +1,156 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/parse"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"spacing", "print   1  +   2", "print 1 + 2\n"},
+		{"line breaks kept", "a: 1\nb: 2\n", "a: 1\nb: 2\n"},
+		{"blank lines collapse", "a: 1\n\n\n\nb: 2", "a: 1\n\nb: 2\n"},
+		{"leading and trailing blank lines", "\n\nx\n\n\n", "x\n"},
+		{"inline block", "[ 1   2 [3 ] ]", "[1 2 [3]]\n"},
+		{"adjacent blocks", `either x[a][b]`, "either x [a] [b]\n"},
+		{"paren", "( 1 + 2 ) * 3", "(1 + 2) * 3\n"},
+		{"empty blocks", "[ ] [\n] ()", "[] [] ()\n"},
+		{
+			"multiline block indented",
+			"f: fn [x] [\nprint x\n      x * 2]",
+			"f: fn [x] [\n    print x\n    x * 2\n]\n",
+		},
+		{
+			"nested indentation",
+			"a [\nb [\nc\n]\n]",
+			"a [\n    b [\n        c\n    ]\n]\n",
+		},
+		{
+			"multiline inner block opens outer",
+			"when x [print [\n1]]",
+			"when x [\n    print [\n        1\n    ]\n]\n",
+		},
+		{"comment kept", "; note\nx", "; note\nx\n"},
+		{"trailing comment", "x   ; note   ", "x ; note\n"},
+		{"comment after bracket", "b: [ ; items\n1 2\n]", "b: [ ; items\n    1 2\n]\n"},
+		{"comment in block", "[\n; first\n1\n]", "[\n    ; first\n    1\n]\n"},
+		{"literals verbatim", `x: 1.50 y: 'w z: #{CAFE} p: a.(b + 1).c s: "a\tb"`, `x: 1.50 y: 'w z: #{CAFE} p: a.(b + 1).c s: "a\tb"` + "\n"},
+		{"multiline string untouched", "[\ns: \"one\n  two\"\n]", "[\n    s: \"one\n  two\"\n]\n"},
+		{
+			"object fields aligned",
+			"p: make object! [\nname: \"Al\"\nage: 3\n]",
+			"p: make object! [\n    name: \"Al\"\n    age:  3\n]\n",
+		},
+		{
+			"object alignment stops at blank line",
+			"object [\na: 1\nlong: 2\n\nbb: 3\nc: 4\n]",
+			"object [\n    a:    1\n    long: 2\n\n    bb: 3\n    c:  4\n]\n",
+		},
+		{
+			"object alignment skips multiline values",
+			"context [\na: 1\nf: fn [] [\n1\n]\nlonger: 2\nz: 3\n]",
+			"context [\n    a: 1\n    f: fn [] [\n        1\n    ]\n    longer: 2\n    z:      3\n]\n",
+		},
+		{
+			"non-object block not aligned",
+			"fn [] [\na: 1\nlong: 2\n]",
+			"fn [] [\n    a: 1\n    long: 2\n]\n",
+		},
+		{
+			"long block wrapped",
+			"data: [" + strings.Repeat("item ", 20) + "]",
+			"data: [\n    item item item item item item item item item item item item item item item\n    item item item item item\n]\n",
+		},
+		{
+			"long line in multiline block wrapped",
+			"x: [\n" + strings.Repeat("item ", 22) + "\n]",
+			"x: [\n    " + strings.TrimSpace(strings.Repeat("item ", 15)) + "\n    " + strings.TrimSpace(strings.Repeat("item ", 7)) + "\n]\n",
+		},
+		{
+			"wrapped line keeps set-words with their values",
+			"f: fn [] [\nprint " + strings.Repeat("x", 60) + " name: \"value\"\n]",
+			"f: fn [] [\n    print " + strings.Repeat("x", 60) + "\n    name: \"value\"\n]\n",
+		},
+		{
+			"long line without blocks kept",
+			"print " + strings.Repeat("x", 90),
+			"print " + strings.Repeat("x", 90) + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source(tt.input, "(test)")
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Source() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSource_SyntaxError(t *testing.T) {
+	if _, err := Source("print [1 2", "(test)"); err == nil {
+		t.Fatal("Source() error = nil, want syntax error")
+	}
+}
+
+// TestSource_IdempotentAndSafe formats every example script and checks that
+// formatting again changes nothing and the values parse the same.
+func TestSource_IdempotentAndSafe(t *testing.T) {
+	inputs := map[string]string{
+		"wrapped nested": "x: [" + strings.Repeat("[a b c] ", 12) + "]\n",
+		"aligned wrap":   "object [\na: [" + strings.Repeat("word ", 14) + "]\nlonger-name: 1\n]\n",
+		"comments":       "a [ ; one\n  ; two\n b ; three\n\n\n ; four\n]\n",
+		"wrapped line":   "f: fn [] [\n" + strings.Repeat("a: b ", 20) + "[" + strings.Repeat("c ", 40) + "]\n]\n",
+	}
+	files, _ := filepath.Glob(filepath.Join("..", "..", "examples", "*.viro"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[filepath.Base(file)] = string(data)
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			once, err := Source(input, name)
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+			twice, err := Source(once, name)
+			if err != nil {
+				t.Fatalf("second Source() error = %v", err)
+			}
+			if once != twice {
+				t.Errorf("not idempotent:\n%s\n---\n%s", once, twice)
+			}
+
+			before, _, _ := parse.Parse(input)
+			after, _, err := parse.Parse(once)
+			if err != nil {
+				t.Fatalf("formatted output does not parse: %v", err)
+			}
+			if !sameValues(before, after) {
+				t.Error("formatting changed the parsed values")
+			}
+			for _, l := range strings.Split(once, "\n") {
+				if strings.TrimRight(l, " \t") != l {
+					t.Errorf("trailing whitespace in %q", l)
+				}
+			}
+		})
+	}
+}
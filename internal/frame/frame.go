@@ -22,9 +22,10 @@
 package frame
 
 import (
+	"sync/atomic"
+
 	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/value"
-	"slices"
 )
 
 const (
@@ -34,11 +35,31 @@ const (
 	FrameTypeFrame                          // Type frame for action dispatch
 )
 
+// slotIndexThreshold is the binding count above which a frame keeps a
+// symbol-to-slot map; smaller frames are scanned linearly, which is faster.
+const slotIndexThreshold = 8
+
+// bindingEpoch changes whenever a new word is added to a frame that a cached
+// lookup has passed through, i.e. whenever a cached resolution may now be
+// shadowed. Caches compare it against the epoch they were filled in.
+var bindingEpoch atomic.Uint64
+
+// BindingEpoch returns the current binding epoch.
+func BindingEpoch() uint64 {
+	return bindingEpoch.Load()
+}
+
+// InvalidateBindings forces every binding cache to be refilled.
+func InvalidateBindings() {
+	bindingEpoch.Add(1)
+}
+
 // Frame represents a variable binding context.
-// Maps word symbols (strings) to values.
+// Maps word symbols to values.
 //
 // Design per data-model.md §6:
-// - Parallel arrays: Words and Values
+// - Parallel arrays: Words, Syms and Values; a binding's position is its slot
+// - Frames with more than slotIndexThreshold bindings also index slots by symbol
 // - Parent index for lexical scoping (-1 = no parent)
 // - Local-by-default: words assigned in frame are local
 //
@@ -54,10 +75,20 @@ const (
 type Frame struct {
 	Type   core.FrameType // Frame category
 	Words  []string       // Symbol names (parallel to Values)
+	Syms   []core.Symbol  // Interned symbols (parallel to Words)
 	Values []core.Value   // Bound values (parallel to Words)
 	Parent int            // Parent frame index for lexical scoping (-1 if none). Essential for frame chain traversal.
 	Index  int            // Position in evaluator's frameStore (-1 if not yet stored)
 	Name   string         // Optional function or context name for diagnostics
+
+	slots    map[core.Symbol]int // Symbol to slot, built once the frame outgrows a linear scan
+	observed bool                // A cached lookup passed through this frame without a match
+
+	// parentFrame keeps the lexical parent reachable for as long as this frame is,
+	// so the evaluator's frameStore can hold inactive frames weakly.
+	parentFrame *Frame
+	referenced  bool // Another frame or a function value depends on this frame
+	active      int  // Number of times the frame is on the evaluator's frame stack
 }
 
 // NewFrame creates an empty frame.
@@ -65,6 +96,7 @@ func NewFrame(frameType core.FrameType, parent int) core.Frame {
 	return &Frame{
 		Type:   frameType,
 		Words:  []string{},
+		Syms:   []core.Symbol{},
 		Values: []core.Value{},
 		Parent: parent,
 		Index:  -1,
@@ -78,6 +110,7 @@ func NewFrameWithCapacity(frameType core.FrameType, parent int, capacity int) *F
 	return &Frame{
 		Type:   frameType,
 		Words:  make([]string, 0, capacity),
+		Syms:   make([]core.Symbol, 0, capacity),
 		Values: make([]core.Value, 0, capacity),
 		Parent: parent,
 		Index:  -1,
@@ -91,6 +124,7 @@ func NewObjectFrame(parent int, words []string, types []core.ValueType) *Frame {
 	return &Frame{
 		Type:   FrameObject,
 		Words:  make([]string, 0, len(words)),
+		Syms:   make([]core.Symbol, 0, len(words)),
 		Values: make([]core.Value, 0, len(words)),
 		Parent: parent,
 		Index:  -1,
@@ -132,18 +166,96 @@ func (f *Frame) SetName(name string) {
 // Per data-model.md: This is the core of local-by-default scoping.
 // Assignment in a function creates a local variable, NOT a global.
 func (f *Frame) Bind(symbol string, val core.Value) {
-	// Check if word already exists in this frame
-	for i, w := range f.Words {
-		if w == symbol {
-			// Update existing binding
-			f.Values[i] = val
-			return
-		}
+	f.BindSymbol(core.Intern(symbol), symbol, val)
+}
+
+// BindSymbol is Bind for a word whose symbol is already interned.
+// Returns the slot of the binding.
+func (f *Frame) BindSymbol(sym core.Symbol, name string, val core.Value) int {
+	if slot := f.Slot(sym); slot >= 0 {
+		f.Values[slot] = val
+		return slot
 	}
 
 	// Add new binding (local-by-default)
-	f.Words = append(f.Words, symbol)
+	slot := len(f.Words)
+	f.Words = append(f.Words, name)
+	f.Syms = append(f.Syms, sym)
 	f.Values = append(f.Values, val)
+	if f.slots != nil {
+		f.slots[sym] = slot
+	} else if len(f.Syms) > slotIndexThreshold {
+		f.slots = make(map[core.Symbol]int, 2*len(f.Syms))
+		for i, s := range f.Syms {
+			f.slots[s] = i
+		}
+	}
+	if f.observed {
+		// A cached lookup may have resolved this word past this frame.
+		bindingEpoch.Add(1)
+	}
+	return slot
+}
+
+// Slot returns the position of sym in this frame, or -1 if it is not bound here.
+func (f *Frame) Slot(sym core.Symbol) int {
+	if f.slots != nil {
+		if slot, ok := f.slots[sym]; ok {
+			return slot
+		}
+		return -1
+	}
+	for i, s := range f.Syms {
+		if s == sym {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValueAt returns the value in a slot returned by Slot or BindSymbol.
+func (f *Frame) ValueAt(slot int) core.Value {
+	return f.Values[slot]
+}
+
+// MarkObserved records that a cached lookup passed through this frame, so that
+// later adding a word here must invalidate binding caches.
+func (f *Frame) MarkObserved() {
+	f.observed = true
+}
+
+// LinkParent records a strong reference to the lexical parent and marks the
+// parent as referenced.
+func (f *Frame) LinkParent(parent *Frame) {
+	f.parentFrame = parent
+	if parent != nil {
+		parent.referenced = true
+	}
+}
+
+// MarkReferenced records that something outside the evaluator's frame stack
+// (a function value, a child frame) depends on this frame.
+func (f *Frame) MarkReferenced() {
+	f.referenced = true
+}
+
+// Referenced reports whether the frame may be needed after it is popped.
+func (f *Frame) Referenced() bool {
+	return f.referenced
+}
+
+// Activate records that the frame was pushed on the evaluator's frame stack.
+func (f *Frame) Activate() {
+	f.active++
+}
+
+// Deactivate records that the frame was popped and reports whether it is
+// still on the frame stack elsewhere.
+func (f *Frame) Deactivate() bool {
+	if f.active > 0 {
+		f.active--
+	}
+	return f.active > 0
 }
 
 // Get retrieves the value bound to a symbol in this frame.
@@ -152,10 +264,16 @@ func (f *Frame) Bind(symbol string, val core.Value) {
 // LOCAL LOOKUP ONLY - does NOT search parent frame.
 // Evaluator is responsible for walking frame chain if needed.
 func (f *Frame) Get(symbol string) (core.Value, bool) {
-	for i, w := range f.Words {
-		if w == symbol {
-			return f.Values[i], true
-		}
+	if sym, ok := core.LookupSymbol(symbol); ok {
+		return f.GetSymbol(sym)
+	}
+	return value.NewNoneVal(), false
+}
+
+// GetSymbol is Get for an interned symbol.
+func (f *Frame) GetSymbol(sym core.Symbol) (core.Value, bool) {
+	if slot := f.Slot(sym); slot >= 0 {
+		return f.Values[slot], true
 	}
 	return value.NewNoneVal(), false
 }
@@ -164,18 +282,21 @@ func (f *Frame) Get(symbol string) (core.Value, bool) {
 // Returns true if word was found and updated, false if not found.
 // Does NOT create new binding (use Bind for that).
 func (f *Frame) Set(symbol string, val core.Value) bool {
-	for i, w := range f.Words {
-		if w == symbol {
-			f.Values[i] = val
-			return true
-		}
+	sym, ok := core.LookupSymbol(symbol)
+	if !ok {
+		return false
+	}
+	if slot := f.Slot(sym); slot >= 0 {
+		f.Values[slot] = val
+		return true
 	}
 	return false
 }
 
 // HasWord checks if a symbol is bound in this frame.
 func (f *Frame) HasWord(symbol string) bool {
-	return slices.Contains(f.Words, symbol)
+	sym, ok := core.LookupSymbol(symbol)
+	return ok && f.Slot(sym) >= 0
 }
 
 // Count returns the number of bindings in this frame.
@@ -201,15 +322,29 @@ func (f *Frame) GetAll() []core.Binding {
 // Used for closure capture.
 func (f *Frame) Clone() core.Frame {
 	wordsCopy := make([]string, len(f.Words))
+	symsCopy := make([]core.Symbol, len(f.Syms))
 	valuesCopy := make([]core.Value, len(f.Values))
 	copy(wordsCopy, f.Words)
+	copy(symsCopy, f.Syms)
 	copy(valuesCopy, f.Values)
 
+	var slots map[core.Symbol]int
+	if f.slots != nil {
+		slots = make(map[core.Symbol]int, len(f.slots))
+		for sym, slot := range f.slots {
+			slots[sym] = slot
+		}
+	}
+
 	return &Frame{
 		Type:   f.Type,
 		Words:  wordsCopy,
+		Syms:   symsCopy,
 		Values: valuesCopy,
+		slots:  slots,
 		Parent: f.Parent,
-		Index:  -1, // Clone gets a new index when added to frameStore
+
+		parentFrame: f.parentFrame,
+		Index:       -1, // Clone gets a new index when added to frameStore
 	}
 }
@@ -0,0 +1,703 @@
+// Package lint implements the static checks behind `viro --check`.
+//
+// Viro code is data until it runs, so the checker follows the evaluator's
+// own reading of a script: it consumes function arguments by the callee's
+// ParamSpec list and only descends into blocks that a known native is going
+// to evaluate (if/when bodies, loop bodies, fn bodies, object specs and so
+// on). Blocks it cannot classify are treated as data. Words are resolved
+// against the root frame and the set-words of the enclosing function or
+// object, so a name assigned anywhere in a scope counts as defined there.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Severity classifies a diagnostic. Errors make `viro --check` fail.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic codes.
+const (
+	CodeSyntax            = "syntax"
+	CodeUndefinedWord     = "undefined-word"
+	CodeArity             = "arity"
+	CodeUnknownRefinement = "unknown-refinement"
+	CodeLoopControl       = "loop-control-outside-loop"
+	CodeUnusedParam       = "unused-param"
+	CodeUnusedLocal       = "unused-local"
+	CodeShadowedNative    = "shadowed-native"
+)
+
+// Diagnostic is one finding, positioned at the value it concerns.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+// String renders the diagnostic in the usual file:line:column form.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", d.File, d.Line, d.Column, d.Severity, d.Message, d.Code)
+}
+
+// HasErrors reports whether any diagnostic is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type codeKind int
+
+const (
+	// codeInline blocks run in the caller's frame (if, when, do, reduce).
+	codeInline codeKind = iota
+	// codeLoop blocks are loop bodies, where break and continue are allowed.
+	codeLoop
+	// codeObject blocks are object specs, evaluated in a frame of their own.
+	codeObject
+)
+
+// codeParams lists, for natives that evaluate block arguments, which
+// positional parameters hold code.
+var codeParams = map[string]map[int]codeKind{
+	"when":         {1: codeInline},
+	"if":           {1: codeInline, 2: codeInline},
+	"loop":         {1: codeLoop},
+	"while":        {0: codeInline, 1: codeLoop},
+	"foreach":      {2: codeLoop},
+	"do":           {0: codeInline},
+	"reduce":       {0: codeInline},
+	"print":        {0: codeInline},
+	"rejoin":       {0: codeInline},
+	"object":       {0: codeObject},
+	"test":         {1: codeInline},
+	"assert":       {0: codeInline},
+	"assert-error": {0: codeInline},
+	"context":      {0: codeObject},
+	"make":         {1: codeObject},
+}
+
+type scopeKind int
+
+const (
+	scopeRoot scopeKind = iota
+	scopeScript
+	scopeFunction
+	scopeObject
+)
+
+// symbol is what the checker knows about a name in a scope.
+type symbol struct {
+	// fn is the function the name is bound to, when that is known
+	// statically: a native, or a user function assigned from an fn literal.
+	fn     *value.FunctionValue
+	native bool
+	param  bool
+	// set is true once a set-word assigns the name; loc is the first one.
+	set bool
+	loc core.SourceLocation
+}
+
+type scope struct {
+	kind   scopeKind
+	parent *scope
+	names  map[string]*symbol
+}
+
+func newScope(kind scopeKind, parent *scope) *scope {
+	return &scope{kind: kind, parent: parent, names: make(map[string]*symbol)}
+}
+
+func (s *scope) lookup(name string) *symbol {
+	for sc := s; sc != nil; sc = sc.parent {
+		if sym, ok := sc.names[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// assign records a set-word in the scope. fn is the function it is
+// assigned from, or nil. A name assigned more than one way loses its
+// function, so calls to it are not checked.
+func (s *scope) assign(name string, fn *value.FunctionValue, loc core.SourceLocation) {
+	sym, ok := s.names[name]
+	if !ok {
+		s.names[name] = &symbol{fn: fn, loc: loc, set: true}
+		return
+	}
+	if !sym.set {
+		sym.set = true
+		sym.loc = loc
+	}
+	if sym.fn != fn {
+		sym.fn = nil
+	}
+}
+
+// declare records a name bound some other way (a lit-word given to set,
+// a loop variable) whose value is not known.
+func (s *scope) declare(name string) {
+	if sym, ok := s.names[name]; ok {
+		if !sym.param {
+			sym.fn = nil
+		}
+		return
+	}
+	s.names[name] = &symbol{}
+}
+
+type checker struct {
+	root        *scope
+	diagnostics []Diagnostic
+	// shadowed avoids repeating a shadowing warning for each assignment.
+	shadowed map[*scope]map[string]bool
+	// onExpression, when set, is called with the start of each expression
+	// the walk visits.
+	onExpression func(loc core.SourceLocation)
+}
+
+// walk is the state of one walk through code: the scope words resolve in
+// and how many loops enclose the code within the current function.
+type walk struct {
+	c     *checker
+	scope *scope
+	loops int
+}
+
+// SyntaxDiagnostic converts a parse error into a diagnostic, so syntax
+// errors can be reported alongside the lint findings.
+func SyntaxDiagnostic(err error, source string) Diagnostic {
+	d := Diagnostic{File: source, Line: 1, Column: 1, Severity: SeverityError, Code: CodeSyntax, Message: err.Error()}
+	if vErr, ok := err.(*verror.Error); ok {
+		d.Message = vErr.Message
+		if vErr.Line > 0 {
+			d.Line = vErr.Line
+			d.Column = vErr.Column
+		}
+	}
+	return d
+}
+
+// Check analyses a parsed script. root supplies the natives and other
+// predefined words the script can refer to. A leading `Viro [...]` header
+// is metadata and is skipped.
+func Check(values []core.Value, locations []core.SourceLocation, root core.Frame) []Diagnostic {
+	c := newChecker(root)
+	c.run(values, locations)
+
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i], c.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.diagnostics
+}
+
+// Expressions returns where each expression in a script starts, in the
+// code the checker can tell is evaluated: the top level, function bodies,
+// and the block arguments of natives such as if, loop and object. Blocks
+// passed to other functions count as data and contribute nothing.
+func Expressions(values []core.Value, locations []core.SourceLocation, root core.Frame) []core.SourceLocation {
+	var starts []core.SourceLocation
+	c := newChecker(root)
+	c.onExpression = func(loc core.SourceLocation) {
+		starts = append(starts, loc)
+	}
+	c.run(values, locations)
+	return starts
+}
+
+func newChecker(root core.Frame) *checker {
+	c := &checker{root: newScope(scopeRoot, nil), shadowed: make(map[*scope]map[string]bool)}
+	for _, binding := range root.GetAll() {
+		sym := &symbol{native: true}
+		if fn, ok := value.AsFunctionValue(binding.Value); ok {
+			sym.fn = fn
+		}
+		c.root.names[binding.Symbol] = sym
+	}
+	return c
+}
+
+// run walks a script, skipping its header.
+func (c *checker) run(values []core.Value, locations []core.SourceLocation) {
+	if hasHeader(values) {
+		values = values[2:]
+		locations = locations[min(2, len(locations)):]
+	}
+	script := newScope(scopeScript, c.root)
+	hoist(script, values, locations)
+	w := &walk{c: c, scope: script}
+	w.block(values, locations, core.SourceLocation{})
+}
+
+// hasHeader reports whether a script starts with a `Viro [...]` header.
+func hasHeader(values []core.Value) bool {
+	if len(values) < 2 || values[1].GetType() != value.TypeBlock {
+		return false
+	}
+	word, ok := wordName(values[0], value.TypeWord)
+	return ok && strings.EqualFold(word, "viro")
+}
+
+func (c *checker) report(loc core.SourceLocation, severity Severity, code, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		File:     loc.File,
+		Line:     loc.Line,
+		Column:   loc.Column,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkShadow warns when a function or script binds the name of a native
+// function. Object fields are exempt: they are reached through paths.
+func (c *checker) checkShadow(sc *scope, name string, loc core.SourceLocation) {
+	if sc.kind == scopeObject {
+		return
+	}
+	sym, ok := c.root.names[name]
+	if !ok || sym.fn == nil {
+		return
+	}
+	if c.shadowed[sc] == nil {
+		c.shadowed[sc] = make(map[string]bool)
+	}
+	if c.shadowed[sc][name] {
+		return
+	}
+	c.shadowed[sc][name] = true
+	c.report(loc, SeverityWarning, CodeShadowedNative, "'%s' shadows the native function of the same name", name)
+}
+
+func locationAt(locations []core.SourceLocation, i int, fallback core.SourceLocation) core.SourceLocation {
+	if i < len(locations) {
+		return locations[i]
+	}
+	return fallback
+}
+
+func blockParts(v core.Value) ([]core.Value, []core.SourceLocation, bool) {
+	block, ok := value.AsBlockValue(v)
+	if !ok {
+		return nil, nil, false
+	}
+	return block.Elements, block.Locations(), true
+}
+
+func wordName(v core.Value, t core.ValueType) (string, bool) {
+	if v.GetType() != t {
+		return "", false
+	}
+	return value.AsWordValue(v)
+}
+
+func isWord(v core.Value, name string) bool {
+	w, ok := wordName(v, value.TypeWord)
+	return ok && w == name
+}
+
+func refinementName(v core.Value) (string, bool) {
+	w, ok := wordName(v, value.TypeWord)
+	if !ok || !strings.HasPrefix(w, "--") {
+		return "", false
+	}
+	return strings.TrimPrefix(w, "--"), true
+}
+
+func pathOf(v core.Value) *value.PathExpression {
+	switch v.GetType() {
+	case value.TypePath:
+		p, _ := value.AsPath(v)
+		return p
+	case value.TypeGetPath:
+		if p, ok := value.AsGetPath(v); ok {
+			return p.PathExpression
+		}
+	case value.TypeSetPath:
+		if p, ok := value.AsSetPath(v); ok {
+			return p.PathExpression
+		}
+	}
+	return nil
+}
+
+// fnLiteral returns the function an `fn [spec] [body]` at elems[i] would
+// create, or nil.
+func fnLiteral(elems []core.Value, i int) *value.FunctionValue {
+	if i+2 >= len(elems) || !isWord(elems[i], "fn") {
+		return nil
+	}
+	spec, ok := value.AsBlockValue(elems[i+1])
+	if !ok || elems[i+1].GetType() != value.TypeBlock {
+		return nil
+	}
+	body, ok := value.AsBlockValue(elems[i+2])
+	if !ok || elems[i+2].GetType() != value.TypeBlock {
+		return nil
+	}
+	params, err := native.ParseParamSpecs(spec)
+	if err != nil {
+		return nil
+	}
+	return value.NewUserFunction("", params, body, -1, nil)
+}
+
+// hoist declares the names a block assigns, looking into nested blocks but
+// not into fn literals and object specs, which get scopes of their own.
+// Lit-words count as declarations too, since they are how set, loop
+// --with-index and foreach name their variables.
+func hoist(sc *scope, elems []core.Value, locations []core.SourceLocation) {
+	for i := 0; i < len(elems); i++ {
+		v := elems[i]
+		switch v.GetType() {
+		case value.TypeSetWord:
+			name, _ := value.AsWordValue(v)
+			sc.assign(name, fnLiteral(elems, i+1), locationAt(locations, i, core.SourceLocation{}))
+		case value.TypeLitWord:
+			name, _ := value.AsWordValue(v)
+			sc.declare(name)
+		case value.TypeWord:
+			switch {
+			case fnLiteral(elems, i) != nil:
+				i += 2
+			case (isWord(v, "object") || isWord(v, "context")) && i+1 < len(elems) && elems[i+1].GetType() == value.TypeBlock:
+				i++
+			case isWord(v, "make") && i+2 < len(elems) && elems[i+2].GetType() == value.TypeBlock:
+				i += 2
+			}
+		case value.TypeBlock, value.TypeParen:
+			nested, nestedLocs, _ := blockParts(v)
+			hoist(sc, nested, nestedLocs)
+		}
+	}
+}
+
+// block walks a block of code expression by expression.
+func (w *walk) block(elems []core.Value, locations []core.SourceLocation, blockLoc core.SourceLocation) {
+	if len(locations) != len(elems) {
+		locations = nil
+	}
+	for pos := 0; pos < len(elems); {
+		pos = w.expression(elems, locations, pos, blockLoc)
+	}
+}
+
+// expression walks one expression starting at pos, including infix
+// operators that follow it, and returns the position after it.
+func (w *walk) expression(elems []core.Value, locations []core.SourceLocation, pos int, blockLoc core.SourceLocation) int {
+	if w.c.onExpression != nil && locations != nil {
+		w.c.onExpression(locations[pos])
+	}
+	pos = w.element(elems, locations, pos, blockLoc)
+	for pos < len(elems) {
+		name, ok := wordName(elems[pos], value.TypeWord)
+		if !ok {
+			break
+		}
+		sym := w.scope.lookup(name)
+		if sym == nil || sym.fn == nil || !sym.fn.Infix {
+			break
+		}
+		opPos := pos
+		pos++
+		if pos >= len(elems) {
+			w.c.report(locationAt(locations, opPos, blockLoc), SeverityError, CodeArity, "'%s' is missing its right operand", name)
+			return pos
+		}
+		pos = w.element(elems, locations, pos, blockLoc)
+	}
+	return pos
+}
+
+// element walks the single value at pos; function calls consume their
+// arguments.
+func (w *walk) element(elems []core.Value, locations []core.SourceLocation, pos int, blockLoc core.SourceLocation) int {
+	v := elems[pos]
+	loc := locationAt(locations, pos, blockLoc)
+
+	switch v.GetType() {
+	case value.TypeSetWord:
+		name, _ := value.AsWordValue(v)
+		w.c.checkShadow(w.scope, name, loc)
+		if pos+1 < len(elems) {
+			return w.expression(elems, locations, pos+1, blockLoc)
+		}
+		return pos + 1
+	case value.TypeWord:
+		name, _ := value.AsWordValue(v)
+		if strings.HasPrefix(name, "--") {
+			return pos + 1
+		}
+		sym := w.scope.lookup(name)
+		if sym == nil {
+			w.c.report(loc, SeverityError, CodeUndefinedWord, "'%s' is not defined", name)
+			return pos + 1
+		}
+		if sym.fn != nil {
+			return w.call(name, sym, elems, locations, pos, blockLoc)
+		}
+		return pos + 1
+	case value.TypeGetWord:
+		name, _ := value.AsWordValue(v)
+		if w.scope.lookup(name) == nil {
+			w.c.report(loc, SeverityError, CodeUndefinedWord, "'%s' is not defined", name)
+		}
+		return pos + 1
+	case value.TypePath, value.TypeGetPath, value.TypeSetPath:
+		w.path(pathOf(v), loc)
+		if v.GetType() == value.TypeSetPath && pos+1 < len(elems) {
+			return w.expression(elems, locations, pos+1, blockLoc)
+		}
+		return pos + 1
+	case value.TypeParen:
+		nested, nestedLocs, _ := blockParts(v)
+		w.block(nested, nestedLocs, loc)
+		return pos + 1
+	}
+	return pos + 1
+}
+
+func (w *walk) path(p *value.PathExpression, loc core.SourceLocation) {
+	if p == nil {
+		return
+	}
+	for i, seg := range p.Segments {
+		if block, ok := seg.AsEvalBlock(); ok {
+			w.block(block.Elements, block.Locations(), loc)
+			continue
+		}
+		if i == 0 {
+			if name, ok := seg.AsWord(); ok && w.scope.lookup(name) == nil {
+				w.c.report(loc, SeverityError, CodeUndefinedWord, "'%s' is not defined", name)
+			}
+		}
+	}
+}
+
+// call walks a call to a known function at pos and returns the position
+// after its last argument.
+func (w *walk) call(name string, sym *symbol, elems []core.Value, locations []core.SourceLocation, pos int, blockLoc core.SourceLocation) int {
+	loc := locationAt(locations, pos, blockLoc)
+	fn := sym.fn
+
+	var codes map[int]codeKind
+	if sym.native {
+		switch name {
+		case "break", "continue":
+			if w.loops == 0 {
+				w.c.report(loc, SeverityError, CodeLoopControl, "'%s' used outside of a loop", name)
+			}
+		case "fn":
+			if f := fnLiteral(elems, pos); f != nil {
+				w.function(f, elems[pos+1], locationAt(locations, pos+1, loc))
+				return pos + 3
+			}
+		}
+		codes = codeParams[name]
+	}
+
+	var positional []value.ParamSpec
+	refinements := make(map[string]value.ParamSpec)
+	for _, spec := range fn.Params {
+		if spec.Refinement {
+			refinements[spec.Name] = spec
+		} else {
+			positional = append(positional, spec)
+		}
+	}
+
+	p := pos + 1
+	for i, spec := range positional {
+		p = w.refinements(name, refinements, elems, locations, p, blockLoc)
+		if p >= len(elems) {
+			if !spec.Optional {
+				w.c.report(loc, SeverityError, CodeArity, "'%s' expects %d argument%s, got %d", name, len(positional), plural(len(positional)), i)
+			}
+			return p
+		}
+
+		if kind, ok := codes[i]; ok && elems[p].GetType() == value.TypeBlock {
+			w.code(kind, elems[p], locationAt(locations, p, blockLoc))
+			p++
+			continue
+		}
+		if sym.native && name == "foreach" && i == 1 {
+			w.declareLoopVars(elems[p])
+		}
+		if spec.Eval {
+			p = w.expression(elems, locations, p, blockLoc)
+		} else {
+			p++
+		}
+	}
+	return w.refinements(name, refinements, elems, locations, p, blockLoc)
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// refinements walks the refinements at pos, checking them against the
+// callee's specs.
+func (w *walk) refinements(name string, specs map[string]value.ParamSpec, elems []core.Value, locations []core.SourceLocation, pos int, blockLoc core.SourceLocation) int {
+	for pos < len(elems) {
+		ref, ok := refinementName(elems[pos])
+		if !ok {
+			return pos
+		}
+		loc := locationAt(locations, pos, blockLoc)
+		spec, ok := specs[ref]
+		if !ok {
+			w.c.report(loc, SeverityError, CodeUnknownRefinement, "'%s' has no refinement --%s", name, ref)
+			pos++
+			continue
+		}
+		if !spec.TakesValue {
+			pos++
+			continue
+		}
+		if pos+1 >= len(elems) {
+			w.c.report(loc, SeverityError, CodeArity, "refinement --%s of '%s' needs a value", ref, name)
+			return pos + 1
+		}
+		if spec.Eval {
+			pos = w.expression(elems, locations, pos+1, blockLoc)
+		} else {
+			pos += 2
+		}
+	}
+	return pos
+}
+
+func (w *walk) declareLoopVars(v core.Value) {
+	if name, ok := value.AsWordValue(v); ok {
+		w.scope.declare(name)
+		return
+	}
+	if elems, _, ok := blockParts(v); ok {
+		for _, e := range elems {
+			if name, ok := value.AsWordValue(e); ok {
+				w.scope.declare(name)
+			}
+		}
+	}
+}
+
+// code walks a block argument a native evaluates.
+func (w *walk) code(kind codeKind, v core.Value, loc core.SourceLocation) {
+	elems, locations, _ := blockParts(v)
+	switch kind {
+	case codeInline:
+		w.block(elems, locations, loc)
+	case codeLoop:
+		w.loops++
+		w.block(elems, locations, loc)
+		w.loops--
+	case codeObject:
+		sc := newScope(scopeObject, w.scope)
+		hoist(sc, elems, locations)
+		inner := &walk{c: w.c, scope: sc}
+		inner.block(elems, locations, loc)
+	}
+}
+
+// function walks the body of an fn literal in a scope of its own, then
+// reports parameters and locals it never reads.
+func (w *walk) function(fn *value.FunctionValue, specVal core.Value, specLoc core.SourceLocation) {
+	sc := newScope(scopeFunction, w.scope)
+	specElems, specLocs, _ := blockParts(specVal)
+
+	paramLocs := make(map[string]core.SourceLocation)
+	for i, e := range specElems {
+		if name, ok := value.AsWordValue(e); ok {
+			paramLocs[strings.TrimPrefix(name, "--")] = locationAt(specLocs, i, specLoc)
+		}
+	}
+	for _, spec := range fn.Params {
+		sc.names[spec.Name] = &symbol{param: true, loc: paramLocs[spec.Name]}
+		w.c.checkShadow(sc, spec.Name, paramLocs[spec.Name])
+	}
+
+	body := fn.Body
+	hoist(sc, body.Elements, body.Locations())
+	inner := &walk{c: w.c, scope: sc}
+	inner.block(body.Elements, body.Locations(), specLoc)
+
+	reads := make(map[string]bool)
+	collectReads(body.Elements, reads)
+	for _, spec := range fn.Params {
+		if reads[spec.Name] || strings.HasPrefix(spec.Name, "_") {
+			continue
+		}
+		if spec.Refinement {
+			w.c.report(paramLocs[spec.Name], SeverityWarning, CodeUnusedParam, "refinement --%s is never used", spec.Name)
+		} else {
+			w.c.report(paramLocs[spec.Name], SeverityWarning, CodeUnusedParam, "parameter '%s' is never used", spec.Name)
+		}
+	}
+	names := make([]string, 0, len(sc.names))
+	for name := range sc.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sym := sc.names[name]
+		if sym.set && !sym.param && !reads[name] && !strings.HasPrefix(name, "_") {
+			w.c.report(sym.loc, SeverityWarning, CodeUnusedLocal, "local '%s' is assigned but never used", name)
+		}
+	}
+}
+
+// collectReads records every name a block tree refers to other than by
+// assigning it, including inside nested functions and data blocks.
+func collectReads(elems []core.Value, reads map[string]bool) {
+	for _, v := range elems {
+		switch v.GetType() {
+		case value.TypeWord, value.TypeGetWord, value.TypeLitWord:
+			name, _ := value.AsWordValue(v)
+			reads[name] = true
+		case value.TypePath, value.TypeGetPath, value.TypeSetPath:
+			p := pathOf(v)
+			if p == nil {
+				continue
+			}
+			for i, seg := range p.Segments {
+				if block, ok := seg.AsEvalBlock(); ok {
+					collectReads(block.Elements, reads)
+				} else if name, ok := seg.AsWord(); ok && i == 0 {
+					reads[name] = true
+				}
+			}
+		case value.TypeBlock, value.TypeParen:
+			nested, _, _ := blockParts(v)
+			collectReads(nested, reads)
+		}
+	}
+}
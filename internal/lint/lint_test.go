@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+)
+
+func check(t *testing.T, src string) []Diagnostic {
+	t.Helper()
+	values, locations, err := parse.ParseWithSource(src, "test.viro")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	e := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+	bootstrap.InjectSystem(e, bootstrap.SystemInfo{})
+	return Check(values, locations, e.GetFrameByIndex(0))
+}
+
+func render(diags []Diagnostic) string {
+	lines := make([]string, len(diags))
+	for i, d := range diags {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCheck_Findings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"undefined word", "print missing", "test.viro:1:7: error: 'missing' is not defined [undefined-word]"},
+		{"undefined get-word", "x: :missing", "test.viro:1:4: error: 'missing' is not defined [undefined-word]"},
+		{"undefined path base", "print missing.field", "test.viro:1:7: error: 'missing' is not defined [undefined-word]"},
+		{"undefined inside print block", `print ["a" nope]`, "test.viro:1:12: error: 'nope' is not defined [undefined-word]"},
+		{"native arity at end of block", "when true [append [1]]", "test.viro:1:12: error: 'append' expects 2 arguments, got 1 [arity]"},
+		{"user function arity", "add: fn [a b] [a + b]\nwhen true [add 1]", "test.viro:2:12: error: 'add' expects 2 arguments, got 1 [arity]"},
+		{"infix missing operand", "x: 1 +", "test.viro:1:6: error: '+' is missing its right operand [arity]"},
+		{"refinement missing value", "copy [1 2] --part", "test.viro:1:12: error: refinement --part of 'copy' needs a value [arity]"},
+		{"unknown native refinement", "copy --deep [1]", "test.viro:1:6: error: 'copy' has no refinement --deep [unknown-refinement]"},
+		{"unknown user refinement", "f: fn [x --loud] [when loud [print x]]\nf --quiet 1", "test.viro:2:3: error: 'f' has no refinement --quiet [unknown-refinement]"},
+		{"break at top level", "break", "test.viro:1:1: error: 'break' used outside of a loop [loop-control-outside-loop]"},
+		{"continue in function body", "loop 2 [f: fn [] [continue] f]", "test.viro:1:19: error: 'continue' used outside of a loop [loop-control-outside-loop]"},
+		{"unused parameter", "f: fn [a b] [a]", "test.viro:1:10: warning: parameter 'b' is never used [unused-param]"},
+		{"unused refinement", "f: fn [a --verbose] [a]", "test.viro:1:10: warning: refinement --verbose is never used [unused-param]"},
+		{"unused local", "f: fn [] [tmp: 1 2]", "test.viro:1:11: warning: local 'tmp' is assigned but never used [unused-local]"},
+		{"shadowed native", "first: 1 first: 2", "test.viro:1:1: warning: 'first' shadows the native function of the same name [shadowed-native]"},
+		{"shadowing parameter", "f: fn [length?] [length?]", "test.viro:1:8: warning: 'length?' shadows the native function of the same name [shadowed-native]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(check(t, tt.src)); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Clean(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"forward reference", "f: fn [] [g] g: fn [] [1] f"},
+		{"recursion", "fib: fn [n] [if n <= 1 [n] [(fib n - 1) + (fib n - 2)]] fib 10"},
+		{"closure reads outer", "x: 1 f: fn [] [x] f"},
+		{"object fields and methods", "c: make object! [v: 0 inc: fn [] [v + 1] first: 1] c.inc"},
+		{"object fields may shadow", "o: object [copy: 1 length?: 2]"},
+		{"set defines", "set 'later 1 print later"},
+		{"foreach variables", "foreach [1 2] [k] [print k] foreach [3] 'n [print n]"},
+		{"loop index", "loop 2 --with-index 'i [print i]"},
+		{"break in loop", "loop 3 [when true [break]] while [false] [continue]"},
+		{"data blocks not checked", "data: [undefined words here] first data"},
+		{"refinements anywhere", "copy --part 2 [1 2 3] copy [1 2 3] --part 2"},
+		{"prefix infix", "x: (+ 1 2)"},
+		{"underscore parameter", "f: fn [_ignored] [1]"},
+		{"system object", "print system.args"},
+		{"local read in closure", "f: fn [] [n: 1 g: fn [] [n] g]"},
+		{"parameter rebinding", "f: fn [n] [n: n + 1 n]"},
+		{"path eval segment", "b: [1 2] i: 1 print b.(i)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(t, tt.src); len(got) != 0 {
+				t.Errorf("unexpected diagnostics:\n%s", render(got))
+			}
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors(check(t, "f: fn [a] [1]")) {
+		t.Error("warnings alone should not count as errors")
+	}
+	if !HasErrors(check(t, "print nope")) {
+		t.Error("undefined word should be an error")
+	}
+}
+
+func TestCheck_SkipsHeader(t *testing.T) {
+	if got := check(t, "Viro [title: \"demo\" undefined-in-header]\nprint 1"); len(got) != 0 {
+		t.Errorf("unexpected diagnostics:\n%s", render(got))
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	src := "Viro [title: \"x\"]\nf: fn [a] [\n    print a\n]\ndata: [1 2 3]\nwhen f 1 [f 2]\n"
+	values, locations, err := parse.ParseWithSource(src, "test.viro")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	e := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+
+	var got []string
+	for _, loc := range Expressions(values, locations, e.GetFrameByIndex(0)) {
+		got = append(got, fmt.Sprintf("%d:%d", loc.Line, loc.Column))
+	}
+	// f:, the fn call, print a, a, data:, the data block, when, f 1, 1,
+	// f 2, 2. Nothing inside the header or the data block.
+	want := "2:1 2:4 3:5 3:11 5:1 5:7 6:1 6:6 6:8 6:11 6:13"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,253 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/marcin-radoszewski/viro/internal/tokenize"
+)
+
+// document is an open text document and an outline of its tokens. The
+// outline is built from tokens rather than parsed values so that it keeps
+// end positions and survives unbalanced brackets while the user types.
+type document struct {
+	uri     string
+	version int
+	text    string
+	lines   []string
+	// root is nil when the text cannot be tokenized (an unterminated
+	// string, for instance); navigation then finds nothing.
+	root   *node
+	scopes map[*node]*scope
+}
+
+type nodeKind int
+
+const (
+	nodeAtom nodeKind = iota
+	nodeComment
+	nodeGroup
+)
+
+// node is a token, or a bracketed group of nodes.
+type node struct {
+	kind   nodeKind
+	tok    tokenize.Token
+	end    tokenize.Token
+	parent *node
+	// children of a group.
+	children []*node
+	// closed is false for a group whose closing bracket is missing.
+	closed bool
+}
+
+func newDocument(uri string, version int, text string) *document {
+	doc := &document{uri: uri, version: version, text: text, lines: strings.Split(text, "\n")}
+	tokenizer := tokenize.NewTokenizer(text)
+	tokenizer.SetKeepComments(true)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		return doc
+	}
+	doc.root = buildOutline(tokens)
+	doc.scopes = buildScopes(doc.root)
+	return doc
+}
+
+// buildOutline nests tokens into groups. Stray closing brackets are
+// skipped and groups left open at the end are closed there.
+func buildOutline(tokens []tokenize.Token) *node {
+	root := &node{kind: nodeGroup, closed: true}
+	current := root
+	for _, tok := range tokens {
+		switch tok.Type {
+		case tokenize.TokenEOF:
+			for current != root {
+				current.end = tok
+				current = current.parent
+			}
+			root.end = tok
+		case tokenize.TokenLBracket, tokenize.TokenLParen:
+			group := &node{kind: nodeGroup, tok: tok, parent: current}
+			current.children = append(current.children, group)
+			current = group
+		case tokenize.TokenRBracket, tokenize.TokenRParen:
+			if current == root || closerOf(current.tok.Type) != tok.Type {
+				continue
+			}
+			current.end = tok
+			current.closed = true
+			current = current.parent
+		case tokenize.TokenComment:
+			current.children = append(current.children, &node{kind: nodeComment, tok: tok, parent: current})
+		default:
+			current.children = append(current.children, &node{kind: nodeAtom, tok: tok, parent: current})
+		}
+	}
+	return root
+}
+
+func closerOf(t tokenize.TokenType) tokenize.TokenType {
+	if t == tokenize.TokenLParen {
+		return tokenize.TokenRParen
+	}
+	return tokenize.TokenRBracket
+}
+
+// isBlock reports whether n is a square-bracket group.
+func (n *node) isBlock() bool {
+	return n != nil && n.kind == nodeGroup && n.tok.Type == tokenize.TokenLBracket
+}
+
+// isWord reports whether n is the plain word name.
+func (n *node) isWord(name string) bool {
+	return n != nil && n.kind == nodeAtom && n.tok.Type == tokenize.TokenLiteral && n.tok.Raw == name
+}
+
+// setWordName returns the name n assigns if it is a set-word.
+func (n *node) setWordName() (string, bool) {
+	if n == nil || n.kind != nodeAtom || n.tok.Type != tokenize.TokenLiteral {
+		return "", false
+	}
+	raw := n.tok.Raw
+	if len(raw) < 2 || !strings.HasSuffix(raw, ":") || strings.HasPrefix(raw, ":") || strings.ContainsAny(raw, ".'") {
+		return "", false
+	}
+	return raw[:len(raw)-1], true
+}
+
+// sibling returns the node offset places after n in its group, or nil.
+func (n *node) sibling(offset int) *node {
+	if n.parent == nil {
+		return nil
+	}
+	code := n.parent.code()
+	for i, c := range code {
+		if c == n {
+			if i+offset < len(code) {
+				return code[i+offset]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// code returns a group's children without comments.
+func (n *node) code() []*node {
+	code := make([]*node, 0, len(n.children))
+	for _, c := range n.children {
+		if c.kind != nodeComment {
+			code = append(code, c)
+		}
+	}
+	return code
+}
+
+// startPos and endPos are the token positions of a node as 1-based line and
+// byte column; endPos is just past the node.
+func (n *node) startPos() (int, int) {
+	return n.tok.Line, n.tok.Column
+}
+
+func (n *node) endPos() (int, int) {
+	tok := n.tok
+	if n.kind == nodeGroup {
+		if !n.closed {
+			return n.end.Line, n.end.Column
+		}
+		tok = n.end
+	}
+	if i := strings.LastIndexByte(tok.Raw, '\n'); i >= 0 {
+		return tok.Line + strings.Count(tok.Raw, "\n"), len(tok.Raw) - i
+	}
+	return tok.Line, tok.Column + len(tok.Raw)
+}
+
+// contains reports whether the 1-based line and byte column lie within n,
+// counting the position just past its end.
+func (n *node) contains(line, column int) bool {
+	sl, sc := n.startPos()
+	el, ec := n.endPos()
+	if line < sl || line > el {
+		return false
+	}
+	if line == sl && column < sc {
+		return false
+	}
+	return line != el || column <= ec
+}
+
+// atomAt returns the innermost atom at a position and the innermost group
+// enclosing it.
+func (d *document) atomAt(line, column int) (*node, *node) {
+	group := d.root
+	if group == nil {
+		return nil, nil
+	}
+	for {
+		var next *node
+		for _, c := range group.children {
+			if !c.contains(line, column) {
+				continue
+			}
+			if c.kind == nodeAtom {
+				return c, group
+			}
+			if c.kind == nodeGroup {
+				next = c
+			}
+		}
+		if next == nil {
+			return nil, group
+		}
+		group = next
+	}
+}
+
+// Position conversion. LSP positions count UTF-16 code units from zero;
+// tokens count bytes from one.
+
+func (d *document) toPosition(line, column int) position {
+	p := position{Line: max(line-1, 0)}
+	if p.Line < len(d.lines) {
+		text := d.lines[p.Line]
+		p.Character = utf16Len(text[:min(max(column-1, 0), len(text))])
+	}
+	return p
+}
+
+func (d *document) fromPosition(p position) (int, int) {
+	if p.Line >= len(d.lines) {
+		return p.Line + 1, 1
+	}
+	text := d.lines[p.Line]
+	units := 0
+	for i, r := range text {
+		if units >= p.Character {
+			return p.Line + 1, i + 1
+		}
+		units += utf16.RuneLen(r)
+	}
+	return p.Line + 1, len(text) + 1
+}
+
+func (d *document) rangeOf(n *node) lspRange {
+	sl, sc := n.startPos()
+	el, ec := n.endPos()
+	return lspRange{Start: d.toPosition(sl, sc), End: d.toPosition(el, ec)}
+}
+
+// fullRange covers the whole document.
+func (d *document) fullRange() lspRange {
+	last := len(d.lines) - 1
+	return lspRange{End: position{Line: last, Character: utf16Len(d.lines[last])}}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
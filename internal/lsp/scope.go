@@ -0,0 +1,210 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/tokenize"
+)
+
+type defKind int
+
+const (
+	defValue defKind = iota
+	defFunction
+	defObject
+	defParam
+)
+
+// definition is a name bound in the document: a set-word, or a function
+// parameter.
+type definition struct {
+	name string
+	node *node
+	kind defKind
+	// spec is the fn spec block of a function, or the spec block of an
+	// object.
+	spec *node
+	// proto is the word naming an object's prototype in `make proto [...]`.
+	proto *node
+}
+
+// scope mirrors the evaluator's scoping: the script, each fn body and each
+// object spec bind their own set-words. Other blocks share the scope they
+// appear in, and a name assigned anywhere in a scope is visible throughout
+// it.
+type scope struct {
+	block  *node
+	parent *scope
+	object bool
+	defs   map[string][]*definition
+	order  []*definition
+}
+
+func newScope(block *node, parent *scope, object bool) *scope {
+	return &scope{block: block, parent: parent, object: object, defs: make(map[string][]*definition)}
+}
+
+func (sc *scope) add(def *definition) {
+	sc.defs[def.name] = append(sc.defs[def.name], def)
+	sc.order = append(sc.order, def)
+}
+
+// buildScopes maps every group in the outline to the scope words in it
+// resolve in.
+func buildScopes(root *node) map[*node]*scope {
+	scopes := make(map[*node]*scope)
+	collectScope(root, newScope(root, nil, false), scopes)
+	return scopes
+}
+
+func collectScope(group *node, sc *scope, scopes map[*node]*scope) {
+	scopes[group] = sc
+	code := group.code()
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if name, ok := c.setWordName(); ok {
+			sc.add(classify(name, c, code[i+1:]))
+			continue
+		}
+		if c.kind != nodeGroup {
+			continue
+		}
+
+		prev := func(k int) *node {
+			if i-k < 0 {
+				return nil
+			}
+			return code[i-k]
+		}
+		switch {
+		case c.isBlock() && prev(1).isWord("fn") && i+1 < len(code) && code[i+1].isBlock():
+			body := newScope(code[i+1], sc, false)
+			for _, param := range params(c) {
+				body.add(param)
+			}
+			collectScope(c, sc, scopes)
+			collectScope(code[i+1], body, scopes)
+			i++
+		case c.isBlock() && (prev(1).isWord("object") || prev(1).isWord("context") || prev(2).isWord("make")):
+			collectScope(c, newScope(c, sc, true), scopes)
+		default:
+			collectScope(c, sc, scopes)
+		}
+	}
+}
+
+// classify records what a set-word is assigned from, given the nodes that
+// follow it.
+func classify(name string, setWord *node, rest []*node) *definition {
+	def := &definition{name: name, node: setWord}
+	at := func(k int) *node {
+		if k < len(rest) {
+			return rest[k]
+		}
+		return nil
+	}
+	switch {
+	case at(0).isWord("fn") && at(1).isBlock() && at(2).isBlock():
+		def.kind = defFunction
+		def.spec = at(1)
+	case (at(0).isWord("object") || at(0).isWord("context")) && at(1).isBlock():
+		def.kind = defObject
+		def.spec = at(1)
+	case at(0).isWord("make") && at(1) != nil && at(1).kind == nodeAtom && at(2).isBlock():
+		def.kind = defObject
+		def.spec = at(2)
+		def.proto = at(1)
+	}
+	return def
+}
+
+// params lists the names an fn spec binds: its words, lit-words and
+// refinements (bound without their dashes).
+func params(spec *node) []*definition {
+	var defs []*definition
+	for _, c := range spec.code() {
+		if c.kind != nodeAtom || c.tok.Type != tokenize.TokenLiteral {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(c.tok.Raw, "--"), "'")
+		if name == "" || strings.ContainsAny(name, ":.") {
+			continue
+		}
+		defs = append(defs, &definition{name: name, node: c, kind: defParam})
+	}
+	return defs
+}
+
+// lookup resolves a name as seen from inside group, innermost scope first.
+func (d *document) lookup(name string, group *node) *definition {
+	for sc := d.scopes[group]; sc != nil; sc = sc.parent {
+		if defs := sc.defs[name]; len(defs) > 0 {
+			return defs[0]
+		}
+	}
+	return nil
+}
+
+// fields returns an object definition's fields, including those of its
+// prototype when the prototype is defined in the document.
+func (d *document) fields(def *definition) []*definition {
+	return d.fieldsSeen(def, map[*definition]bool{})
+}
+
+func (d *document) fieldsSeen(def *definition, seen map[*definition]bool) []*definition {
+	if def == nil || def.kind != defObject || seen[def] {
+		return nil
+	}
+	seen[def] = true
+	var fields []*definition
+	names := make(map[string]bool)
+	if sc := d.scopes[def.spec]; sc != nil {
+		for _, f := range sc.order {
+			if !names[f.name] {
+				names[f.name] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	if def.proto != nil {
+		for _, f := range d.fieldsSeen(d.lookup(def.proto.tok.Raw, def.node.parent), seen) {
+			if !names[f.name] {
+				names[f.name] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// field finds one field of an object definition.
+func (d *document) field(def *definition, name string) *definition {
+	for _, f := range d.fields(def) {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// resolvePath resolves the path segments as seen from inside group.
+func (d *document) resolvePath(segments []string, group *node) *definition {
+	def := d.lookup(segments[0], group)
+	for _, seg := range segments[1:] {
+		if def == nil {
+			return nil
+		}
+		def = d.field(def, seg)
+	}
+	return def
+}
+
+// enclosingScope returns the scope of the innermost group containing a
+// position.
+func (d *document) enclosingScope(line, column int) (*scope, *node) {
+	_, group := d.atomAt(line, column)
+	if group == nil {
+		return nil, nil
+	}
+	return d.scopes[group], group
+}
@@ -0,0 +1,735 @@
+// Package lsp implements the language server behind `viro lsp`.
+//
+// The server speaks the Language Server Protocol over a pair of streams
+// and keeps open documents in memory. Diagnostics come from the parser and
+// the lint pass, hover text from the same function documentation `?`
+// prints, formatting from the source formatter. Navigation works on an
+// outline of the document's tokens, so it keeps working while brackets
+// are unbalanced.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/format"
+	"github.com/marcin-radoszewski/viro/internal/lint"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/tokenize"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/version"
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends exit
+// without asking the server to shut down first.
+var ErrExitWithoutShutdown = errors.New("exit received before shutdown")
+
+// Server is a language server for Viro source files.
+type Server struct {
+	root        core.Frame
+	globals     map[string]core.Value
+	docs        map[string]*document
+	out         io.Writer
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer creates a server. root supplies the natives and predefined
+// words that documents are checked and completed against.
+func NewServer(root core.Frame) *Server {
+	globals := make(map[string]core.Value)
+	for _, binding := range root.GetAll() {
+		globals[binding.Symbol] = binding.Value
+	}
+	return &Server{root: root, globals: globals, docs: make(map[string]*document)}
+}
+
+// Serve reads requests from in and writes responses and notifications to
+// out until the client sends exit or closes the stream.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.respond(json.RawMessage("null"), nil, &rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.dispatch(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+type handler func(s *Server, params json.RawMessage) (any, error)
+
+var requests = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"shutdown":                    (*Server).shutdownRequest,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/completion":     (*Server).completion,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/formatting":     (*Server).formatting,
+}
+
+var notifications = map[string]handler{
+	"textDocument/didOpen":   (*Server).didOpen,
+	"textDocument/didChange": (*Server).didChange,
+	"textDocument/didClose":  (*Server).didClose,
+}
+
+// dispatch handles one message. Only failures to write are returned;
+// everything else is reported to the client.
+func (s *Server) dispatch(msg *message) error {
+	if msg.ID == nil {
+		if h, ok := notifications[msg.Method]; ok && s.initialized {
+			_, err := h(s, msg.Params)
+			var rpcErr *rpcError
+			if errors.As(err, &rpcErr) {
+				return nil
+			}
+			return err
+		}
+		return nil
+	}
+
+	h, ok := requests[msg.Method]
+	switch {
+	case !ok:
+		return s.respond(*msg.ID, nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method})
+	case !s.initialized && msg.Method != "initialize":
+		return s.respond(*msg.ID, nil, &rpcError{Code: codeServerNotInitialized, Message: "server not initialized"})
+	case s.shutdown:
+		return s.respond(*msg.ID, nil, &rpcError{Code: codeInvalidRequest, Message: "server is shutting down"})
+	}
+
+	result, err := h(s, msg.Params)
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return s.respond(*msg.ID, nil, rpcErr)
+	}
+	return s.respond(*msg.ID, result, nil)
+}
+
+func (s *Server) respond(id json.RawMessage, result any, rpcErr *rpcError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = data
+	}
+	return writeMessage(s.out, resp)
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func decode(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// Lifecycle.
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	s.initialized = true
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":           1,
+			"hoverProvider":              true,
+			"definitionProvider":         true,
+			"completionProvider":         map[string]any{"triggerCharacters": []string{".", "-"}},
+			"documentSymbolProvider":     true,
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]any{"name": "viro", "version": version.Version},
+	}, nil
+}
+
+func (s *Server) shutdownRequest(params json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+// Document synchronisation.
+
+func (s *Server) didOpen(params json.RawMessage) (any, error) {
+	var p didOpenParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	doc := newDocument(p.TextDocument.URI, p.TextDocument.Version, p.TextDocument.Text)
+	s.docs[doc.uri] = doc
+	return nil, s.publishDiagnostics(doc)
+}
+
+func (s *Server) didChange(params json.RawMessage) (any, error) {
+	var p didChangeParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+	// The server asks for full synchronisation, so the last change holds
+	// the whole text.
+	text := p.ContentChanges[len(p.ContentChanges)-1].Text
+	doc := newDocument(p.TextDocument.URI, p.TextDocument.Version, text)
+	s.docs[doc.uri] = doc
+	return nil, s.publishDiagnostics(doc)
+}
+
+func (s *Server) didClose(params json.RawMessage) (any, error) {
+	var p didCloseParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	delete(s.docs, p.TextDocument.URI)
+	return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []diagnostic{}})
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &rpcError{Code: codeRequestFailed, Message: "document not open: " + uri}
+	}
+	return doc, nil
+}
+
+// sourceName is the name diagnostics and errors use for a document.
+func sourceName(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		return u.Path
+	}
+	return uri
+}
+
+// Diagnostics.
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	name := sourceName(doc.uri)
+	var findings []lint.Diagnostic
+	values, locations, err := parse.ParseWithSource(doc.text, name)
+	if err != nil {
+		findings = []lint.Diagnostic{lint.SyntaxDiagnostic(err, name)}
+	} else {
+		findings = lint.Check(values, locations, s.root)
+	}
+
+	diagnostics := make([]diagnostic, 0, len(findings))
+	for _, f := range findings {
+		severity := severityWarning
+		if f.Severity == lint.SeverityError {
+			severity = severityError
+		}
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    doc.rangeAt(f.Line, f.Column),
+			Severity: severity,
+			Code:     f.Code,
+			Source:   "viro",
+			Message:  f.Message,
+		})
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: doc.uri, Version: doc.version, Diagnostics: diagnostics})
+}
+
+// rangeAt covers the token starting at a 1-based line and byte column, or
+// the single character there when no token starts at it.
+func (d *document) rangeAt(line, column int) lspRange {
+	if atom, _ := d.atomAt(line, column); atom != nil {
+		if l, c := atom.startPos(); l == line && c == column {
+			return d.rangeOf(atom)
+		}
+	}
+	start := d.toPosition(line, column)
+	return lspRange{Start: start, End: d.toPosition(line, column+1)}
+}
+
+// Words under the cursor.
+
+// wordAt splits a word-like atom into its path segments and returns the
+// index of the segment under column. It fails for literals that are not
+// words, such as numbers, strings and refinements.
+func wordAt(n *node, column int) ([]string, int, bool) {
+	if n == nil || n.kind != nodeAtom || n.tok.Type != tokenize.TokenLiteral {
+		return nil, 0, false
+	}
+	raw := n.tok.Raw
+	offset := column - n.tok.Column
+	if strings.HasPrefix(raw, ":") || strings.HasPrefix(raw, "'") {
+		raw = raw[1:]
+		offset--
+	}
+	raw = strings.TrimSuffix(raw, ":")
+	if raw == "" || strings.HasPrefix(raw, "--") || !isOperator(raw) && !startsWord(raw) {
+		return nil, 0, false
+	}
+	segments := strings.Split(raw, ".")
+	index := 0
+	for index < len(segments)-1 && offset > len(segments[index]) {
+		offset -= len(segments[index]) + 1
+		index++
+	}
+	if segments[0] == "" || strings.ContainsAny(segments[index], "()") {
+		return nil, 0, false
+	}
+	return segments, index, true
+}
+
+// startsWord reports whether a literal starts the way words do rather
+// than numbers, files, issues and other literals.
+func startsWord(raw string) bool {
+	if strings.ContainsRune("0123456789\"#%$@", rune(raw[0])) {
+		return false
+	}
+	return !(len(raw) > 1 && strings.ContainsRune("+-", rune(raw[0])) && raw[1] >= '0' && raw[1] <= '9')
+}
+
+// isOperator reports whether a literal is an operator word such as <= or
+// +, which the checks on leading characters would otherwise reject.
+func isOperator(raw string) bool {
+	return strings.Trim(raw, "+-*/<>=!") == ""
+}
+
+// Hover.
+
+func (s *Server) hover(params json.RawMessage) (any, error) {
+	var p textDocumentPositionParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	line, column := doc.fromPosition(p.Position)
+	atom, group := doc.atomAt(line, column)
+	segments, index, ok := wordAt(atom, column)
+	if !ok {
+		return nil, nil
+	}
+
+	var text string
+	if def := doc.resolvePath(segments[:index+1], group); def != nil {
+		text = doc.describe(def)
+	} else if index == 0 {
+		text = s.describeGlobal(segments[0])
+	}
+	if text == "" {
+		return nil, nil
+	}
+	r := doc.rangeOf(atom)
+	return hover{Contents: markupContent{Kind: "markdown", Value: text}, Range: &r}, nil
+}
+
+// describe renders a document definition: its source line in a code block,
+// followed by the comment lines directly above it.
+func (d *document) describe(def *definition) string {
+	var code string
+	switch def.kind {
+	case defFunction:
+		code = def.name + ": fn " + sourceText(def.spec)
+	case defObject:
+		fields := d.fields(def)
+		names := make([]string, len(fields))
+		for i, f := range fields {
+			names[i] = f.name
+		}
+		code = def.name + ": object [" + strings.Join(names, " ") + "]"
+	case defParam:
+		code = def.name + " (parameter)"
+	default:
+		code = def.node.tok.Raw
+		if next := def.node.sibling(1); next != nil {
+			code += " " + sourceText(next)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("```viro\n" + code + "\n```")
+	if comments := leadingComments(def.node); len(comments) > 0 {
+		b.WriteString("\n\n" + strings.Join(comments, "\n"))
+	}
+	return b.String()
+}
+
+// sourceText prints a node on one line, shortening long groups.
+func sourceText(n *node) string {
+	if n.kind != nodeGroup {
+		return n.tok.Raw
+	}
+	parts := make([]string, 0, len(n.children))
+	for _, c := range n.code() {
+		parts = append(parts, sourceText(c))
+	}
+	text := n.tok.Raw + strings.Join(parts, " ") + string(closeRaw(n.tok.Type))
+	if len(text) > 80 {
+		return n.tok.Raw + "..." + string(closeRaw(n.tok.Type))
+	}
+	return text
+}
+
+func closeRaw(t tokenize.TokenType) byte {
+	if t == tokenize.TokenLParen {
+		return ')'
+	}
+	return ']'
+}
+
+// leadingComments returns the text of the comment lines immediately above
+// a node, with the comment markers removed.
+func leadingComments(n *node) []string {
+	siblings := n.parent.children
+	i := 0
+	for i < len(siblings) && siblings[i] != n {
+		i++
+	}
+	var comments []string
+	line := n.tok.Line
+	for j := i - 1; j >= 0; j-- {
+		c := siblings[j]
+		if c.kind != nodeComment || c.tok.Line != line-1 {
+			break
+		}
+		comments = append([]string{strings.TrimSpace(strings.TrimLeft(c.tok.Raw, ";"))}, comments...)
+		line--
+	}
+	return comments
+}
+
+// describeGlobal renders a predefined word: the help text for documented
+// functions, otherwise its type.
+func (s *Server) describeGlobal(name string) string {
+	v, ok := s.globals[name]
+	if !ok {
+		return ""
+	}
+	if fn, ok := value.AsFunctionValue(v); ok && fn.Doc != nil {
+		return "```text\n" + strings.Trim(native.FormatHelp(name, fn.Doc), "\n") + "\n```"
+	}
+	return fmt.Sprintf("```viro\n%s: %s\n```", name, value.TypeToString(v.GetType()))
+}
+
+// Go to definition.
+
+func (s *Server) definition(params json.RawMessage) (any, error) {
+	var p textDocumentPositionParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	line, column := doc.fromPosition(p.Position)
+	atom, group := doc.atomAt(line, column)
+	segments, index, ok := wordAt(atom, column)
+	if !ok {
+		return nil, nil
+	}
+	def := doc.resolvePath(segments[:index+1], group)
+	if def == nil {
+		return nil, nil
+	}
+	return []location{{URI: doc.uri, Range: doc.rangeOf(def.node)}}, nil
+}
+
+// Completion.
+
+// completion offers words visible at the cursor and the predefined words;
+// object fields after a dot; and the caller's refinements after `--`.
+func (s *Server) completion(params json.RawMessage) (any, error) {
+	var p textDocumentPositionParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	line, column := doc.fromPosition(p.Position)
+	prefix := doc.prefixAt(line, column)
+	_, group := doc.atomAt(line, column)
+
+	var items []completionItem
+	switch {
+	case strings.HasPrefix(prefix, "--"):
+		items = s.completeRefinements(doc, group, line, column, prefix[2:])
+	case strings.Contains(prefix, "."):
+		segments := strings.Split(strings.TrimLeft(prefix, ":'"), ".")
+		items = s.completeFields(doc, group, segments[:len(segments)-1], segments[len(segments)-1])
+	default:
+		items = s.completeWords(doc, group, strings.TrimLeft(prefix, ":'"))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	if items == nil {
+		items = []completionItem{}
+	}
+	return completionList{Items: items}, nil
+}
+
+// prefixAt returns the partial word before a 1-based line and byte column.
+func (d *document) prefixAt(line, column int) string {
+	if line-1 >= len(d.lines) {
+		return ""
+	}
+	text := d.lines[line-1]
+	before := text[:min(column-1, len(text))]
+	start := strings.LastIndexAny(before, " \t\r[]();\"") + 1
+	return before[start:]
+}
+
+func (s *Server) completeWords(doc *document, group *node, prefix string) []completionItem {
+	seen := make(map[string]bool)
+	var items []completionItem
+	for sc := doc.scopes[group]; sc != nil; sc = sc.parent {
+		for _, def := range sc.order {
+			if seen[def.name] || !strings.HasPrefix(def.name, prefix) {
+				continue
+			}
+			seen[def.name] = true
+			items = append(items, definitionItem(def, ""))
+		}
+	}
+	for name, v := range s.globals {
+		if seen[name] || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		items = append(items, globalItem(name, v))
+	}
+	return items
+}
+
+func definitionItem(def *definition, detail string) completionItem {
+	item := completionItem{Label: def.name, Kind: completionVariable, Detail: detail}
+	switch def.kind {
+	case defFunction:
+		item.Kind = completionFunction
+		item.Detail = "fn " + sourceText(def.spec)
+	case defObject:
+		item.Detail = "object"
+	}
+	return item
+}
+
+func globalItem(name string, v core.Value) completionItem {
+	item := completionItem{Label: name, Kind: completionVariable, Detail: value.TypeToString(v.GetType())}
+	if fn, ok := value.AsFunctionValue(v); ok {
+		item.Kind = completionFunction
+		if fn.Doc != nil {
+			item.Detail = fn.Doc.Summary
+		}
+	}
+	return item
+}
+
+func (s *Server) completeFields(doc *document, group *node, path []string, prefix string) []completionItem {
+	var items []completionItem
+	if def := doc.resolvePath(path, group); def != nil {
+		for _, f := range doc.fields(def) {
+			if strings.HasPrefix(f.name, prefix) {
+				item := definitionItem(f, "")
+				if item.Kind == completionVariable {
+					item.Kind = completionField
+				}
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	// A predefined object such as system.
+	v, ok := s.globals[path[0]]
+	for _, seg := range path[1:] {
+		if !ok {
+			break
+		}
+		obj, isObj := value.AsObject(v)
+		if !isObj {
+			return nil
+		}
+		v, ok = obj.GetFieldWithProto(seg)
+	}
+	if !ok {
+		return nil
+	}
+	obj, isObj := value.AsObject(v)
+	if !isObj {
+		return nil
+	}
+	for _, binding := range obj.GetAllFieldsWithProto() {
+		if strings.HasPrefix(binding.Symbol, prefix) {
+			items = append(items, completionItem{Label: binding.Symbol, Kind: completionField, Detail: value.TypeToString(binding.Value.GetType())})
+		}
+	}
+	return items
+}
+
+// completeRefinements offers the refinements of the nearest function word
+// before the cursor in the same block.
+func (s *Server) completeRefinements(doc *document, group *node, line, column int, prefix string) []completionItem {
+	if group == nil {
+		return nil
+	}
+	code := group.code()
+	for i := len(code) - 1; i >= 0; i-- {
+		c := code[i]
+		if l, col := c.startPos(); l > line || l == line && col >= column-len(prefix)-2 {
+			continue
+		}
+		segments, _, ok := wordAt(c, c.tok.Column)
+		if !ok || len(segments) != 1 || c.tok.Raw != segments[0] {
+			continue
+		}
+		if items, ok := s.refinementItems(doc, group, segments[0], prefix); ok {
+			return items
+		}
+	}
+	return nil
+}
+
+func (s *Server) refinementItems(doc *document, group *node, name, prefix string) ([]completionItem, bool) {
+	var items []completionItem
+	if def := doc.lookup(name, group); def != nil {
+		if def.kind != defFunction {
+			return nil, false
+		}
+		for _, c := range def.spec.code() {
+			if ref, ok := strings.CutPrefix(c.tok.Raw, "--"); ok && c.kind == nodeAtom && strings.HasPrefix(ref, prefix) {
+				items = append(items, completionItem{Label: "--" + ref, Kind: completionProperty, Detail: "refinement of " + name})
+			}
+		}
+		return items, true
+	}
+
+	v, ok := s.globals[name]
+	if !ok {
+		return nil, false
+	}
+	fn, ok := value.AsFunctionValue(v)
+	if !ok {
+		return nil, false
+	}
+	for _, spec := range fn.Params {
+		if !spec.Refinement || !strings.HasPrefix(spec.Name, prefix) {
+			continue
+		}
+		item := completionItem{Label: "--" + spec.Name, Kind: completionProperty, Detail: "refinement of " + name}
+		if fn.Doc != nil {
+			for _, param := range fn.Doc.Parameters {
+				if param.Name == "--"+spec.Name {
+					item.Detail = param.Description
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
+
+// Document symbols.
+
+func (s *Server) documentSymbol(params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	symbols := []documentSymbol{}
+	if doc.root != nil {
+		symbols = doc.symbols(doc.scopes[doc.root].order, false)
+	}
+	return symbols, nil
+}
+
+// symbols lists the functions and objects among defs. Inside objects every
+// field is listed.
+func (d *document) symbols(defs []*definition, object bool) []documentSymbol {
+	symbols := []documentSymbol{}
+	seen := make(map[string]bool)
+	for _, def := range defs {
+		if seen[def.name] || !object && def.kind != defFunction && def.kind != defObject {
+			continue
+		}
+		seen[def.name] = true
+		sym := documentSymbol{Name: def.name, Kind: symbolField, SelectionRange: d.rangeOf(def.node)}
+		end := def.node
+		switch def.kind {
+		case defFunction:
+			sym.Kind = symbolFunction
+			if object {
+				sym.Kind = symbolMethod
+			}
+			sym.Detail = "fn " + sourceText(def.spec)
+			end = def.spec.sibling(1)
+		case defObject:
+			sym.Kind = symbolObject
+			sym.Children = d.symbols(d.scopes[def.spec].order, true)
+			end = def.spec
+		default:
+			if next := def.node.sibling(1); next != nil {
+				end = next
+			}
+		}
+		sym.Range = lspRange{Start: sym.SelectionRange.Start, End: d.rangeOf(end).End}
+		symbols = append(symbols, sym)
+	}
+	return symbols
+}
+
+// Formatting.
+
+func (s *Server) formatting(params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(doc.text, sourceName(doc.uri))
+	if err != nil {
+		return nil, &rpcError{Code: codeRequestFailed, Message: err.Error()}
+	}
+	if formatted == doc.text {
+		return []textEdit{}, nil
+	}
+	return []textEdit{{Range: doc.fullRange(), NewText: formatted}}, nil
+}
@@ -0,0 +1,466 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+)
+
+const testURI = "file:///work/test.viro"
+
+// session runs a scripted exchange: it frames the messages, serves them and
+// returns the decoded output. Requests are numbered from 1 in the order
+// given; use notify for notifications.
+type session struct {
+	t      *testing.T
+	in     bytes.Buffer
+	nextID int
+}
+
+type exchange struct {
+	responses     map[int]json.RawMessage
+	errors        map[int]rpcError
+	notifications []notification
+	serveErr      error
+}
+
+func newSession(t *testing.T) *session {
+	s := &session{t: t}
+	s.request("initialize", map[string]any{"capabilities": map[string]any{}})
+	s.notify("initialized", map[string]any{})
+	return s
+}
+
+func (s *session) write(v any) {
+	if err := writeMessage(&s.in, v); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func (s *session) request(method string, params any) int {
+	s.nextID++
+	s.write(map[string]any{"jsonrpc": "2.0", "id": s.nextID, "method": method, "params": params})
+	return s.nextID
+}
+
+func (s *session) notify(method string, params any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *session) open(text string) {
+	s.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": testURI, "languageId": "viro", "version": 1, "text": text},
+	})
+}
+
+func (s *session) at(method string, line, character int) int {
+	return s.request(method, map[string]any{
+		"textDocument": map[string]any{"uri": testURI},
+		"position":     map[string]any{"line": line, "character": character},
+	})
+}
+
+func (s *session) run() *exchange {
+	s.request("shutdown", nil)
+	s.notify("exit", nil)
+	return s.serve()
+}
+
+func (s *session) serve() *exchange {
+	s.t.Helper()
+	root := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+	bootstrap.InjectSystem(root, bootstrap.SystemInfo{})
+
+	var out bytes.Buffer
+	ex := &exchange{responses: make(map[int]json.RawMessage), errors: make(map[int]rpcError)}
+	ex.serveErr = NewServer(root.GetFrameByIndex(0)).Serve(&s.in, &out)
+
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		var msg struct {
+			ID     int             `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Error  *rpcError       `json:"error"`
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.t.Fatalf("invalid message %s: %v", body, err)
+		}
+		json.Unmarshal(body, &fields)
+		result, hasResult := fields["result"]
+		switch {
+		case msg.Method != "":
+			ex.notifications = append(ex.notifications, notification{Method: msg.Method, Params: msg.Params})
+		case msg.Error != nil:
+			ex.errors[msg.ID] = *msg.Error
+		case hasResult:
+			ex.responses[msg.ID] = result
+		default:
+			s.t.Fatalf("response without result or error: %s", body)
+		}
+	}
+	return ex
+}
+
+func (ex *exchange) result(t *testing.T, id int, v any) {
+	t.Helper()
+	raw, ok := ex.responses[id]
+	if !ok {
+		t.Fatalf("no result for request %d (error %+v)", id, ex.errors[id])
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("decoding result %s: %v", raw, err)
+	}
+}
+
+func (ex *exchange) diagnostics(t *testing.T) [][]diagnostic {
+	t.Helper()
+	var all [][]diagnostic
+	for _, n := range ex.notifications {
+		if n.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var p publishDiagnosticsParams
+		if err := json.Unmarshal(n.Params.(json.RawMessage), &p); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, p.Diagnostics)
+	}
+	return all
+}
+
+func TestLifecycle(t *testing.T) {
+	s := &session{t: t}
+	early := s.request("textDocument/hover", map[string]any{})
+	init := s.request("initialize", map[string]any{})
+	unknown := s.request("workspace/unknown", nil)
+	shutdown := s.request("shutdown", nil)
+	after := s.request("textDocument/hover", map[string]any{})
+	s.notify("exit", nil)
+	ex := s.serve()
+
+	if ex.serveErr != nil {
+		t.Fatalf("Serve() error = %v", ex.serveErr)
+	}
+	if ex.errors[early].Code != codeServerNotInitialized {
+		t.Errorf("request before initialize: %+v", ex.errors[early])
+	}
+	var result struct {
+		Capabilities map[string]any `json:"capabilities"`
+		ServerInfo   struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	ex.result(t, init, &result)
+	for _, capability := range []string{"hoverProvider", "definitionProvider", "completionProvider", "documentSymbolProvider", "documentFormattingProvider"} {
+		if result.Capabilities[capability] == nil {
+			t.Errorf("capability %s missing", capability)
+		}
+	}
+	if result.ServerInfo.Name != "viro" {
+		t.Errorf("serverInfo = %+v", result.ServerInfo)
+	}
+	if ex.errors[unknown].Code != codeMethodNotFound {
+		t.Errorf("unknown method: %+v", ex.errors[unknown])
+	}
+	if string(ex.responses[shutdown]) != "null" {
+		t.Errorf("shutdown result = %s", ex.responses[shutdown])
+	}
+	if ex.errors[after].Code != codeInvalidRequest {
+		t.Errorf("request after shutdown: %+v", ex.errors[after])
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	s := newSession(t)
+	s.notify("exit", nil)
+	if ex := s.serve(); ex.serveErr != ErrExitWithoutShutdown {
+		t.Errorf("Serve() error = %v, want %v", ex.serveErr, ErrExitWithoutShutdown)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	s := newSession(t)
+	s.open("f: fn [a b] [a]\nprint missing\n")
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": testURI, "version": 2},
+		"contentChanges": []map[string]any{{"text": "print [1 2\n"}},
+	})
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": testURI, "version": 3},
+		"contentChanges": []map[string]any{{"text": "print 1\n"}},
+	})
+	s.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": testURI}})
+	all := s.run().diagnostics(t)
+
+	if len(all) != 4 {
+		t.Fatalf("got %d diagnostic notifications, want 4", len(all))
+	}
+	lintDiags := all[0]
+	if len(lintDiags) != 2 {
+		t.Fatalf("lint diagnostics = %+v", lintDiags)
+	}
+	unused, undefined := lintDiags[0], lintDiags[1]
+	if unused.Code != "unused-param" || unused.Severity != severityWarning ||
+		unused.Range != (lspRange{Start: position{0, 9}, End: position{0, 10}}) {
+		t.Errorf("unused parameter diagnostic = %+v", unused)
+	}
+	if undefined.Code != "undefined-word" || undefined.Severity != severityError || undefined.Source != "viro" ||
+		undefined.Range != (lspRange{Start: position{1, 6}, End: position{1, 13}}) {
+		t.Errorf("undefined word diagnostic = %+v", undefined)
+	}
+	if len(all[1]) != 1 || all[1][0].Code != "syntax" || all[1][0].Severity != severityError {
+		t.Errorf("syntax diagnostics = %+v", all[1])
+	}
+	if len(all[2]) != 0 || len(all[3]) != 0 {
+		t.Errorf("diagnostics after fix and close = %+v, %+v", all[2], all[3])
+	}
+}
+
+const navSource = `; Adds two numbers.
+; Both must be numbers.
+add: fn [a b --twice] [
+    sum: a + b
+    when twice [sum: sum * 2]
+    sum
+]
+point: make object! [
+    x: 1
+    y: 2
+    norm: fn [] [x + y]
+]
+print add 1 2
+print point.norm
+print first [1 2]
+`
+
+func TestHover(t *testing.T) {
+	s := newSession(t)
+	s.open(navSource)
+	user := s.at("textDocument/hover", 12, 7)
+	native := s.at("textDocument/hover", 14, 7)
+	param := s.at("textDocument/hover", 3, 10)
+	field := s.at("textDocument/hover", 13, 13)
+	print := s.at("textDocument/hover", 12, 1)
+	number := s.at("textDocument/hover", 12, 10)
+	ex := s.run()
+
+	var h hover
+	ex.result(t, user, &h)
+	want := "```viro\nadd: fn [a b --twice]\n```\n\nAdds two numbers.\nBoth must be numbers."
+	if h.Contents.Value != want || h.Contents.Kind != "markdown" {
+		t.Errorf("user function hover = %q", h.Contents.Value)
+	}
+	if h.Range == nil || *h.Range != (lspRange{Start: position{12, 6}, End: position{12, 9}}) {
+		t.Errorf("hover range = %+v", h.Range)
+	}
+
+	ex.result(t, native, &h)
+	if !strings.Contains(h.Contents.Value, "FIRST - ") || !strings.Contains(h.Contents.Value, "USAGE:") {
+		t.Errorf("native hover = %q", h.Contents.Value)
+	}
+	ex.result(t, param, &h)
+	if !strings.Contains(h.Contents.Value, "a (parameter)") {
+		t.Errorf("parameter hover = %q", h.Contents.Value)
+	}
+	ex.result(t, field, &h)
+	if !strings.Contains(h.Contents.Value, "norm: fn []") {
+		t.Errorf("field hover = %q", h.Contents.Value)
+	}
+	ex.result(t, print, &h)
+	if !strings.Contains(h.Contents.Value, "PRINT - ") {
+		t.Errorf("print hover = %q", h.Contents.Value)
+	}
+	if string(ex.responses[number]) != "null" {
+		t.Errorf("hover on a number = %s", ex.responses[number])
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	s := newSession(t)
+	s.open(navSource)
+	call := s.at("textDocument/definition", 12, 7)
+	local := s.at("textDocument/definition", 5, 4)
+	param := s.at("textDocument/definition", 4, 10)
+	field := s.at("textDocument/definition", 13, 13)
+	closure := s.at("textDocument/definition", 10, 17)
+	native := s.at("textDocument/definition", 14, 7)
+	ex := s.run()
+
+	tests := []struct {
+		name string
+		id   int
+		want position
+	}{
+		{"function", call, position{2, 0}},
+		{"local", local, position{3, 4}},
+		{"refinement parameter", param, position{2, 13}},
+		{"object field through path", field, position{10, 4}},
+		{"field from method", closure, position{8, 4}},
+	}
+	for _, tt := range tests {
+		var locs []location
+		ex.result(t, tt.id, &locs)
+		if len(locs) != 1 || locs[0].URI != testURI || locs[0].Range.Start != tt.want {
+			t.Errorf("%s: definition = %+v, want start %+v", tt.name, locs, tt.want)
+		}
+	}
+	if string(ex.responses[native]) != "null" {
+		t.Errorf("definition of a native = %s", ex.responses[native])
+	}
+}
+
+func labels(items []completionItem) map[string]completionItem {
+	m := make(map[string]completionItem)
+	for _, item := range items {
+		m[item.Label] = item
+	}
+	return m
+}
+
+func TestCompletion(t *testing.T) {
+	s := newSession(t)
+	s.open(navSource + "ad\npoint.\nsystem.\ncopy [1] --\nadd 1 2 --t\nx: po\n")
+	words := s.at("textDocument/completion", 15, 2)
+	fields := s.at("textDocument/completion", 16, 6)
+	systemFields := s.at("textDocument/completion", 17, 7)
+	nativeRefs := s.at("textDocument/completion", 18, 11)
+	userRefs := s.at("textDocument/completion", 19, 11)
+	topLevel := s.at("textDocument/completion", 20, 3)
+	inBody := s.at("textDocument/completion", 5, 4)
+	ex := s.run()
+
+	var list completionList
+	ex.result(t, words, &list)
+	got := labels(list.Items)
+	if got["add"].Kind != completionFunction || got["add"].Detail != "fn [a b --twice]" {
+		t.Errorf("add item = %+v", got["add"])
+	}
+	if _, ok := got["print"]; ok {
+		t.Error("completion not filtered by prefix")
+	}
+
+	ex.result(t, topLevel, &list)
+	got = labels(list.Items)
+	if _, ok := got["sum"]; ok {
+		t.Error("local of a function offered outside it")
+	}
+	if _, ok := got["point"]; !ok {
+		t.Error("top-level word not offered")
+	}
+
+	ex.result(t, fields, &list)
+	got = labels(list.Items)
+	if len(got) != 3 || got["x"].Kind != completionField || got["norm"].Kind != completionFunction {
+		t.Errorf("object fields = %+v", list.Items)
+	}
+
+	ex.result(t, systemFields, &list)
+	if got = labels(list.Items); got["args"].Kind != completionField {
+		t.Errorf("system fields = %+v", list.Items)
+	}
+
+	ex.result(t, nativeRefs, &list)
+	if got = labels(list.Items); got["--part"].Kind != completionProperty || got["--part"].Detail == "" {
+		t.Errorf("copy refinements = %+v", list.Items)
+	}
+
+	ex.result(t, userRefs, &list)
+	if len(list.Items) != 1 || list.Items[0].Label != "--twice" {
+		t.Errorf("user refinements = %+v", list.Items)
+	}
+
+	ex.result(t, inBody, &list)
+	got = labels(list.Items)
+	for _, name := range []string{"sum", "a", "b", "twice", "add"} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("%q not offered inside the function body", name)
+		}
+	}
+	if _, ok := got["first"]; !ok {
+		t.Error("natives not offered")
+	}
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	s := newSession(t)
+	s.open(navSource + "count: 3\n")
+	id := s.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": testURI}})
+	ex := s.run()
+
+	var symbols []documentSymbol
+	ex.result(t, id, &symbols)
+	if len(symbols) != 2 {
+		t.Fatalf("symbols = %+v", symbols)
+	}
+	add, point := symbols[0], symbols[1]
+	if add.Name != "add" || add.Kind != symbolFunction ||
+		add.Range != (lspRange{Start: position{2, 0}, End: position{6, 1}}) ||
+		add.SelectionRange != (lspRange{Start: position{2, 0}, End: position{2, 4}}) {
+		t.Errorf("add symbol = %+v", add)
+	}
+	if point.Name != "point" || point.Kind != symbolObject || len(point.Children) != 3 {
+		t.Fatalf("point symbol = %+v", point)
+	}
+	if norm := point.Children[2]; norm.Name != "norm" || norm.Kind != symbolMethod {
+		t.Errorf("norm symbol = %+v", norm)
+	}
+	if x := point.Children[0]; x.Name != "x" || x.Kind != symbolField || x.Range.End != (position{8, 8}) {
+		t.Errorf("x symbol = %+v", x)
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	s := newSession(t)
+	s.open("x:   [1  2]\nprint x")
+	formatted := s.request("textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": testURI}})
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": testURI, "version": 2},
+		"contentChanges": []map[string]any{{"text": "x: [1 2]\n"}},
+	})
+	clean := s.request("textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": testURI}})
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": testURI, "version": 3},
+		"contentChanges": []map[string]any{{"text": "x: [1 2\n"}},
+	})
+	broken := s.request("textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": testURI}})
+	closed := s.request("textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": "file:///other.viro"}})
+	ex := s.run()
+
+	var edits []textEdit
+	ex.result(t, formatted, &edits)
+	if len(edits) != 1 || edits[0].NewText != "x: [1 2]\nprint x\n" ||
+		edits[0].Range != (lspRange{End: position{1, 7}}) {
+		t.Errorf("edits = %+v", edits)
+	}
+	ex.result(t, clean, &edits)
+	if len(edits) != 0 {
+		t.Errorf("edits for formatted text = %+v", edits)
+	}
+	if ex.errors[broken].Code != codeRequestFailed || ex.errors[closed].Code != codeRequestFailed {
+		t.Errorf("errors = %+v, %+v", ex.errors[broken], ex.errors[closed])
+	}
+}
+
+func TestPositionsUseUTF16(t *testing.T) {
+	s := newSession(t)
+	s.open("s: \"😀\" print missing\n")
+	ex := s.run()
+	diags := ex.diagnostics(t)[0]
+	if len(diags) != 1 || diags[0].Range != (lspRange{Start: position{0, 14}, End: position{0, 21}}) {
+		t.Errorf("diagnostics = %+v", diags)
+	}
+}
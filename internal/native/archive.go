@@ -0,0 +1,488 @@
+package native
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Archive natives. Archives are read and written through sandboxed paths,
+// and every entry is checked before extraction so that a crafted archive
+// ("zip-slip") cannot write outside the destination directory.
+
+// archiveFormats lists the formats accepted by --format.
+const archiveFormats = "tar tar.gz zip"
+
+// archiveEntry is one member of a tar or zip archive.
+type archiveEntry struct {
+	name    string
+	isDir   bool
+	regular bool // false for links, devices and other special entries
+	mode    fs.FileMode
+	open    func() (io.ReadCloser, error)
+}
+
+// archiveFormat picks the archive format from --format or the file extension.
+func archiveFormat(name, userPath string, refValues map[string]core.Value) (string, error) {
+	if hasFormat, formatVal := getRefinementValue(refValues, "format"); hasFormat {
+		format, ok := value.AsWordValue(formatVal)
+		if !ok {
+			str, isStr := value.AsStringValue(formatVal)
+			if !isStr {
+				return "", typeError(name, "word!", formatVal)
+			}
+			format = str.String()
+		}
+		format = strings.ToLower(format)
+		for _, f := range strings.Fields(archiveFormats) {
+			if f == format {
+				return format, nil
+			}
+		}
+		return "", verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("%s: unknown format '%s' (expected one of: %s)", name, format, archiveFormats), "", ""},
+		)
+	}
+
+	lower := strings.ToLower(userPath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip", nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar", nil
+	}
+	return "", verror.NewScriptError(
+		verror.ErrIDInvalidOperation,
+		[3]string{fmt.Sprintf("%s: cannot tell the archive format of %s (use --format)", name, userPath), "", ""},
+	)
+}
+
+// walkArchive calls fn for each entry of the archive at resolved, in archive
+// order. Entry readers are only valid during the call.
+func walkArchive(format, resolved string, fn func(archiveEntry) error) error {
+	if format == "zip" {
+		zr, err := zip.OpenReader(resolved)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			mode := f.Mode()
+			entry := archiveEntry{
+				name:    f.Name,
+				isDir:   mode.IsDir() || strings.HasSuffix(f.Name, "/"),
+				regular: mode.IsRegular() || mode.IsDir(),
+				mode:    mode.Perm(),
+				open:    f.Open,
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if format == "tar.gz" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		entry := archiveEntry{
+			name:    hdr.Name,
+			isDir:   hdr.Typeflag == tar.TypeDir,
+			regular: hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeDir,
+			mode:    fs.FileMode(hdr.Mode).Perm(),
+			open:    func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveEntryName is the listing form of an entry: slash separated, with a
+// trailing slash for directories.
+func archiveEntryName(entry archiveEntry) string {
+	name := strings.TrimSuffix(strings.ReplaceAll(entry.name, "\\", "/"), "/")
+	if entry.isDir {
+		name += "/"
+	}
+	return name
+}
+
+// unsafeEntryError reports an archive entry that extraction refuses to write.
+func unsafeEntryError(name, reason string) error {
+	return verror.NewAccessError(verror.ErrIDUnsafeArchiveEntry, [3]string{name, reason, ""})
+}
+
+// extractionTarget maps an entry name to its path below dest. Absolute
+// names, names that climb out with "..", and names that reach outside dest
+// through a symlink already on disk are rejected.
+func extractionTarget(dest, name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	if path.IsAbs(slashed) || filepath.VolumeName(name) != "" {
+		return "", unsafeEntryError(name, "absolute path")
+	}
+	cleaned := path.Clean(slashed)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", unsafeEntryError(name, "path leaves the destination")
+	}
+
+	resolved, err := eval.ResolveSandboxPath(filepath.Join(dest, filepath.FromSlash(cleaned)))
+	if err != nil {
+		return "", unsafeEntryError(name, "path leaves the sandbox")
+	}
+	if resolved != dest && !strings.HasPrefix(resolved, dest+string(filepath.Separator)) {
+		return "", unsafeEntryError(name, "path leaves the destination")
+	}
+	return resolved, nil
+}
+
+// extractEntry writes one archive entry to target.
+func extractEntry(entry archiveEntry, target string) error {
+	if entry.isDir {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	mode := entry.mode
+	if mode == 0 {
+		mode = 0644
+	}
+
+	src, err := entry.open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// archiveReadError converts a failure while reading an archive into either
+// the verror it already is or a corrupt-data error.
+func archiveReadError(format string, err error) error {
+	var verr *verror.Error
+	if errors.As(err, &verr) {
+		return verr
+	}
+	return corruptDataError(format, err)
+}
+
+// ArchiveListNative implements `archive-list`: the entry names of a tar,
+// tar.gz or zip archive, in archive order. Directories end with a slash.
+func ArchiveListNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("archive-list", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("archive-list", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	format, err := archiveFormat("archive-list", userPath, refValues)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if _, err := os.Stat(resolved); err != nil {
+		return value.NewNoneVal(), fileError("archive-list", userPath, err)
+	}
+
+	elements := []core.Value{}
+	err = walkArchive(format, resolved, func(entry archiveEntry) error {
+		elements = append(elements, value.NewStrVal(archiveEntryName(entry)))
+		return nil
+	})
+	if err != nil {
+		return value.NewNoneVal(), archiveReadError(format, err)
+	}
+	return value.NewBlockVal(elements), nil
+}
+
+// ArchiveExtractNative implements `archive-extract`: unpacks an archive into
+// a directory inside the sandbox, creating it if needed, and returns the
+// names of the extracted entries. Links and other special entries are
+// refused, as is any entry whose path would land outside the directory; in
+// that case nothing is extracted.
+func ArchiveExtractNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("archive-extract", 2, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("archive-extract", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	dest, destPath, err := resolveFilePath("archive-extract", args[1])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	format, err := archiveFormat("archive-extract", userPath, refValues)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if _, err := os.Stat(resolved); err != nil {
+		return value.NewNoneVal(), fileError("archive-extract", userPath, err)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return value.NewNoneVal(), fileError("archive-extract", destPath, err)
+	}
+
+	// Check every entry before writing anything, so a rejected archive leaves
+	// the destination untouched.
+	err = walkArchive(format, resolved, func(entry archiveEntry) error {
+		if !entry.regular {
+			return unsafeEntryError(entry.name, "links and special files are not supported")
+		}
+		_, err := extractionTarget(dest, entry.name)
+		return err
+	})
+	if err != nil {
+		return value.NewNoneVal(), archiveReadError(format, err)
+	}
+
+	elements := []core.Value{}
+	err = walkArchive(format, resolved, func(entry archiveEntry) error {
+		target, err := extractionTarget(dest, entry.name)
+		if err != nil {
+			return err
+		}
+		if err := extractEntry(entry, target); err != nil {
+			return fileError("archive-extract", entry.name, err)
+		}
+		elements = append(elements, value.NewStrVal(archiveEntryName(entry)))
+		return nil
+	})
+	if err != nil {
+		return value.NewNoneVal(), archiveReadError(format, err)
+	}
+	return value.NewBlockVal(elements), nil
+}
+
+// archiveWriter adds files and directories to a tar or zip archive.
+type archiveWriter interface {
+	addDir(name string, info fs.FileInfo) error
+	addFile(name string, info fs.FileInfo, src io.Reader) error
+	Close() error
+}
+
+type tarArchiveWriter struct {
+	tw *tar.Writer
+	gz *gzip.Writer
+}
+
+func (w *tarArchiveWriter) addDir(name string, info fs.FileInfo) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name + "/"
+	return w.tw.WriteHeader(hdr)
+}
+
+func (w *tarArchiveWriter) addFile(name string, info fs.FileInfo, src io.Reader) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(w.tw, src)
+	return err
+}
+
+func (w *tarArchiveWriter) Close() error {
+	err := w.tw.Close()
+	if w.gz != nil {
+		if gzErr := w.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	return err
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) addDir(name string, info fs.FileInfo) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name + "/"
+	_, err = w.zw.CreateHeader(hdr)
+	return err
+}
+
+func (w *zipArchiveWriter) addFile(name string, info fs.FileInfo, src io.Reader) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+	dst, err := w.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+// addArchiveSource adds the file or directory tree at resolved, skipping the
+// archive being written. Entry names are relative to the source's parent
+// directory, so "logs" becomes "logs/" followed by "logs/app.log" and so on.
+func addArchiveSource(w archiveWriter, resolved, archivePath string, names *[]core.Value) error {
+	base := filepath.Dir(resolved)
+	return filepath.WalkDir(resolved, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if p == archivePath {
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			if err := w.addDir(name, info); err != nil {
+				return err
+			}
+			*names = append(*names, value.NewStrVal(name+"/"))
+		case info.Mode().IsRegular():
+			src, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			err = w.addFile(name, info, src)
+			src.Close()
+			if err != nil {
+				return err
+			}
+			*names = append(*names, value.NewStrVal(name))
+		}
+		return nil
+	})
+}
+
+// ArchiveCreateNative implements `archive-create`: writes the given files and
+// directories (recursively) into a new tar, tar.gz or zip archive and returns
+// the names of the entries written. Symlinks and special files are skipped.
+func ArchiveCreateNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("archive-create", 2, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("archive-create", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	format, err := archiveFormat("archive-create", userPath, refValues)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	sourceArgs := []core.Value{args[1]}
+	if block, ok := value.AsBlockValue(args[1]); ok {
+		sourceArgs = block.Elements
+	}
+	sources := make([]string, len(sourceArgs))
+	for i, src := range sourceArgs {
+		p, _, err := resolveFilePath("archive-create", src)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		sources[i] = p
+	}
+
+	if err := os.MkdirAll(filepath.Dir(resolved), 0755); err != nil {
+		return value.NewNoneVal(), fileError("archive-create", userPath, err)
+	}
+	file, err := os.Create(resolved)
+	if err != nil {
+		return value.NewNoneVal(), fileError("archive-create", userPath, err)
+	}
+	defer file.Close()
+
+	var w archiveWriter
+	switch format {
+	case "zip":
+		w = &zipArchiveWriter{zw: zip.NewWriter(file)}
+	case "tar.gz":
+		gz := gzip.NewWriter(file)
+		w = &tarArchiveWriter{tw: tar.NewWriter(gz), gz: gz}
+	default:
+		w = &tarArchiveWriter{tw: tar.NewWriter(file)}
+	}
+
+	elements := []core.Value{}
+	for _, src := range sources {
+		if err := addArchiveSource(w, src, resolved, &elements); err != nil {
+			w.Close()
+			return value.NewNoneVal(), fileError("archive-create", userPath, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return value.NewNoneVal(), fileError("archive-create", userPath, err)
+	}
+	return value.NewBlockVal(elements), nil
+}
@@ -0,0 +1,188 @@
+package native
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func assertionError(message string) *verror.Error {
+	return verror.NewScriptError(verror.ErrIDAssertionFailed, [3]string{message, "", ""})
+}
+
+// Assert implements the `assert` native.
+//
+// Contract: assert condition [--message text]
+// - A block condition is evaluated; its source is quoted on failure
+// - Fails with an assertion-failed error when the condition is falsy
+// - Returns true otherwise
+func Assert(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("assert", 1, len(args))
+	}
+
+	result := args[0]
+	description := "condition is " + result.Mold()
+	if block, ok := value.AsBlockValue(args[0]); ok {
+		var err error
+		result, err = eval.DoBlock(block.Elements, block.Locations())
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		description = block.Mold()
+	}
+	if ToTruthy(result) {
+		return value.NewLogicVal(true), nil
+	}
+
+	if msg, ok := refValues["message"]; ok && msg.GetType() != value.TypeNone {
+		description = msg.Form()
+	}
+	return value.NewNoneVal(), assertionError(description)
+}
+
+// AssertEqual implements the `assert-equal` native.
+//
+// Contract: assert-equal expected actual
+// - Compares with the same equality as =
+// - On failure the message shows both values molded and where they differ
+func AssertEqual(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("assert-equal", 2, len(args))
+	}
+
+	expected, actual := args[0], args[1]
+	if expected.Equals(actual) {
+		return value.NewLogicVal(true), nil
+	}
+	return value.NewNoneVal(), assertionError("values differ\n" + moldDiff(expected, actual))
+}
+
+// moldDiff describes how two values differ. Single-line molds get a caret
+// under the first differing character; multi-line molds are compared line
+// by line.
+func moldDiff(expected, actual core.Value) string {
+	want, got := expected.Mold(), actual.Mold()
+	if want == got {
+		return fmt.Sprintf("expected: %s (%s)\n  actual: %s (%s)",
+			want, value.TypeToString(expected.GetType()), got, value.TypeToString(actual.GetType()))
+	}
+
+	if !strings.Contains(want, "\n") && !strings.Contains(got, "\n") {
+		at := 0
+		for at < len(want) && at < len(got) && want[at] == got[at] {
+			at++
+		}
+		return fmt.Sprintf("expected: %s\n  actual: %s\n          %s^", want, got, strings.Repeat(" ", len([]rune(want[:at]))))
+	}
+
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	b.WriteString("--- expected\n+++ actual")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		hasW, hasG := i < len(wantLines), i < len(gotLines)
+		if hasW {
+			w = wantLines[i]
+		}
+		if hasG {
+			g = gotLines[i]
+		}
+		switch {
+		case hasW && hasG && w == g:
+			b.WriteString("\n  " + w)
+		default:
+			if hasW {
+				b.WriteString("\n- " + w)
+			}
+			if hasG {
+				b.WriteString("\n+ " + g)
+			}
+		}
+	}
+	return b.String()
+}
+
+// AssertError implements the `assert-error` native.
+//
+// Contract: assert-error [block] [--id 'error-id]
+// - Evaluates the block and fails unless it raises an error
+// - With --id, the error's id must match as well
+// - Returns the error message as a string
+//
+// Signals that are not errors (return, exit) pass through unchanged.
+func AssertError(args []core.Value, refValues map[string]core.Value, evaluator core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("assert-error", 1, len(args))
+	}
+	block, ok := value.AsBlockValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("assert-error", "block", args[0])
+	}
+
+	var wantID string
+	if id, ok := refValues["id"]; ok && id.GetType() != value.TypeNone {
+		if wantID, ok = value.AsWordValue(id); !ok {
+			return value.NewNoneVal(), typeError("assert-error --id", "word", id)
+		}
+	}
+
+	result, err := evaluator.DoBlock(block.Elements, block.Locations())
+	if err == nil {
+		return value.NewNoneVal(), assertionError(fmt.Sprintf("expected an error, but %s returned %s", block.Mold(), result.Mold()))
+	}
+	switch err.(type) {
+	case *eval.ExitSignal, *eval.ReturnSignal:
+		return value.NewNoneVal(), err
+	}
+	if isControl, _ := isLoopControlSignal(err); isControl {
+		return value.NewNoneVal(), err
+	}
+
+	vErr, ok := err.(*verror.Error)
+	if !ok {
+		return value.NewStrVal(err.Error()), nil
+	}
+	if wantID != "" && vErr.ID != wantID {
+		return value.NewNoneVal(), assertionError(fmt.Sprintf("expected error %s, got %s: %s", wantID, vErr.ID, vErr.Message))
+	}
+	return value.NewStrVal(vErr.Message), nil
+}
+
+// DoInNewFrame evaluates a block in a new frame whose parent is the
+// current one, so the block sees the surrounding words but its own
+// assignments stay local. It is how test cases are isolated.
+func DoInNewFrame(eval core.Evaluator, block *value.BlockValue, name string) (core.Value, error) {
+	f := frame.NewFrame(frame.FrameFunctionArgs, eval.CurrentFrameIndex())
+	f.SetName(name)
+	eval.PushFrameContext(f)
+	defer eval.PopFrameContext()
+	return eval.DoBlock(block.Elements, block.Locations())
+}
+
+// Test implements the `test` native.
+//
+// Contract: test name [body]
+// - Evaluates body in a new frame and returns its result
+//
+// `viro test` replaces this binding with one that collects the cases so
+// it can run and report them individually.
+func Test(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("test", 2, len(args))
+	}
+	name, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("test", "string", args[0])
+	}
+	body, ok := value.AsBlockValue(args[1])
+	if !ok {
+		return value.NewNoneVal(), typeError("test", "block", args[1])
+	}
+	return DoInNewFrame(eval, body, "test "+name.String())
+}
@@ -2,6 +2,7 @@ package native
 
 import (
 	"fmt"
+	"math/big"
 	"math/bits"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
@@ -14,6 +15,10 @@ func BitAnd(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		return value.NewNoneVal(), arityError("bit.and", 2, len(args))
 	}
 
+	if left, right, ok := bigIntOperands(args[0], args[1]); ok {
+		return value.NewBigIntVal(new(big.Int).And(left, right)), nil
+	}
+
 	leftType := args[0].GetType()
 	rightType := args[1].GetType()
 
@@ -36,7 +41,7 @@ func BitAnd(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		return binaryAnd(left, right), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.and", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.and", "integer! bigint! binary!", args[0])
 	}
 }
 
@@ -45,6 +50,10 @@ func BitOr(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return value.NewNoneVal(), arityError("bit.or", 2, len(args))
 	}
 
+	if left, right, ok := bigIntOperands(args[0], args[1]); ok {
+		return value.NewBigIntVal(new(big.Int).Or(left, right)), nil
+	}
+
 	leftType := args[0].GetType()
 	rightType := args[1].GetType()
 
@@ -67,7 +76,7 @@ func BitOr(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return binaryOr(left, right), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.or", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.or", "integer! bigint! binary!", args[0])
 	}
 }
 
@@ -76,6 +85,10 @@ func BitXor(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		return value.NewNoneVal(), arityError("bit.xor", 2, len(args))
 	}
 
+	if left, right, ok := bigIntOperands(args[0], args[1]); ok {
+		return value.NewBigIntVal(new(big.Int).Xor(left, right)), nil
+	}
+
 	leftType := args[0].GetType()
 	rightType := args[1].GetType()
 
@@ -98,7 +111,7 @@ func BitXor(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		return binaryXor(left, right), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.xor", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.xor", "integer! bigint! binary!", args[0])
 	}
 }
 
@@ -112,12 +125,16 @@ func BitNot(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		val, _ := value.AsIntValue(args[0])
 		return value.NewIntVal(^val), nil
 
+	case value.TypeBigInt:
+		val, _ := value.AsBigIntValue(args[0])
+		return value.NewBigIntVal(new(big.Int).Not(val)), nil
+
 	case value.TypeBinary:
 		bin, _ := value.AsBinaryValue(args[0])
 		return binaryNot(bin), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.not", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.not", "integer! bigint! binary!", args[0])
 	}
 }
 
@@ -144,12 +161,19 @@ func BitShl(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		val, _ := value.AsIntValue(args[0])
 		return value.NewIntVal(val << uint(count)), nil
 
+	case value.TypeBigInt:
+		val, _ := value.AsBigIntValue(args[0])
+		if val.Sign() != 0 && count > maxBigIntBits-int64(val.BitLen()) {
+			return value.NewNoneVal(), bigIntLimitError("bit.shl")
+		}
+		return value.NewBigIntVal(new(big.Int).Lsh(val, uint(count))), nil
+
 	case value.TypeBinary:
 		bin, _ := value.AsBinaryValue(args[0])
 		return binaryShl(bin, count), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.shl", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.shl", "integer! bigint! binary!", args[0])
 	}
 }
 
@@ -176,12 +200,16 @@ func BitShr(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		val, _ := value.AsIntValue(args[0])
 		return value.NewIntVal(val >> uint(count)), nil
 
+	case value.TypeBigInt:
+		val, _ := value.AsBigIntValue(args[0])
+		return value.NewBigIntVal(new(big.Int).Rsh(val, uint(count))), nil
+
 	case value.TypeBinary:
 		bin, _ := value.AsBinaryValue(args[0])
 		return binaryShr(bin, count), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.shr", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.shr", "integer! bigint! binary!", args[0])
 	}
 }
 
@@ -190,6 +218,10 @@ func BitOn(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return value.NewNoneVal(), arityError("bit.on", 2, len(args))
 	}
 
+	if args[0].GetType() == value.TypeBigInt {
+		return bigIntSetBit("bit.on", args, 1)
+	}
+
 	if args[0].GetType() != value.TypeInteger {
 		return value.NewNoneVal(), typeError("bit.on", "integer!", args[0])
 	}
@@ -216,6 +248,10 @@ func BitOff(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		return value.NewNoneVal(), arityError("bit.off", 2, len(args))
 	}
 
+	if args[0].GetType() == value.TypeBigInt {
+		return bigIntSetBit("bit.off", args, 0)
+	}
+
 	if args[0].GetType() != value.TypeInteger {
 		return value.NewNoneVal(), typeError("bit.off", "integer!", args[0])
 	}
@@ -247,13 +283,73 @@ func BitCount(args []core.Value, refValues map[string]core.Value, eval core.Eval
 		val, _ := value.AsIntValue(args[0])
 		return value.NewIntVal(int64(bits.OnesCount64(uint64(val)))), nil
 
+	case value.TypeBigInt:
+		val, _ := value.AsBigIntValue(args[0])
+		if val.Sign() < 0 {
+			return value.NewNoneVal(), verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"bit.count: a negative bigint! has infinitely many set bits", "", ""},
+			)
+		}
+		var count int64
+		for _, word := range val.Bits() {
+			count += int64(bits.OnesCount(uint(word)))
+		}
+		return value.NewIntVal(count), nil
+
 	case value.TypeBinary:
 		bin, _ := value.AsBinaryValue(args[0])
 		return value.NewIntVal(countBinaryBits(bin)), nil
 
 	default:
-		return value.NewNoneVal(), typeError("bit.count", "integer! binary!", args[0])
+		return value.NewNoneVal(), typeError("bit.count", "integer! bigint! binary!", args[0])
+	}
+}
+
+// bigIntOperands returns both operands as big integers when one of them is a
+// bigint! and the other an integer! or bigint!.
+func bigIntOperands(left, right core.Value) (*big.Int, *big.Int, bool) {
+	if left.GetType() != value.TypeBigInt && right.GetType() != value.TypeBigInt {
+		return nil, nil, false
+	}
+	a, ok := promoteToBigInt(left)
+	if !ok {
+		return nil, nil, false
+	}
+	b, ok := promoteToBigInt(right)
+	if !ok {
+		return nil, nil, false
+	}
+	return a, b, true
+}
+
+// maxBigIntBits caps the size of a bigint! built by a shift, a bit position
+// or a power, so that a single expression cannot exhaust memory.
+const maxBigIntBits = 1 << 24
+
+// bigIntLimitError reports a bigint! result that would exceed maxBigIntBits.
+func bigIntLimitError(op string) error {
+	return overflowError(fmt.Sprintf("%s (result over %d bits)", op, maxBigIntBits))
+}
+
+// bigIntSetBit implements bit.on and bit.off for bigint!, whose bit position
+// is limited only by maxBigIntBits.
+func bigIntSetBit(name string, args []core.Value, bit uint) (core.Value, error) {
+	val, _ := value.AsBigIntValue(args[0])
+	if args[1].GetType() != value.TypeInteger {
+		return value.NewNoneVal(), typeError(name, "integer!", args[1])
+	}
+	pos, _ := value.AsIntValue(args[1])
+	if pos < 0 {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("%s: bit position %d must not be negative", name, pos), "", ""},
+		)
+	}
+	if pos >= maxBigIntBits {
+		return value.NewNoneVal(), bigIntLimitError(name)
 	}
+	return value.NewBigIntVal(new(big.Int).SetBit(val, int(pos), bit)), nil
 }
 
 func binaryLogicOp(left, right *value.BinaryValue, op func(byte, byte) byte, padZero bool) core.Value {
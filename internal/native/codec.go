@@ -0,0 +1,242 @@
+package native
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// hashMethods are the digests accepted by checksum and hmac.
+var hashMethods = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// codecBytes returns the bytes of a binary, or the UTF-8 encoding of a string.
+func codecBytes(name string, v core.Value) ([]byte, error) {
+	if bin, ok := value.AsBinaryValue(v); ok {
+		return bin.Bytes(), nil
+	}
+	if str, ok := value.AsStringValue(v); ok {
+		return []byte(str.String()), nil
+	}
+	return nil, typeError(name, "binary! string!", v)
+}
+
+// codecBase reads the --base and --url refinements shared by enbase and debase.
+func codecBase(name string, refValues map[string]core.Value) (int64, bool, error) {
+	base := int64(64)
+	if hasBase, baseVal := getRefinementValue(refValues, "base"); hasBase {
+		b, ok := value.AsIntValue(baseVal)
+		if !ok {
+			return 0, false, typeError(name, "integer!", baseVal)
+		}
+		base = b
+	}
+	switch base {
+	case 2, 16, 32, 64:
+	default:
+		return 0, false, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("%s: unsupported base %d (expected 2, 16, 32 or 64)", name, base), "", ""},
+		)
+	}
+	url := hasRefinement(refValues, "url")
+	if url && base != 64 {
+		return 0, false, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{name + ": --url only applies to base 64", "", ""},
+		)
+	}
+	return base, url, nil
+}
+
+// EnbaseNative implements `enbase`: encodes a binary (or the UTF-8 bytes of a
+// string) as base 64 text, or base 2, 16 or 32 with --base. --url selects the
+// unpadded URL-safe base 64 alphabet.
+func EnbaseNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("enbase", 1, len(args))
+	}
+	data, err := codecBytes("enbase", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	base, url, err := codecBase("enbase", refValues)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	switch base {
+	case 2:
+		var b strings.Builder
+		b.Grow(len(data) * 8)
+		for _, octet := range data {
+			fmt.Fprintf(&b, "%08b", octet)
+		}
+		return value.NewStrVal(b.String()), nil
+	case 16:
+		return value.NewStrVal(strings.ToUpper(hex.EncodeToString(data))), nil
+	case 32:
+		return value.NewStrVal(base32.StdEncoding.EncodeToString(data)), nil
+	default:
+		if url {
+			return value.NewStrVal(base64.RawURLEncoding.EncodeToString(data)), nil
+		}
+		return value.NewStrVal(base64.StdEncoding.EncodeToString(data)), nil
+	}
+}
+
+// DebaseNative implements `debase`: decodes text produced by enbase back into
+// a binary. Whitespace is ignored, hexadecimal digits may use either case and
+// base 64 padding is optional.
+func DebaseNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("debase", 1, len(args))
+	}
+	str, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("debase", "string!", args[0])
+	}
+	base, url, err := codecBase("debase", refValues)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	text := strings.Join(strings.Fields(str.String()), "")
+	var data []byte
+	switch base {
+	case 2:
+		data, err = decodeBase2(text)
+	case 16:
+		data, err = hex.DecodeString(text)
+	case 32:
+		data, err = base32.StdEncoding.DecodeString(text)
+	default:
+		text = strings.TrimRight(text, "=")
+		if url {
+			data, err = base64.RawURLEncoding.DecodeString(text)
+		} else {
+			data, err = base64.RawStdEncoding.DecodeString(text)
+		}
+	}
+	if err != nil {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidEncoding,
+			[3]string{fmt.Sprintf("%d", base), str.String(), ""},
+		)
+	}
+	return value.NewBinaryVal(data), nil
+}
+
+// decodeBase2 parses groups of eight binary digits into bytes.
+func decodeBase2(text string) ([]byte, error) {
+	if len(text)%8 != 0 {
+		return nil, fmt.Errorf("length %d is not a multiple of 8", len(text))
+	}
+	data := make([]byte, len(text)/8)
+	for i := range data {
+		var octet byte
+		for _, digit := range text[i*8 : i*8+8] {
+			if digit != '0' && digit != '1' {
+				return nil, fmt.Errorf("invalid digit %q", digit)
+			}
+			octet = octet<<1 | byte(digit-'0')
+		}
+		data[i] = octet
+	}
+	return data, nil
+}
+
+// hashMethodArg reads a --method refinement naming one of allowed.
+func hashMethodArg(name string, refValues map[string]core.Value, fallback string, allowed string) (string, error) {
+	hasMethod, methodVal := getRefinementValue(refValues, "method")
+	if !hasMethod {
+		return fallback, nil
+	}
+	method, ok := value.AsWordValue(methodVal)
+	if !ok {
+		str, isStr := value.AsStringValue(methodVal)
+		if !isStr {
+			return "", typeError(name, "word!", methodVal)
+		}
+		method = str.String()
+	}
+	method = strings.ToLower(method)
+	for _, m := range strings.Fields(allowed) {
+		if m == method {
+			return method, nil
+		}
+	}
+	return "", verror.NewScriptError(
+		verror.ErrIDInvalidOperation,
+		[3]string{fmt.Sprintf("%s: unknown method '%s' (expected one of: %s)", name, method, allowed), "", ""},
+	)
+}
+
+// ChecksumNative implements `checksum`: a digest of a binary or of the UTF-8
+// bytes of a string. crc32 and adler32 return integers; md5, sha1, sha256
+// (the default) and sha512 return binaries.
+func ChecksumNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("checksum", 1, len(args))
+	}
+	data, err := codecBytes("checksum", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	method, err := hashMethodArg("checksum", refValues, "sha256", "crc32 adler32 md5 sha1 sha256 sha512")
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	switch method {
+	case "crc32":
+		return value.NewIntVal(int64(crc32.ChecksumIEEE(data))), nil
+	case "adler32":
+		return value.NewIntVal(int64(adler32.Checksum(data))), nil
+	}
+	h := hashMethods[method]()
+	h.Write(data)
+	return value.NewBinaryVal(h.Sum(nil)), nil
+}
+
+// HmacNative implements `hmac`: a keyed message authentication code of data
+// using sha256, or md5, sha1 or sha512 with --method.
+func HmacNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("hmac", 2, len(args))
+	}
+	data, err := codecBytes("hmac", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	key, err := codecBytes("hmac", args[1])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	method, err := hashMethodArg("hmac", refValues, "sha256", "md5 sha1 sha256 sha512")
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	mac := hmac.New(hashMethods[method], key)
+	mac.Write(data)
+	return value.NewBinaryVal(mac.Sum(nil)), nil
+}
@@ -0,0 +1,114 @@
+package native
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// compressionMethods lists the formats accepted by compress and decompress.
+const compressionMethods = "gzip zlib deflate"
+
+// compressBytes compresses data with method at the default compression level.
+func compressBytes(method string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch method {
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		w = fw
+	default:
+		w = gzip.NewWriter(&buf)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressBytes reverses compressBytes. Concatenated gzip members, as
+// produced by appending to a .gz file, decompress to their joined contents.
+func decompressBytes(method string, data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch method {
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	case "deflate":
+		r = flate.NewReader(bytes.NewReader(data))
+	default:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// corruptDataError reports compressed input that could not be decoded.
+func corruptDataError(method string, err error) error {
+	return verror.NewScriptError(verror.ErrIDCorruptData, [3]string{method, err.Error(), ""})
+}
+
+// CompressNative implements `compress`: compresses a binary (or the UTF-8
+// bytes of a string) with gzip, or zlib or raw deflate with --method.
+func CompressNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("compress", 1, len(args))
+	}
+	data, err := codecBytes("compress", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	method, err := hashMethodArg("compress", refValues, "gzip", compressionMethods)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	out, err := compressBytes(method, data)
+	if err != nil {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"compress failed: " + err.Error(), "", ""},
+		)
+	}
+	return value.NewBinaryVal(out), nil
+}
+
+// DecompressNative implements `decompress`: the inverse of compress. The
+// result is a binary, or with --string the data decoded as UTF-8 text.
+func DecompressNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("decompress", 1, len(args))
+	}
+	bin, ok := value.AsBinaryValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("decompress", "binary!", args[0])
+	}
+	method, err := hashMethodArg("decompress", refValues, "gzip", compressionMethods)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	out, err := decompressBytes(method, bin.Bytes())
+	if err != nil {
+		return value.NewNoneVal(), corruptDataError(method, err)
+	}
+	if hasRefinement(refValues, "string") {
+		return value.NewStrVal(string(out)), nil
+	}
+	return value.NewBinaryVal(out), nil
+}
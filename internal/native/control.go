@@ -7,11 +7,14 @@ package native
 import (
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/debug"
+	"github.com/marcin-radoszewski/viro/internal/frame"
 	"github.com/marcin-radoszewski/viro/internal/trace"
 	"github.com/marcin-radoszewski/viro/internal/value"
 	"github.com/marcin-radoszewski/viro/internal/verror"
@@ -430,10 +433,15 @@ func parseLevelsRefinement(refValues map[string]core.Value) (int64, error) {
 
 // Trace implements the 'trace' native for tracing control (Feature 002, FR-020).
 //
-// Contract: trace --on [--only block] [--exclude block] [--file path] [--append]
+// Contract: trace --on [--only block] [--exclude block] [--types block]
 //
+//	[--file path] [--append] [--format word] [--buffer n] [--memory]
 //	trace --off
 //
+// --only and --exclude take words or glob strings. --file, --format,
+// --buffer and --memory replace the session's sink; without them events go
+// where the session was configured to send them.
+//
 // T144: Implements trace --on with refinements
 // T145: Implements trace --off
 func Trace(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
@@ -481,81 +489,30 @@ func Trace(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 
 	filters := trace.TraceFilters{}
 
-	// Handle --only refinement
-	if onlyVal, ok := refValues["only"]; ok && onlyVal.GetType() != value.TypeNone {
-		if onlyVal.GetType() != value.TypeBlock {
-			return value.NewNoneVal(), verror.NewScriptError(
-				verror.ErrIDTypeMismatch,
-				[3]string{"--only requires block of words", "", ""},
-			)
-		}
-		onlyBlk, _ := value.AsBlockValue(onlyVal)
-		for _, elem := range onlyBlk.Elements {
-			if elem.GetType() != value.TypeWord {
-				return value.NewNoneVal(), verror.NewScriptError(
-					verror.ErrIDTypeMismatch,
-					[3]string{"--only block must contain only words", "", ""},
-				)
-			}
-			word, _ := value.AsWordValue(elem)
-			filters.IncludeWords = append(filters.IncludeWords, word)
-		}
+	var err error
+	if filters.IncludeWords, err = traceNames("--only", refValues["only"], false); err != nil {
+		return value.NewNoneVal(), err
 	}
-
-	// Handle --exclude refinement
-	if excludeVal, ok := refValues["exclude"]; ok && excludeVal.GetType() != value.TypeNone {
-		if excludeVal.GetType() != value.TypeBlock {
-			return value.NewNoneVal(), verror.NewScriptError(
-				verror.ErrIDTypeMismatch,
-				[3]string{"--exclude requires block of words", "", ""},
-			)
-		}
-		excludeBlk, _ := value.AsBlockValue(excludeVal)
-		for _, elem := range excludeBlk.Elements {
-			if elem.GetType() != value.TypeWord {
-				return value.NewNoneVal(), verror.NewScriptError(
-					verror.ErrIDTypeMismatch,
-					[3]string{"--exclude block must contain only words", "", ""},
-				)
-			}
-			word, _ := value.AsWordValue(elem)
-			filters.ExcludeWords = append(filters.ExcludeWords, word)
-		}
+	if filters.ExcludeWords, err = traceNames("--exclude", refValues["exclude"], false); err != nil {
+		return value.NewNoneVal(), err
 	}
-
-	// Handle --file refinement with sandbox validation
-	if fileVal, ok := refValues["file"]; ok && fileVal.GetType() != value.TypeNone {
-		if fileVal.GetType() != value.TypeString {
-			return value.NewNoneVal(), verror.NewScriptError(
-				verror.ErrIDTypeMismatch,
-				[3]string{"--file requires string path", "", ""},
-			)
-		}
-		fileStr, _ := value.AsStringValue(fileVal)
-		filePath := fileStr.String()
-
-		// Validate path is within sandbox
-		_, err := resolveSandboxPath(filePath)
-		if err != nil {
-			return value.NewNoneVal(), verror.NewAccessError(
-				verror.ErrIDSandboxViolation,
-				[3]string{"trace file path escapes sandbox", filePath, ""},
-			)
-		}
-
-		// Note: Actual file redirection would require reinitializing the trace session
-		// For now, we only validate the path is within sandbox
-		// Full implementation deferred until trace session supports dynamic reconfiguration
-	} // Handle --append refinement (validates file must also be provided)
-	if appendVal, ok := refValues["append"]; ok && ToTruthy(appendVal) {
-		if _, hasFile := refValues["file"]; !hasFile || refValues["file"].GetType() == value.TypeNone {
+	if filters.EventTypes, err = traceNames("--types", refValues["types"], true); err != nil {
+		return value.NewNoneVal(), err
+	}
+	for _, name := range filters.EventTypes {
+		if !slices.Contains(trace.EventTypes, name) {
 			return value.NewNoneVal(), verror.NewScriptError(
 				verror.ErrIDInvalidOperation,
-				[3]string{"--append requires --file to be specified", "", ""},
+				[3]string{fmt.Sprintf("--types: unknown event type %s (valid: %s)", name, strings.Join(trace.EventTypes, ", ")), "", ""},
 			)
 		}
 	}
 
+	sink, err := traceSink(refValues, eval)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
 	// Handle --verbose refinement (Phase 3)
 	if verboseVal, ok := refValues["verbose"]; ok && ToTruthy(verboseVal) {
 		filters.Verbose = true
@@ -602,6 +559,15 @@ func Trace(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		filters.MaxDepth = int(maxDepth)
 	}
 
+	if sink != nil {
+		if err := trace.GlobalTraceSession.SetSink(sink); err != nil {
+			return value.NewNoneVal(), verror.NewAccessError(
+				verror.ErrIDInvalidOperation,
+				[3]string{fmt.Sprintf("trace failed to close previous output: %v", err), "", ""},
+			)
+		}
+	}
+
 	// Reset step counter when enabling trace (Phase 3)
 	trace.GlobalTraceSession.ResetStepCounter()
 
@@ -610,6 +576,181 @@ func Trace(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 	return value.NewNoneVal(), nil
 }
 
+// traceNames reads a filter refinement: a block of words, or of strings
+// holding glob patterns. Event types are words only.
+func traceNames(name string, val core.Value, wordsOnly bool) ([]string, error) {
+	if val == nil || val.GetType() == value.TypeNone {
+		return nil, nil
+	}
+	what := "words or glob strings"
+	if wordsOnly {
+		what = "words"
+	}
+	blk, ok := value.AsBlockValue(val)
+	if !ok {
+		return nil, verror.NewScriptError(
+			verror.ErrIDTypeMismatch,
+			[3]string{fmt.Sprintf("%s requires block of %s", name, what), "", ""},
+		)
+	}
+	names := make([]string, 0, len(blk.Elements))
+	for _, elem := range blk.Elements {
+		if word, ok := value.AsWordValue(elem); ok && elem.GetType() == value.TypeWord {
+			names = append(names, word)
+			continue
+		}
+		if str, ok := value.AsStringValue(elem); ok && !wordsOnly {
+			names = append(names, str.String())
+			continue
+		}
+		return nil, verror.NewScriptError(
+			verror.ErrIDTypeMismatch,
+			[3]string{fmt.Sprintf("%s block must contain only %s", name, what), "", ""},
+		)
+	}
+	return names, nil
+}
+
+// traceSink builds the sink requested by trace's output refinements, or
+// returns nil when none were given.
+func traceSink(refValues map[string]core.Value, eval core.Evaluator) (trace.Sink, error) {
+	given := func(name string) (core.Value, bool) {
+		val, ok := refValues[name]
+		return val, ok && val.GetType() != value.TypeNone && !(val.GetType() == value.TypeLogic && !ToTruthy(val))
+	}
+	fileVal, hasFile := given("file")
+	formatVal, hasFormat := given("format")
+	bufferVal, hasBuffer := given("buffer")
+	_, hasMemory := given("memory")
+	_, hasAppend := given("append")
+
+	// Handle --append refinement (validates file must also be provided)
+	if hasAppend && !hasFile {
+		return nil, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"--append requires --file to be specified", "", ""},
+		)
+	}
+	if hasMemory && (hasFile || hasFormat || hasBuffer) {
+		return nil, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"--memory cannot be combined with --file, --format or --buffer", "", ""},
+		)
+	}
+	if hasMemory {
+		return trace.NewMemorySink(), nil
+	}
+	if !hasFile && !hasFormat && !hasBuffer {
+		return nil, nil
+	}
+
+	format := "json"
+	if hasFormat {
+		word, ok := value.AsWordValue(formatVal)
+		if !ok || !slices.Contains(trace.Formats, word) {
+			return nil, verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{fmt.Sprintf("--format must be one of %s", strings.Join(trace.Formats, ", ")), formatVal.Mold(), ""},
+			)
+		}
+		format = word
+	}
+
+	size := 0
+	if hasBuffer {
+		n, ok := value.AsIntValue(bufferVal)
+		if !ok || n <= 0 {
+			return nil, verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"--buffer requires a positive integer", bufferVal.Mold(), ""},
+			)
+		}
+		size = int(n)
+	}
+
+	var sink trace.Sink
+	if hasFile {
+		// Handle --file refinement with sandbox validation
+		if fileVal.GetType() != value.TypeString {
+			return nil, verror.NewScriptError(
+				verror.ErrIDTypeMismatch,
+				[3]string{"--file requires string path", "", ""},
+			)
+		}
+		fileStr, _ := value.AsStringValue(fileVal)
+		filePath := fileStr.String()
+
+		// Validate path is within sandbox
+		resolved, err := resolveSandboxPath(filePath)
+		if err != nil {
+			return nil, verror.NewAccessError(
+				verror.ErrIDSandboxViolation,
+				[3]string{"trace file path escapes sandbox", filePath, ""},
+			)
+		}
+		if sink, err = trace.OpenSink(format, resolved, hasAppend); err != nil {
+			return nil, fileError("trace --file", filePath, err)
+		}
+	} else {
+		sink, _ = trace.NewFormatSink(format, eval.GetErrorWriter(), nil)
+	}
+
+	if size > 0 {
+		sink = trace.NewRingSink(size, sink)
+	}
+	return sink, nil
+}
+
+// TraceEvents implements the 'trace-events' native.
+//
+// Contract: trace-events [--clear]
+//
+// Returns the events held by an in-memory or ring-buffer trace sink as a
+// block of objects, oldest first. --clear empties the buffer afterwards.
+func TraceEvents(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 0 {
+		return value.NewNoneVal(), arityError("trace-events", 0, len(args))
+	}
+	var buffered trace.Buffered
+	if trace.GlobalTraceSession != nil {
+		buffered, _ = trace.GlobalTraceSession.Sink().(trace.Buffered)
+	}
+	if buffered == nil {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"trace events are not buffered; use trace --on --memory or --buffer", "", ""},
+		)
+	}
+
+	events := buffered.Events()
+	if val, ok := refValues["clear"]; ok && ToTruthy(val) {
+		buffered.Clear()
+	}
+
+	entries := make([]core.Value, 0, len(events))
+	for _, ev := range events {
+		objFrame := frame.NewFrame(frame.FrameObject, -1)
+		if ev.EventType != "" {
+			objFrame.Bind("type", value.NewWordVal(ev.EventType))
+		} else {
+			objFrame.Bind("type", value.NewNoneVal())
+		}
+		objFrame.Bind("word", value.NewStrVal(ev.Word))
+		objFrame.Bind("expression", value.NewStrVal(ev.Expression))
+		objFrame.Bind("value", value.NewStrVal(ev.Value))
+		objFrame.Bind("depth", value.NewIntVal(int64(ev.Depth)))
+		objFrame.Bind("step", value.NewIntVal(ev.Step))
+		objFrame.Bind("duration", value.NewIntVal(ev.Duration))
+		if ev.Error != "" {
+			objFrame.Bind("error", value.NewStrVal(ev.Error))
+		} else {
+			objFrame.Bind("error", value.NewNoneVal())
+		}
+		entries = append(entries, value.ObjectVal(value.NewObject(objFrame)))
+	}
+	return value.NewBlockVal(entries), nil
+}
+
 // TraceQuery implements the 'trace?' query native (Feature 002, FR-020).
 //
 // Contract: trace?
@@ -969,6 +1110,33 @@ func Return(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 	return value.NewNoneVal(), eval.NewReturnSignal(returnVal)
 }
 
+// Exit implements `exit` and `quit`: stops the program with an optional status
+// code (default 0). Status codes must fit a process exit status (0-255).
+func Exit(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 0 {
+		return value.NewNoneVal(), arityError("exit", 0, len(args))
+	}
+
+	// The status is a refinement so that a bare exit never takes the value
+	// that follows it as its status.
+	code := int64(0)
+	if arg, ok := refValues["code"]; ok && arg.GetType() != value.TypeNone {
+		status, ok := value.AsIntValue(arg)
+		if !ok {
+			return value.NewNoneVal(), typeError("exit --code", "integer!", arg)
+		}
+		if status < 0 || status > 255 {
+			return value.NewNoneVal(), verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{fmt.Sprintf("exit status must be between 0 and 255, got %d", status), "", ""},
+			)
+		}
+		code = status
+	}
+
+	return value.NewNoneVal(), eval.NewExitSignal(int(code))
+}
+
 func Probe(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	if len(args) != 1 {
 		return value.NewNoneVal(), arityError("probe", 1, len(args))
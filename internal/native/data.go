@@ -1,6 +1,9 @@
 package native
 
 import (
+	"errors"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -521,8 +524,8 @@ func Put(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 // ToInteger implements the `to-integer` native for converting values to integers.
 //
 // Contract: to-integer value -> integer!
-// - Converts integer (pass-through), decimal (truncate), string (parse) to integer
-// - Returns error for invalid conversions
+// - Converts integer (pass-through), bigint (range-checked), decimal or float (truncate), string (parse) to integer
+// - Returns error for invalid conversions, including NaN, infinities and out-of-range values
 func ToInteger(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	if len(args) != 1 {
 		return value.NewNoneVal(), arityError("to-integer", 1, len(args))
@@ -545,6 +548,24 @@ func ToInteger(args []core.Value, refValues map[string]core.Value, eval core.Eva
 		}
 		return value.NewIntVal(i), nil
 
+	case value.TypeBigInt:
+		n, _ := value.AsBigIntValue(val)
+		if !n.IsInt64() {
+			return value.NewNoneVal(), verror.NewMathError("to-integer-overflow", [3]string{val.Form(), "", ""})
+		}
+		return value.NewIntVal(n.Int64()), nil
+
+	case value.TypeFloat:
+		f, _ := value.AsFloatValue(val)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return value.NewNoneVal(), verror.NewMathError(verror.ErrIDNonFiniteFloat, [3]string{val.Form(), "integer!", ""})
+		}
+		t := math.Trunc(f)
+		if t < math.MinInt64 || t >= math.MaxInt64 {
+			return value.NewNoneVal(), verror.NewMathError("to-integer-overflow", [3]string{val.Form(), "", ""})
+		}
+		return value.NewIntVal(int64(t)), nil
+
 	case value.TypeString:
 		if str, ok := value.AsStringValue(val); ok {
 			goStr := str.String()
@@ -557,15 +578,15 @@ func ToInteger(args []core.Value, refValues map[string]core.Value, eval core.Eva
 		return value.NewNoneVal(), verror.NewScriptError("to-integer-invalid-string", [3]string{"", "", ""})
 
 	default:
-		return value.NewNoneVal(), typeError("to-integer", "integer, decimal, or string", val)
+		return value.NewNoneVal(), typeError("to-integer", "integer, bigint, decimal, float, or string", val)
 	}
 }
 
 // ToDecimal implements the `to-decimal` native for converting values to decimals.
 //
 // Contract: to-decimal value -> decimal!
-// - Converts integer (exact), decimal (pass-through), string (parse) to decimal
-// - Returns error for invalid conversions
+// - Converts integer and bigint (exact), decimal (pass-through), float (shortest form), string (parse) to decimal
+// - Returns error for invalid conversions, including NaN and infinities
 func ToDecimal(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	if len(args) != 1 {
 		return value.NewNoneVal(), arityError("to-decimal", 1, len(args))
@@ -581,9 +602,15 @@ func ToDecimal(args []core.Value, refValues map[string]core.Value, eval core.Eva
 		}
 		return value.NewNoneVal(), verror.NewScriptError("to-decimal-invalid-integer", [3]string{"", "", ""})
 
+	case value.TypeBigInt:
+		return value.DecimalVal(promoteToDecimal(val, nil, nil), 0), nil
+
 	case value.TypeDecimal:
 		return val, nil
 
+	case value.TypeFloat:
+		return floatToDecimal(val)
+
 	case value.TypeString:
 		if str, ok := value.AsStringValue(val); ok {
 			goStr := str.String()
@@ -602,8 +629,121 @@ func ToDecimal(args []core.Value, refValues map[string]core.Value, eval core.Eva
 		return value.NewNoneVal(), verror.NewScriptError("to-decimal-invalid-string", [3]string{"", "", ""})
 
 	default:
-		return value.NewNoneVal(), typeError("to-decimal", "integer, decimal, or string", val)
+		return value.NewNoneVal(), typeError("to-decimal", "integer, bigint, decimal, float, or string", val)
+	}
+}
+
+// ToFloat implements the `to-float` native for converting values to floats.
+//
+// Contract: to-float value -> float!
+// - Converts integer, bigint and decimal (nearest float), float (pass-through), string (parse) to float
+// - Strings may also be "nan", "inf", "+inf" or "-inf", in any case
+func ToFloat(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("to-float", 1, len(args))
+	}
+
+	val := args[0]
+
+	switch val.GetType() {
+	case value.TypeFloat:
+		return val, nil
+
+	case value.TypeInteger, value.TypeBigInt, value.TypeDecimal:
+		f, ok := promoteToFloat(val)
+		if !ok {
+			return value.NewNoneVal(), typeError("to-float", "integer, bigint, decimal, float, or string", val)
+		}
+		return value.NewFloatVal(f), nil
+
+	case value.TypeString:
+		str, _ := value.AsStringValue(val)
+		goStr := strings.TrimSpace(str.String())
+		numStr := goStr
+		if n := len(numStr); n > 1 && numStr[n-1] == 'f' && numStr[n-2] >= '0' && numStr[n-2] <= '9' {
+			numStr = numStr[:n-1]
+		}
+		f, err := strconv.ParseFloat(numStr, 64)
+		if err != nil {
+			var numErr *strconv.NumError
+			if !errors.As(err, &numErr) || numErr.Err != strconv.ErrRange {
+				return value.NewNoneVal(), verror.NewScriptError("to-float-invalid-string", [3]string{goStr, "", ""})
+			}
+		}
+		return value.NewFloatVal(f), nil
+
+	default:
+		return value.NewNoneVal(), typeError("to-float", "integer, bigint, decimal, float, or string", val)
+	}
+}
+
+// ToBigInt implements the `to-bigint` native for converting values to bigints.
+//
+// Contract: to-bigint value -> bigint!
+// - Converts integer (exact), bigint (pass-through), decimal or float (truncate), string (parse) to bigint
+// - Strings may carry the literal's n suffix; NaN and infinities are errors
+func ToBigInt(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("to-bigint", 1, len(args))
+	}
+
+	val := args[0]
+
+	switch val.GetType() {
+	case value.TypeBigInt:
+		return val, nil
+
+	case value.TypeInteger:
+		n, _ := promoteToBigInt(val)
+		return value.NewBigIntVal(n), nil
+
+	case value.TypeDecimal:
+		dec, _ := value.AsDecimal(val)
+		ctx := decimal.Context128
+		ctx.RoundingMode = decimal.ToZero
+		t := new(decimal.Big).Copy(dec.Magnitude)
+		ctx.RoundToInt(t)
+		return value.NewBigIntVal(t.Int(new(big.Int))), nil
+
+	case value.TypeFloat:
+		f, _ := value.AsFloatValue(val)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return value.NewNoneVal(), verror.NewMathError(verror.ErrIDNonFiniteFloat, [3]string{val.Form(), "bigint!", ""})
+		}
+		n, _ := big.NewFloat(math.Trunc(f)).Int(nil)
+		return value.NewBigIntVal(n), nil
+
+	case value.TypeString:
+		str, _ := value.AsStringValue(val)
+		goStr := strings.TrimSpace(str.String())
+		n, ok := new(big.Int).SetString(strings.TrimSuffix(goStr, "n"), 10)
+		if !ok {
+			return value.NewNoneVal(), verror.NewScriptError("to-bigint-invalid-string", [3]string{goStr, "", ""})
+		}
+		return value.NewBigIntVal(n), nil
+
+	default:
+		return value.NewNoneVal(), typeError("to-bigint", "integer, bigint, decimal, float, or string", val)
+	}
+}
+
+// NanQ implements the `nan?` native. NaN never compares equal to itself, so
+// this is the only reliable test for it.
+func NanQ(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("nan?", 1, len(args))
+	}
+	f, ok := value.AsFloatValue(args[0])
+	return value.NewLogicVal(ok && math.IsNaN(f)), nil
+}
+
+// InfiniteQ implements the `infinite?` native.
+func InfiniteQ(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("infinite?", 1, len(args))
 	}
+	f, ok := value.AsFloatValue(args[0])
+	return value.NewLogicVal(ok && math.IsInf(f, 0)), nil
 }
 
 func ToString(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
@@ -0,0 +1,254 @@
+package native
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// maxDecimalPrecision is the largest precision a decimal context may request;
+// decimal! values are decimal128, which holds 34 significant digits.
+const maxDecimalPrecision = 34
+
+// roundingModes maps the rounding mode words accepted by scripts to the
+// decimal library's modes.
+var roundingModes = map[string]decimal.RoundingMode{
+	"half-even": decimal.ToNearestEven,
+	"half-up":   decimal.ToNearestAway,
+	"half-down": decimal.ToNearestTowardZero,
+	"up":        decimal.AwayFromZero,
+	"down":      decimal.ToZero,
+	"ceiling":   decimal.ToPositiveInf,
+	"floor":     decimal.ToNegativeInf,
+}
+
+// roundingModeList names the accepted rounding modes for error messages.
+const roundingModeList = "half-even half-up half-down up down ceiling floor"
+
+// activeDecimalContext returns the evaluator's decimal context. Natives called
+// without an evaluator (benchmarks, direct calls) get the default context.
+func activeDecimalContext(eval core.Evaluator) core.DecimalContext {
+	if eval == nil {
+		return core.DefaultDecimalContext
+	}
+	return eval.DecimalContext()
+}
+
+// arithmeticContext builds the library context for cfg. The exponent range
+// stays decimal128's; only precision and rounding change.
+func arithmeticContext(cfg core.DecimalContext) decimal.Context {
+	ctx := decimal.Context128
+	ctx.Precision = cfg.Precision
+	ctx.RoundingMode = roundingModes[cfg.Rounding]
+	return ctx
+}
+
+// checkDecimalTraps raises decimal-precision when traps are enabled and the
+// result of operation name had to be rounded to fit the context's precision.
+func checkDecimalTraps(name string, cfg core.DecimalContext, result *decimal.Big) error {
+	if !cfg.Traps || result.Context.Conditions&decimal.Inexact == 0 {
+		return nil
+	}
+	return verror.NewMathError(verror.ErrIDDecimalPrecision, [3]string{name, strconv.Itoa(cfg.Precision), ""})
+}
+
+// roundingModeName validates a rounding mode given as a word or string.
+func roundingModeName(name string, val core.Value) (string, error) {
+	var mode string
+	if word, ok := value.AsWordValue(val); ok {
+		mode = word
+	} else if str, ok := value.AsStringValue(val); ok {
+		mode = str.String()
+	} else {
+		return "", typeError(name, "word!", val)
+	}
+	if _, ok := roundingModes[mode]; !ok {
+		return "", verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"unknown rounding mode '" + mode + "' (expected one of: " + roundingModeList + ")", "", ""},
+		)
+	}
+	return mode, nil
+}
+
+// parseDecimalContextSpec evaluates a spec block such as
+// [precision: 10 rounding: 'half-up traps: true]. Fields that are not given
+// keep their value from base.
+func parseDecimalContextSpec(spec *value.BlockValue, base core.DecimalContext, eval core.Evaluator) (core.DecimalContext, error) {
+	cfg := base
+	elems := spec.Elements
+	locations := spec.Locations()
+	for i := 0; i < len(elems); {
+		if elems[i].GetType() != value.TypeSetWord {
+			return cfg, verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"decimal context spec expects field: value pairs, got " + elems[i].Mold(), "", ""},
+			)
+		}
+		field, _ := value.AsWordValue(elems[i])
+		if i+1 >= len(elems) {
+			return cfg, verror.NewScriptError(
+				verror.ErrIDInvalidSyntax,
+				[3]string{"with-decimal-context", "set-word-without-value", field},
+			)
+		}
+		next, val, err := eval.EvaluateExpression(elems, locations, i+1)
+		if err != nil {
+			return cfg, err
+		}
+		i = next
+
+		switch field {
+		case "precision":
+			p, ok := value.AsIntValue(val)
+			if !ok {
+				return cfg, typeError("with-decimal-context", "integer!", val)
+			}
+			if p < 1 || p > maxDecimalPrecision {
+				return cfg, verror.NewScriptError(
+					verror.ErrIDInvalidOperation,
+					[3]string{"decimal precision must be between 1 and " + strconv.Itoa(maxDecimalPrecision) + ", got " + strconv.FormatInt(p, 10), "", ""},
+				)
+			}
+			cfg.Precision = int(p)
+		case "rounding":
+			mode, err := roundingModeName("with-decimal-context", val)
+			if err != nil {
+				return cfg, err
+			}
+			cfg.Rounding = mode
+		case "traps":
+			traps, ok := value.AsLogicValue(val)
+			if !ok {
+				return cfg, typeError("with-decimal-context", "logic!", val)
+			}
+			cfg.Traps = traps
+		default:
+			return cfg, verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"unknown decimal context field '" + field + "' (expected precision, rounding or traps)", "", ""},
+			)
+		}
+	}
+	return cfg, nil
+}
+
+// WithDecimalContext implements `with-decimal-context`: evaluates body with a
+// decimal context derived from the current one and restores the previous
+// context afterwards, also when body fails or returns early.
+func WithDecimalContext(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("with-decimal-context", 2, len(args))
+	}
+	spec, ok := value.AsBlockValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("with-decimal-context", "block!", args[0])
+	}
+	body, ok := value.AsBlockValue(args[1])
+	if !ok {
+		return value.NewNoneVal(), typeError("with-decimal-context", "block!", args[1])
+	}
+
+	previous := eval.DecimalContext()
+	cfg, err := parseDecimalContextSpec(spec, previous, eval)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	eval.SetDecimalContext(cfg)
+	defer eval.SetDecimalContext(previous)
+	return eval.DoBlock(body.Elements, body.Locations())
+}
+
+// DecimalContextNative implements `decimal-context`: an object describing the
+// active decimal context.
+func DecimalContextNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 0 {
+		return value.NewNoneVal(), arityError("decimal-context", 0, len(args))
+	}
+	cfg := activeDecimalContext(eval)
+
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	objFrame.Bind("precision", value.NewIntVal(int64(cfg.Precision)))
+	objFrame.Bind("rounding", value.NewWordVal(cfg.Rounding))
+	objFrame.Bind("traps", value.NewLogicVal(cfg.Traps))
+	return value.ObjectVal(value.NewObject(objFrame)), nil
+}
+
+// FormatMoneyNative implements `format-money`: a fixed-scale string with
+// grouped thousands, rounded with the active context's rounding mode.
+// --scale sets the digits after the point (default 2) and --separator the
+// grouping string (default ",").
+func FormatMoneyNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("format-money", 1, len(args))
+	}
+	if err := checkFiniteForDecimal(args[0], args[0]); err != nil {
+		return value.NewNoneVal(), err
+	}
+	amount := promoteToDecimal(args[0], nil, nil)
+	if amount == nil {
+		return value.NewNoneVal(), typeError("format-money", "number!", args[0])
+	}
+
+	scale := int64(2)
+	if hasScale, scaleVal := getRefinementValue(refValues, "scale"); hasScale {
+		s, ok := value.AsIntValue(scaleVal)
+		if !ok {
+			return value.NewNoneVal(), typeError("format-money", "integer!", scaleVal)
+		}
+		if s < 0 || s > maxDecimalPrecision {
+			return value.NewNoneVal(), verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"format-money --scale must be between 0 and " + strconv.Itoa(maxDecimalPrecision), "", ""},
+			)
+		}
+		scale = s
+	}
+	separator := ","
+	if hasSep, sepVal := getRefinementValue(refValues, "separator"); hasSep {
+		str, ok := value.AsStringValue(sepVal)
+		if !ok {
+			return value.NewNoneVal(), typeError("format-money", "string!", sepVal)
+		}
+		separator = str.String()
+	}
+
+	cfg := activeDecimalContext(eval)
+	ctx := arithmeticContext(cfg)
+	ctx.Precision = maxDecimalPrecision
+	result := new(decimal.Big).Copy(amount)
+	ctx.Quantize(result, int(scale))
+	if result.IsNaN(0) {
+		return value.NewNoneVal(), verror.NewMathError(verror.ErrIDDecimalPrecision, [3]string{"format-money", strconv.Itoa(maxDecimalPrecision), ""})
+	}
+
+	text := strings.TrimPrefix(result.String(), "-")
+	if result.Sign() < 0 {
+		return value.NewStrVal("-" + groupThousands(text, separator)), nil
+	}
+	return value.NewStrVal(groupThousands(text, separator)), nil
+}
+
+// groupThousands inserts separator between groups of three digits in the
+// integer part of an unsigned plain-notation number.
+func groupThousands(text, separator string) string {
+	intPart, fracPart, hasFrac := strings.Cut(text, ".")
+	var b strings.Builder
+	for i, ch := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(separator)
+		}
+		b.WriteRune(ch)
+	}
+	if hasFrac {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
@@ -0,0 +1,446 @@
+package native
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Filesystem natives. Every user path goes through eval.ResolveSandboxPath,
+// so these natives can never observe or modify anything outside the sandbox.
+
+// resolveFilePath validates a path argument and resolves it inside the sandbox.
+// The sandbox defaults to the working directory when it was not configured,
+// mirroring the behaviour of the port natives.
+func resolveFilePath(name string, arg core.Value) (string, string, error) {
+	return resolveWith(name, arg, eval.ResolveSandboxPath)
+}
+
+// resolveEntryPath is like resolveFilePath but does not follow a symlink in
+// the final component, so delete, rename and move act on the link itself.
+func resolveEntryPath(name string, arg core.Value) (string, string, error) {
+	return resolveWith(name, arg, eval.ResolveSandboxEntry)
+}
+
+func resolveWith(name string, arg core.Value, resolve func(string) (string, error)) (string, string, error) {
+	str, ok := value.AsStringValue(arg)
+	if !ok {
+		return "", "", typeError(name, "string!", arg)
+	}
+	userPath := str.String()
+
+	if eval.SandboxRoot == "" {
+		if err := eval.InitSandbox(""); err != nil {
+			return "", userPath, fileError(name, userPath, err)
+		}
+	}
+
+	resolved, err := resolve(userPath)
+	if err != nil {
+		return "", userPath, verror.NewAccessError(
+			verror.ErrIDSandboxViolation,
+			[3]string{userPath, "", ""},
+		)
+	}
+	return resolved, userPath, nil
+}
+
+// fileError wraps a Go filesystem error as an access error.
+func fileError(name, userPath string, err error) error {
+	return verror.NewAccessError(
+		verror.ErrIDInvalidOperation,
+		[3]string{fmt.Sprintf("%s failed: %v", name, err), userPath, ""},
+	)
+}
+
+// statOrNone stats a resolved path, returning nil info when it does not exist.
+func statOrNone(name, userPath, resolved string) (fs.FileInfo, error) {
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fileError(name, userPath, err)
+	}
+	return info, nil
+}
+
+// ExistsNative implements `exists?`: true when the path names a file or directory.
+func ExistsNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("exists?", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("exists?", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	info, err := statOrNone("exists?", userPath, resolved)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return value.NewLogicVal(info != nil), nil
+}
+
+// DirNative implements `dir?`: true when the path names an existing directory.
+func DirNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("dir?", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("dir?", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	info, err := statOrNone("dir?", userPath, resolved)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return value.NewLogicVal(info != nil && info.IsDir()), nil
+}
+
+// SizeNative implements `size?`: the byte size of a file, none for directories and missing paths.
+func SizeNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("size?", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("size?", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	info, err := statOrNone("size?", userPath, resolved)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if info == nil || info.IsDir() {
+		return value.NewNoneVal(), nil
+	}
+	return value.NewIntVal(info.Size()), nil
+}
+
+// ModifiedNative implements `modified?`: the modification time in Unix seconds, none if missing.
+func ModifiedNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("modified?", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("modified?", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	info, err := statOrNone("modified?", userPath, resolved)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if info == nil {
+		return value.NewNoneVal(), nil
+	}
+	return value.NewIntVal(info.ModTime().Unix()), nil
+}
+
+// InfoNative implements `info?`: an object describing the path, none if missing.
+func InfoNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("info?", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("info?", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	info, err := statOrNone("info?", userPath, resolved)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if info == nil {
+		return value.NewNoneVal(), nil
+	}
+	return createFileInfoObject(userPath, info), nil
+}
+
+func createFileInfoObject(userPath string, info fs.FileInfo) core.Value {
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+
+	kind := "file"
+	if info.IsDir() {
+		kind = "dir"
+	}
+	objFrame.Bind("name", value.NewStrVal(info.Name()))
+	objFrame.Bind("path", value.NewStrVal(userPath))
+	objFrame.Bind("type", value.NewWordVal(kind))
+	objFrame.Bind("size", value.NewIntVal(info.Size()))
+	objFrame.Bind("modified", value.NewIntVal(info.ModTime().Unix()))
+	objFrame.Bind("mode", value.NewStrVal(info.Mode().String()))
+
+	obj := value.NewObject(objFrame)
+	return value.ObjectVal(obj)
+}
+
+// DeleteNative implements `delete`. Non-empty directories require --recursive,
+// and the sandbox root itself can never be deleted.
+func DeleteNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("delete", 1, len(args))
+	}
+	resolved, userPath, err := resolveEntryPath("delete", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if isSandboxRoot(resolved) {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"delete failed: cannot delete sandbox root", userPath, ""},
+		)
+	}
+	if _, err := os.Lstat(resolved); err != nil {
+		return value.NewNoneVal(), fileError("delete", userPath, err)
+	}
+
+	if hasRefinement(refValues, "recursive") {
+		err = os.RemoveAll(resolved)
+	} else {
+		err = os.Remove(resolved)
+	}
+	if err != nil {
+		return value.NewNoneVal(), fileError("delete", userPath, err)
+	}
+	return value.NewNoneVal(), nil
+}
+
+// RenameNative implements `rename`: renames source to exactly the target path.
+func RenameNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("rename", 2, len(args))
+	}
+	return renamePath("rename", args[0], args[1], false)
+}
+
+// MoveNative implements `move`: like rename, but moves into the target when it is a directory.
+func MoveNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("move", 2, len(args))
+	}
+	return renamePath("move", args[0], args[1], true)
+}
+
+func renamePath(name string, source, target core.Value, intoDir bool) (core.Value, error) {
+	src, srcPath, err := resolveEntryPath(name, source)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	dst, dstPath, err := resolveEntryPath(name, target)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if isSandboxRoot(src) {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{name + " failed: cannot move sandbox root", srcPath, ""},
+		)
+	}
+
+	if intoDir {
+		if info, err := os.Stat(dst); err == nil && info.IsDir() {
+			// Moving into a directory follows a symlink to it, so the
+			// directory itself must be inside the sandbox.
+			dir, _, err := resolveFilePath(name, target)
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			dst = filepath.Join(dir, filepath.Base(src))
+		}
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		return value.NewNoneVal(), fileError(name, dstPath, err)
+	}
+	return value.NewNoneVal(), nil
+}
+
+// CopyFileNative implements `copy-file`: copies file contents and permissions,
+// creating missing parent directories of the target like `write` does.
+func CopyFileNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("copy-file", 2, len(args))
+	}
+	src, srcPath, err := resolveFilePath("copy-file", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	dst, dstPath, err := resolveFilePath("copy-file", args[1])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return value.NewNoneVal(), fileError("copy-file", srcPath, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return value.NewNoneVal(), fileError("copy-file", srcPath, err)
+	}
+	if info.IsDir() {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"copy-file failed: source is a directory", srcPath, ""},
+		)
+	}
+	// Opening the destination truncates it, which would wipe the source
+	// when both name the same file.
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(info, dstInfo) {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"copy-file failed: source and destination are the same file", srcPath, dstPath},
+		)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return value.NewNoneVal(), fileError("copy-file", dstPath, err)
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return value.NewNoneVal(), fileError("copy-file", dstPath, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return value.NewNoneVal(), fileError("copy-file", dstPath, err)
+	}
+	if err := out.Close(); err != nil {
+		return value.NewNoneVal(), fileError("copy-file", dstPath, err)
+	}
+	return value.NewNoneVal(), nil
+}
+
+// MakeDirNative implements `make-dir`. Without --deep the parent must exist.
+// An already existing directory is not an error.
+func MakeDirNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("make-dir", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("make-dir", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	if info, err := os.Stat(resolved); err == nil {
+		if info.IsDir() {
+			return value.NewNoneVal(), nil
+		}
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"make-dir failed: file exists", userPath, ""},
+		)
+	}
+
+	if hasRefinement(refValues, "deep") {
+		err = os.MkdirAll(resolved, 0755)
+	} else {
+		err = os.Mkdir(resolved, 0755)
+	}
+	if err != nil {
+		return value.NewNoneVal(), fileError("make-dir", userPath, err)
+	}
+	return value.NewNoneVal(), nil
+}
+
+// ListDirNative implements `list-dir`. Entries are relative to the listed
+// directory, use forward slashes and carry a trailing slash for directories.
+// --filter matches a glob pattern against entry names; with --recursive the
+// filter selects entries but every subdirectory is still descended into.
+func ListDirNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("list-dir", 1, len(args))
+	}
+	resolved, userPath, err := resolveFilePath("list-dir", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	pattern := ""
+	if hasFilter, filterVal := getRefinementValue(refValues, "filter"); hasFilter {
+		filterStr, ok := value.AsStringValue(filterVal)
+		if !ok {
+			return value.NewNoneVal(), typeError("list-dir --filter", "string!", filterVal)
+		}
+		pattern = filterStr.String()
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return value.NewNoneVal(), verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{fmt.Sprintf("invalid glob pattern: %s", pattern), "", ""},
+			)
+		}
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return value.NewNoneVal(), fileError("list-dir", userPath, err)
+	}
+	if !info.IsDir() {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"list-dir failed: not a directory", userPath, ""},
+		)
+	}
+
+	matches := func(name string) bool {
+		if pattern == "" {
+			return true
+		}
+		ok, _ := filepath.Match(pattern, name)
+		return ok
+	}
+	entryName := func(rel string, isDir bool) core.Value {
+		rel = filepath.ToSlash(rel)
+		if isDir {
+			rel += "/"
+		}
+		return value.NewStrVal(rel)
+	}
+
+	elements := []core.Value{}
+	if !hasRefinement(refValues, "recursive") {
+		entries, err := os.ReadDir(resolved)
+		if err != nil {
+			return value.NewNoneVal(), fileError("list-dir", userPath, err)
+		}
+		for _, entry := range entries {
+			if matches(entry.Name()) {
+				elements = append(elements, entryName(entry.Name(), entry.IsDir()))
+			}
+		}
+		return value.NewBlockVal(elements), nil
+	}
+
+	err = filepath.WalkDir(resolved, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path == resolved {
+			return nil
+		}
+		if matches(d.Name()) {
+			rel, err := filepath.Rel(resolved, path)
+			if err != nil {
+				return err
+			}
+			elements = append(elements, entryName(rel, d.IsDir()))
+		}
+		return nil
+	})
+	if err != nil {
+		return value.NewNoneVal(), fileError("list-dir", userPath, err)
+	}
+	return value.NewBlockVal(elements), nil
+}
+
+func isSandboxRoot(resolved string) bool {
+	return filepath.Clean(resolved) == filepath.Clean(eval.SandboxRoot)
+}
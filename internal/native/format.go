@@ -17,9 +17,13 @@ func FormatHelp(funcName string, doc *NativeDoc) string {
 
 	var b strings.Builder
 
-	// Header
-	b.WriteString(fmt.Sprintf("\n%s - %s\n", strings.ToUpper(funcName), doc.Category))
-	b.WriteString(strings.Repeat("=", len(funcName)+len(doc.Category)+3))
+	// Header; user functions documented by a docstring have no category.
+	header := strings.ToUpper(funcName)
+	if doc.Category != "" {
+		header += " - " + doc.Category
+	}
+	b.WriteString("\n" + header + "\n")
+	b.WriteString(strings.Repeat("=", len(header)))
 	b.WriteString("\n\n")
 
 	// Summary
@@ -45,23 +49,29 @@ func FormatHelp(funcName string, doc *NativeDoc) string {
 				optMarker = " (optional)"
 			}
 			b.WriteString(fmt.Sprintf("    %-12s [%s]%s\n", param.Name, param.Type, optMarker))
-			b.WriteString(fmt.Sprintf("        %s\n", param.Description))
+			if param.Description != "" {
+				b.WriteString(fmt.Sprintf("        %s\n", param.Description))
+			}
 		}
 		b.WriteString("\n")
 	}
 
 	// Returns section
-	b.WriteString("RETURNS:\n")
-	b.WriteString(fmt.Sprintf("    %s\n\n", doc.Returns))
+	if doc.Returns != "" {
+		b.WriteString("RETURNS:\n")
+		b.WriteString(fmt.Sprintf("    %s\n\n", doc.Returns))
+	}
 
 	// Description section
-	b.WriteString("DESCRIPTION:\n")
-	for _, line := range strings.Split(strings.TrimSpace(doc.Description), "\n") {
-		b.WriteString("    ")
-		b.WriteString(line)
+	if doc.Description != "" {
+		b.WriteString("DESCRIPTION:\n")
+		for _, line := range strings.Split(strings.TrimSpace(doc.Description), "\n") {
+			b.WriteString("    ")
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
 		b.WriteString("\n")
 	}
-	b.WriteString("\n")
 
 	// Examples section
 	if len(doc.Examples) > 0 {
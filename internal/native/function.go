@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/docmodel"
 	"github.com/marcin-radoszewski/viro/internal/value"
 	"github.com/marcin-radoszewski/viro/internal/verror"
 )
@@ -11,6 +12,7 @@ import (
 type frameProvider interface {
 	CurrentFrameIndex() int
 	MarkFrameCaptured(index int)
+	GetFrameByIndex(index int) core.Frame
 }
 
 // Fn implements the function definition native.
@@ -41,6 +43,7 @@ func Fn(args []core.Value, refValues map[string]core.Value, eval core.Evaluator)
 	if err != nil {
 		return value.NewNoneVal(), err
 	}
+	doc := ParseFuncDoc(paramsBlock)
 
 	bodyVal := args[1]
 	if bodyVal.GetType() != value.TypeBlock {
@@ -56,14 +59,17 @@ func Fn(args []core.Value, refValues map[string]core.Value, eval core.Evaluator)
 	bodyClone.SetIndex(0)
 
 	parentIndex := -1
+	var parentFrame core.Frame
 	if provider, ok := eval.(frameProvider); ok {
 		parentIndex = provider.CurrentFrameIndex()
 		if parentIndex >= 0 {
 			provider.MarkFrameCaptured(parentIndex)
+			parentFrame = provider.GetFrameByIndex(parentIndex)
 		}
 	}
 
-	fnValue := value.NewUserFunction("", specs, bodyClone.(*value.BlockValue), parentIndex, nil)
+	fnValue := value.NewUserFunction("", specs, bodyClone.(*value.BlockValue), parentIndex, doc)
+	fnValue.ParentFrame = parentFrame
 	return value.NewFuncVal(fnValue), nil
 }
 
@@ -76,6 +82,11 @@ func ParseParamSpecs(block *value.BlockValue) ([]value.ParamSpec, error) {
 		eval := true
 		paramName := ""
 
+		// Docstrings are read by ParseFuncDoc.
+		if elem.GetType() == value.TypeString {
+			continue
+		}
+
 		// Obsługa lit-wordów
 		if elem.GetType() == value.TypeLitWord {
 			wordStr, ok := value.AsWordValue(elem)
@@ -149,3 +160,69 @@ func ParseParamSpecs(block *value.BlockValue) ([]value.ParamSpec, error) {
 
 	return specs, nil
 }
+
+// ParseFuncDoc builds a function's documentation from the strings in its
+// spec block, or returns nil when there are none:
+//
+//	fn [
+//	    "Returns n squared.
+//	    square 4  ; => 16"
+//	    n "The number to square"
+//	] [n * n]
+//
+// In the string before the parameters, the first line is the summary, lines
+// with a `; =>` annotation are examples and the other lines the description.
+// A string after a parameter or refinement describes it.
+func ParseFuncDoc(block *value.BlockValue) *docmodel.FuncDoc {
+	var doc *docmodel.FuncDoc
+	var params []docmodel.ParamDoc
+	for i, elem := range block.Elements {
+		text, ok := value.AsStringValue(elem)
+		if ok && elem.GetType() == value.TypeString {
+			if doc == nil {
+				doc = &docmodel.FuncDoc{}
+			}
+			if len(params) == 0 {
+				describeFunc(doc, text.String())
+			} else {
+				params[len(params)-1].Description = strings.TrimSpace(text.String())
+			}
+			continue
+		}
+		name, ok := value.AsWordValue(elem)
+		if !ok || (elem.GetType() != value.TypeWord && elem.GetType() != value.TypeLitWord) {
+			continue
+		}
+		param := docmodel.ParamDoc{Name: name, Type: "any-type!"}
+		if strings.HasPrefix(name, "--") {
+			param.Optional = true
+			if i+1 < len(block.Elements) && block.Elements[i+1].GetType() == value.TypeBlock {
+				param.Type = block.Elements[i+1].Form()
+			} else {
+				param.Type = "logic!"
+			}
+		}
+		params = append(params, param)
+	}
+	if doc != nil {
+		doc.Parameters = params
+	}
+	return doc
+}
+
+// describeFunc fills doc from a function's docstring.
+func describeFunc(doc *docmodel.FuncDoc, text string) {
+	var description []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case doc.Summary == "":
+			doc.Summary = line
+		case strings.Contains(line, "; =>"):
+			doc.Examples = append(doc.Examples, line)
+		default:
+			description = append(description, line)
+		}
+	}
+	doc.Description = strings.TrimSpace(strings.Join(description, "\n"))
+}
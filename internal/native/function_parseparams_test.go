@@ -39,3 +39,43 @@ func TestParseParamSpecs_LitWordRefinementError(t *testing.T) {
 		t.Error("expected error for lit-word refinement, got nil")
 	}
 }
+
+func TestParseFuncDoc(t *testing.T) {
+	block := &value.BlockValue{
+		Elements: []core.Value{
+			value.NewStrVal("Scales a number\nMultiplies n by the factor.\nscale 3  ; => 6"),
+			value.NewWordVal("n"),
+			value.NewStrVal("The number to scale"),
+			value.NewWordVal("--by"),
+			value.NewBlockVal([]core.Value{value.NewWordVal("integer!")}),
+		},
+	}
+	params, err := native.ParseParamSpecs(block)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params) != 2 {
+		t.Fatalf("expected docstrings to be skipped, got %d params", len(params))
+	}
+
+	doc := native.ParseFuncDoc(block)
+	if doc == nil {
+		t.Fatal("expected documentation")
+	}
+	if doc.Summary != "Scales a number" || doc.Description != "Multiplies n by the factor." {
+		t.Errorf("Summary = %q, Description = %q", doc.Summary, doc.Description)
+	}
+	if len(doc.Examples) != 1 || doc.Examples[0] != "scale 3  ; => 6" {
+		t.Errorf("Examples = %q", doc.Examples)
+	}
+	if len(doc.Parameters) != 2 || doc.Parameters[0].Description != "The number to scale" {
+		t.Fatalf("Parameters = %+v", doc.Parameters)
+	}
+	if by := doc.Parameters[1]; !by.Optional || by.Type != "integer!" {
+		t.Errorf("refinement doc = %+v, want optional integer!", by)
+	}
+
+	if native.ParseFuncDoc(&value.BlockValue{Elements: []core.Value{value.NewWordVal("n")}}) != nil {
+		t.Error("expected no documentation without docstrings")
+	}
+}
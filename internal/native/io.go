@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
 	"github.com/marcin-radoszewski/viro/internal/frame"
 	"github.com/marcin-radoszewski/viro/internal/trace"
 	"github.com/marcin-radoszewski/viro/internal/value"
@@ -55,20 +56,11 @@ func resolveSandboxPath(userPath string) (string, error) {
 		candidate = filepath.Join(SandboxRoot, cleaned)
 	}
 
-	// Evaluate symlinks to detect escape attempts
-	resolved, err := filepath.EvalSymlinks(candidate)
+	// Evaluate symlinks to detect escape attempts, including through
+	// dangling links and directories that don't exist yet
+	resolved, err := eval.EvalSymlinksPartial(candidate)
 	if err != nil {
-		// Path doesn't exist yet - check if it's within sandbox
-		dir := filepath.Dir(candidate)
-		resolvedDir, err := filepath.EvalSymlinks(dir)
-		if err != nil {
-			// Parent doesn't exist either - verify candidate is within sandbox
-			if !strings.HasPrefix(filepath.Clean(candidate)+string(filepath.Separator), filepath.Clean(SandboxRoot)+string(filepath.Separator)) {
-				return "", fmt.Errorf("path escapes sandbox: %s", userPath)
-			}
-			return candidate, nil
-		}
-		resolved = filepath.Join(resolvedDir, filepath.Base(candidate))
+		return "", fmt.Errorf("failed to resolve %s: %w", userPath, err)
 	}
 
 	// Verify resolved path is within sandbox using resolved sandbox root
@@ -496,11 +488,74 @@ func ClosePort(portVal core.Value) error {
 	return nil
 }
 
+// readOptions are the refinements of read.
+type readOptions struct {
+	isBinary  bool
+	isLines   bool
+	isGzip    bool
+	partCount int
+	seekPos   int64
+}
+
 // ReadPort implements the `read` native (T067)
 func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
-	// Parse refinements
+	ro, err := parseReadOptions(opts)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	// Check if the spec is a directory (for file:// scheme only)
+	if !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://") && !strings.HasPrefix(spec, "tcp://") {
+		resolved, err := resolveSandboxPath(spec)
+		if err != nil {
+			return value.NewNoneVal(), fmt.Errorf("sandbox violation: %w", err)
+		}
+
+		info, err := os.Stat(resolved)
+		if err == nil && info.IsDir() {
+			entries, err := os.ReadDir(resolved)
+			if err != nil {
+				return value.NewNoneVal(), fmt.Errorf("failed to read directory: %w", err)
+			}
+
+			elements := make([]core.Value, len(entries))
+			for i, entry := range entries {
+				elements[i] = value.NewStrVal(entry.Name())
+			}
+
+			return value.NewBlockVal(elements), nil
+		}
+	}
+
+	// Open temporary port
+	portVal, err := OpenPort(spec, opts)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	port, _ := value.AsPort(portVal)
+	result, err := readFromPort(port, ro)
+	ClosePort(portVal)
+	return result, err
+}
+
+// readOpenPort reads from a port opened with open, leaving it open.
+func readOpenPort(port *value.Port, opts map[string]core.Value) (core.Value, error) {
+	if port.State != value.PortOpen {
+		return value.NewNoneVal(), fmt.Errorf("port is closed")
+	}
+	ro, err := parseReadOptions(opts)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return readFromPort(port, ro)
+}
+
+// parseReadOptions reads the refinements of read from opts.
+func parseReadOptions(opts map[string]core.Value) (readOptions, error) {
 	isBinary := false
 	isLines := false
+	isGzip := false
 	partCount := -1
 	seekPos := int64(0)
 	encoding := "utf-8"
@@ -516,6 +571,11 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 				isLines, _ = value.AsLogicValue(linesVal)
 			}
 		}
+		if gzipVal, ok := opts["gzip"]; ok {
+			if gzipVal.GetType() == value.TypeLogic {
+				isGzip, _ = value.AsLogicValue(gzipVal)
+			}
+		}
 		if partVal, ok := opts["part"]; ok {
 			if partVal.GetType() == value.TypeInteger {
 				pc, _ := value.AsIntValue(partVal)
@@ -526,7 +586,7 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 			if seekVal.GetType() == value.TypeInteger {
 				sp, _ := value.AsIntValue(seekVal)
 				if sp < 0 {
-					return value.NewNoneVal(), fmt.Errorf("--seek position must be non-negative, got %d", sp)
+					return readOptions{}, fmt.Errorf("--seek position must be non-negative, got %d", sp)
 				}
 				seekPos = sp
 			}
@@ -544,51 +604,31 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 
 	// Validate conflicting options
 	if isBinary && isLines {
-		return value.NewNoneVal(), fmt.Errorf("--binary and --lines cannot be used together")
+		return readOptions{}, fmt.Errorf("--binary and --lines cannot be used together")
 	}
 	if isBinary && encoding != "utf-8" {
-		return value.NewNoneVal(), fmt.Errorf("--binary and --as cannot be used together")
+		return readOptions{}, fmt.Errorf("--binary and --as cannot be used together")
 	}
 	if encoding != "utf-8" {
-		return value.NewNoneVal(), fmt.Errorf("encoding support not yet implemented (only utf-8 supported)")
+		return readOptions{}, fmt.Errorf("encoding support not yet implemented (only utf-8 supported)")
 	}
-
-	// Check if the spec is a directory (for file:// scheme only)
-	if !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://") && !strings.HasPrefix(spec, "tcp://") {
-		resolved, err := resolveSandboxPath(spec)
-		if err != nil {
-			return value.NewNoneVal(), fmt.Errorf("sandbox violation: %w", err)
-		}
-
-		info, err := os.Stat(resolved)
-		if err == nil && info.IsDir() {
-			entries, err := os.ReadDir(resolved)
-			if err != nil {
-				return value.NewNoneVal(), fmt.Errorf("failed to read directory: %w", err)
-			}
-
-			elements := make([]core.Value, len(entries))
-			for i, entry := range entries {
-				elements[i] = value.NewStrVal(entry.Name())
-			}
-
-			return value.NewBlockVal(elements), nil
-		}
+	if isGzip && seekPos > 0 {
+		return readOptions{}, fmt.Errorf("--gzip and --seek cannot be used together")
 	}
 
-	// Open temporary port
-	portVal, err := OpenPort(spec, opts)
-	if err != nil {
-		return value.NewNoneVal(), err
-	}
+	return readOptions{isBinary: isBinary, isLines: isLines, isGzip: isGzip, partCount: partCount, seekPos: seekPos}, nil
+}
 
-	port, _ := value.AsPort(portVal)
+// readFromPort reads an open port to its end, or to --part, and returns the
+// data in the form the options ask for. The port stays open.
+func readFromPort(port *value.Port, ro readOptions) (core.Value, error) {
+	isBinary, isLines, isGzip, partCount := ro.isBinary, ro.isLines, ro.isGzip, ro.partCount
+	spec := port.Spec
 
 	// Handle --seek for file ports
-	if seekPos >= 0 && port.Scheme == "file" {
+	if ro.seekPos >= 0 && port.Scheme == "file" {
 		if fileDriver, ok := port.Driver.(*fileDriver); ok {
-			if _, err := fileDriver.file.Seek(seekPos, 0); err != nil {
-				ClosePort(portVal)
+			if _, err := fileDriver.file.Seek(ro.seekPos, 0); err != nil {
 				return value.NewNoneVal(), fmt.Errorf("seek failed: %w", err)
 			}
 		}
@@ -599,9 +639,11 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 	var data []byte
 	totalBytes := 0
 
-	// Determine read limit (bytes for binary/string mode, no limit for lines mode)
+	// Determine read limit (bytes for binary/string mode, no limit for lines mode).
+	// Compressed input is always read whole; --part then applies to the
+	// decompressed bytes.
 	readLimit := -1
-	if partCount > 0 && !isLines {
+	if partCount > 0 && !isLines && !isGzip {
 		readLimit = partCount
 	}
 
@@ -624,7 +666,6 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 		}
 		if err != nil {
 			trace.TracePortError(port.Scheme, spec, err)
-			ClosePort(portVal)
 			return value.NewNoneVal(), err
 		}
 		if readLimit > 0 && totalBytes >= readLimit {
@@ -633,7 +674,17 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 	}
 
 	trace.TracePortRead(port.Scheme, spec, totalBytes)
-	ClosePort(portVal)
+
+	if isGzip {
+		var err error
+		data, err = decompressBytes("gzip", data)
+		if err != nil {
+			return value.NewNoneVal(), fmt.Errorf("gzip: %w", err)
+		}
+		if partCount > 0 && !isLines && len(data) > partCount {
+			data = data[:partCount]
+		}
+	}
 
 	// Return based on mode
 	if isBinary {
@@ -667,26 +718,27 @@ func ReadPort(spec string, opts map[string]core.Value) (core.Value, error) {
 
 // WritePort implements the `write` native (T068)
 func WritePort(spec string, data core.Value, opts map[string]core.Value) error {
-	// Check for append mode
+	// Check for append and gzip modes
 	append := false
+	isGzip := false
 	if opts != nil {
 		if appendVal, ok := opts["append"]; ok {
 			if appendVal.GetType() == value.TypeLogic {
 				append, _ = value.AsLogicValue(appendVal)
 			}
 		}
+		if gzipVal, ok := opts["gzip"]; ok {
+			if gzipVal.GetType() == value.TypeLogic {
+				isGzip, _ = value.AsLogicValue(gzipVal)
+			}
+		}
 	}
 
-	// Get content as bytes (handle both string and binary)
-	var contentBytes []byte
-	if data.GetType() == value.TypeBinary {
-		bin, _ := value.AsBinaryValue(data)
-		contentBytes = bin.Bytes()
-	} else if data.GetType() == value.TypeString {
-		str, _ := value.AsStringValue(data)
-		contentBytes = []byte(str.String())
-	} else {
-		contentBytes = []byte(data.Mold())
+	// Appending a gzip member to an existing .gz file yields a valid
+	// multi-member stream, so --gzip combines with --append.
+	contentBytes, err := writeData(data, isGzip)
+	if err != nil {
+		return err
 	}
 
 	// For file operations with append mode
@@ -748,6 +800,46 @@ func WritePort(spec string, data core.Value, opts map[string]core.Value) error {
 	return err
 }
 
+// writeData returns the bytes write sends for data: a binary as is, a string
+// as UTF-8 and any other value molded, gzip-compressed with --gzip.
+func writeData(data core.Value, isGzip bool) ([]byte, error) {
+	var contentBytes []byte
+	if data.GetType() == value.TypeBinary {
+		bin, _ := value.AsBinaryValue(data)
+		contentBytes = bin.Bytes()
+	} else if data.GetType() == value.TypeString {
+		str, _ := value.AsStringValue(data)
+		contentBytes = []byte(str.String())
+	} else {
+		contentBytes = []byte(data.Mold())
+	}
+	if isGzip {
+		return compressBytes("gzip", contentBytes)
+	}
+	return contentBytes, nil
+}
+
+// writeOpenPort writes to a port opened with open, leaving it open.
+func writeOpenPort(port *value.Port, data core.Value, opts map[string]core.Value) error {
+	if port.State != value.PortOpen {
+		return fmt.Errorf("port is closed")
+	}
+	isGzip := false
+	if gzipVal, ok := opts["gzip"]; ok {
+		isGzip, _ = value.AsLogicValue(gzipVal)
+	}
+	contentBytes, err := writeData(data, isGzip)
+	if err != nil {
+		return err
+	}
+	if _, err := port.Driver.Write(contentBytes); err != nil {
+		trace.TracePortError(port.Scheme, port.Spec, err)
+		return err
+	}
+	trace.TracePortWrite(port.Scheme, port.Spec, len(contentBytes))
+	return nil
+}
+
 // QueryPort implements the `query` native (T071)
 func QueryPort(portVal core.Value) (core.Value, error) {
 	port, ok := value.AsPort(portVal)
@@ -857,7 +949,7 @@ func serializeValue(val core.Value) string {
 	case value.TypeInteger:
 		intVal, _ := value.AsIntValue(val)
 		return formatInt(intVal)
-	case value.TypeDecimal:
+	case value.TypeDecimal, value.TypeFloat, value.TypeBigInt:
 		return val.Mold()
 	case value.TypeString:
 		str, _ := value.AsStringValue(val)
@@ -1015,22 +1107,27 @@ func ReadNative(args []core.Value, refValues map[string]core.Value, eval core.Ev
 		return value.NewNoneVal(), arityError("read", 1, len(args))
 	}
 
-	// Get spec string
-	var spec string
-	if args[0].GetType() == value.TypeString {
-		str, _ := value.AsStringValue(args[0])
-		spec = str.String()
-	} else {
-		spec = args[0].Mold()
-	}
-
 	// Build options map from refinements
 	opts := make(map[string]core.Value)
 	if refValues != nil {
 		maps.Copy(opts, refValues)
 	}
 
-	result, err := ReadPort(spec, opts)
+	var spec string
+	var result core.Value
+	var err error
+	if port, ok := value.AsPort(args[0]); ok {
+		spec = port.Spec
+		result, err = readOpenPort(port, opts)
+	} else {
+		if args[0].GetType() == value.TypeString {
+			str, _ := value.AsStringValue(args[0])
+			spec = str.String()
+		} else {
+			spec = args[0].Mold()
+		}
+		result, err = ReadPort(spec, opts)
+	}
 	if err != nil {
 		return value.NewNoneVal(), verror.NewAccessError(
 			verror.ErrIDInvalidOperation,
@@ -1047,16 +1144,20 @@ func WriteNative(args []core.Value, refValues map[string]core.Value, eval core.E
 		return value.NewNoneVal(), arityError("write", 2, len(args))
 	}
 
-	// Get spec string
 	var spec string
-	if args[0].GetType() == value.TypeString {
-		str, _ := value.AsStringValue(args[0])
-		spec = str.String()
+	var err error
+	if port, ok := value.AsPort(args[0]); ok {
+		spec = port.Spec
+		err = writeOpenPort(port, args[1], refValues)
 	} else {
-		spec = args[0].Mold()
+		if args[0].GetType() == value.TypeString {
+			str, _ := value.AsStringValue(args[0])
+			spec = str.String()
+		} else {
+			spec = args[0].Mold()
+		}
+		err = WritePort(spec, args[1], refValues)
 	}
-
-	err := WritePort(spec, args[1], nil)
 	if err != nil {
 		return value.NewNoneVal(), verror.NewAccessError(
 			verror.ErrIDInvalidOperation,
@@ -6,6 +6,7 @@ package native
 
 import (
 	"math"
+	"math/big"
 
 	"github.com/ericlagergren/decimal"
 	"github.com/marcin-radoszewski/viro/internal/core"
@@ -17,9 +18,17 @@ import (
 // Returns the result and a boolean indicating if overflow occurred.
 type intOp func(a, b int64) (result int64, overflow bool)
 
+// bigIntOp represents an arbitrary-precision integer operation, in the form
+// of big.Int's methods: it sets z to the result and returns z.
+type bigIntOp func(z, a, b *big.Int) *big.Int
+
 // decimalOp represents a decimal arithmetic operation.
 type decimalOp func(ctx decimal.Context, result, a, b *decimal.Big) *decimal.Big
 
+// floatOp represents a binary64 arithmetic operation. IEEE-754 rules apply, so
+// it never fails: overflow gives an infinity and invalid operations give NaN.
+type floatOp func(a, b float64) float64
+
 // intCompareFn represents an integer comparison operation.
 // Returns true if the comparison holds.
 type intCompareFn func(a, b int64) bool
@@ -28,16 +37,43 @@ type intCompareFn func(a, b int64) bool
 // Returns true if the comparison holds.
 type decimalCompareFn func(a, b *decimal.Big) bool
 
+// floatCompareFn represents a binary64 comparison operation. Every ordered
+// comparison involving NaN is false.
+type floatCompareFn func(a, b float64) bool
+
+// numericKind picks the arithmetic used for two operands. Promotion goes
+// integer → bigint → float → decimal: a float mixed with an integer stays a
+// float, and any decimal operand makes the operation decimal so exact values
+// are never silently turned into binary floating point.
+func numericKind(a, b core.Value) core.ValueType {
+	ta, tb := a.GetType(), b.GetType()
+	switch {
+	case ta == value.TypeDecimal || tb == value.TypeDecimal:
+		return value.TypeDecimal
+	case ta == value.TypeFloat || tb == value.TypeFloat:
+		return value.TypeFloat
+	case ta == value.TypeBigInt || tb == value.TypeBigInt:
+		return value.TypeBigInt
+	default:
+		return value.TypeInteger
+	}
+}
+
 // mathOp provides a generic template for binary arithmetic operations.
-// It handles type checking, decimal promotion, and overflow detection.
-func mathOp(name string, args []core.Value, intFn intOp, decFn decimalOp) (core.Value, error) {
+// It handles type checking, float and decimal promotion, and overflow detection.
+// Decimal arithmetic runs in the evaluator's active decimal context.
+func mathOp(name string, args []core.Value, eval core.Evaluator, intFn intOp, bigFn bigIntOp, floatFn floatOp, decFn decimalOp) (core.Value, error) {
 	if len(args) != 2 {
 		return value.NewNoneVal(), arityError(name, 2, len(args))
 	}
 
-	// Check if either argument is decimal - if so, promote to decimal arithmetic
-	if args[0].GetType() == value.TypeDecimal || args[1].GetType() == value.TypeDecimal {
-		return decimalMathOp(name, args[0], args[1], decFn)
+	switch numericKind(args[0], args[1]) {
+	case value.TypeDecimal:
+		return decimalMathOp(name, args[0], args[1], eval, decFn)
+	case value.TypeFloat:
+		return floatMathOp(name, args[0], args[1], floatFn)
+	case value.TypeBigInt:
+		return bigIntMathOp(name, args[0], args[1], bigFn)
 	}
 
 	// Integer arithmetic
@@ -60,8 +96,41 @@ func mathOp(name string, args []core.Value, intFn intOp, decFn decimalOp) (core.
 	return value.NewIntVal(result), nil
 }
 
+// floatMathOp handles binary64 arithmetic with integer promotion.
+func floatMathOp(name string, a, b core.Value, floatFn floatOp) (core.Value, error) {
+	aVal, ok := promoteToFloat(a)
+	if !ok {
+		return value.NewNoneVal(), mathTypeError(name, a)
+	}
+	bVal, ok := promoteToFloat(b)
+	if !ok {
+		return value.NewNoneVal(), mathTypeError(name, b)
+	}
+	return value.NewFloatVal(floatFn(aVal, bVal)), nil
+}
+
+// bigIntMathOp handles arbitrary-precision integer arithmetic with integer
+// promotion. It cannot overflow; only division by zero fails.
+func bigIntMathOp(name string, a, b core.Value, bigFn bigIntOp) (core.Value, error) {
+	aVal, ok := promoteToBigInt(a)
+	if !ok {
+		return value.NewNoneVal(), mathTypeError(name, a)
+	}
+	bVal, ok := promoteToBigInt(b)
+	if !ok {
+		return value.NewNoneVal(), mathTypeError(name, b)
+	}
+	if (name == "/" || name == "mod") && bVal.Sign() == 0 {
+		return value.NewNoneVal(), verror.NewMathError(verror.ErrIDDivByZero, [3]string{"", "", ""})
+	}
+	return value.NewBigIntVal(bigFn(new(big.Int), aVal, bVal)), nil
+}
+
 // decimalMathOp handles decimal arithmetic with promotion.
-func decimalMathOp(name string, a, b core.Value, decFn decimalOp) (core.Value, error) {
+func decimalMathOp(name string, a, b core.Value, eval core.Evaluator, decFn decimalOp) (core.Value, error) {
+	if err := checkFiniteForDecimal(a, b); err != nil {
+		return value.NewNoneVal(), err
+	}
 	aVal := promoteToDecimal(a, nil, nil)
 	bVal := promoteToDecimal(b, nil, nil)
 	if aVal == nil || bVal == nil {
@@ -72,23 +141,37 @@ func decimalMathOp(name string, a, b core.Value, decFn decimalOp) (core.Value, e
 		return value.NewNoneVal(), verror.NewMathError(verror.ErrIDDivByZero, [3]string{"", "", ""})
 	}
 
-	ctx := decimal.Context128
+	cfg := activeDecimalContext(eval)
 	result := new(decimal.Big)
-	decFn(ctx, result, aVal, bVal)
+	decFn(arithmeticContext(cfg), result, aVal, bVal)
+	if err := checkDecimalTraps(name, cfg, result); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	return value.DecimalVal(result, 2), nil
 }
 
 // compareOp provides a generic template for binary comparison operations.
-// It handles type checking and decimal promotion.
-func compareOp(name string, args []core.Value, intFn intCompareFn, decFn decimalCompareFn) (core.Value, error) {
+// It handles type checking and the same promotion rules as mathOp.
+func compareOp(name string, args []core.Value, intFn intCompareFn, floatFn floatCompareFn, decFn decimalCompareFn) (core.Value, error) {
 	if len(args) != 2 {
 		return value.NewNoneVal(), arityError(name, 2, len(args))
 	}
 
-	// Check if either argument is decimal - if so, promote to decimal comparison
-	if args[0].GetType() == value.TypeDecimal || args[1].GetType() == value.TypeDecimal {
-		return decimalCompareOp(name, args[0], args[1], decFn)
+	switch numericKind(args[0], args[1]) {
+	case value.TypeDecimal, value.TypeBigInt:
+		// Decimals hold any bigint exactly, so bigint comparisons reuse them.
+		return decimalCompareOp(name, args[0], args[1], floatFn, decFn)
+	case value.TypeFloat:
+		a, ok := promoteToFloat(args[0])
+		if !ok {
+			return value.NewNoneVal(), mathTypeError(name, args[0])
+		}
+		b, ok := promoteToFloat(args[1])
+		if !ok {
+			return value.NewNoneVal(), mathTypeError(name, args[1])
+		}
+		return value.NewLogicVal(floatFn(a, b)), nil
 	}
 
 	// Integer comparison
@@ -105,8 +188,17 @@ func compareOp(name string, args []core.Value, intFn intCompareFn, decFn decimal
 	return value.NewLogicVal(intFn(a, b)), nil
 }
 
-// decimalCompareOp handles decimal comparison with promotion.
-func decimalCompareOp(name string, a, b core.Value, decFn decimalCompareFn) (core.Value, error) {
+// decimalCompareOp handles decimal comparison with promotion. A NaN or
+// infinite float has no decimal equivalent, so it is compared as a float.
+func decimalCompareOp(name string, a, b core.Value, floatFn floatCompareFn, decFn decimalCompareFn) (core.Value, error) {
+	if checkFiniteForDecimal(a, b) != nil {
+		af, aok := promoteToFloat(a)
+		bf, bok := promoteToFloat(b)
+		if !aok || !bok {
+			return value.NewNoneVal(), verror.NewMathError(name+"-type-error", [3]string{value.TypeToString(a.GetType()), value.TypeToString(b.GetType()), ""})
+		}
+		return value.NewLogicVal(floatFn(af, bf)), nil
+	}
 	aVal := promoteToDecimal(a, nil, nil)
 	bVal := promoteToDecimal(b, nil, nil)
 	if aVal == nil || bVal == nil {
@@ -119,11 +211,11 @@ func decimalCompareOp(name string, a, b core.Value, decFn decimalCompareFn) (cor
 // Add implements the + native function.
 //
 // Contract: + value1 value2 → sum
-// - Arguments can be integers or decimals
-// - Returns arithmetic sum with type promotion (integer + decimal → decimal)
+// - Arguments can be integers, bigints, floats or decimals
+// - Returns arithmetic sum with type promotion (integer → bigint → float → decimal)
 // - Detects overflow
 func Add(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
-	return mathOp("+", args,
+	return mathOp("+", args, eval,
 		func(a, b int64) (int64, bool) {
 			// Check for overflow
 			// Positive overflow: a > 0 && b > 0 && a > MaxInt64 - b
@@ -136,6 +228,8 @@ func Add(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 			}
 			return a + b, false
 		},
+		(*big.Int).Add,
+		func(a, b float64) float64 { return a + b },
 		func(ctx decimal.Context, result, a, b *decimal.Big) *decimal.Big {
 			return ctx.Add(result, a, b)
 		})
@@ -144,11 +238,11 @@ func Add(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 // Subtract implements the - native function.
 //
 // Contract: - value1 value2 → difference
-// - Arguments can be integers or decimals
+// - Arguments can be integers, bigints, floats or decimals
 // - Returns arithmetic difference (value1 - value2) with type promotion
 // - Detects overflow
 func Subtract(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
-	return mathOp("-", args,
+	return mathOp("-", args, eval,
 		func(a, b int64) (int64, bool) {
 			// Check for overflow
 			// a - b can overflow if:
@@ -162,6 +256,8 @@ func Subtract(args []core.Value, refValues map[string]core.Value, eval core.Eval
 			}
 			return a - b, false
 		},
+		(*big.Int).Sub,
+		func(a, b float64) float64 { return a - b },
 		func(ctx decimal.Context, result, a, b *decimal.Big) *decimal.Big {
 			return ctx.Sub(result, a, b)
 		})
@@ -170,11 +266,11 @@ func Subtract(args []core.Value, refValues map[string]core.Value, eval core.Eval
 // Multiply implements the * native function.
 //
 // Contract: * value1 value2 → product
-// - Arguments can be integers or decimals
+// - Arguments can be integers, bigints, floats or decimals
 // - Returns arithmetic product with type promotion
 // - Detects overflow
 func Multiply(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
-	return mathOp("*", args,
+	return mathOp("*", args, eval,
 		func(a, b int64) (int64, bool) {
 			// Special cases: a == 0 or b == 0 (no overflow)
 			if a == 0 || b == 0 {
@@ -197,6 +293,8 @@ func Multiply(args []core.Value, refValues map[string]core.Value, eval core.Eval
 
 			return result, false
 		},
+		(*big.Int).Mul,
+		func(a, b float64) float64 { return a * b },
 		func(ctx decimal.Context, result, a, b *decimal.Big) *decimal.Big {
 			return ctx.Mul(result, a, b)
 		})
@@ -205,18 +303,17 @@ func Multiply(args []core.Value, refValues map[string]core.Value, eval core.Eval
 // Divide implements the / native function.
 //
 // Contract: / value1 value2 → quotient
-// - Both arguments must be integers
-// - Returns arithmetic quotient (truncated toward zero)
-// - Division by zero is an error
+// - Arguments can be integers, bigints, floats or decimals
+// - Integer quotients are truncated toward zero
+// - Division by zero is an error; float division yields an infinity or NaN
 func Divide(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
-	// Special handling for division by zero in integer case
-	if len(args) == 2 && args[0].GetType() != value.TypeDecimal && args[1].GetType() != value.TypeDecimal {
+	if len(args) == 2 && numericKind(args[0], args[1]) == value.TypeInteger {
 		if b, ok := value.AsIntValue(args[1]); ok && b == 0 {
 			return value.NewNoneVal(), verror.NewMathError(verror.ErrIDDivByZero, [3]string{"", "", ""})
 		}
 	}
 
-	return mathOp("/", args,
+	return mathOp("/", args, eval,
 		func(a, b int64) (int64, bool) {
 			// Check for overflow: MinInt64 / -1 overflows
 			if a == math.MinInt64 && b == -1 {
@@ -224,19 +321,21 @@ func Divide(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 			}
 			return a / b, false
 		},
+		(*big.Int).Quo,
+		func(a, b float64) float64 { return a / b },
 		func(ctx decimal.Context, result, a, b *decimal.Big) *decimal.Big {
 			return ctx.Quo(result, a, b)
 		})
 }
 
 func Mod(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
-	if len(args) == 2 && args[0].GetType() != value.TypeDecimal && args[1].GetType() != value.TypeDecimal {
+	if len(args) == 2 && numericKind(args[0], args[1]) == value.TypeInteger {
 		if b, ok := value.AsIntValue(args[1]); ok && b == 0 {
 			return value.NewNoneVal(), verror.NewMathError(verror.ErrIDDivByZero, [3]string{"", "", ""})
 		}
 	}
 
-	return mathOp("mod", args,
+	return mathOp("mod", args, eval,
 		func(a, b int64) (int64, bool) {
 			// Check for overflow: MinInt64 % -1 panics
 			if a == math.MinInt64 && b == -1 {
@@ -244,6 +343,8 @@ func Mod(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 			}
 			return a % b, false
 		},
+		(*big.Int).Rem,
+		math.Mod,
 		func(ctx decimal.Context, result, a, b *decimal.Big) *decimal.Big {
 			return ctx.Rem(result, a, b)
 		})
@@ -252,44 +353,48 @@ func Mod(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 // LessThan implements the < native function.
 //
 // Contract: < value1 value2 → logic
-// - Arguments can be integers or decimals
+// - Arguments can be integers, bigints, floats or decimals
 // - Returns true if value1 < value2, false otherwise
 func LessThan(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	return compareOp("<", args,
 		func(a, b int64) bool { return a < b },
+		func(a, b float64) bool { return a < b },
 		func(a, b *decimal.Big) bool { return a.Cmp(b) < 0 })
 }
 
 // GreaterThan implements the > native function.
 //
 // Contract: > value1 value2 → logic
-// - Arguments can be integers or decimals
+// - Arguments can be integers, bigints, floats or decimals
 // - Returns true if value1 > value2, false otherwise
 func GreaterThan(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	return compareOp(">", args,
 		func(a, b int64) bool { return a > b },
+		func(a, b float64) bool { return a > b },
 		func(a, b *decimal.Big) bool { return a.Cmp(b) > 0 })
 }
 
 // LessOrEqual implements the <= native function.
 //
 // Contract: <= value1 value2 → logic
-// - Arguments can be integers or decimals
+// - Arguments can be integers, bigints, floats or decimals
 // - Returns true if value1 <= value2, false otherwise
 func LessOrEqual(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	return compareOp("<=", args,
 		func(a, b int64) bool { return a <= b },
+		func(a, b float64) bool { return a <= b },
 		func(a, b *decimal.Big) bool { return a.Cmp(b) <= 0 })
 }
 
 // GreaterOrEqual implements the >= native function.
 //
 // Contract: >= value1 value2 → logic
-// - Arguments can be integers or decimals
+// - Arguments can be integers, bigints, floats or decimals
 // - Returns true if value1 >= value2, false otherwise
 func GreaterOrEqual(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	return compareOp(">=", args,
 		func(a, b int64) bool { return a >= b },
+		func(a, b float64) bool { return a >= b },
 		func(a, b *decimal.Big) bool { return a.Cmp(b) >= 0 })
 }
 
@@ -298,12 +403,13 @@ func GreaterOrEqual(args []core.Value, refValues map[string]core.Value, eval cor
 // Contract: = value1 value2 → logic
 // - Arguments can be any type
 // - Returns true if value1 equals value2, false otherwise
-// - Uses polymorphic Equals method for type-specific comparison
+// - Numbers of different types are promoted like < and >, so 1 = 1.0 is true
+// - Other values use the polymorphic Equals method
 func Equal(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	if len(args) != 2 {
 		return value.NewNoneVal(), arityError("=", 2, len(args))
 	}
-	return value.NewLogicVal(args[0].Equals(args[1])), nil
+	return value.NewLogicVal(valuesEqual(args[0], args[1])), nil
 }
 
 // NotEqual implements the <> native function.
@@ -311,12 +417,39 @@ func Equal(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 // Contract: <> value1 value2 → logic
 // - Arguments can be any type
 // - Returns true if value1 does not equal value2, false otherwise
-// - Uses polymorphic Equals method for type-specific comparison
+// - Numbers of different types are promoted as for =
 func NotEqual(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
 	if len(args) != 2 {
 		return value.NewNoneVal(), arityError("<>", 2, len(args))
 	}
-	return value.NewLogicVal(!args[0].Equals(args[1])), nil
+	return value.NewLogicVal(!valuesEqual(args[0], args[1])), nil
+}
+
+// valuesEqual compares two values for = and <>. Numbers of different types
+// go through compareOp, so equality agrees with <= and >=.
+func valuesEqual(a, b core.Value) bool {
+	if a.GetType() == b.GetType() || !isNumber(a) || !isNumber(b) {
+		return a.Equals(b)
+	}
+	result, err := compareOp("=", []core.Value{a, b},
+		func(a, b int64) bool { return a == b },
+		func(a, b float64) bool { return a == b },
+		func(a, b *decimal.Big) bool { return a.Cmp(b) == 0 })
+	if err != nil {
+		return false
+	}
+	equal, _ := value.AsLogicValue(result)
+	return equal
+}
+
+// isNumber reports whether v is one of the numeric types that promote into
+// each other.
+func isNumber(v core.Value) bool {
+	switch v.GetType() {
+	case value.TypeInteger, value.TypeBigInt, value.TypeFloat, value.TypeDecimal:
+		return true
+	}
+	return false
 }
 
 // And implements the and native function.
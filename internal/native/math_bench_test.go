@@ -1,6 +1,7 @@
 package native_test
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
@@ -113,6 +114,54 @@ func BenchmarkDecimalRound(b *testing.B) {
 	benchmarkMathOp(b, native.Round, args)
 }
 
+// Float arithmetic benchmarks: the binary64 counterparts of the decimal
+// benchmarks above. Compare with -bench 'Decimal|Float'.
+
+func BenchmarkFloatAdd(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(123456789.123456789), value.NewFloatVal(987654321.987654321)}
+	benchmarkMathOp(b, native.Add, args)
+}
+
+func BenchmarkFloatMultiply(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(12345.6789), value.NewFloatVal(6789.12345)}
+	benchmarkMathOp(b, native.Multiply, args)
+}
+
+func BenchmarkFloatDivide(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(9876543210.123), value.NewFloatVal(12345.6789)}
+	benchmarkMathOp(b, native.Divide, args)
+}
+
+func BenchmarkFloatSqrt(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(123456.789)}
+	benchmarkMathOp(b, native.Sqrt, args)
+}
+
+func BenchmarkFloatPow(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(2.5), value.NewFloatVal(3.2)}
+	benchmarkMathOp(b, native.Pow, args)
+}
+
+func BenchmarkFloatExp(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(2.5)}
+	benchmarkMathOp(b, native.Exp, args)
+}
+
+func BenchmarkFloatLog(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(12345.6789)}
+	benchmarkMathOp(b, native.Log, args)
+}
+
+func BenchmarkFloatSin(b *testing.B) {
+	args := []core.Value{value.NewFloatVal(1.5707963267948966)}
+	benchmarkMathOp(b, native.Sin, args)
+}
+
+func BenchmarkMixedIntegerFloatMultiply(b *testing.B) {
+	args := []core.Value{value.NewIntVal(12345), value.NewFloatVal(6789.12345)}
+	benchmarkMathOp(b, native.Multiply, args)
+}
+
 // Mixed integer-decimal promotion benchmark
 func BenchmarkMixedIntegerDecimalMultiply(b *testing.B) {
 	i := value.NewIntVal(12345)
@@ -120,3 +169,15 @@ func BenchmarkMixedIntegerDecimalMultiply(b *testing.B) {
 	args := []core.Value{i, d}
 	benchmarkMathOp(b, native.Multiply, args)
 }
+
+func BenchmarkBigIntAdd(b *testing.B) {
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	args := []core.Value{value.NewBigIntVal(x), value.NewIntVal(987654321)}
+	benchmarkMathOp(b, native.Add, args)
+}
+
+func BenchmarkBigIntMultiply(b *testing.B) {
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	args := []core.Value{value.NewBigIntVal(x), value.NewBigIntVal(x)}
+	benchmarkMathOp(b, native.Multiply, args)
+}
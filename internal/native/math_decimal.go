@@ -1,9 +1,13 @@
 // Package native provides advanced math operations for decimal values.
 // This file implements transcendental and rounding functions for decimal! type.
+// Float arguments take a binary64 fast path that follows IEEE-754: domain
+// errors give NaN and overflow gives an infinity instead of raising errors.
 package native
 
 import (
 	"math"
+	"math/big"
+	"strconv"
 
 	"github.com/ericlagergren/decimal"
 	"github.com/marcin-radoszewski/viro/internal/core"
@@ -32,6 +36,12 @@ func DecimalConstructor(args []core.Value, refValues map[string]core.Value, eval
 		// Already a decimal, return as-is
 		return arg, nil
 
+	case value.TypeBigInt:
+		return value.DecimalVal(promoteToDecimal(arg, nil, nil), 0), nil
+
+	case value.TypeFloat:
+		return floatToDecimal(arg)
+
 	case value.TypeString:
 		// Parse string to decimal
 		if str, ok := value.AsStringValue(arg); ok {
@@ -71,6 +81,29 @@ func Pow(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("pow-arity", [3]string{"2", "", ""})
 	}
 
+	if numericKind(args[0], args[1]) == value.TypeFloat {
+		base, ok := promoteToFloat(args[0])
+		exp, ok2 := promoteToFloat(args[1])
+		if !ok || !ok2 {
+			return value.NewNoneVal(), verror.NewMathError("pow-invalid-type", [3]string{value.TypeToString(args[0].GetType()), value.TypeToString(args[1].GetType()), ""})
+		}
+		return value.NewFloatVal(math.Pow(base, exp)), nil
+	}
+
+	if numericKind(args[0], args[1]) == value.TypeBigInt {
+		base, ok := promoteToBigInt(args[0])
+		exp, ok2 := promoteToBigInt(args[1])
+		if ok && ok2 && exp.Sign() >= 0 {
+			if base.CmpAbs(big.NewInt(1)) > 0 && (!exp.IsInt64() || exp.Int64() > maxBigIntBits/int64(base.BitLen())) {
+				return value.NewNoneVal(), bigIntLimitError("pow")
+			}
+			return value.NewBigIntVal(new(big.Int).Exp(base, exp, nil)), nil
+		}
+	}
+
+	if err := checkFiniteForDecimal(args[0], args[1]); err != nil {
+		return value.NewNoneVal(), err
+	}
 	base := promoteToDecimal(args[0], nil, nil)
 	exp := promoteToDecimal(args[1], nil, nil)
 
@@ -78,7 +111,8 @@ func Pow(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("pow-invalid-type", [3]string{value.TypeToString(args[0].GetType()), value.TypeToString(args[1].GetType()), ""})
 	}
 
-	ctx := decimal.Context128
+	cfg := activeDecimalContext(eval)
+	ctx := arithmeticContext(cfg)
 	result := new(decimal.Big)
 	ctx.Pow(result, base, exp)
 
@@ -86,6 +120,9 @@ func Pow(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 	if result.IsInf(0) {
 		return value.NewNoneVal(), verror.NewMathError("pow-overflow", [3]string{"", "", ""})
 	}
+	if err := checkDecimalTraps("pow", cfg, result); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	return value.DecimalVal(result, 2), nil
 }
@@ -96,6 +133,10 @@ func Sqrt(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 		return value.NewNoneVal(), verror.NewMathError("sqrt-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Sqrt(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("sqrt-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -106,9 +147,13 @@ func Sqrt(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 		return value.NewNoneVal(), verror.NewMathError(verror.ErrIDSqrtNegative, [3]string{val.String(), "", ""})
 	}
 
-	ctx := decimal.Context128
+	cfg := activeDecimalContext(eval)
+	ctx := arithmeticContext(cfg)
 	result := new(decimal.Big)
 	ctx.Sqrt(result, val)
+	if err := checkDecimalTraps("sqrt", cfg, result); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	return value.DecimalVal(result, 2), nil
 }
@@ -119,12 +164,17 @@ func Exp(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("exp-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Exp(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("exp-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
 	}
 
-	ctx := decimal.Context128
+	cfg := activeDecimalContext(eval)
+	ctx := arithmeticContext(cfg)
 	result := new(decimal.Big)
 	ctx.Exp(result, val)
 
@@ -132,6 +182,9 @@ func Exp(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 	if result.IsInf(0) {
 		return value.NewNoneVal(), verror.NewMathError(verror.ErrIDExpOverflow, [3]string{val.String(), "", ""})
 	}
+	if err := checkDecimalTraps("exp", cfg, result); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	return value.DecimalVal(result, 2), nil
 }
@@ -142,6 +195,10 @@ func Log(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("log-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Log(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("log-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -152,9 +209,13 @@ func Log(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError(verror.ErrIDLogDomain, [3]string{val.String(), "", ""})
 	}
 
-	ctx := decimal.Context128
+	cfg := activeDecimalContext(eval)
+	ctx := arithmeticContext(cfg)
 	result := new(decimal.Big)
 	ctx.Log(result, val)
+	if err := checkDecimalTraps("log", cfg, result); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	return value.DecimalVal(result, 2), nil
 }
@@ -165,6 +226,10 @@ func Log10(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return value.NewNoneVal(), verror.NewMathError("log-10-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Log10(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("log-10-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -175,9 +240,13 @@ func Log10(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return value.NewNoneVal(), verror.NewMathError("log-10-domain", [3]string{val.String(), "", ""})
 	}
 
-	ctx := decimal.Context128
+	cfg := activeDecimalContext(eval)
+	ctx := arithmeticContext(cfg)
 	result := new(decimal.Big)
 	ctx.Log10(result, val)
+	if err := checkDecimalTraps("log-10", cfg, result); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	return value.DecimalVal(result, 2), nil
 }
@@ -188,6 +257,10 @@ func Sin(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("sin-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Sin(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("sin-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -197,7 +270,7 @@ func Sin(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 	f, _ := val.Float64()
 	result := math.Sin(f)
 
-	d := decimal.New(int64(result*1e10), -10)
+	d := decimal.New(int64(result*1e10), 10)
 	return value.DecimalVal(d, 10), nil
 }
 
@@ -207,6 +280,10 @@ func Cos(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("cos-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Cos(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("cos-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -216,7 +293,7 @@ func Cos(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 	f, _ := val.Float64()
 	result := math.Cos(f)
 
-	d := decimal.New(int64(result*1e10), -10)
+	d := decimal.New(int64(result*1e10), 10)
 	return value.DecimalVal(d, 10), nil
 }
 
@@ -226,6 +303,10 @@ func Tan(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 		return value.NewNoneVal(), verror.NewMathError("tan-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Tan(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("tan-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -235,7 +316,7 @@ func Tan(args []core.Value, refValues map[string]core.Value, eval core.Evaluator
 	f, _ := val.Float64()
 	result := math.Tan(f)
 
-	d := decimal.New(int64(result*1e10), -10)
+	d := decimal.New(int64(result*1e10), 10)
 	return value.DecimalVal(d, 10), nil
 }
 
@@ -245,6 +326,10 @@ func Asin(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 		return value.NewNoneVal(), verror.NewMathError("asin-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Asin(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("asin-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -257,7 +342,7 @@ func Asin(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 	}
 
 	result := math.Asin(f)
-	d := decimal.New(int64(result*1e10), -10)
+	d := decimal.New(int64(result*1e10), 10)
 	return value.DecimalVal(d, 10), nil
 }
 
@@ -267,6 +352,10 @@ func Acos(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 		return value.NewNoneVal(), verror.NewMathError("acos-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Acos(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("acos-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -279,7 +368,7 @@ func Acos(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 	}
 
 	result := math.Acos(f)
-	d := decimal.New(int64(result*1e10), -10)
+	d := decimal.New(int64(result*1e10), 10)
 	return value.DecimalVal(d, 10), nil
 }
 
@@ -289,6 +378,10 @@ func Atan(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 		return value.NewNoneVal(), verror.NewMathError("atan-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Atan(f)), nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("atan-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -297,14 +390,44 @@ func Atan(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 	f, _ := val.Float64()
 	result := math.Atan(f)
 
-	d := decimal.New(int64(result*1e10), -10)
+	d := decimal.New(int64(result*1e10), 10)
 	return value.DecimalVal(d, 10), nil
 }
 
-// Round rounds a decimal to specified places with optional rounding mode
+// Round rounds a number to the nearest multiple of --to (default 1). Decimals
+// round with --mode or the active context's rounding mode; floats round with
+// --mode or half-even.
 func Round(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
-	if len(args) < 1 || len(args) > 3 {
-		return value.NewNoneVal(), verror.NewMathError("round-arity", [3]string{"1-3", "", ""})
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("round", 1, len(args))
+	}
+
+	mode := ""
+	if hasMode, modeVal := getRefinementValue(refValues, "mode"); hasMode {
+		name, err := roundingModeName("round", modeVal)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		mode = name
+	}
+	hasTo, quantum := getRefinementValue(refValues, "to")
+	if !hasTo && args[0].GetType() == value.TypeBigInt {
+		return args[0], nil
+	}
+
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		step := 1.0
+		if hasTo {
+			q, ok := promoteToFloat(quantum)
+			if !ok || !(q > 0) || math.IsInf(q, 0) {
+				return value.NewNoneVal(), roundQuantumError(quantum)
+			}
+			step = q
+		}
+		if mode == "" {
+			mode = "half-even"
+		}
+		return value.NewFloatVal(roundFloat(f/step, mode) * step), nil
 	}
 
 	val := promoteToDecimal(args[0], nil, nil)
@@ -312,20 +435,63 @@ func Round(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return value.NewNoneVal(), verror.NewMathError("round-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
 	}
 
-	places := int32(0)
-	if len(args) >= 2 && args[1].GetType() == value.TypeInteger {
-		if i, ok := value.AsIntValue(args[1]); ok {
-			places = int32(i)
+	step := decimal.New(1, 0)
+	if hasTo {
+		if err := checkFiniteForDecimal(quantum, quantum); err != nil {
+			return value.NewNoneVal(), roundQuantumError(quantum)
+		}
+		step = promoteToDecimal(quantum, nil, nil)
+		if step == nil || step.Sign() <= 0 {
+			return value.NewNoneVal(), roundQuantumError(quantum)
 		}
 	}
 
-	// For now, use default half-even rounding
-
+	cfg := activeDecimalContext(eval)
+	if mode != "" {
+		cfg.Rounding = mode
+	}
+	ctx := arithmeticContext(cfg)
 	result := new(decimal.Big)
-	result.Copy(val)
-	result.Round(int(places))
+	ctx.Quo(result, val, step)
+	ctx.RoundToInt(result)
+	ctx.Mul(result, result, step)
 
-	return value.DecimalVal(result, int16(places)), nil
+	return value.DecimalVal(result, int16(max(step.Scale(), 0))), nil
+}
+
+// roundQuantumError reports a --to value that is not a positive number.
+func roundQuantumError(quantum core.Value) error {
+	return verror.NewScriptError(
+		verror.ErrIDInvalidOperation,
+		[3]string{"round --to expects a positive number, got " + quantum.Mold(), "", ""},
+	)
+}
+
+// roundFloat rounds f to an integral value using the named rounding mode.
+func roundFloat(f float64, mode string) float64 {
+	switch mode {
+	case "half-up":
+		return math.Round(f)
+	case "half-down":
+		t := math.Trunc(f)
+		if math.Abs(f-t) > 0.5 {
+			return t + math.Copysign(1, f)
+		}
+		return t
+	case "up":
+		if f < 0 {
+			return math.Floor(f)
+		}
+		return math.Ceil(f)
+	case "down":
+		return math.Trunc(f)
+	case "ceiling":
+		return math.Ceil(f)
+	case "floor":
+		return math.Floor(f)
+	default:
+		return math.RoundToEven(f)
+	}
 }
 
 // Ceil returns the smallest integer >= the decimal value
@@ -334,6 +500,13 @@ func Ceil(args []core.Value, refValues map[string]core.Value, eval core.Evaluato
 		return value.NewNoneVal(), verror.NewMathError("ceil-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Ceil(f)), nil
+	}
+	if args[0].GetType() == value.TypeBigInt {
+		return args[0], nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("ceil-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -352,6 +525,13 @@ func Floor(args []core.Value, refValues map[string]core.Value, eval core.Evaluat
 		return value.NewNoneVal(), verror.NewMathError("floor-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Floor(f)), nil
+	}
+	if args[0].GetType() == value.TypeBigInt {
+		return args[0], nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("floor-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
@@ -370,21 +550,42 @@ func Truncate(args []core.Value, refValues map[string]core.Value, eval core.Eval
 		return value.NewNoneVal(), verror.NewMathError("truncate-arity", [3]string{"1", "", ""})
 	}
 
+	if f, ok := value.AsFloatValue(args[0]); ok {
+		return value.NewFloatVal(math.Trunc(f)), nil
+	}
+	if args[0].GetType() == value.TypeBigInt {
+		return args[0], nil
+	}
+
 	val := promoteToDecimal(args[0], nil, nil)
 	if val == nil {
 		return value.NewNoneVal(), verror.NewMathError("truncate-invalid-type", [3]string{value.TypeToString(args[0].GetType()), "", ""})
 	}
 
+	ctx := decimal.Context128
+	ctx.RoundingMode = decimal.ToZero
 	result := new(decimal.Big)
 	result.Copy(val)
-	result.RoundToInt()
+	ctx.RoundToInt(result)
 
 	return value.DecimalVal(result, 0), nil
 }
 
-// promoteToDecimal converts integer or decimal values to *decimal.Big
+// promoteToDecimal converts integer, bigint, float or decimal values to *decimal.Big.
+// A float converts through its shortest representation, so 0.1f becomes 0.1
+// rather than the exact binary expansion. NaN and infinities return nil.
 func promoteToDecimal(v core.Value, _ map[string]core.Value, _ core.Evaluator) *decimal.Big {
 	switch v.GetType() {
+	case value.TypeFloat:
+		f, _ := value.AsFloatValue(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		d, ok := new(decimal.Big).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if !ok {
+			return nil
+		}
+		return d
 	case value.TypeDecimal:
 		if dec, ok := value.AsDecimal(v); ok && dec != nil {
 			return dec.Magnitude
@@ -395,7 +596,79 @@ func promoteToDecimal(v core.Value, _ map[string]core.Value, _ core.Evaluator) *
 			return decimal.New(i, 0)
 		}
 		return nil
+	case value.TypeBigInt:
+		if n, ok := value.AsBigIntValue(v); ok {
+			return new(decimal.Big).SetBigMantScale(n, 0)
+		}
+		return nil
 	default:
 		return nil
 	}
 }
+
+// floatToDecimal converts a finite float to a decimal whose scale matches the
+// float's shortest representation.
+func floatToDecimal(v core.Value) (core.Value, error) {
+	if err := checkFiniteForDecimal(v); err != nil {
+		return value.NewNoneVal(), err
+	}
+	f, _ := value.AsFloatValue(v)
+	text := strconv.FormatFloat(f, 'f', -1, 64)
+	scale := int16(0)
+	if idx := findDecimalPoint(text); idx >= 0 {
+		scale = int16(len(text) - idx - 1)
+	}
+	return value.DecimalVal(promoteToDecimal(v, nil, nil), scale), nil
+}
+
+// promoteToFloat converts integer, bigint, float or decimal values to float64.
+// A bigint too large for binary64 becomes an infinity.
+func promoteToFloat(v core.Value) (float64, bool) {
+	switch v.GetType() {
+	case value.TypeFloat:
+		return value.AsFloatValue(v)
+	case value.TypeInteger:
+		i, ok := value.AsIntValue(v)
+		return float64(i), ok
+	case value.TypeBigInt:
+		n, ok := value.AsBigIntValue(v)
+		if !ok {
+			return 0, false
+		}
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, true
+	case value.TypeDecimal:
+		if dec, ok := value.AsDecimal(v); ok && dec != nil && dec.Magnitude != nil {
+			f, _ := dec.Magnitude.Float64()
+			return f, true
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+}
+
+// promoteToBigInt converts integer or bigint values to *big.Int. The result
+// may be shared with the value and must not be modified.
+func promoteToBigInt(v core.Value) (*big.Int, bool) {
+	switch v.GetType() {
+	case value.TypeBigInt:
+		return value.AsBigIntValue(v)
+	case value.TypeInteger:
+		i, ok := value.AsIntValue(v)
+		return big.NewInt(i), ok
+	default:
+		return nil, false
+	}
+}
+
+// checkFiniteForDecimal rejects NaN and infinite floats on their way into
+// decimal arithmetic, which cannot represent them.
+func checkFiniteForDecimal(vals ...core.Value) error {
+	for _, v := range vals {
+		if f, ok := value.AsFloatValue(v); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			return verror.NewMathError(verror.ErrIDNonFiniteFloat, [3]string{value.FloatValue(f).Form(), "decimal!", ""})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package native
+
+import (
+	"runtime"
+	"sort"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// StatsNative implements `stats`: an object describing the evaluator's frame
+// store and memory use, without collecting anything. With --allocations it
+// returns the per-function allocation counts instead.
+func StatsNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 0 {
+		return value.NewNoneVal(), arityError("stats", 0, len(args))
+	}
+	if allocs, ok := refValues["allocations"]; ok && ToTruthy(allocs) {
+		return allocationStats(eval)
+	}
+	return createStatsObject(eval.FrameStats()), nil
+}
+
+// allocationStats returns a block with an object per function that
+// allocated while accounting was on, most allocations first.
+func allocationStats(eval core.Evaluator) (core.Value, error) {
+	observing, ok := eval.(callObserving)
+	if !ok || !observing.AllocationAccounting() {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"allocation accounting is off; run with --profile-allocs or inside profile --allocs", "", ""},
+		)
+	}
+
+	counts := observing.Allocations()
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a, b := counts[names[i]].Total(), counts[names[j]].Total(); a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+
+	entries := make([]core.Value, 0, len(names))
+	for _, name := range names {
+		s := counts[name]
+		objFrame := frame.NewFrame(frame.FrameObject, -1)
+		objFrame.Bind("function", value.NewStrVal(name))
+		objFrame.Bind("blocks", value.NewIntVal(s.Blocks))
+		objFrame.Bind("strings", value.NewIntVal(s.Strings))
+		objFrame.Bind("binaries", value.NewIntVal(s.Binaries))
+		objFrame.Bind("frames", value.NewIntVal(s.Frames))
+		objFrame.Bind("growth", value.NewIntVal(s.Growth))
+		objFrame.Bind("total", value.NewIntVal(s.Total()))
+		entries = append(entries, value.ObjectVal(value.NewObject(objFrame)))
+	}
+	return value.NewBlockVal(entries), nil
+}
+
+// RecycleNative implements `recycle`: reclaims frames that are no longer
+// reachable and returns the resulting stats.
+func RecycleNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 0 {
+		return value.NewNoneVal(), arityError("recycle", 0, len(args))
+	}
+	return createStatsObject(eval.RecycleFrames()), nil
+}
+
+func createStatsObject(stats core.FrameStats) core.Value {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	objFrame.Bind("frame-slots", value.NewIntVal(int64(stats.Slots)))
+	objFrame.Bind("live-frames", value.NewIntVal(int64(stats.Live)))
+	objFrame.Bind("active-frames", value.NewIntVal(int64(stats.Active)))
+	objFrame.Bind("free-slots", value.NewIntVal(int64(stats.Free)))
+	objFrame.Bind("reclaimed", value.NewIntVal(int64(stats.Reclaimed)))
+	objFrame.Bind("sweeps", value.NewIntVal(int64(stats.Sweeps)))
+	objFrame.Bind("symbols", value.NewIntVal(int64(core.SymbolCount())))
+	objFrame.Bind("heap-alloc", value.NewIntVal(int64(mem.HeapAlloc)))
+
+	obj := value.NewObject(objFrame)
+	return value.ObjectVal(obj)
+}
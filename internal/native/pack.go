@@ -0,0 +1,466 @@
+package native
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Binary layout dialect shared by pack and unpack. A spec is a sequence of
+//
+//	int8 uint8                      one byte
+//	int16-be uint32-le ...          8 to 64 bit integers, -be or -le
+//	float32-be float64-le ...       IEEE 754 floats
+//	bytes COUNT                     raw bytes
+//	string COUNT                    UTF-8 text
+//	pad COUNT                       zero bytes, skipped when unpacking
+//
+// each followed by a field name (unpack) or a value expression (pack).
+// COUNT is an integer, the word rest (everything that is left), an integer
+// type such as uint16-be naming a length prefix, or for unpack the name of
+// an integer field read earlier.
+
+// packByteOrder is implemented by binary.BigEndian and binary.LittleEndian.
+type packByteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// packNumber describes an integer or float field type.
+type packNumber struct {
+	size    int
+	signed  bool
+	float   bool
+	order   packByteOrder
+	typName string
+}
+
+// parsePackNumber recognises numeric field types such as uint16-be.
+func parsePackNumber(word string) (packNumber, bool) {
+	num := packNumber{typName: word, order: binary.BigEndian}
+	base := word
+	switch {
+	case strings.HasSuffix(word, "-be"):
+		base = strings.TrimSuffix(word, "-be")
+	case strings.HasSuffix(word, "-le"):
+		base = strings.TrimSuffix(word, "-le")
+		num.order = binary.LittleEndian
+	}
+
+	var bits string
+	switch {
+	case strings.HasPrefix(base, "uint"):
+		bits = strings.TrimPrefix(base, "uint")
+	case strings.HasPrefix(base, "int"):
+		bits = strings.TrimPrefix(base, "int")
+		num.signed = true
+	case strings.HasPrefix(base, "float"):
+		bits = strings.TrimPrefix(base, "float")
+		num.float = true
+	default:
+		return num, false
+	}
+
+	switch bits {
+	case "8":
+		num.size = 1
+	case "16":
+		num.size = 2
+	case "32":
+		num.size = 4
+	case "64":
+		num.size = 8
+	default:
+		return num, false
+	}
+	if num.float && num.size < 4 {
+		return num, false
+	}
+	// Single bytes have no byte order; wider types must name one.
+	if (num.size == 1) != (base == word) {
+		return num, false
+	}
+	return num, true
+}
+
+// packSpecError reports a malformed pack or unpack spec.
+func packSpecError(name, msg string) error {
+	return verror.NewScriptError(verror.ErrIDInvalidOperation, [3]string{name + ": " + msg, "", ""})
+}
+
+// decodeNumber reads a numeric field from b, which holds exactly num.size bytes.
+func decodeNumber(num packNumber, b []byte) core.Value {
+	var raw uint64
+	switch num.size {
+	case 1:
+		raw = uint64(b[0])
+	case 2:
+		raw = uint64(num.order.Uint16(b))
+	case 4:
+		raw = uint64(num.order.Uint32(b))
+	default:
+		raw = num.order.Uint64(b)
+	}
+
+	if num.float {
+		if num.size == 4 {
+			return value.NewFloatVal(float64(math.Float32frombits(uint32(raw))))
+		}
+		return value.NewFloatVal(math.Float64frombits(raw))
+	}
+	if num.signed {
+		shift := 64 - 8*num.size
+		return value.NewIntVal(int64(raw<<shift) >> shift)
+	}
+	if raw > math.MaxInt64 {
+		return value.NewBigIntVal(new(big.Int).SetUint64(raw))
+	}
+	return value.NewIntVal(int64(raw))
+}
+
+// encodeNumber appends v to out as a numeric field.
+func encodeNumber(out []byte, num packNumber, v core.Value) ([]byte, error) {
+	var raw uint64
+	if num.float {
+		f, ok := promoteToFloat(v)
+		if !ok {
+			return nil, typeError("pack", "number!", v)
+		}
+		if num.size == 4 {
+			raw = uint64(math.Float32bits(float32(f)))
+		} else {
+			raw = math.Float64bits(f)
+		}
+	} else {
+		n, ok := promoteToBigInt(v)
+		if !ok {
+			return nil, typeError("pack", "integer!", v)
+		}
+		bits := uint(8 * num.size)
+		lo, hi := new(big.Int), new(big.Int).Lsh(big.NewInt(1), bits)
+		if num.signed {
+			hi.Rsh(hi, 1)
+			lo.Neg(hi)
+		}
+		if n.Cmp(lo) < 0 || n.Cmp(hi) >= 0 {
+			return nil, packSpecError("pack", fmt.Sprintf("%s does not fit in %s", n.String(), num.typName))
+		}
+		if n.Sign() < 0 {
+			raw = uint64(n.Int64())
+		} else {
+			raw = n.Uint64()
+		}
+	}
+
+	switch num.size {
+	case 1:
+		return append(out, byte(raw)), nil
+	case 2:
+		return num.order.AppendUint16(out, uint16(raw)), nil
+	case 4:
+		return num.order.AppendUint32(out, uint32(raw)), nil
+	default:
+		return num.order.AppendUint64(out, raw), nil
+	}
+}
+
+// unpackCount resolves the COUNT of a bytes, string or pad field while
+// unpacking, reading a length prefix from data at pos when COUNT names an
+// integer type. It returns the count and the position after any prefix.
+func unpackCount(tok core.Value, data []byte, pos int, fields map[string]core.Value) (int, int, error) {
+	if n, ok := value.AsIntValue(tok); ok {
+		if n < 0 {
+			return 0, pos, packSpecError("unpack", "negative count "+strconv.FormatInt(n, 10))
+		}
+		return int(n), pos, nil
+	}
+	word, ok := value.AsWordValue(tok)
+	if !ok || tok.GetType() != value.TypeWord {
+		return 0, pos, packSpecError("unpack", "expected a count, got "+tok.Mold())
+	}
+	if word == "rest" {
+		return len(data) - pos, pos, nil
+	}
+
+	var countVal core.Value
+	if num, isNum := parsePackNumber(word); isNum && !num.float {
+		if len(data)-pos < num.size {
+			return 0, pos, shortDataError(word, num.size, len(data)-pos)
+		}
+		countVal = decodeNumber(num, data[pos:pos+num.size])
+		pos += num.size
+	} else if countVal, ok = fields[word]; !ok {
+		return 0, pos, packSpecError("unpack", "count '"+word+"' is not an earlier field")
+	}
+
+	n, ok := value.AsIntValue(countVal)
+	if !ok || n < 0 {
+		return 0, pos, packSpecError("unpack", "count '"+word+"' is not a non-negative integer: "+countVal.Mold())
+	}
+	return int(n), pos, nil
+}
+
+// shortDataError reports data that ends before a field is complete.
+func shortDataError(field string, need, left int) error {
+	return verror.NewScriptError(
+		verror.ErrIDShortData,
+		[3]string{field, strconv.Itoa(need), strconv.Itoa(left)},
+	)
+}
+
+// UnpackNative implements `unpack`: decodes a binary according to a layout
+// spec and returns an object with one field per name, or with --block the
+// values in spec order. Bytes after the last field are ignored.
+func UnpackNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("unpack", 2, len(args))
+	}
+	bin, ok := value.AsBinaryValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("unpack", "binary!", args[0])
+	}
+	spec, ok := value.AsBlockValue(args[1])
+	if !ok {
+		return value.NewNoneVal(), typeError("unpack", "block!", args[1])
+	}
+
+	data := bin.Bytes()
+	elems := spec.Elements
+	pos := 0
+	fields := make(map[string]core.Value)
+	var names []string
+	var values []core.Value
+
+	// take returns the next spec token, or an error naming what was expected.
+	i := 0
+	take := func(what string) (core.Value, error) {
+		if i >= len(elems) {
+			return nil, packSpecError("unpack", "missing "+what+" at end of spec")
+		}
+		tok := elems[i]
+		i++
+		return tok, nil
+	}
+	takeName := func() (string, error) {
+		tok, err := take("field name")
+		if err != nil {
+			return "", err
+		}
+		if tok.GetType() != value.TypeWord {
+			return "", packSpecError("unpack", "expected a field name, got "+tok.Mold())
+		}
+		name, _ := value.AsWordValue(tok)
+		if _, dup := fields[name]; dup {
+			return "", packSpecError("unpack", "duplicate field '"+name+"'")
+		}
+		return name, nil
+	}
+
+	for i < len(elems) {
+		tok := elems[i]
+		i++
+		kind, ok := value.AsWordValue(tok)
+		if !ok || tok.GetType() != value.TypeWord {
+			return value.NewNoneVal(), packSpecError("unpack", "expected a field type, got "+tok.Mold())
+		}
+
+		switch kind {
+		case "pad", "bytes", "string":
+			countTok, err := take("count")
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			n, next, err := unpackCount(countTok, data, pos, fields)
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			pos = next
+			if len(data)-pos < n {
+				return value.NewNoneVal(), shortDataError(kind, n, len(data)-pos)
+			}
+			chunk := data[pos : pos+n]
+			pos += n
+			if kind == "pad" {
+				continue
+			}
+
+			name, err := takeName()
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			var field core.Value
+			if kind == "bytes" {
+				field = value.NewBinaryVal(bytes.Clone(chunk))
+			} else {
+				// Fixed-width text fields are commonly NUL padded.
+				field = value.NewStrVal(string(bytes.TrimRight(chunk, "\x00")))
+			}
+			fields[name] = field
+			names = append(names, name)
+			values = append(values, field)
+
+		default:
+			num, ok := parsePackNumber(kind)
+			if !ok {
+				return value.NewNoneVal(), packSpecError("unpack", "unknown field type '"+kind+"'")
+			}
+			name, err := takeName()
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			if len(data)-pos < num.size {
+				return value.NewNoneVal(), shortDataError(name, num.size, len(data)-pos)
+			}
+			field := decodeNumber(num, data[pos:pos+num.size])
+			pos += num.size
+			fields[name] = field
+			names = append(names, name)
+			values = append(values, field)
+		}
+	}
+
+	if hasRefinement(refValues, "block") {
+		return value.NewBlockVal(values), nil
+	}
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	for idx, name := range names {
+		objFrame.Bind(name, values[idx])
+	}
+	return value.ObjectVal(value.NewObject(objFrame)), nil
+}
+
+// packBytes returns the bytes of a bytes or string field value.
+func packBytes(kind string, v core.Value) ([]byte, error) {
+	if kind == "string" {
+		str, ok := value.AsStringValue(v)
+		if !ok {
+			return nil, typeError("pack", "string!", v)
+		}
+		return []byte(str.String()), nil
+	}
+	return codecBytes("pack", v)
+}
+
+// PackNative implements `pack`: builds a binary from a layout spec whose
+// fields are followed by value expressions. Fixed-size bytes and string
+// fields are zero padded; values longer than the field are an error.
+func PackNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("pack", 1, len(args))
+	}
+	spec, ok := value.AsBlockValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("pack", "block!", args[0])
+	}
+
+	elems := spec.Elements
+	locations := spec.Locations()
+	var out []byte
+
+	i := 0
+	next := func(what string) (core.Value, error) {
+		if i >= len(elems) {
+			return nil, packSpecError("pack", "missing "+what+" at end of spec")
+		}
+		n, val, err := eval.EvaluateExpression(elems, locations, i)
+		if err != nil {
+			return nil, err
+		}
+		i = n
+		return val, nil
+	}
+
+	for i < len(elems) {
+		tok := elems[i]
+		i++
+		kind, ok := value.AsWordValue(tok)
+		if !ok || tok.GetType() != value.TypeWord {
+			return value.NewNoneVal(), packSpecError("pack", "expected a field type, got "+tok.Mold())
+		}
+
+		switch kind {
+		case "pad", "bytes", "string":
+			// The count is a length prefix type, rest, or an expression.
+			var prefix *packNumber
+			rest := false
+			if kind != "pad" && i < len(elems) && elems[i].GetType() == value.TypeWord {
+				word, _ := value.AsWordValue(elems[i])
+				if num, isNum := parsePackNumber(word); isNum && !num.float {
+					prefix = &num
+					i++
+				} else if word == "rest" {
+					rest = true
+					i++
+				}
+			}
+			fixed := -1
+			if prefix == nil && !rest {
+				countVal, err := next("count")
+				if err != nil {
+					return value.NewNoneVal(), err
+				}
+				n, ok := value.AsIntValue(countVal)
+				if !ok {
+					return value.NewNoneVal(), typeError("pack", "integer!", countVal)
+				}
+				if n < 0 {
+					return value.NewNoneVal(), packSpecError("pack", "negative count "+strconv.FormatInt(n, 10))
+				}
+				fixed = int(n)
+			}
+			if kind == "pad" {
+				out = append(out, make([]byte, fixed)...)
+				continue
+			}
+
+			v, err := next("value")
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			chunk, err := packBytes(kind, v)
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			switch {
+			case prefix != nil:
+				out, err = encodeNumber(out, *prefix, value.NewIntVal(int64(len(chunk))))
+				if err != nil {
+					return value.NewNoneVal(), packSpecError("pack", fmt.Sprintf("%d bytes do not fit a %s length prefix", len(chunk), prefix.typName))
+				}
+				out = append(out, chunk...)
+			case fixed >= 0:
+				if len(chunk) > fixed {
+					return value.NewNoneVal(), packSpecError("pack", fmt.Sprintf("%s of %d bytes does not fit in %d", kind, len(chunk), fixed))
+				}
+				out = append(out, chunk...)
+				out = append(out, make([]byte, fixed-len(chunk))...)
+			default:
+				out = append(out, chunk...)
+			}
+
+		default:
+			num, ok := parsePackNumber(kind)
+			if !ok {
+				return value.NewNoneVal(), packSpecError("pack", "unknown field type '"+kind+"'")
+			}
+			v, err := next("value")
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			out, err = encodeNumber(out, num, v)
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+		}
+	}
+	return value.NewBinaryVal(out), nil
+}
@@ -0,0 +1,279 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// AllowExec gates subprocess execution through `call`.
+// Set during initialization from CLI flag --allow-exec; disabled by default
+// because a child process is not bound by the sandbox.
+var AllowExec bool
+
+// CallNative implements `call`: runs an external program and returns an object
+// with the fields exit-code, output and error.
+//
+// The command is a block of arguments passed directly to the program (no shell),
+// unless --shell is given, in which case it is a string run by the system shell.
+// A non-zero exit status is reported through exit-code, not as an error.
+func CallNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("call", 1, len(args))
+	}
+	if !AllowExec {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDExecDisabled,
+			[3]string{args[0].Form(), "", ""},
+		)
+	}
+
+	argv, err := callArgv(args[0], hasRefinement(refValues, "shell"))
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	ctx := context.Background()
+	if hasTimeout, timeoutVal := getRefinementValue(refValues, "timeout"); hasTimeout {
+		ms, ok := value.AsIntValue(timeoutVal)
+		if !ok {
+			return value.NewNoneVal(), typeError("call --timeout", "integer!", timeoutVal)
+		}
+		if ms <= 0 {
+			return value.NewNoneVal(), verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"call --timeout must be a positive number of milliseconds", "", ""},
+			)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(ms)*time.Millisecond)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	// Don't let grandchildren holding the output pipes outlive a timeout.
+	cmd.WaitDelay = time.Second
+
+	if hasDir, dirVal := getRefinementValue(refValues, "dir"); hasDir {
+		resolved, userPath, err := resolveFilePath("call --dir", dirVal)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		info, err := statOrNone("call", userPath, resolved)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		if info == nil || !info.IsDir() {
+			return value.NewNoneVal(), fileError("call", userPath, fmt.Errorf("not a directory"))
+		}
+		cmd.Dir = resolved
+	} else {
+		resolved, _, err := resolveFilePath("call", value.NewStrVal("."))
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		cmd.Dir = resolved
+	}
+
+	if hasEnv, envVal := getRefinementValue(refValues, "env"); hasEnv {
+		env, err := callEnv(envVal)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		cmd.Env = env
+	}
+
+	if hasInput, inputVal := getRefinementValue(refValues, "input"); hasInput {
+		switch inputVal.GetType() {
+		case value.TypeString:
+			str, _ := value.AsStringValue(inputVal)
+			cmd.Stdin = strings.NewReader(str.String())
+		case value.TypeBinary:
+			bin, _ := value.AsBinaryValue(inputVal)
+			cmd.Stdin = bytes.NewReader(bin.Bytes())
+		default:
+			return value.NewNoneVal(), typeError("call --input", "string! or binary!", inputVal)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if hasRefinement(refValues, "stream") {
+		cmd.Stdout = io.MultiWriter(&stdout, eval.GetOutputWriter())
+		cmd.Stderr = io.MultiWriter(&stderr, eval.GetErrorWriter())
+	} else {
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+	}
+
+	runErr := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDTimeout,
+			[3]string{fmt.Sprintf("call %s exceeded timeout", argv[0]), "", ""},
+		)
+	}
+
+	exitCode := 0
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return value.NewNoneVal(), verror.NewAccessError(
+				verror.ErrIDInvalidOperation,
+				[3]string{fmt.Sprintf("call failed: %v", runErr), argv[0], ""},
+			)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	return createCallResultObject(exitCode, stdout.String(), stderr.String()), nil
+}
+
+// callArgv converts the command argument into an argv slice.
+func callArgv(cmdVal core.Value, shell bool) ([]string, error) {
+	if shell {
+		str, ok := value.AsStringValue(cmdVal)
+		if !ok {
+			return nil, typeError("call --shell", "string!", cmdVal)
+		}
+		if runtime.GOOS == "windows" {
+			return []string{"cmd", "/C", str.String()}, nil
+		}
+		return []string{"/bin/sh", "-c", str.String()}, nil
+	}
+
+	block, ok := value.AsBlockValue(cmdVal)
+	if !ok {
+		return nil, typeError("call", "block!", cmdVal)
+	}
+	if block.Length() == 0 {
+		return nil, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"call requires a non-empty command block", "", ""},
+		)
+	}
+
+	argv := make([]string, block.Length())
+	for i := range block.Length() {
+		elem := block.At(i)
+		switch elem.GetType() {
+		case value.TypeString, value.TypeWord, value.TypeInteger, value.TypeBigInt, value.TypeDecimal, value.TypeFloat:
+			argv[i] = elem.Form()
+		default:
+			return nil, typeError("call", "block of string!, word! or number! values", elem)
+		}
+	}
+	return argv, nil
+}
+
+// callEnv builds the child environment: the current environment with the
+// fields of the given object applied on top. A none field removes the variable.
+func callEnv(envVal core.Value) ([]string, error) {
+	obj, ok := value.AsObject(envVal)
+	if !ok {
+		return nil, typeError("call --env", "object!", envVal)
+	}
+
+	overrides := make(map[string]core.Value)
+	for _, binding := range obj.GetAllFieldsWithProto() {
+		overrides[binding.Symbol] = binding.Value
+	}
+
+	env := make([]string, 0, len(os.Environ())+len(overrides))
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, overridden := overrides[name]; overridden {
+			continue
+		}
+		env = append(env, kv)
+	}
+	for _, binding := range obj.GetAllFieldsWithProto() {
+		if binding.Value.GetType() == value.TypeNone {
+			continue
+		}
+		env = append(env, binding.Symbol+"="+binding.Value.Form())
+	}
+	return env, nil
+}
+
+func createCallResultObject(exitCode int, output, errOutput string) core.Value {
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	objFrame.Bind("exit-code", value.NewIntVal(int64(exitCode)))
+	objFrame.Bind("output", value.NewStrVal(output))
+	objFrame.Bind("error", value.NewStrVal(errOutput))
+
+	obj := value.NewObject(objFrame)
+	return value.ObjectVal(obj)
+}
+
+// GetEnvNative implements `get-env`: the value of an environment variable, or none if unset.
+func GetEnvNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("get-env", 1, len(args))
+	}
+	name, err := envVarName("get-env", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return value.NewNoneVal(), nil
+	}
+	return value.NewStrVal(val), nil
+}
+
+// SetEnvNative implements `set-env`: sets an environment variable for this process
+// and programs started with call. Setting none removes the variable.
+func SetEnvNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("set-env", 2, len(args))
+	}
+	name, err := envVarName("set-env", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	if args[1].GetType() == value.TypeNone {
+		err = os.Unsetenv(name)
+	} else {
+		err = os.Setenv(name, args[1].Form())
+	}
+	if err != nil {
+		return value.NewNoneVal(), verror.NewAccessError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("set-env failed: %v", err), name, ""},
+		)
+	}
+	return args[1], nil
+}
+
+func envVarName(name string, arg core.Value) (string, error) {
+	var varName string
+	switch arg.GetType() {
+	case value.TypeString:
+		str, _ := value.AsStringValue(arg)
+		varName = str.String()
+	case value.TypeWord, value.TypeLitWord:
+		varName, _ = value.AsWordValue(arg)
+	default:
+		return "", typeError(name, "string! or word!", arg)
+	}
+	if varName == "" || strings.ContainsAny(varName, "=\x00") {
+		return "", verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("%s: invalid environment variable name %q", name, varName), "", ""},
+		)
+	}
+	return varName, nil
+}
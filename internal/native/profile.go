@@ -0,0 +1,100 @@
+package native
+
+import (
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/profile"
+	"github.com/marcin-radoszewski/viro/internal/value"
+)
+
+// callObserving is implemented by evaluators that report calls to an
+// eval.CallObserver and can count allocations.
+type callObserving interface {
+	CallObserver() eval.CallObserver
+	SetCallObserver(o eval.CallObserver)
+	AllocationAccounting() bool
+	SetAllocationAccounting(on bool)
+	Allocations() map[string]core.AllocStats
+}
+
+// callFanout passes calls on to two observers, so `profile` can run while
+// --profile is recording the whole script.
+type callFanout [2]eval.CallObserver
+
+func (f callFanout) EnterCall(name string) {
+	f[0].EnterCall(name)
+	f[1].EnterCall(name)
+}
+
+func (f callFanout) ExitCall() {
+	f[0].ExitCall()
+	f[1].ExitCall()
+}
+
+func (f callFanout) Allocated(kind core.AllocKind, n int) {
+	for _, o := range f {
+		if a, ok := o.(eval.AllocationObserver); ok {
+			a.Allocated(kind, n)
+		}
+	}
+}
+
+// Profile implements the `profile` native.
+//
+// Contract: profile [block] [--save file] [--allocs]
+//
+// Evaluates the block under a call-tree profiler, prints the report and
+// returns the block's result. With --save the profile is also written to
+// the file, as pprof for .pprof/.pb/.pb.gz names, JSON for .json and
+// collapsed stacks otherwise. --allocs counts allocations for the block
+// when accounting is not already on. The report is printed even when the
+// block fails.
+func Profile(args []core.Value, refValues map[string]core.Value, evaluator core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("profile", 1, len(args))
+	}
+	block, ok := value.AsBlockValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("profile", "block", args[0])
+	}
+
+	var resolved, userPath string
+	if save, ok := refValues["save"]; ok && save.GetType() != value.TypeNone {
+		var err error
+		if resolved, userPath, err = resolveFilePath("profile --save", save); err != nil {
+			return value.NewNoneVal(), err
+		}
+	}
+
+	observing, ok := evaluator.(callObserving)
+	if !ok {
+		return evaluator.DoBlock(block.Elements, block.Locations())
+	}
+
+	tree := profile.NewCallTree("profile")
+	previous := observing.CallObserver()
+	if previous != nil {
+		observing.SetCallObserver(callFanout{previous, tree})
+	} else {
+		observing.SetCallObserver(tree)
+	}
+	if allocs, ok := refValues["allocs"]; ok && ToTruthy(allocs) && !observing.AllocationAccounting() {
+		observing.SetAllocationAccounting(true)
+		defer observing.SetAllocationAccounting(false)
+	}
+	tree.Start()
+	result, err := evaluator.DoBlock(block.Elements, block.Locations())
+	tree.Stop()
+	observing.SetCallObserver(previous)
+
+	tree.FormatText(evaluator.GetOutputWriter())
+	if resolved != "" {
+		if writeErr := tree.WriteFile(resolved); writeErr != nil && err == nil {
+			err = fileError("profile --save", userPath, writeErr)
+		}
+	}
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package native
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"math/rand/v2"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// randomDigits is the number of decimal digits in a random decimal! fraction.
+const randomDigits = 18
+
+// cryptoSource is a rand.Source reading from the operating system's secure
+// generator, so random --secure can reuse the math/rand/v2 helpers.
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	cryptorand.Read(b[:])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// secureRandom draws from crypto/rand.
+var secureRandom = rand.New(cryptoSource{})
+
+// randomGenerator picks the generator for a random call: the secure one, the
+// evaluator's seeded one, or a fresh unseeded one when there is no evaluator.
+func randomGenerator(refValues map[string]core.Value, eval core.Evaluator) *rand.Rand {
+	if hasRefinement(refValues, "secure") {
+		return secureRandom
+	}
+	if eval == nil {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return eval.Random()
+}
+
+// randomBigBelow returns a uniform value in [0, limit) for positive limit,
+// by rejection sampling on the bit length of limit.
+func randomBigBelow(r *rand.Rand, limit *big.Int) *big.Int {
+	bitLen := limit.BitLen()
+	buf := make([]byte, (bitLen+7)/8)
+	n := new(big.Int)
+	for {
+		for i := 0; i < len(buf); i += 8 {
+			var word [8]byte
+			binary.BigEndian.PutUint64(word[:], r.Uint64())
+			copy(buf[i:], word[:])
+		}
+		// Clear the bits above bitLen so at most half the draws are rejected.
+		if extra := len(buf)*8 - bitLen; extra > 0 {
+			buf[0] &= byte(0xFF >> extra)
+		}
+		n.SetBytes(buf)
+		if n.Cmp(limit) < 0 {
+			return n
+		}
+	}
+}
+
+// randomInteger returns a value between 1 and n inclusive, between n and -1
+// for negative n, and 0 for 0.
+func randomInteger(r *rand.Rand, n int64) int64 {
+	switch {
+	case n > 0:
+		return 1 + r.Int64N(n)
+	case n < 0:
+		// Counting in uint64 keeps math.MinInt64 in range.
+		return -1 - int64(r.Uint64N(uint64(-(n+1))+1))
+	}
+	return 0
+}
+
+// RandomNative implements `random`. Numbers produce a value of the same type:
+// 1 to n for integer! and bigint!, [0, n) for decimal! and float!. Series
+// need --only, which picks one element from the current position, or
+// --shuffle, which permutes the whole series in place. --seed reseeds the
+// evaluator's generator with an integer, making later results repeatable.
+// --secure draws from crypto/rand instead.
+func RandomNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("random", 1, len(args))
+	}
+	arg := args[0]
+	seed := hasRefinement(refValues, "seed")
+	secure := hasRefinement(refValues, "secure")
+	only := hasRefinement(refValues, "only")
+	shuffle := hasRefinement(refValues, "shuffle")
+
+	if seed {
+		if secure || only || shuffle {
+			return value.NewNoneVal(), verror.NewScriptError(
+				verror.ErrIDInvalidOperation,
+				[3]string{"random --seed cannot be combined with other refinements", "", ""},
+			)
+		}
+		n, ok := value.AsIntValue(arg)
+		if !ok {
+			return value.NewNoneVal(), typeError("random --seed", "integer!", arg)
+		}
+		if eval != nil {
+			eval.SeedRandom(uint64(n))
+		}
+		return value.NewNoneVal(), nil
+	}
+	if only && shuffle {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"random: --only and --shuffle cannot be used together", "", ""},
+		)
+	}
+
+	r := randomGenerator(refValues, eval)
+
+	if series, ok := arg.(value.Series); ok {
+		return randomSeries(r, series, only, shuffle)
+	}
+	if only || shuffle {
+		return value.NewNoneVal(), typeError("random", "series!", arg)
+	}
+
+	switch arg.GetType() {
+	case value.TypeInteger:
+		n, _ := value.AsIntValue(arg)
+		return value.NewIntVal(randomInteger(r, n)), nil
+	case value.TypeBigInt:
+		n, _ := value.AsBigIntValue(arg)
+		if n.Sign() == 0 {
+			return arg, nil
+		}
+		limit := new(big.Int).Abs(n)
+		result := randomBigBelow(r, limit)
+		result.Add(result, big.NewInt(1))
+		if n.Sign() < 0 {
+			result.Neg(result)
+		}
+		return value.NewBigIntVal(result), nil
+	case value.TypeFloat:
+		f, _ := value.AsFloatValue(arg)
+		return value.NewFloatVal(r.Float64() * f), nil
+	case value.TypeDecimal:
+		limit := promoteToDecimal(arg, nil, nil)
+		fraction := decimal.New(int64(r.Uint64N(1_000_000_000_000_000_000)), randomDigits)
+		ctx := arithmeticContext(activeDecimalContext(eval))
+		result := new(decimal.Big)
+		ctx.Mul(result, fraction, limit)
+		scale := randomDigits
+		if limitScale := limit.Scale(); limitScale > 0 {
+			scale += limitScale
+		}
+		if scale > maxDecimalPrecision {
+			scale = maxDecimalPrecision
+		}
+		return value.DecimalVal(result, int16(scale)), nil
+	}
+	return value.NewNoneVal(), typeError("random", "number! or series!", arg)
+}
+
+// randomSeries handles random on a series.
+func randomSeries(r *rand.Rand, series value.Series, only, shuffle bool) (core.Value, error) {
+	switch {
+	case only:
+		remaining := series.Length() - series.GetIndex()
+		if remaining <= 0 {
+			return value.NewNoneVal(), nil
+		}
+		return series.ElementAt(series.GetIndex() + r.IntN(remaining)), nil
+	case shuffle:
+		if block, ok := value.AsBlockValue(series); ok {
+			value.ShuffleBlock(block, r.Shuffle)
+		} else if str, ok := value.AsStringValue(series); ok {
+			value.ShuffleString(str, r.Shuffle)
+		} else if bin, ok := value.AsBinaryValue(series); ok {
+			value.ShuffleBinary(bin, r.Shuffle)
+		} else {
+			return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDActionNoImpl, [3]string{value.TypeToString(series.GetType()), "", ""})
+		}
+		return series, nil
+	}
+	return value.NewNoneVal(), verror.NewScriptError(
+		verror.ErrIDInvalidOperation,
+		[3]string{"random on a series needs --only or --shuffle", "", ""},
+	)
+}
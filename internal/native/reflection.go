@@ -116,7 +116,7 @@ func BodyOf(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 		bindings := obj.GetAllFieldsWithProto()
 		for _, binding := range bindings {
 			bodyElements = append(bodyElements, value.NewSetWordVal(binding.Symbol))
-			bodyElements = append(bodyElements, value.NewNoneVal()) // Placeholder
+			bodyElements = append(bodyElements, copyValue(binding.Value))
 		}
 		return value.NewBlockVal(bodyElements), nil
 
@@ -128,6 +128,22 @@ func BodyOf(args []core.Value, refValues map[string]core.Value, eval core.Evalua
 	}
 }
 
+// copyValue returns a copy of v that shares no series with it, so the
+// block body-of returns cannot change the object it describes.
+func copyValue(v core.Value) core.Value {
+	if block, ok := value.AsBlockValue(v); ok {
+		clone := block.Clone().(*value.BlockValue)
+		for i, elem := range clone.Elements {
+			clone.Elements[i] = copyValue(elem)
+		}
+		return clone
+	}
+	if series, ok := v.(value.Series); ok {
+		return series.Clone()
+	}
+	return v
+}
+
 // WordsOf implements the `words-of` native (T158).
 //
 // Contract: words-of value -> block! of words
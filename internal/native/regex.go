@@ -0,0 +1,295 @@
+package native
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Regular expression support for string!. Patterns are plain strings using
+// Go's RE2 syntax, so matching runs in linear time and never backtracks.
+// All positions returned to Viro are 1-based character (rune) indexes.
+
+const regexCacheLimit = 256
+
+var (
+	regexCacheMu sync.Mutex
+	regexCache   = make(map[string]*regexp.Regexp)
+)
+
+// compileRegex compiles a pattern, reusing previously compiled expressions.
+func compileRegex(name string, pattern core.Value) (*regexp.Regexp, error) {
+	str, ok := value.AsStringValue(pattern)
+	if !ok {
+		return nil, typeError(name, "string!", pattern)
+	}
+	src := str.String()
+
+	regexCacheMu.Lock()
+	defer regexCacheMu.Unlock()
+
+	if re, ok := regexCache[src]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(src)
+	if err != nil {
+		return nil, verror.NewScriptError(
+			verror.ErrIDInvalidRegex,
+			[3]string{str.Mold(), err.Error(), ""},
+		)
+	}
+	if len(regexCache) >= regexCacheLimit {
+		clear(regexCache)
+	}
+	regexCache[src] = re
+	return re, nil
+}
+
+// regexSubject returns the string argument matched against a pattern.
+func regexSubject(name string, arg core.Value) (*value.StringValue, error) {
+	str, ok := value.AsStringValue(arg)
+	if !ok {
+		return nil, typeError(name, "string!", arg)
+	}
+	return str, nil
+}
+
+// runeIndex converts a byte offset in s into a 1-based rune index.
+func runeIndex(s string, byteOffset int) int64 {
+	return int64(utf8.RuneCountInString(s[:byteOffset]) + 1)
+}
+
+// captureBlock builds [whole group1 group2 ...] from submatch byte offsets.
+// Groups that did not participate in the match are none.
+func captureBlock(s string, loc []int) *value.BlockValue {
+	elements := make([]core.Value, len(loc)/2)
+	for i := range elements {
+		start, end := loc[2*i], loc[2*i+1]
+		if start < 0 {
+			elements[i] = value.NewNoneVal()
+		} else {
+			elements[i] = value.NewStrVal(s[start:end])
+		}
+	}
+	return value.NewBlockValue(elements)
+}
+
+// captureObject builds an object with one field per named group.
+func captureObject(re *regexp.Regexp, s string, loc []int) core.Value {
+	objFrame := frame.NewFrame(frame.FrameObject, -1)
+	for i, groupName := range re.SubexpNames() {
+		if i == 0 || groupName == "" {
+			continue
+		}
+		start, end := loc[2*i], loc[2*i+1]
+		if start < 0 {
+			objFrame.Bind(groupName, value.NewNoneVal())
+		} else {
+			objFrame.Bind(groupName, value.NewStrVal(s[start:end]))
+		}
+	}
+	return value.ObjectVal(value.NewObject(objFrame))
+}
+
+// rejectRegex reports an error when --regex is used with a non-string series.
+func rejectRegex(name string, refValues map[string]core.Value, series core.Value) error {
+	if hasRefinement(refValues, "regex") {
+		return typeError(name+" --regex", "string!", series)
+	}
+	return nil
+}
+
+// regexFind implements `find --regex`: the 1-based index of the first (or last) match.
+func regexFind(str *value.StringValue, pattern core.Value, last bool) (core.Value, error) {
+	re, err := compileRegex("find", pattern)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	s := str.String()
+
+	if !last {
+		loc := re.FindStringIndex(s)
+		if loc == nil {
+			return value.NewNoneVal(), nil
+		}
+		return value.NewIntVal(runeIndex(s, loc[0])), nil
+	}
+
+	all := re.FindAllStringIndex(s, -1)
+	if len(all) == 0 {
+		return value.NewNoneVal(), nil
+	}
+	return value.NewIntVal(runeIndex(s, all[len(all)-1][0])), nil
+}
+
+// MatchesNative implements `matches?`: true when the pattern matches anywhere
+// in the string. Anchor the pattern with ^ and $ to require a full match.
+func MatchesNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("matches?", 2, len(args))
+	}
+	str, err := regexSubject("matches?", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	re, err := compileRegex("matches?", args[1])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return value.NewLogicVal(re.MatchString(str.String())), nil
+}
+
+// MatchNative implements `match`: the captures of the first match as a block
+// [whole group1 ...], or none when the pattern does not match.
+//
+// Refinements:
+//
+//	--all: a block of capture blocks, one per non-overlapping match
+//	--named: an object of the named groups instead of a block
+func MatchNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("match", 2, len(args))
+	}
+	str, err := regexSubject("match", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	re, err := compileRegex("match", args[1])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	s := str.String()
+	named := hasRefinement(refValues, "named")
+
+	capture := func(loc []int) core.Value {
+		if named {
+			return captureObject(re, s, loc)
+		}
+		return captureBlock(s, loc)
+	}
+
+	if hasRefinement(refValues, "all") {
+		all := re.FindAllStringSubmatchIndex(s, -1)
+		results := make([]core.Value, len(all))
+		for i, loc := range all {
+			results[i] = capture(loc)
+		}
+		return value.NewBlockVal(results), nil
+	}
+
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return value.NewNoneVal(), nil
+	}
+	return capture(loc), nil
+}
+
+// ReplaceNative implements `replace`: replaces the first occurrence of search in
+// the target string (every occurrence with --all), modifying the target in place.
+//
+// A literal search is case-sensitive unless --ignore-case is given. With
+// --regex, search is a pattern (case handled by the pattern itself, e.g. (?i))
+// and a string replacement may refer to groups as $1 or ${name}.
+// The replacement is either a string or a function.
+// A function replacement is called once per match with the capture block
+// [whole group1 ...] and its result is formed into the output.
+func ReplaceNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 3 {
+		return value.NewNoneVal(), arityError("replace", 3, len(args))
+	}
+	str, err := regexSubject("replace", args[0])
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	replacement := args[2]
+	replFn := replacement.GetType() == value.TypeFunction
+	replStr, isStr := value.AsStringValue(replacement)
+	if !replFn && !isStr {
+		return value.NewNoneVal(), typeError("replace", "string! or function!", replacement)
+	}
+
+	var re *regexp.Regexp
+	if hasRefinement(refValues, "regex") {
+		re, err = compileRegex("replace", args[1])
+	} else {
+		re, err = literalRegex(args[1], !hasRefinement(refValues, "ignore-case"))
+	}
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	s := str.String()
+	limit := 1
+	if hasRefinement(refValues, "all") {
+		limit = -1
+	}
+	matches := re.FindAllStringSubmatchIndex(s, limit)
+	if len(matches) == 0 {
+		return str, nil
+	}
+
+	var out strings.Builder
+	prev := 0
+	for _, loc := range matches {
+		out.WriteString(s[prev:loc[0]])
+		switch {
+		case replFn:
+			result, err := eval.CallFunction(replacement, []core.Value{captureBlock(s, loc)})
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			out.WriteString(result.Form())
+		case hasRefinement(refValues, "regex"):
+			out.Write(re.ExpandString(nil, replStr.String(), s, loc))
+		default:
+			out.WriteString(replStr.String())
+		}
+		prev = loc[1]
+	}
+	out.WriteString(s[prev:])
+
+	str.SetRunes([]rune(out.String()))
+	return str, nil
+}
+
+// literalRegex compiles a search string as a literal pattern so that replace
+// shares one code path for literal and regex searches. Unless caseSensitive is
+// set the pattern uses Unicode case folding.
+func literalRegex(search core.Value, caseSensitive bool) (*regexp.Regexp, error) {
+	str, ok := value.AsStringValue(search)
+	if !ok {
+		return nil, typeError("replace", "string!", search)
+	}
+	if str.String() == "" {
+		return nil, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"replace: empty search string not allowed", "", ""},
+		)
+	}
+	pattern := regexp.QuoteMeta(str.String())
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.MustCompile(pattern), nil
+}
+
+// regexSplit implements `split --regex`: splits a string on every match.
+func regexSplit(str *value.StringValue, pattern core.Value) (core.Value, error) {
+	re, err := compileRegex("split", pattern)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	parts := re.Split(str.String(), -1)
+	elements := make([]core.Value, len(parts))
+	for i, part := range parts {
+		elements[i] = value.NewStrVal(part)
+	}
+	return value.NewBlockVal(elements), nil
+}
@@ -29,9 +29,9 @@ For binaries: byte-by-byte AND from right (LSB first), zeros remaining bytes fro
 			},
 			Returns: "Same type as input",
 			Examples: []string{
-				"2 bit.and 3  ; => 2",
-				"#{FF00} bit.and #{0FF0}  ; => #{0F00}",
-				"#{FFFF} bit.and #{FF}  ; => #{00FF}",
+				"bit.and 2 3  ; => 2",
+				"#{FF00} & #{0FF0}  ; => #{0F00}",
+				"#{FFFF} & #{FF}  ; => #{00FF}",
 			},
 			SeeAlso: []string{"bit.or", "bit.xor", "bit.not"},
 			Tags:    []string{"bitwise", "logic"},
@@ -61,9 +61,9 @@ For binaries: byte-by-byte OR from right (LSB first), copies remaining bytes fro
 			},
 			Returns: "Same type as input",
 			Examples: []string{
-				"2 bit.or 4  ; => 6",
-				"#{0F00} bit.or #{F00F}  ; => #{FF0F}",
-				"#{FFFF} bit.or #{FF}  ; => #{FFFF}",
+				"bit.or 2 4  ; => 6",
+				"#{0F00} | #{F00F}  ; => #{FF0F}",
+				"#{FFFF} | #{FF}  ; => #{FFFF}",
 			},
 			SeeAlso: []string{"bit.and", "bit.xor", "bit.not"},
 			Tags:    []string{"bitwise", "logic"},
@@ -93,9 +93,9 @@ For binaries: byte-by-byte XOR from right (LSB first), copies remaining bytes fr
 			},
 			Returns: "Same type as input",
 			Examples: []string{
-				"6 bit.xor 3  ; => 5",
-				"#{FF00} bit.xor #{0FF0}  ; => #{F0F0}",
-				"#{FFFF} bit.xor #{FF}  ; => #{FF00}",
+				"bit.xor 6 3  ; => 5",
+				"#{FF00} ^ #{0FF0}  ; => #{F0F0}",
+				"#{FFFF} ^ #{FF}  ; => #{FF00}",
 			},
 			SeeAlso: []string{"bit.and", "bit.or", "bit.not"},
 			Tags:    []string{"bitwise", "logic"},
@@ -160,10 +160,10 @@ Left shift by N positions is equivalent to multiplying by 2^N for integers.`,
 			},
 			Returns: "Same type as input value",
 			Examples: []string{
-				"1 bit.shl 3  ; => 8",
-				"#{01} bit.shl 2  ; => #{04}",
-				"#{80} bit.shl 1  ; => #{00} (overflow lost)",
-				"#{0100} bit.shl 8  ; => #{0001} (multi-byte shift)",
+				"bit.shl 1 3  ; => 8",
+				"#{01} << 2  ; => #{04}",
+				"#{80} << 1  ; => #{00} (overflow lost)",
+				"#{0100} << 8  ; => #{0001} (multi-byte shift)",
 			},
 			SeeAlso: []string{"bit.shr", "<<", ">>"},
 			Tags:    []string{"bitwise", "shift"},
@@ -198,10 +198,10 @@ Right shift by N positions is equivalent to dividing by 2^N for integers (with t
 			},
 			Returns: "Same type as input value",
 			Examples: []string{
-				"8 bit.shr 2  ; => 2",
-				"#{08} bit.shr 2  ; => #{02}",
-				"#{01} bit.shr 1  ; => #{00} (underflow lost)",
-				"#{0080} bit.shr 8  ; => #{8000} (multi-byte shift)",
+				"bit.shr 8 2  ; => 2",
+				"#{08} >> 2  ; => #{02}",
+				"#{01} >> 1  ; => #{00} (underflow lost)",
+				"#{0080} >> 8  ; => #{8000} (multi-byte shift)",
 			},
 			SeeAlso: []string{"bit.shl", "<<", ">>"},
 			Tags:    []string{"bitwise", "shift"},
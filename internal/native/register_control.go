@@ -171,7 +171,7 @@ Be careful to avoid infinite loops.`,
 				"foreach [a b c] --with-index 'pos [print pos]  ; prints: 0 1 2",
 				"foreach [10 20 30] --with-index 'i [v] [print [i v]]  ; prints: [0 10] [1 20] [2 30]",
 			},
-			SeeAlso: []string{"loop", "while", "map", "filter"},
+			SeeAlso: []string{"loop", "while"},
 			Tags:    []string{"control", "iteration", "loop", "foreach"},
 		},
 	))
@@ -190,13 +190,15 @@ Be careful to avoid infinite loops.`,
 			Summary:  "Creates a new function",
 			Description: `Defines a new function with parameters and a body. The first argument is a block
 containing parameter names, and the second is a block containing the function body code.
-Returns a function value that can be called. Functions capture their defining context (closure).`,
+Returns a function value that can be called. Functions capture their defining context (closure).
+A string before the parameters documents the function for ? and viro doc: its first line is
+the summary and lines annotated with "; =>" are examples. A string after a parameter describes it.`,
 			Parameters: []ParamDoc{
 				{Name: "params", Type: "block!", Description: "A block of parameter names (words)", Optional: false},
 				{Name: "body", Type: "block!", Description: "A block of code to execute when the function is called", Optional: false},
 			},
 			Returns:  "[function!] The newly created function",
-			Examples: []string{"square: fn [n] [n * n]  ; => function", "add: fn [a b] [a + b]\nadd 3 4  ; => 7", "greet: fn [name] [print [\"Hello\" name]]\ngreet \"Alice\"  ; prints: Hello Alice"},
+			Examples: []string{"square: fn [n] [n * n]  ; => function[square]", "add: fn [a b] [a + b]\nadd 3 4  ; => 7", "greet: fn [name] [print [\"Hello\" name]]\ngreet \"Alice\"  ; prints: Hello Alice", "half: fn [\"Halves n\" n \"An integer\"] [n / 2]\nhalf 8  ; => 4"},
 			SeeAlso:  []string{"set", "get"}, Tags: []string{"function", "definition", "lambda", "closure"},
 		},
 	))
@@ -250,7 +252,7 @@ Refinements:
 				"print head? b  ; prints false",
 				"print index? b  ; prints 3",
 			},
-			SeeAlso: []string{"reduce", "compose", "eval"},
+			SeeAlso: []string{"reduce", "compose"},
 			Tags:    []string{"control", "evaluation", "do"},
 		},
 	))
@@ -313,4 +315,151 @@ Refinements:
 			Tags:     []string{"control", "function", "return"},
 		},
 	))
+
+	registerAndBind("exit", value.NewNativeFunction(
+		"exit",
+		[]value.ParamSpec{
+			value.NewRefinementSpec("code", true),
+		},
+		Exit,
+		false,
+		&NativeDoc{
+			Category:    "Control",
+			Summary:     "Stops the program with an exit status",
+			Description: "Stops evaluation immediately and ends the program with exit status 0, or the status given with --code (0-255). The signal passes through functions and loops. When running a script or -c expression, the status becomes the process exit code; in the REPL it ends the session.\n\nRefinements:\n  --code N: Exit with status N instead of 0",
+			Parameters: []ParamDoc{
+				{Name: "--code", Type: "integer!", Description: "The exit status (defaults to 0)", Optional: true},
+			},
+			Returns:  "[none!] Does not return",
+			Examples: []string{"when empty? system.args [print \"usage: tool FILE\" exit --code 64]  ; exits with status 64", "exit  ; exits with status 0"},
+			SeeAlso:  []string{"quit", "return"},
+			Tags:     []string{"control", "exit", "status"},
+		},
+	))
+
+	registerAndBind("quit", value.NewNativeFunction(
+		"quit",
+		[]value.ParamSpec{
+			value.NewRefinementSpec("code", true),
+		},
+		Exit,
+		false,
+		&NativeDoc{
+			Category:    "Control",
+			Summary:     "Stops the program with an exit status (synonym of exit)",
+			Description: "Same as exit: stops evaluation immediately and ends the program with exit status 0, or the status given with --code (0-255).",
+			Parameters: []ParamDoc{
+				{Name: "--code", Type: "integer!", Description: "The exit status (defaults to 0)", Optional: true},
+			},
+			Returns:  "[none!] Does not return",
+			Examples: []string{"quit --code 2  ; exits with status 2"},
+			SeeAlso:  []string{"exit"},
+			Tags:     []string{"control", "exit", "quit", "status"},
+		},
+	))
+
+	registerAndBind("assert", value.NewNativeFunction(
+		"assert",
+		[]value.ParamSpec{
+			value.NewParamSpec("condition", true),
+			value.NewRefinementSpec("message", true),
+		},
+		Assert,
+		false,
+		&NativeDoc{
+			Category: "Testing",
+			Summary:  "Fails unless a condition is true",
+			Description: `Raises an assertion-failed error when the condition is falsy (false or none). A block
+condition is evaluated first and its source is quoted in the error message, which makes failures
+easier to read than those of a plain value.
+
+Refinements:
+  --message text: Use text as the failure message instead.`,
+			Parameters: []ParamDoc{
+				{Name: "condition", Type: "any-type!", Description: "The value to test, or a block to evaluate", Optional: false},
+				{Name: "--message", Type: "string!", Description: "Message to report on failure", Optional: true},
+			},
+			Returns:  "[logic!] true when the assertion holds",
+			Examples: []string{"assert [1 + 1 = 2]  ; => true", "assert --message \"list must not be empty\" not empty? items", "assert [x > 10]  ; ** Script error: Assertion failed: [x > 10]"},
+			SeeAlso:  []string{"assert-equal", "assert-error", "test"},
+			Tags:     []string{"testing", "assert"},
+		},
+	))
+
+	registerAndBind("assert-equal", value.NewNativeFunction(
+		"assert-equal",
+		[]value.ParamSpec{
+			value.NewParamSpec("expected", true),
+			value.NewParamSpec("actual", true),
+		},
+		AssertEqual,
+		false,
+		&NativeDoc{
+			Category: "Testing",
+			Summary:  "Fails unless two values are equal",
+			Description: `Compares the values the way = does and raises an assertion-failed error when they differ.
+The message shows both values molded, with a caret under the first difference, or a line-by-line
+comparison when the molded values span several lines.`,
+			Parameters: []ParamDoc{
+				{Name: "expected", Type: "any-type!", Description: "The expected value", Optional: false},
+				{Name: "actual", Type: "any-type!", Description: "The value produced by the code under test", Optional: false},
+			},
+			Returns:  "[logic!] true when the values are equal",
+			Examples: []string{"assert-equal 6 (2 * 3)  ; => true", "assert-equal [1 2 3] reverse [3 2 1]  ; => true"},
+			SeeAlso:  []string{"assert", "assert-error", "="},
+			Tags:     []string{"testing", "assert", "equality"},
+		},
+	))
+
+	registerAndBind("assert-error", value.NewNativeFunction(
+		"assert-error",
+		[]value.ParamSpec{
+			value.NewParamSpec("block", false),
+			value.NewRefinementSpec("id", true),
+		},
+		AssertError,
+		false,
+		&NativeDoc{
+			Category: "Testing",
+			Summary:  "Fails unless a block raises an error",
+			Description: `Evaluates the block and raises an assertion-failed error if it completes normally. On
+success returns the message of the error the block raised.
+
+Refinements:
+  --id 'word: The error must also have this id (for example 'div-zero or 'type-mismatch).`,
+			Parameters: []ParamDoc{
+				{Name: "block", Type: "block!", Description: "The code expected to fail", Optional: false},
+				{Name: "--id", Type: "word!", Description: "The expected error id", Optional: true},
+			},
+			Returns:  "[string!] The message of the raised error",
+			Examples: []string{"assert-error [1 / 0]  ; => \"Division by zero\"", "assert-error --id 'div-zero [10 / 0]"},
+			SeeAlso:  []string{"assert", "assert-equal", "test"},
+			Tags:     []string{"testing", "assert", "error"},
+		},
+	))
+
+	registerAndBind("test", value.NewNativeFunction(
+		"test",
+		[]value.ParamSpec{
+			value.NewParamSpec("name", true),
+			value.NewParamSpec("body", false),
+		},
+		Test,
+		false,
+		&NativeDoc{
+			Category: "Testing",
+			Summary:  "Defines a named test case",
+			Description: `Declares a test case in a *_test.viro file. Under viro test, cases are collected while the
+file loads and then run one by one, each in a fresh frame, and reported by name. Run as an ordinary
+script, test simply evaluates the body in a fresh frame.`,
+			Parameters: []ParamDoc{
+				{Name: "name", Type: "string!", Description: "The name the case is reported under", Optional: false},
+				{Name: "body", Type: "block!", Description: "The code of the test case", Optional: false},
+			},
+			Returns:  "[any-type!] The result of the body",
+			Examples: []string{"test \"addition\" [\n    assert-equal 4 (2 + 2)\n]"},
+			SeeAlso:  []string{"assert", "assert-equal", "assert-error"},
+			Tags:     []string{"testing", "test"},
+		},
+	))
 }
@@ -45,7 +45,7 @@ The word is not evaluated; the value is evaluated before assignment. Returns the
 			},
 			Returns:  "[any-type!] The value that was assigned",
 			Examples: []string{"set 'x 42  ; => 42 (x is now 42)", "set 'name \"Alice\"  ; => \"Alice\"", "set 'data [1 2 3]  ; => [1 2 3]"},
-			SeeAlso:  []string{"get", ":", "type?"}, Tags: []string{"data", "assignment", "variable"},
+			SeeAlso:  []string{"get", "type?"}, Tags: []string{"data", "assignment", "variable"},
 		},
 	))
 
@@ -66,7 +66,7 @@ The word is not evaluated. Raises an error if the word is not bound to a value.`
 			},
 			Returns:  "[any-type!] The value bound to the word",
 			Examples: []string{"x: 42\nget 'x  ; => 42", "name: \"Bob\"\nget 'name  ; => \"Bob\""},
-			SeeAlso:  []string{"set", ":", "type?"}, Tags: []string{"data", "access", "variable"},
+			SeeAlso:  []string{"set", "type?"}, Tags: []string{"data", "access", "variable"},
 		},
 	))
 
@@ -199,7 +199,7 @@ into a new block. This is useful for computing values dynamically and building d
 				{Name: "block", Type: "block!", Description: "The block containing elements to evaluate", Optional: false},
 			},
 			Returns:  "[block!] A new block containing the evaluated results",
-			Examples: []string{"reduce [1 2 3]  ; => [1 2 3]", "reduce [1 + 2, 3 * 4]  ; => [3, 12]", "reduce []  ; => []"},
+			Examples: []string{"reduce [1 2 3]  ; => [1 2 3]", "reduce [1 + 2 3 * 4]  ; => [3 12]", "reduce []  ; => []"},
 			SeeAlso:  []string{"form", "mold"}, Tags: []string{"data", "evaluation", "block", "reduce"},
 		},
 	))
@@ -222,7 +222,7 @@ Returns the newly created object.`,
 				{Name: "spec", Type: "block!", Description: "A block containing word definitions to become object fields", Optional: false},
 			},
 			Returns:  "[object!] The newly created object",
-			Examples: []string{"obj: object [x: 10 y: 20]  ; => object with fields x and y", "person: object [name: \"Alice\" age: 30]"},
+			Examples: []string{"obj: object [x: 10 y: 20]  ; => make object! [x: 10 y: 20]", "person: object [name: \"Alice\" age: 30]"},
 			SeeAlso:  []string{"context", "make"}, Tags: []string{"objects", "context", "creation"},
 		},
 	))
@@ -268,7 +268,7 @@ new or overriding field definitions. The new object shares the parent's fields b
 				{Name: "spec", Type: "block!", Description: "A block of field definitions to add or override", Optional: false},
 			},
 			Returns:  "[object!] The newly created derived object",
-			Examples: []string{"base: object [x: 1 y: 2]\nderived: make base [z: 3]  ; => object with x, y, z", "point: object [x: 0 y: 0]\npoint3d: make point [z: 0]"},
+			Examples: []string{"base: object [x: 1 y: 2]\nderived: make base [z: 3]  ; => make object! [x: 1 y: 2 z: 3]", "point: object [x: 0 y: 0]\npoint3d: make point [z: 0]"},
 			SeeAlso:  []string{"object", "context"}, Tags: []string{"objects", "inheritance", "derivation"},
 		},
 	))
@@ -307,7 +307,7 @@ Returns the assigned value.`,
 			},
 			Returns: "[any-type!] The assigned value",
 			Examples: []string{
-				"obj: object [x: 10 y: 20]\nput obj 'x 42  ; => 42, obj.x is now 42",
+				"obj: object [x: 10 y: 20]\nput obj 'x 42  ; => 42\nobj.x  ; => 42",
 				"person: object [name: \"Alice\" age: 30]\nput person 'age 31",
 			},
 			SeeAlso: []string{"select", "set", "object"},
@@ -325,18 +325,42 @@ Returns the assigned value.`,
 		&NativeDoc{
 			Category: "Data",
 			Summary:  "Converts a value to an integer",
-			Description: `Converts integer (pass-through), decimal (truncate), or string (parse) to integer.
-Decimal values are truncated towards zero. String values must contain valid integer format.`,
+			Description: `Converts integer (pass-through), bigint, decimal or float (truncate), or string (parse) to integer.
+Decimal and float values are truncated towards zero. NaN, infinities and values outside
+the integer range raise a math error. String values must contain valid integer format.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal! string!", Description: "The value to convert", Optional: false},
+				{Name: "value", Type: "integer! bigint! decimal! float! string!", Description: "The value to convert", Optional: false},
 			},
 			Returns:  "[integer!] The converted integer value",
-			Examples: []string{"to-integer 42  ; => 42", "to-integer 3.7  ; => 3", `to-integer "123"  ; => 123`},
-			SeeAlso:  []string{"to-decimal", "to-string", "type?"},
+			Examples: []string{"to-integer 42  ; => 42", "to-integer 3.7  ; => 3", "to-integer -2.5f  ; => -2", `to-integer "123"  ; => 123`},
+			SeeAlso:  []string{"to-bigint", "to-decimal", "to-float", "to-string", "type?"},
 			Tags:     []string{"data", "conversion", "type"},
 		},
 	))
 
+	registerAndBind("to-bigint", value.NewNativeFunction(
+		"to-bigint",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true), // evaluated
+		},
+		ToBigInt,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Converts a value to an arbitrary-precision integer",
+			Description: `Converts integer (exact), bigint (pass-through), decimal or float (truncate), or string (parse)
+to bigint!. Arithmetic on bigint! never overflows. Strings may end in the literal's n suffix.
+NaN and infinities raise a math error.`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "integer! bigint! decimal! float! string!", Description: "The value to convert", Optional: false},
+			},
+			Returns:  "[bigint!] The converted bigint value",
+			Examples: []string{"to-bigint 42  ; => 42n", "to-bigint 3.7  ; => 3n", `to-bigint "123456789012345678901234567890"  ; => 123456789012345678901234567890n`},
+			SeeAlso:  []string{"to-integer", "to-decimal", "to-string", "type?"},
+			Tags:     []string{"data", "conversion", "type", "bigint"},
+		},
+	))
+
 	registerAndBind("to-decimal", value.NewNativeFunction(
 		"to-decimal",
 		[]value.ParamSpec{
@@ -347,18 +371,86 @@ Decimal values are truncated towards zero. String values must contain valid inte
 		&NativeDoc{
 			Category: "Data",
 			Summary:  "Converts a value to a decimal",
-			Description: `Converts integer (exact), decimal (pass-through), or string (parse) to decimal.
-Integer values are converted to exact decimal representation. String values must contain valid decimal format.`,
+			Description: `Converts integer or bigint (exact), decimal (pass-through), float, or string (parse) to decimal.
+Integer values are converted to exact decimal representation. Floats convert through their
+shortest representation, so 0.1f becomes 0.1; NaN and infinities raise a math error.
+String values must contain valid decimal format.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal! string!", Description: "The value to convert", Optional: false},
+				{Name: "value", Type: "integer! bigint! decimal! float! string!", Description: "The value to convert", Optional: false},
 			},
 			Returns:  "[decimal!] The converted decimal value",
-			Examples: []string{"to-decimal 42  ; => 42.0", "to-decimal 3.7  ; => 3.7", `to-decimal "12.34"  ; => 12.34`},
-			SeeAlso:  []string{"to-integer", "to-string", "type?"},
+			Examples: []string{"to-decimal 42  ; => 42", "to-decimal 3.7  ; => 3.7", "to-decimal 0.1f  ; => 0.1", `to-decimal "12.34"  ; => 12.34`},
+			SeeAlso:  []string{"to-integer", "to-float", "to-string", "type?"},
 			Tags:     []string{"data", "conversion", "type"},
 		},
 	))
 
+	registerAndBind("to-float", value.NewNativeFunction(
+		"to-float",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true), // evaluated
+		},
+		ToFloat,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Converts a value to a 64-bit float",
+			Description: `Converts integer, bigint, decimal, float (pass-through), or string (parse) to an IEEE-754
+binary64 float. Bigints and decimals are rounded to the nearest float. Strings may use the f suffix
+and may also be "nan", "inf" or "-inf", which is how those values are created.`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "integer! bigint! decimal! float! string!", Description: "The value to convert", Optional: false},
+			},
+			Returns:  "[float!] The converted float value",
+			Examples: []string{"to-float 3  ; => 3.0f", "to-float 0.1  ; => 0.1f", `to-float "2.5"  ; => 2.5f`, `to-float "inf"  ; => inf`},
+			SeeAlso:  []string{"to-integer", "to-decimal", "nan?", "infinite?"},
+			Tags:     []string{"data", "conversion", "type", "float"},
+		},
+	))
+
+	registerAndBind("nan?", value.NewNativeFunction(
+		"nan?",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+		},
+		NanQ,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Returns true if the value is a float NaN",
+			Description: `Tests for the IEEE-754 not-a-number value. NaN is not equal to anything,
+itself included, so = cannot detect it. Returns false for every non-float value.`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "any-type!", Description: "The value to test", Optional: false},
+			},
+			Returns:  "[logic!] True if value is NaN",
+			Examples: []string{`nan? to-float "nan"  ; => true`, "nan? 0f / 0f  ; => true", "nan? 1.5f  ; => false"},
+			SeeAlso:  []string{"infinite?", "to-float"},
+			Tags:     []string{"data", "float", "predicate"},
+		},
+	))
+
+	registerAndBind("infinite?", value.NewNativeFunction(
+		"infinite?",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+		},
+		InfiniteQ,
+		false,
+		&NativeDoc{
+			Category:    "Data",
+			Summary:     "Returns true if the value is a float infinity",
+			Description: `Tests for positive or negative IEEE-754 infinity. Returns false for every non-float value.`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "any-type!", Description: "The value to test", Optional: false},
+			},
+			Returns:  "[logic!] True if value is +inf or -inf",
+			Examples: []string{"infinite? 1f / 0f  ; => true", "infinite? 1e308f  ; => false"},
+			SeeAlso:  []string{"nan?", "to-float"},
+			Tags:     []string{"data", "float", "predicate"},
+		},
+	))
+
 	registerAndBind("to-string", value.NewNativeFunction(
 		"to-string",
 		[]value.ParamSpec{
@@ -376,9 +468,260 @@ For blocks and other types, returns their human-readable form.`,
 				{Name: "value", Type: "any-type!", Description: "The value to convert", Optional: false},
 			},
 			Returns:  "[string!] The converted string value",
-			Examples: []string{"to-string 42  ; => \"42\"", "to-string 3.7  ; => \"3.70\"", `to-string [1 2 3]  ; => "1 2 3"`},
+			Examples: []string{"to-string 42  ; => \"42\"", "to-string 3.7  ; => \"3.7\"", `to-string [1 2 3]  ; => "1 2 3"`},
 			SeeAlso:  []string{"to-integer", "to-decimal", "form", "mold"},
 			Tags:     []string{"data", "conversion", "type", "string"},
 		},
 	))
+	registerAndBind("enbase", value.NewNativeFunction(
+		"enbase",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("base", true),
+			value.NewRefinementSpec("url", false),
+		},
+		EnbaseNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Encodes a binary or string as base 64, 32, 16 or 2 text",
+			Description: `Encodes the bytes of a binary, or the UTF-8 bytes of a string, as text in the given base.
+Base 64 (the default) and base 32 use the standard padded alphabets; base 16 gives upper-case
+hexadecimal digits and base 2 eight binary digits per byte.
+
+Refinements:
+  --base: 2, 16, 32 or 64 (default 64)
+  --url: Use the URL-safe base 64 alphabet (- and _) without padding`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "binary! string!", Description: "The data to encode", Optional: false},
+				{Name: "--base", Type: "integer!", Description: "Encoding base", Optional: true},
+			},
+			Returns:  "[string!] The encoded text",
+			Examples: []string{`enbase "hello"  ; => "aGVsbG8="`, `enbase --base 16 #{CAFE}  ; => "CAFE"`, `enbase --base 2 #{0F}  ; => "00001111"`, `enbase --url #{FBFF}  ; => "-_8"`},
+			SeeAlso:  []string{"debase", "checksum", "to-hex"},
+			Tags:     []string{"data", "binary", "encoding", "base64"},
+		},
+	))
+
+	registerAndBind("debase", value.NewNativeFunction(
+		"debase",
+		[]value.ParamSpec{
+			value.NewParamSpec("text", true),
+			value.NewRefinementSpec("base", true),
+			value.NewRefinementSpec("url", false),
+		},
+		DebaseNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Decodes base 64, 32, 16 or 2 text into a binary",
+			Description: `Decodes text produced by enbase. Whitespace is ignored, so wrapped text decodes as is;
+hexadecimal digits may use either case and base 64 padding is optional. Text that is not
+valid in the base raises an invalid-encoding error. Use to-string on the result to get text.
+
+Refinements:
+  --base: 2, 16, 32 or 64 (default 64)
+  --url: Use the URL-safe base 64 alphabet (- and _)`,
+			Parameters: []ParamDoc{
+				{Name: "text", Type: "string!", Description: "The encoded text", Optional: false},
+				{Name: "--base", Type: "integer!", Description: "Encoding base", Optional: true},
+			},
+			Returns:  "[binary!] The decoded bytes",
+			Examples: []string{`debase "aGVsbG8="  ; => #{68656C6C6F}`, `debase --base 16 "cafe"  ; => #{CAFE}`, `debase --url "-_8"  ; => #{FBFF}`},
+			SeeAlso:  []string{"enbase", "from-hex"},
+			Tags:     []string{"data", "binary", "encoding", "base64"},
+		},
+	))
+
+	registerAndBind("checksum", value.NewNativeFunction(
+		"checksum",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("method", true),
+		},
+		ChecksumNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Computes a checksum or cryptographic hash",
+			Description: `Computes a digest of a binary, or of the UTF-8 bytes of a string. crc32 and adler32
+return an integer; md5, sha1, sha256 and sha512 return a binary, which enbase turns into text.
+
+Refinements:
+  --method: crc32, adler32, md5, sha1, sha256 (default) or sha512`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "binary! string!", Description: "The data to digest", Optional: false},
+				{Name: "--method", Type: "word!", Description: "Checksum or hash algorithm", Optional: true},
+			},
+			Returns:  "[binary! integer!] The digest",
+			Examples: []string{`checksum --method 'crc32 "hello"  ; => 907060870`, `enbase --base 16 checksum --method 'md5 "hello"  ; => "5D41402ABC4B2A76B9719D911017C592"`, `length? checksum "hello"  ; => 32 (sha256)`},
+			SeeAlso:  []string{"hmac", "enbase"},
+			Tags:     []string{"data", "binary", "hash", "checksum"},
+		},
+	))
+
+	registerAndBind("hmac", value.NewNativeFunction(
+		"hmac",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewParamSpec("key", true),
+			value.NewRefinementSpec("method", true),
+		},
+		HmacNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Computes a keyed message authentication code",
+			Description: `Computes the HMAC of a binary or string with the given key, for signing API requests
+and verifying webhooks. Strings are hashed as UTF-8 bytes.
+
+Refinements:
+  --method: md5, sha1, sha256 (default) or sha512`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "binary! string!", Description: "The message", Optional: false},
+				{Name: "key", Type: "binary! string!", Description: "The secret key", Optional: false},
+				{Name: "--method", Type: "word!", Description: "Hash algorithm", Optional: true},
+			},
+			Returns:  "[binary!] The authentication code",
+			Examples: []string{`enbase --base 16 hmac "payload" "secret"`, `enbase hmac --method 'sha1 "payload" "secret"`},
+			SeeAlso:  []string{"checksum", "enbase"},
+			Tags:     []string{"data", "binary", "hash", "signing"},
+		},
+	))
+
+	registerAndBind("compress", value.NewNativeFunction(
+		"compress",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("method", true),
+		},
+		CompressNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Compresses a binary or string",
+			Description: `Compresses a binary, or the UTF-8 bytes of a string, and returns the compressed bytes.
+gzip output matches .gz files and HTTP gzip encoding; zlib and raw deflate are available
+with --method.
+
+Refinements:
+  --method: gzip (default), zlib or deflate`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "binary! string!", Description: "The data to compress", Optional: false},
+				{Name: "--method", Type: "word!", Description: "Compression format", Optional: true},
+			},
+			Returns:  "[binary!] The compressed bytes",
+			Examples: []string{`packed: compress "hello hello hello"`, `compress --method 'zlib #{00000000}`},
+			SeeAlso:  []string{"decompress", "read", "write"},
+			Tags:     []string{"data", "binary", "compression", "gzip"},
+		},
+	))
+
+	registerAndBind("decompress", value.NewNativeFunction(
+		"decompress",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("method", true),
+			value.NewRefinementSpec("string", false),
+		},
+		DecompressNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Decompresses gzip, zlib or deflate data",
+			Description: `Decompresses bytes produced by compress or by other gzip, zlib or deflate tools.
+Concatenated gzip members decompress to their joined contents. Data that is not valid
+in the chosen format raises a corrupt-data error.
+
+Refinements:
+  --method: gzip (default), zlib or deflate
+  --string: Return the result as UTF-8 text instead of binary!`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "binary!", Description: "The compressed data", Optional: false},
+				{Name: "--method", Type: "word!", Description: "Compression format", Optional: true},
+			},
+			Returns:  "[binary! string!] The decompressed data",
+			Examples: []string{`decompress --string compress "hello"  ; => "hello"`, `decompress --method 'zlib data`},
+			SeeAlso:  []string{"compress", "read"},
+			Tags:     []string{"data", "binary", "compression", "gzip"},
+		},
+	))
+
+	registerAndBind("pack", value.NewNativeFunction(
+		"pack",
+		[]value.ParamSpec{
+			value.NewParamSpec("spec", true),
+		},
+		PackNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Builds a binary from a layout spec",
+			Description: `Encodes values into a binary following a layout spec. Each field type is followed by
+an expression giving its value:
+
+  int8 uint8                 one byte
+  int16-be ... uint64-le     8 to 64 bit integers, big (-be) or little (-le) endian
+  float32-be ... float64-le  IEEE 754 floats
+  bytes COUNT                a binary or the UTF-8 bytes of a string
+  string COUNT               UTF-8 text
+  pad COUNT                  zero bytes
+
+COUNT is an integer expression (the value is zero padded to that size), rest (the
+value as is) or an integer type such as uint16-be, which writes a length prefix.
+Integers that do not fit their field raise an error.`,
+			Parameters: []ParamDoc{
+				{Name: "spec", Type: "block!", Description: "Field types followed by value expressions", Optional: false},
+			},
+			Returns: "[binary!] The encoded bytes",
+			Examples: []string{
+				`pack [uint16-be 258 int8 -1]  ; => #{0102FF}`,
+				`pack [string uint8 "hi" pad 2]  ; => #{0268690000}`,
+				`pack [float32-le 1.5f bytes 4 #{CAFE}]  ; => #{0000C03FCAFE0000}`,
+			},
+			SeeAlso: []string{"unpack", "enbase"},
+			Tags:    []string{"data", "binary", "encoding", "protocol"},
+		},
+	))
+
+	registerAndBind("unpack", value.NewNativeFunction(
+		"unpack",
+		[]value.ParamSpec{
+			value.NewParamSpec("data", true),
+			value.NewParamSpec("spec", true),
+			value.NewRefinementSpec("block", false),
+		},
+		UnpackNative,
+		false,
+		&NativeDoc{
+			Category: "Data",
+			Summary:  "Decodes a binary with a layout spec",
+			Description: `Decodes fixed-layout records using the same field types as pack, each followed by a
+field name. Returns an object with one field per name. Unsigned 64-bit values above the
+integer! range become bigint!. Fixed-size string fields drop trailing zero bytes, and pad
+fields are skipped. Bytes after the last field are ignored.
+
+COUNT may also name an integer field read earlier in the same spec:
+
+  unpack data [uint16-be len  int32-le id  bytes len name]
+
+Data that ends before a field is complete raises a short-data error.
+
+Refinements:
+  --block: Return the values in spec order instead of an object`,
+			Parameters: []ParamDoc{
+				{Name: "data", Type: "binary!", Description: "The bytes to decode", Optional: false},
+				{Name: "spec", Type: "block!", Description: "Field types followed by field names", Optional: false},
+			},
+			Returns: "[object! block!] The decoded fields",
+			Examples: []string{
+				`msg: unpack #{00020000002A6869} [uint16-be len uint32-be id string len name]
+msg.name  ; => "hi"`,
+				`unpack --block #{FF01} [int8 a uint8 b]  ; => [-1 1]`,
+				`unpack packet [uint8 kind pad 3 bytes rest payload]`,
+			},
+			SeeAlso: []string{"pack", "debase"},
+			Tags:    []string{"data", "binary", "decoding", "protocol"},
+		},
+	))
 }
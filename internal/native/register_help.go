@@ -77,6 +77,10 @@ Useful for programmatic access to available functionality.`,
 			value.NewRefinementSpec("step-level", true),
 			value.NewRefinementSpec("include-args", false),
 			value.NewRefinementSpec("max-depth", true),
+			value.NewRefinementSpec("types", true),
+			value.NewRefinementSpec("format", true),
+			value.NewRefinementSpec("buffer", true),
+			value.NewRefinementSpec("memory", false),
 		},
 		Trace,
 		false,
@@ -85,22 +89,30 @@ Useful for programmatic access to available functionality.`,
 			Summary:  "Controls execution tracing with enhanced debugging options",
 			Description: `Enables or disables tracing of code execution. When enabled, traces function calls
 and other execution events to a log file. Supports filtering, custom output destinations, and enhanced
-debugging modes including verbose frame state, expression-level tracing, and call depth limiting.`,
+debugging modes including verbose frame state, expression-level tracing, and call depth limiting.
+Word filters accept glob strings such as "http-*". Output is line-delimited JSON by default; --format
+'tree writes an indented call tree and 'chrome the trace-event format read by chrome://tracing and
+Perfetto. --buffer keeps only the last n events, written out when a script fails, and --memory keeps
+every event for trace-events.`,
 			Parameters: []ParamDoc{
 				{Name: "--on", Type: "logic!", Description: "Enable tracing", Optional: true},
 				{Name: "--off", Type: "logic!", Description: "Disable tracing", Optional: true},
-				{Name: "--only", Type: "block!", Description: "Block of words to include in trace (whitelist)", Optional: true},
-				{Name: "--exclude", Type: "block!", Description: "Block of words to exclude from trace (blacklist)", Optional: true},
+				{Name: "--only", Type: "block!", Description: "Block of words or glob strings to include in trace (whitelist)", Optional: true},
+				{Name: "--exclude", Type: "block!", Description: "Block of words or glob strings to exclude from trace (blacklist)", Optional: true},
 				{Name: "--file", Type: "string!", Description: "Custom file path for trace output", Optional: true},
 				{Name: "--append", Type: "logic!", Description: "Append to trace file instead of overwriting", Optional: true},
 				{Name: "--verbose", Type: "logic!", Description: "Include frame state (local variables) in trace events", Optional: true},
 				{Name: "--step-level", Type: "integer!", Description: "Control granularity: 0=calls only, 1=expressions, 2=all", Optional: true},
 				{Name: "--include-args", Type: "logic!", Description: "Include function arguments in trace events", Optional: true},
 				{Name: "--max-depth", Type: "integer!", Description: "Limit trace to specified call stack depth (0=unlimited)", Optional: true},
+				{Name: "--types", Type: "block!", Description: "Event types to trace: call, return, eval, block-enter, block-exit, debug, port, object, replay", Optional: true},
+				{Name: "--format", Type: "word!", Description: "Output format: json, tree or chrome", Optional: true},
+				{Name: "--buffer", Type: "integer!", Description: "Keep only the last n events, written out when the script fails", Optional: true},
+				{Name: "--memory", Type: "logic!", Description: "Keep events in memory for trace-events", Optional: true},
 			},
 			Returns:  "[none!] Always returns none",
-			Examples: []string{"trace --on  ; enable tracing", "trace --on --verbose --include-args --step-level 1  ; enhanced tracing", "trace --on --only [calculate-interest] --include-args  ; trace specific function with args", "trace --on --max-depth 3  ; limit trace depth", "trace --off  ; disable tracing"},
-			SeeAlso:  []string{"trace?", "debug"}, Tags: []string{"debug", "trace", "observability", "llm"},
+			Examples: []string{"trace --on  ; enable tracing", "trace --on --verbose --include-args --step-level 1  ; enhanced tracing", "trace --on --only [calculate-interest] --include-args  ; trace specific function with args", "trace --on --max-depth 3  ; limit trace depth", `trace --on --only ["http-*"] --types [call return]  ; glob and event-type filters`, `trace --on --format 'chrome --file "trace.json"  ; open in Perfetto`, "trace --on --buffer 1000  ; last 1000 events before a crash", "trace --off  ; disable tracing"},
+			SeeAlso:  []string{"trace?", "trace-events", "debug"}, Tags: []string{"debug", "trace", "observability", "llm"},
 		},
 	)))
 
@@ -120,6 +132,28 @@ debugging modes including verbose frame state, expression-level tracing, and cal
 		},
 	)))
 
+	rootFrame.Bind("trace-events", value.NewFuncVal(value.NewNativeFunction(
+		"trace-events",
+		[]value.ParamSpec{
+			value.NewRefinementSpec("clear", false),
+		},
+		TraceEvents,
+		false,
+		&NativeDoc{
+			Category: "Debug",
+			Summary:  "Returns buffered trace events",
+			Description: `Returns the events kept by trace --memory or trace --buffer as a block of objects,
+oldest first. Fields: type, word, expression, value, depth, step, duration (nanoseconds)
+and error (none when the expression succeeded).`,
+			Parameters: []ParamDoc{
+				{Name: "--clear", Type: "logic!", Description: "Empty the buffer after reading it", Optional: true},
+			},
+			Returns:  "[block!] Block of event objects",
+			Examples: []string{"trace --on --memory --types [call]\nsquare 3\ntrace --off\nforeach trace-events [ev] [print ev.word]"},
+			SeeAlso:  []string{"trace", "trace?"}, Tags: []string{"debug", "trace", "observability"},
+		},
+	)))
+
 	rootFrame.Bind("debug", value.NewFuncVal(value.NewNativeFunction(
 		"debug",
 		[]value.ParamSpec{
@@ -188,6 +222,83 @@ Returns the original value unchanged, making it useful for debugging in expressi
 		},
 	)))
 
+	rootFrame.Bind("stats", value.NewFuncVal(value.NewNativeFunction(
+		"stats",
+		[]value.ParamSpec{
+			value.NewRefinementSpec("allocations", false),
+		},
+		StatsNative,
+		false,
+		&NativeDoc{
+			Category: "Debug",
+			Summary:  "Reports frame store and memory statistics",
+			Description: `Returns an object describing the evaluator's frame store without collecting.
+Fields: frame-slots (slots in the store, used or free), live-frames, active-frames
+(frames on the call stack), free-slots (available for reuse), reclaimed (slots
+reclaimed so far), sweeps, symbols (interned words) and heap-alloc (bytes).
+With --allocations, returns instead what each function allocated while allocation
+accounting is on (--profile-allocs, or inside profile --allocs): a block of objects with
+function, blocks, strings, binaries, frames, growth (elements added by append and insert)
+and total (series and frames), most allocations first. Allocations made by natives count
+against the user-defined function that called them.`,
+			Parameters: []ParamDoc{
+				{Name: "--allocations", Type: "logic!", Description: "Return per-function allocation counts", Optional: true},
+			},
+			Returns:  "[object! block!] Statistics object, or a block of allocation objects",
+			Examples: []string{"s: stats  s.live-frames", "print stats.heap-alloc", "foreach stats --allocations [a] [print [a.function a.total]]"},
+			SeeAlso:  []string{"recycle", "profile"}, Tags: []string{"debug", "memory", "frames", "allocations"},
+		},
+	)))
+
+	rootFrame.Bind("recycle", value.NewFuncVal(value.NewNativeFunction(
+		"recycle",
+		[]value.ParamSpec{},
+		RecycleNative,
+		false,
+		&NativeDoc{
+			Category: "Debug",
+			Summary:  "Reclaims unreachable frames",
+			Description: `Runs a full collection of the frame store: frames of finished function calls and
+objects that no closure, object or other value refers to any more are released and
+their slots reused. Collection also happens automatically as the store grows; recycle
+forces it and returns the same statistics object as stats.`,
+			Parameters: []ParamDoc{},
+			Returns:    "[object!] Statistics after collection",
+			Examples:   []string{"r: recycle  r.reclaimed"},
+			SeeAlso:    []string{"stats"}, Tags: []string{"debug", "memory", "frames", "gc"},
+		},
+	)))
+
+	rootFrame.Bind("profile", value.NewFuncVal(value.NewNativeFunction(
+		"profile",
+		[]value.ParamSpec{
+			value.NewParamSpec("block", false),
+			value.NewRefinementSpec("save", true),
+			value.NewRefinementSpec("allocs", false),
+		},
+		Profile,
+		false,
+		&NativeDoc{
+			Category: "Debug",
+			Summary:  "Profiles the evaluation of a block",
+			Description: `Evaluates the block while recording a call tree: every call path gets its own
+node with its call count, total time (including callees) and self time. Afterwards prints
+a per-function table and the call tree, then returns the block's result. The report is
+printed even when the block fails. With --save the profile is also written to a file
+inside the sandbox: pprof protobuf for .pprof, .pb and .pb.gz names (for go tool pprof),
+JSON for .json names, collapsed stacks for flamegraph tools otherwise. With --allocs
+the report also counts the series and frames each call path created.`,
+			Parameters: []ParamDoc{
+				{Name: "block", Type: "block!", Description: "The code to profile", Optional: false},
+				{Name: "--save", Type: "string!", Description: "File to write the profile to", Optional: true},
+				{Name: "--allocs", Type: "logic!", Description: "Count allocations while the block runs", Optional: true},
+			},
+			Returns:  "[any-type!] The result of the block",
+			Examples: []string{"profile [fib 20]", `profile --save "fib.folded" [fib 20]`, `profile --save "fib.pprof" [fib 20]`},
+			SeeAlso:  []string{"trace", "stats"}, Tags: []string{"debug", "profile", "performance"},
+		},
+	)))
+
 	rootFrame.Bind("type-of", value.NewFuncVal(value.NewNativeFunction(
 		"type-of",
 		[]value.ParamSpec{
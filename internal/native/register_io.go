@@ -106,7 +106,7 @@ File operations are subject to sandbox restrictions if configured.`,
 			{Name: "spec", Type: "string!", Description: "A URL or file path (e.g., \"file://data.txt\")", Optional: false},
 		},
 		Returns:  "[port!] An open port ready for I/O operations",
-		Examples: []string{`p: open "file://data.txt"  ; => port`, `p: open "file:///tmp/output.log"`},
+		Examples: []string{`p: open "file://data.txt"  ; a port! on data.txt`, `p: open "file:///tmp/output.log"`},
 		SeeAlso:  []string{"close", "read", "write", "save", "load"}, Tags: []string{"ports", "io", "file", "open"},
 	})
 
@@ -132,6 +132,7 @@ After closing, the port should not be used for further I/O operations. Returns n
 			value.NewRefinementSpec("part", true),    // --part length
 			value.NewRefinementSpec("seek", true),    // --seek index
 			value.NewRefinementSpec("as", true),      // --as encoding
+			value.NewRefinementSpec("gzip", false),   // --gzip flag
 		},
 		ReadNative,
 		false,
@@ -148,7 +149,8 @@ Refinements:
   --lines: Return block of lines instead of single string
   --part length: Read only specified number of units (bytes or lines)
   --seek index: Start reading from specific byte position
-  --as encoding: Read with specified encoding (default: utf-8)`,
+  --as encoding: Read with specified encoding (default: utf-8)
+  --gzip: Decompress gzip data; --part then counts decompressed bytes`,
 			Parameters: []ParamDoc{
 				{Name: "source", Type: "port! string!", Description: "A port or file path to read from", Optional: false},
 			},
@@ -160,26 +162,40 @@ Refinements:
 				`partial: read --part 100 "file://data.txt"  ; read first 100 bytes`,
 				`lines: read --lines --part 5 "file://data.txt"  ; read first 5 lines`,
 				`data: read --seek 1000 "file://data.txt"  ; read from byte 1000`,
+				`log: read --gzip "app.log.gz"  ; read compressed text`,
 				`p: open "file://data.txt"\ndata: read p\nclose p`,
 			},
 			SeeAlso: []string{"write", "load", "open", "close"}, Tags: []string{"ports", "io", "read", "file", "binary"},
 		},
 	))
 
-	registerSimpleIOFunc("write", WriteNative, 2, &NativeDoc{
-		Category: "Ports",
-		Summary:  "Writes data to a port or file",
-		Description: `Writes data to a port or directly to a file path.
+	registerAndBind("write", value.NewNativeFunction(
+		"write",
+		[]value.ParamSpec{
+			value.NewParamSpec("target", true),
+			value.NewParamSpec("data", true),
+			value.NewRefinementSpec("gzip", false),
+		},
+		WriteNative,
+		false,
+		&NativeDoc{
+			Category: "Ports",
+			Summary:  "Writes data to a port or file",
+			Description: `Writes data to a port or directly to a file path.
 If the target is a port, writes to that open port. If given a string (file path),
-opens the file, writes the data, and closes it automatically. Overwrites existing content.`,
-		Parameters: []ParamDoc{
-			{Name: "target", Type: "port! string!", Description: "A port or file path to write to", Optional: false},
-			{Name: "data", Type: "string!", Description: "The data to write", Optional: false},
+opens the file, writes the data, and closes it automatically. Overwrites existing content.
+
+Refinements:
+  --gzip: Compress the data with gzip before writing`,
+			Parameters: []ParamDoc{
+				{Name: "target", Type: "port! string!", Description: "A port or file path to write to", Optional: false},
+				{Name: "data", Type: "string!", Description: "The data to write", Optional: false},
+			},
+			Returns:  "[none!] Always returns none",
+			Examples: []string{`write "file://output.txt" "Hello, world!"  ; write to file`, `write --gzip "report.txt.gz" report`, `p: open "file://output.txt"\nwrite p "data"\nclose p`},
+			SeeAlso:  []string{"read", "save", "open", "close", "compress"}, Tags: []string{"ports", "io", "write", "file"},
 		},
-		Returns:  "[none!] Always returns none",
-		Examples: []string{`write "file://output.txt" "Hello, world!"  ; write to file`, `p: open "file://output.txt"\nwrite p "data"\nclose p`},
-		SeeAlso:  []string{"read", "save", "open", "close"}, Tags: []string{"ports", "io", "write", "file"},
-	})
+	))
 
 	registerSimpleIOFunc("save", SaveNative, 2, &NativeDoc{
 		Category: "Ports",
@@ -248,7 +264,7 @@ This is the first stage of the two-stage parser.`,
 			{Name: "source", Type: "string!", Description: "The viro source code to tokenize", Optional: false},
 		},
 		Returns:  "[block!] A block of token objects",
-		Examples: []string{`tokens: tokenize "x: 42"  ; => [object! object! object!]`, `tokens: tokenize "[1 2 3]"`},
+		Examples: []string{`tokens: tokenize "x: 42"  ; a block of token objects`, `tokens: tokenize "[1 2 3]"`},
 		SeeAlso:  []string{"parse", "load-string", "classify"}, Tags: []string{"parser", "tokenize", "lexer"},
 	})
 
@@ -293,6 +309,360 @@ returning the corresponding typed value. For example, "42" becomes integer! 42,
 		SeeAlso:  []string{"tokenize", "parse", "load-string"}, Tags: []string{"parser", "classify", "type", "conversion"},
 	})
 
+	// ===== Group 11: Filesystem operations (11 functions) =====
+	registerSimpleIOFunc("exists?", ExistsNative, 1, &NativeDoc{
+		Category: "Files",
+		Summary:  "Checks whether a file or directory exists",
+		Description: `Returns true if the path names an existing file or directory inside the sandbox.
+Paths are resolved relative to the sandbox root; paths escaping the sandbox raise an access error.`,
+		Parameters: []ParamDoc{
+			{Name: "path", Type: "string!", Description: "The file or directory path to check", Optional: false},
+		},
+		Returns: "[logic!] True if the path exists",
+		Examples: []string{`write "data.txt" "hello"
+exists? "data.txt"  ; => true`, `exists? "missing.txt"  ; => false`},
+		SeeAlso: []string{"dir?", "info?", "size?"}, Tags: []string{"files", "filesystem", "exists"},
+	})
+
+	registerSimpleIOFunc("dir?", DirNative, 1, &NativeDoc{
+		Category: "Files",
+		Summary:  "Checks whether a path is a directory",
+		Description: `Returns true if the path names an existing directory inside the sandbox.
+Returns false for regular files and for paths that do not exist.`,
+		Parameters: []ParamDoc{
+			{Name: "path", Type: "string!", Description: "The path to check", Optional: false},
+		},
+		Returns: "[logic!] True if the path is a directory",
+		Examples: []string{`make-dir "reports"
+dir? "reports"  ; => true`, `dir? "data.txt"  ; => false`},
+		SeeAlso: []string{"exists?", "make-dir", "list-dir"}, Tags: []string{"files", "filesystem", "directory"},
+	})
+
+	registerSimpleIOFunc("size?", SizeNative, 1, &NativeDoc{
+		Category: "Files",
+		Summary:  "Returns the size of a file in bytes",
+		Description: `Returns the size in bytes of the file at the given path.
+Returns none if the path does not exist or names a directory.`,
+		Parameters: []ParamDoc{
+			{Name: "path", Type: "string!", Description: "The file path", Optional: false},
+		},
+		Returns: "[integer! none!] The file size in bytes, or none",
+		Examples: []string{`write "data.txt" "hello"
+size? "data.txt"  ; => 5`, `size? "missing.txt"  ; => none`},
+		SeeAlso: []string{"modified?", "info?", "exists?"}, Tags: []string{"files", "filesystem", "size"},
+	})
+
+	registerSimpleIOFunc("modified?", ModifiedNative, 1, &NativeDoc{
+		Category: "Files",
+		Summary:  "Returns the modification time of a file",
+		Description: `Returns the last modification time of a file or directory as Unix seconds.
+Returns none if the path does not exist. Integer timestamps can be compared directly.`,
+		Parameters: []ParamDoc{
+			{Name: "path", Type: "string!", Description: "The file or directory path", Optional: false},
+		},
+		Returns:  "[integer! none!] Modification time in seconds since the Unix epoch, or none",
+		Examples: []string{`modified? "data.txt"  ; Unix seconds, such as 1735689600`, `(modified? "out.txt") < (modified? "src.txt")  ; true when out.txt is stale`},
+		SeeAlso:  []string{"size?", "info?"}, Tags: []string{"files", "filesystem", "time"},
+	})
+
+	registerSimpleIOFunc("info?", InfoNative, 1, &NativeDoc{
+		Category: "Files",
+		Summary:  "Returns an object describing a file or directory",
+		Description: `Returns an object with the fields name, path, type ('file or 'dir), size,
+modified (Unix seconds) and mode (permission string). Returns none if the path does not exist.`,
+		Parameters: []ParamDoc{
+			{Name: "path", Type: "string!", Description: "The file or directory path", Optional: false},
+		},
+		Returns: "[object! none!] File information object, or none",
+		Examples: []string{`write "data.txt" "hello"
+info: info? "data.txt"
+info.size  ; => 5`, `make-dir "reports"
+info: info? "reports"
+info.type  ; => dir`},
+		SeeAlso: []string{"size?", "modified?", "dir?", "query"}, Tags: []string{"files", "filesystem", "metadata", "info"},
+	})
+
+	registerAndBind("delete", value.NewNativeFunction(
+		"delete",
+		[]value.ParamSpec{
+			value.NewParamSpec("path", true),
+			value.NewRefinementSpec("recursive", false),
+		},
+		DeleteNative,
+		false,
+		&NativeDoc{
+			Category: "Files",
+			Summary:  "Deletes a file or directory",
+			Description: `Deletes the file or empty directory at the given path. Raises an error if the path
+does not exist. The sandbox root itself can never be deleted.
+
+Refinements:
+  --recursive: Delete a directory together with all of its contents`,
+			Parameters: []ParamDoc{
+				{Name: "path", Type: "string!", Description: "The file or directory to delete", Optional: false},
+			},
+			Returns:  "[none!] Always returns none",
+			Examples: []string{`delete "old.log"`, `delete --recursive "build"`},
+			SeeAlso:  []string{"make-dir", "rename", "exists?"}, Tags: []string{"files", "filesystem", "delete", "remove"},
+		},
+	))
+
+	registerSimpleIOFunc("rename", RenameNative, 2, &NativeDoc{
+		Category: "Files",
+		Summary:  "Renames a file or directory",
+		Description: `Renames the source path to exactly the target path. Both paths must be inside
+the sandbox. An existing target file is replaced.`,
+		Parameters: []ParamDoc{
+			{Name: "source", Type: "string!", Description: "The existing path", Optional: false},
+			{Name: "target", Type: "string!", Description: "The new path", Optional: false},
+		},
+		Returns:  "[none!] Always returns none",
+		Examples: []string{`rename "draft.txt" "final.txt"`},
+		SeeAlso:  []string{"move", "copy-file", "delete"}, Tags: []string{"files", "filesystem", "rename"},
+	})
+
+	registerSimpleIOFunc("move", MoveNative, 2, &NativeDoc{
+		Category: "Files",
+		Summary:  "Moves a file or directory",
+		Description: `Moves the source path to the target. If the target is an existing directory,
+the source is moved into it keeping its name; otherwise it behaves like rename.`,
+		Parameters: []ParamDoc{
+			{Name: "source", Type: "string!", Description: "The existing path", Optional: false},
+			{Name: "target", Type: "string!", Description: "The destination path or directory", Optional: false},
+		},
+		Returns:  "[none!] Always returns none",
+		Examples: []string{`move "report.txt" "archive"  ; moves it to archive/report.txt`, `move "a.txt" "b.txt"`},
+		SeeAlso:  []string{"rename", "copy-file"}, Tags: []string{"files", "filesystem", "move"},
+	})
+
+	registerSimpleIOFunc("copy-file", CopyFileNative, 2, &NativeDoc{
+		Category: "Files",
+		Summary:  "Copies a file",
+		Description: `Copies the contents and permissions of the source file to the target path.
+Missing parent directories of the target are created. An existing target is overwritten.`,
+		Parameters: []ParamDoc{
+			{Name: "source", Type: "string!", Description: "The file to copy", Optional: false},
+			{Name: "target", Type: "string!", Description: "The destination file path", Optional: false},
+		},
+		Returns:  "[none!] Always returns none",
+		Examples: []string{`copy-file "config.viro" "backup/config.viro"`},
+		SeeAlso:  []string{"rename", "move", "read", "write"}, Tags: []string{"files", "filesystem", "copy"},
+	})
+
+	registerAndBind("make-dir", value.NewNativeFunction(
+		"make-dir",
+		[]value.ParamSpec{
+			value.NewParamSpec("path", true),
+			value.NewRefinementSpec("deep", false),
+		},
+		MakeDirNative,
+		false,
+		&NativeDoc{
+			Category: "Files",
+			Summary:  "Creates a directory",
+			Description: `Creates a directory at the given path. The parent directory must already exist
+unless --deep is used. Creating a directory that already exists is not an error.
+
+Refinements:
+  --deep: Create all missing parent directories as well`,
+			Parameters: []ParamDoc{
+				{Name: "path", Type: "string!", Description: "The directory to create", Optional: false},
+			},
+			Returns:  "[none!] Always returns none",
+			Examples: []string{`make-dir "out"`, `make-dir --deep "out/reports/2025"`},
+			SeeAlso:  []string{"dir?", "list-dir", "delete"}, Tags: []string{"files", "filesystem", "directory", "mkdir"},
+		},
+	))
+
+	registerAndBind("list-dir", value.NewNativeFunction(
+		"list-dir",
+		[]value.ParamSpec{
+			value.NewParamSpec("path", true),
+			value.NewRefinementSpec("recursive", false),
+			value.NewRefinementSpec("filter", true),
+		},
+		ListDirNative,
+		false,
+		&NativeDoc{
+			Category: "Files",
+			Summary:  "Lists the entries of a directory",
+			Description: `Returns a block of entry names in the directory, sorted by name.
+Directory entries end with a slash. With --recursive, nested entries are returned
+as relative paths using forward slashes.
+
+Refinements:
+  --recursive: Include entries of all subdirectories
+  --filter pattern: Only include entries whose name matches the glob pattern`,
+			Parameters: []ParamDoc{
+				{Name: "path", Type: "string!", Description: "The directory to list", Optional: false},
+			},
+			Returns: "[block!] Block of entry names as strings",
+			Examples: []string{`write "data.txt" ""
+make-dir "reports"
+list-dir "."  ; => ["data.txt" "reports/"]`, `list-dir --recursive --filter "*.viro" "src"  ; such as ["main.viro" "lib/util.viro"]`},
+			SeeAlso: []string{"dir?", "make-dir", "read"}, Tags: []string{"files", "filesystem", "directory", "list", "glob"},
+		},
+	))
+
+	registerAndBind("archive-list", value.NewNativeFunction(
+		"archive-list",
+		[]value.ParamSpec{
+			value.NewParamSpec("archive", true),
+			value.NewRefinementSpec("format", true),
+		},
+		ArchiveListNative,
+		false,
+		&NativeDoc{
+			Category: "Files",
+			Summary:  "Lists the entries of a tar or zip archive",
+			Description: `Returns the entry names of a tar, tar.gz or zip archive in archive order.
+Directory entries end with a slash. The format follows the file extension
+(.tar, .tar.gz, .tgz, .zip) unless --format is given.
+
+Refinements:
+  --format: tar, tar.gz or zip`,
+			Parameters: []ParamDoc{
+				{Name: "archive", Type: "string!", Description: "The archive file", Optional: false},
+			},
+			Returns:  "[block!] Block of entry names as strings",
+			Examples: []string{`archive-list "bundle.tar.gz"  ; such as ["bundle/" "bundle/data.csv"]`, `archive-list --format 'zip "download.bin"`},
+			SeeAlso:  []string{"archive-extract", "archive-create", "list-dir"}, Tags: []string{"files", "archive", "tar", "zip"},
+		},
+	))
+
+	registerAndBind("archive-extract", value.NewNativeFunction(
+		"archive-extract",
+		[]value.ParamSpec{
+			value.NewParamSpec("archive", true),
+			value.NewParamSpec("target", true),
+			value.NewRefinementSpec("format", true),
+		},
+		ArchiveExtractNative,
+		false,
+		&NativeDoc{
+			Category: "Files",
+			Summary:  "Extracts a tar or zip archive into a directory",
+			Description: `Extracts every entry of a tar, tar.gz or zip archive below the target directory,
+which is created if needed, and returns the extracted entry names. Existing files are
+overwritten. Entries with absolute paths, entries that climb out of the target with "..",
+links and special files raise an unsafe-archive-entry error, and in that case nothing
+is extracted.
+
+Refinements:
+  --format: tar, tar.gz or zip`,
+			Parameters: []ParamDoc{
+				{Name: "archive", Type: "string!", Description: "The archive file", Optional: false},
+				{Name: "target", Type: "string!", Description: "The directory to extract into", Optional: false},
+			},
+			Returns:  "[block!] Block of extracted entry names",
+			Examples: []string{`archive-extract "nightly.tar.gz" "work"`},
+			SeeAlso:  []string{"archive-list", "archive-create", "decompress"}, Tags: []string{"files", "archive", "tar", "zip", "extract"},
+		},
+	))
+
+	registerAndBind("archive-create", value.NewNativeFunction(
+		"archive-create",
+		[]value.ParamSpec{
+			value.NewParamSpec("archive", true),
+			value.NewParamSpec("sources", true),
+			value.NewRefinementSpec("format", true),
+		},
+		ArchiveCreateNative,
+		false,
+		&NativeDoc{
+			Category: "Files",
+			Summary:  "Creates a tar or zip archive",
+			Description: `Writes the given files and directories into a new tar, tar.gz or zip archive,
+replacing any existing file, and returns the entry names written. Directories are added
+recursively. Entry names are relative to each source's parent directory, so "logs"
+becomes "logs/", "logs/app.log" and so on. Symlinks and special files are skipped.
+
+Refinements:
+  --format: tar, tar.gz or zip`,
+			Parameters: []ParamDoc{
+				{Name: "archive", Type: "string!", Description: "The archive file to create", Optional: false},
+				{Name: "sources", Type: "string! block!", Description: "A path or block of paths to add", Optional: false},
+			},
+			Returns:  "[block!] Block of entry names written",
+			Examples: []string{`archive-create "logs.tar.gz" "logs"`, `archive-create "release.zip" ["bin" "README.md"]`},
+			SeeAlso:  []string{"archive-list", "archive-extract", "compress"}, Tags: []string{"files", "archive", "tar", "zip"},
+		},
+	))
+
+	// ===== Group 12: Process and environment (3 functions) =====
+	registerAndBind("call", value.NewNativeFunction(
+		"call",
+		[]value.ParamSpec{
+			value.NewParamSpec("command", true),
+			value.NewRefinementSpec("shell", false),
+			value.NewRefinementSpec("input", true),
+			value.NewRefinementSpec("env", true),
+			value.NewRefinementSpec("dir", true),
+			value.NewRefinementSpec("timeout", true),
+			value.NewRefinementSpec("stream", false),
+		},
+		CallNative,
+		false,
+		&NativeDoc{
+			Category: "System",
+			Summary:  "Runs an external program",
+			Description: `Runs an external program and waits for it to finish. The command is a block
+whose first element is the program and whose remaining elements are its arguments;
+no shell is involved. Returns an object with the fields exit-code (integer!),
+output (captured stdout) and error (captured stderr). A non-zero exit code is not
+an error.
+
+Subprocess execution is disabled unless viro is started with --allow-exec
+(or VIRO_ALLOW_EXEC=1), because a child process is not confined to the sandbox. The working directory
+defaults to the sandbox root.
+
+Refinements:
+  --shell: The command is a string run by the system shell (/bin/sh -c)
+  --input data: Feed a string! or binary! to the program's stdin
+  --env vars: Object whose fields override environment variables (none removes one)
+  --dir path: Working directory, resolved inside the sandbox
+  --timeout ms: Kill the program after the given number of milliseconds
+  --stream: Also copy the program's output to stdout/stderr while it runs`,
+			Parameters: []ParamDoc{
+				{Name: "command", Type: "block! string!", Description: "Program and arguments (string! with --shell)", Optional: false},
+			},
+			Returns: "[object!] Object with exit-code, output and error fields",
+			Examples: []string{`result: call ["git" "status" "--short"]
+result.exit-code  ; 0 on success`, `result: call --input "b\na\n" ["sort"]
+result.output  ; the sorted lines, "a\nb\n"`, `env: object [LANG: "C"]
+call --env env --dir "build" ["make"]`, `call --shell "ls *.viro | wc -l"`},
+			SeeAlso: []string{"print"}, Tags: []string{"system", "process", "exec", "subprocess", "shell"},
+		},
+	))
+
+	registerSimpleIOFunc("get-env", GetEnvNative, 1, &NativeDoc{
+		Category: "System",
+		Summary:  "Returns the value of an environment variable",
+		Description: `Returns the value of the named environment variable as a string,
+or none if the variable is not set.`,
+		Parameters: []ParamDoc{
+			{Name: "name", Type: "string! word!", Description: "The variable name", Optional: false},
+		},
+		Returns:  "[string! none!] The variable value, or none",
+		Examples: []string{`get-env "HOME"  ; such as "/home/user"`, `get-env "NO_SUCH_VAR"  ; => none`},
+		SeeAlso:  []string{"set-env", "call"}, Tags: []string{"system", "environment", "env"},
+	})
+
+	registerSimpleIOFunc("set-env", SetEnvNative, 2, &NativeDoc{
+		Category: "System",
+		Summary:  "Sets an environment variable",
+		Description: `Sets the named environment variable for the running interpreter and for
+programs started with call afterwards. Non-string values are formed. Setting
+none removes the variable. Returns the value.`,
+		Parameters: []ParamDoc{
+			{Name: "name", Type: "string! word!", Description: "The variable name", Optional: false},
+			{Name: "value", Type: "any-type!", Description: "The new value, or none to unset", Optional: false},
+		},
+		Returns:  "[any-type!] The value that was set",
+		Examples: []string{`set-env "LOG_LEVEL" "debug"`, `set-env "TMP_TOKEN" none  ; removes TMP_TOKEN`},
+		SeeAlso:  []string{"get-env", "call"}, Tags: []string{"system", "environment", "env"},
+	})
+
 	// Create and bind standard I/O ports
 	stdoutPort := value.NewPort("stdio", "stdout", &stdioWriterDriver{writer: eval.GetOutputWriter()})
 	stderrPort := value.NewPort("stdio", "stderr", &stdioWriterDriver{writer: eval.GetErrorWriter()})
@@ -42,15 +42,16 @@ func RegisterMathNatives(rootFrame core.Frame) {
 		&NativeDoc{
 			Category: "Math",
 			Summary:  "Adds two numbers together",
-			Description: `Performs addition on two numeric values (integers or decimals).
-Returns an integer if both operands are integers, otherwise returns a decimal.
+			Description: `Performs addition on two numeric values (integers, floats or decimals).
+Returns an integer if both operands are integers. Mixing an integer with a float gives a float;
+any decimal operand gives a decimal.
 Supports infix notation for natural mathematical expressions.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal!", Description: "The first number to add", Optional: false},
-				{Name: "right", Type: "integer! decimal!", Description: "The second number to add", Optional: false},
+				{Name: "left", Type: "integer! float! decimal!", Description: "The first number to add", Optional: false},
+				{Name: "right", Type: "integer! float! decimal!", Description: "The second number to add", Optional: false},
 			},
-			Returns:  "[integer! decimal!] The sum of the two numbers",
-			Examples: []string{"3 + 4  ; => 7", "2.5 + 1.5  ; => 4.0", "10 + -5  ; => 5"},
+			Returns:  "[integer! float! decimal!] The sum of the two numbers",
+			Examples: []string{"3 + 4  ; => 7", "2.5 + 1.5  ; => 4.00", "10 + -5  ; => 5"},
 			SeeAlso:  []string{"-", "*", "/"}, Tags: []string{"arithmetic", "math", "addition"},
 		},
 	))
@@ -65,15 +66,16 @@ Supports infix notation for natural mathematical expressions.`,
 		&NativeDoc{
 			Category: "Math",
 			Summary:  "Subtracts the second number from the first",
-			Description: `Performs subtraction on two numeric values (integers or decimals).
-Returns an integer if both operands are integers, otherwise returns a decimal.
+			Description: `Performs subtraction on two numeric values (integers, floats or decimals).
+Returns an integer if both operands are integers. Mixing an integer with a float gives a float;
+any decimal operand gives a decimal.
 Supports infix notation for natural mathematical expressions.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal!", Description: "The number to subtract from", Optional: false},
-				{Name: "right", Type: "integer! decimal!", Description: "The number to subtract", Optional: false},
+				{Name: "left", Type: "integer! float! decimal!", Description: "The number to subtract from", Optional: false},
+				{Name: "right", Type: "integer! float! decimal!", Description: "The number to subtract", Optional: false},
 			},
-			Returns:  "[integer! decimal!] The difference between the two numbers",
-			Examples: []string{"10 - 3  ; => 7", "5.5 - 2.0  ; => 3.5", "0 - 5  ; => -5"},
+			Returns:  "[integer! float! decimal!] The difference between the two numbers",
+			Examples: []string{"10 - 3  ; => 7", "5.5 - 2.0  ; => 3.50", "0 - 5  ; => -5"},
 			SeeAlso:  []string{"+", "*", "/"}, Tags: []string{"arithmetic", "math", "subtraction"},
 		},
 	))
@@ -89,15 +91,16 @@ Supports infix notation for natural mathematical expressions.`,
 		&NativeDoc{
 			Category: "Math",
 			Summary:  "Multiplies two numbers together",
-			Description: `Performs multiplication on two numeric values (integers or decimals).
-Returns an integer if both operands are integers, otherwise returns a decimal.
+			Description: `Performs multiplication on two numeric values (integers, floats or decimals).
+Returns an integer if both operands are integers. Mixing an integer with a float gives a float;
+any decimal operand gives a decimal.
 Supports infix notation for natural mathematical expressions.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal!", Description: "The first number to multiply", Optional: false},
-				{Name: "right", Type: "integer! decimal!", Description: "The second number to multiply", Optional: false},
+				{Name: "left", Type: "integer! float! decimal!", Description: "The first number to multiply", Optional: false},
+				{Name: "right", Type: "integer! float! decimal!", Description: "The second number to multiply", Optional: false},
 			},
-			Returns:  "[integer! decimal!] The product of the two numbers",
-			Examples: []string{"3 * 4  ; => 12", "2.5 * 2.0  ; => 5.0", "7 * -2  ; => -14"},
+			Returns:  "[integer! float! decimal!] The product of the two numbers",
+			Examples: []string{"3 * 4  ; => 12", "2.5 * 2.0  ; => 5.00", "7 * -2  ; => -14"},
 			SeeAlso:  []string{"+", "-", "/", "pow"}, Tags: []string{"arithmetic", "math", "multiplication"},
 		},
 	))
@@ -113,15 +116,16 @@ Supports infix notation for natural mathematical expressions.`,
 		&NativeDoc{
 			Category: "Math",
 			Summary:  "Divides the first number by the second",
-			Description: `Performs division on two numeric values (integers or decimals).
+			Description: `Performs division on two numeric values (integers, floats or decimals).
+Float division follows IEEE-754: dividing by zero gives an infinity or NaN instead of an error.
 Always returns a decimal for precision, even when dividing integers.
 Raises an error if dividing by zero.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal!", Description: "The dividend (number to be divided)", Optional: false},
-				{Name: "right", Type: "integer! decimal!", Description: "The divisor (number to divide by)", Optional: false},
+				{Name: "left", Type: "integer! float! decimal!", Description: "The dividend (number to be divided)", Optional: false},
+				{Name: "right", Type: "integer! float! decimal!", Description: "The divisor (number to divide by)", Optional: false},
 			},
-			Returns:  "[decimal!] The quotient of the division",
-			Examples: []string{"10 / 2  ; => 5.0", "7 / 2  ; => 3.5", "1.0 / 4.0  ; => 0.25"},
+			Returns:  "[integer! float! decimal!] The quotient of the division",
+			Examples: []string{"10 / 2  ; => 5", "7 / 2  ; => 3 (integer division)", "1.0 / 4.0  ; => 0.25"},
 			SeeAlso:  []string{"+", "-", "*", "pow"}, Tags: []string{"arithmetic", "math", "division"},
 		},
 	))
@@ -141,10 +145,10 @@ Raises an error if dividing by zero.`,
 Returns the remainder when dividend is divided by divisor.
 The sign of the result follows the dividend. Supports infix notation.`,
 			Parameters: []ParamDoc{
-				{Name: "dividend", Type: "integer! decimal!", Description: "The number to be divided", Optional: false},
-				{Name: "divisor", Type: "integer! decimal!", Description: "The number to divide by (must not be zero)", Optional: false},
+				{Name: "dividend", Type: "integer! float! decimal!", Description: "The number to be divided", Optional: false},
+				{Name: "divisor", Type: "integer! float! decimal!", Description: "The number to divide by (must not be zero)", Optional: false},
 			},
-			Returns:  "[integer! decimal!] The remainder after division",
+			Returns:  "[integer! float! decimal!] The remainder after division",
 			Examples: []string{"10 mod 3  ; => 1", "17 mod 5  ; => 2", "100 mod 7  ; => 2", "mod 10 3  ; => 1"},
 			SeeAlso:  []string{"/", "*", "-"},
 			Tags:     []string{"arithmetic", "math", "modulo", "remainder"},
@@ -166,8 +170,8 @@ The sign of the result follows the dividend. Supports infix notation.`,
 			Description: `Compares two numeric values and returns true if the first is less than the second.
 Works with both integers and decimals. Uses lexicographic ordering for strings.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal! string!", Description: "The first value to compare", Optional: false},
-				{Name: "right", Type: "integer! decimal! string!", Description: "The second value to compare", Optional: false},
+				{Name: "left", Type: "integer! float! decimal! string!", Description: "The first value to compare", Optional: false},
+				{Name: "right", Type: "integer! float! decimal! string!", Description: "The second value to compare", Optional: false},
 			},
 			Returns:  "[logic!] True if left < right, false otherwise",
 			Examples: []string{"3 < 5  ; => true", "10 < 10  ; => false", "5 < 3  ; => false"},
@@ -189,8 +193,8 @@ Works with both integers and decimals. Uses lexicographic ordering for strings.`
 			Description: `Compares two numeric values and returns true if the first is greater than the second.
 Works with both integers and decimals. Uses lexicographic ordering for strings.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal! string!", Description: "The first value to compare", Optional: false},
-				{Name: "right", Type: "integer! decimal! string!", Description: "The second value to compare", Optional: false},
+				{Name: "left", Type: "integer! float! decimal! string!", Description: "The first value to compare", Optional: false},
+				{Name: "right", Type: "integer! float! decimal! string!", Description: "The second value to compare", Optional: false},
 			},
 			Returns:  "[logic!] True if left > right, false otherwise",
 			Examples: []string{"10 > 5  ; => true", "10 > 10  ; => false", "3 > 5  ; => false"},
@@ -212,8 +216,8 @@ Works with both integers and decimals. Uses lexicographic ordering for strings.`
 			Description: `Compares two numeric values and returns true if the first is less than or equal to the second.
 Works with both integers and decimals. Uses lexicographic ordering for strings.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal! string!", Description: "The first value to compare", Optional: false},
-				{Name: "right", Type: "integer! decimal! string!", Description: "The second value to compare", Optional: false},
+				{Name: "left", Type: "integer! float! decimal! string!", Description: "The first value to compare", Optional: false},
+				{Name: "right", Type: "integer! float! decimal! string!", Description: "The second value to compare", Optional: false},
 			},
 			Returns:  "[logic!] True if left <= right, false otherwise",
 			Examples: []string{"3 <= 5  ; => true", "10 <= 10  ; => true", "15 <= 10  ; => false"},
@@ -235,8 +239,8 @@ Works with both integers and decimals. Uses lexicographic ordering for strings.`
 			Description: `Compares two numeric values and returns true if the first is greater than or equal to the second.
 Works with both integers and decimals. Uses lexicographic ordering for strings.`,
 			Parameters: []ParamDoc{
-				{Name: "left", Type: "integer! decimal! string!", Description: "The first value to compare", Optional: false},
-				{Name: "right", Type: "integer! decimal! string!", Description: "The second value to compare", Optional: false},
+				{Name: "left", Type: "integer! float! decimal! string!", Description: "The first value to compare", Optional: false},
+				{Name: "right", Type: "integer! float! decimal! string!", Description: "The second value to compare", Optional: false},
 			},
 			Returns:  "[logic!] True if left >= right, false otherwise",
 			Examples: []string{"10 >= 5  ; => true", "10 >= 10  ; => true", "3 >= 5  ; => false"},
@@ -256,13 +260,14 @@ Works with both integers and decimals. Uses lexicographic ordering for strings.`
 			Category: "Math",
 			Summary:  "Tests if two values are equal",
 			Description: `Compares two values for equality. Works with all value types including
-integers, decimals, strings, blocks, and objects. Returns true if values are equivalent.`,
+integers, decimals, strings, blocks, and objects. Returns true if values are equivalent.
+Numbers of different types are promoted as for < and >, so 1 = 1.0f is true.`,
 			Parameters: []ParamDoc{
 				{Name: "left", Type: "any-type!", Description: "The first value to compare", Optional: false},
 				{Name: "right", Type: "any-type!", Description: "The second value to compare", Optional: false},
 			},
 			Returns:  "[logic!] True if values are equal, false otherwise",
-			Examples: []string{"5 = 5  ; => true", "3 = 4  ; => false", `"hello" = "hello"  ; => true`},
+			Examples: []string{"5 = 5  ; => true", "3 = 4  ; => false", "1 = 1.0f  ; => true", `"hello" = "hello"  ; => true`},
 			SeeAlso:  []string{"<>", "<", ">", "<=", ">="}, Tags: []string{"comparison", "equality", "logic"},
 		},
 	))
@@ -353,7 +358,7 @@ In viro, any non-zero integer and non-empty string is considered true; zero, emp
 				{Name: "value", Type: "logic! integer!", Description: "The boolean value to negate", Optional: false},
 			},
 			Returns:  "[logic!] The negated boolean value",
-			Examples: []string{"not true  ; => false", "not false  ; => true", "not 0  ; => true", "not 1  ; => false"},
+			Examples: []string{"not true  ; => false", "not false  ; => true", "not 0  ; => false", "not 1  ; => false"},
 			SeeAlso:  []string{"and", "or"}, Tags: []string{"logic", "boolean", "not", "negation"},
 		},
 	))
@@ -373,10 +378,10 @@ In viro, any non-zero integer and non-empty string is considered true; zero, emp
 			Description: `Converts an integer or string to a decimal value with arbitrary precision.
 Useful for financial calculations and when standard floating-point arithmetic is insufficient.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal! string!", Description: "The value to convert to decimal", Optional: false},
+				{Name: "value", Type: "integer! float! decimal! string!", Description: "The value to convert to decimal", Optional: false},
 			},
 			Returns:  "[decimal!] The decimal representation of the input value",
-			Examples: []string{`decimal 42  ; => 42.0`, `decimal "3.14159265358979323846"  ; => 3.14159265358979323846`},
+			Examples: []string{`decimal 42  ; => 42`, `decimal "3.14159265358979323846"  ; => 3.14159265358979323846`},
 			SeeAlso:  []string{"pow", "sqrt"}, Tags: []string{"conversion", "decimal", "precision"},
 		},
 	))
@@ -393,13 +398,14 @@ Useful for financial calculations and when standard floating-point arithmetic is
 			Category: "Math",
 			Summary:  "Raises a number to a power",
 			Description: `Calculates base raised to the exponent power (base^exponent).
-Always returns a decimal for precision. Supports negative exponents for fractional results.`,
+Returns a decimal for precision, or a float when either argument is a float (and neither is a decimal).
+Supports negative exponents for fractional results.`,
 			Parameters: []ParamDoc{
-				{Name: "base", Type: "integer! decimal!", Description: "The base number", Optional: false},
-				{Name: "exponent", Type: "integer! decimal!", Description: "The power to raise the base to", Optional: false},
+				{Name: "base", Type: "integer! float! decimal!", Description: "The base number", Optional: false},
+				{Name: "exponent", Type: "integer! float! decimal!", Description: "The power to raise the base to", Optional: false},
 			},
-			Returns:  "[decimal!] The result of base^exponent",
-			Examples: []string{"pow 2 8  ; => 256.0", "pow 10 -2  ; => 0.01", "pow 2.5 2  ; => 6.25"},
+			Returns:  "[decimal! float!] The result of base^exponent",
+			Examples: []string{"pow 2 8  ; => 256.00", "pow 10 -2  ; => 0.01", "pow 2.5 2  ; => 6.25"},
 			SeeAlso:  []string{"sqrt", "exp", "*"}, Tags: []string{"math", "power", "exponent"},
 		},
 	))
@@ -414,13 +420,13 @@ Always returns a decimal for precision. Supports negative exponents for fraction
 		&NativeDoc{
 			Category: "Math",
 			Summary:  "Calculates the square root of a number",
-			Description: `Returns the square root of a number. Always returns a decimal for precision.
-Raises an error if the input is negative.`,
+			Description: `Returns the square root of a number. Returns a decimal for precision, or a float for a float input.
+Raises an error if a decimal or integer input is negative; a negative float gives NaN.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to take the square root of (must be non-negative)", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to take the square root of (must be non-negative)", Optional: false},
 			},
-			Returns:  "[decimal!] The square root of the input",
-			Examples: []string{"sqrt 16  ; => 4.0", "sqrt 2  ; => 1.414213562373095", "sqrt 0  ; => 0.0"},
+			Returns:  "[decimal! float!] The square root of the input",
+			Examples: []string{"sqrt 16  ; => 4.00", "sqrt 2  ; => 1.41", "sqrt 0  ; => 0.00", "sqrt 2f  ; => 1.4142135623730951f"},
 			SeeAlso:  []string{"pow", "exp"}, Tags: []string{"math", "root", "square"},
 		},
 	))
@@ -438,10 +444,10 @@ Raises an error if the input is negative.`,
 			Description: `Returns e (Euler's number, approximately 2.71828) raised to the given power.
 Useful for exponential growth calculations and mathematical analysis.`,
 			Parameters: []ParamDoc{
-				{Name: "exponent", Type: "integer! decimal!", Description: "The power to raise e to", Optional: false},
+				{Name: "exponent", Type: "integer! float! decimal!", Description: "The power to raise e to", Optional: false},
 			},
-			Returns:  "[decimal!] The value of e^exponent",
-			Examples: []string{"exp 0  ; => 1.0", "exp 1  ; => 2.718281828459045", "exp 2  ; => 7.38905609893065"},
+			Returns:  "[decimal! float!] The value of e^exponent",
+			Examples: []string{"exp 0  ; => 1.00", "exp 1  ; => 2.72", "exp 2  ; => 7.39"},
 			SeeAlso:  []string{"log", "pow"}, Tags: []string{"math", "exponential", "euler"},
 		},
 	))
@@ -459,10 +465,10 @@ Useful for exponential growth calculations and mathematical analysis.`,
 			Description: `Returns the natural logarithm (ln) of a number, which is the logarithm to base e.
 The input must be positive. This is the inverse of the exp function.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to take the logarithm of (must be positive)", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to take the logarithm of (must be positive)", Optional: false},
 			},
-			Returns:  "[decimal!] The natural logarithm of the input",
-			Examples: []string{"log 1  ; => 0.0", "log 2.718281828459045  ; => 1.0", "log 10  ; => 2.302585092994046"},
+			Returns:  "[decimal! float!] The natural logarithm of the input",
+			Examples: []string{"log 1  ; => 0.00", "log 2.718281828459045  ; => 1.0", "log 10  ; => 2.30"},
 			SeeAlso:  []string{"exp", "log-10", "pow"}, Tags: []string{"math", "logarithm", "natural"},
 		},
 	))
@@ -480,10 +486,10 @@ The input must be positive. This is the inverse of the exp function.`,
 			Description: `Returns the logarithm to base 10 of a number. The input must be positive.
 Useful for scientific calculations and order-of-magnitude estimations.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to take the logarithm of (must be positive)", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to take the logarithm of (must be positive)", Optional: false},
 			},
-			Returns:  "[decimal!] The base-10 logarithm of the input",
-			Examples: []string{"log-10 1  ; => 0.0", "log-10 10  ; => 1.0", "log-10 100  ; => 2.0", "log-10 1000  ; => 3.0"},
+			Returns:  "[decimal! float!] The base-10 logarithm of the input",
+			Examples: []string{"log-10 1  ; => 0.00", "log-10 10  ; => 1.00", "log-10 100  ; => 2.00", "log-10 1000  ; => 3.00"},
 			SeeAlso:  []string{"log", "exp", "pow"}, Tags: []string{"math", "logarithm", "base10"},
 		},
 	))
@@ -501,10 +507,10 @@ Useful for scientific calculations and order-of-magnitude estimations.`,
 			Description: `Returns the sine of an angle given in radians.
 Use multiplication by pi/180 to convert from degrees to radians.`,
 			Parameters: []ParamDoc{
-				{Name: "angle", Type: "integer! decimal!", Description: "The angle in radians", Optional: false},
+				{Name: "angle", Type: "integer! float! decimal!", Description: "The angle in radians", Optional: false},
 			},
-			Returns:  "[decimal!] The sine of the angle",
-			Examples: []string{"sin 0  ; => 0.0", "sin 1.5707963267948966  ; => 1.0 (pi/2)", "sin 3.141592653589793  ; => 0.0 (pi)"},
+			Returns:  "[decimal! float!] The sine of the angle",
+			Examples: []string{"sin 0  ; => 0.0000000000", "sin 1.5707963267948966  ; => 1.0000000000 (pi/2)", "sin 3.141592653589793  ; => 0.0000000000 (pi)"},
 			SeeAlso:  []string{"cos", "tan", "asin"}, Tags: []string{"math", "trigonometry", "sine"},
 		},
 	))
@@ -522,10 +528,10 @@ Use multiplication by pi/180 to convert from degrees to radians.`,
 			Description: `Returns the cosine of an angle given in radians.
 Use multiplication by pi/180 to convert from degrees to radians.`,
 			Parameters: []ParamDoc{
-				{Name: "angle", Type: "integer! decimal!", Description: "The angle in radians", Optional: false},
+				{Name: "angle", Type: "integer! float! decimal!", Description: "The angle in radians", Optional: false},
 			},
-			Returns:  "[decimal!] The cosine of the angle",
-			Examples: []string{"cos 0  ; => 1.0", "cos 1.5707963267948966  ; => 0.0 (pi/2)", "cos 3.141592653589793  ; => -1.0 (pi)"},
+			Returns:  "[decimal! float!] The cosine of the angle",
+			Examples: []string{"cos 0  ; => 1.0000000000", "cos 1.5707963267948966  ; => 0.0000000000 (pi/2)", "cos 3.141592653589793  ; => -1.0000000000 (pi)"},
 			SeeAlso:  []string{"sin", "tan", "acos"}, Tags: []string{"math", "trigonometry", "cosine"},
 		},
 	))
@@ -543,10 +549,10 @@ Use multiplication by pi/180 to convert from degrees to radians.`,
 			Description: `Returns the tangent of an angle given in radians.
 Use multiplication by pi/180 to convert from degrees to radians.`,
 			Parameters: []ParamDoc{
-				{Name: "angle", Type: "integer! decimal!", Description: "The angle in radians", Optional: false},
+				{Name: "angle", Type: "integer! float! decimal!", Description: "The angle in radians", Optional: false},
 			},
-			Returns:  "[decimal!] The tangent of the angle",
-			Examples: []string{"tan 0  ; => 0.0", "tan 0.7853981633974483  ; => 1.0 (pi/4)"},
+			Returns:  "[decimal! float!] The tangent of the angle",
+			Examples: []string{"tan 0  ; => 0.0000000000", "tan 0.7853981633974483  ; => 1.0000000000 (pi/4)"},
 			SeeAlso:  []string{"sin", "cos", "atan"}, Tags: []string{"math", "trigonometry", "tangent"},
 		},
 	))
@@ -564,10 +570,10 @@ Use multiplication by pi/180 to convert from degrees to radians.`,
 			Description: `Returns the angle in radians whose sine is the given value.
 The input must be between -1 and 1 (inclusive). Result is in range [-pi/2, pi/2].`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The sine value (must be between -1 and 1)", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The sine value (must be between -1 and 1)", Optional: false},
 			},
-			Returns:  "[decimal!] The angle in radians",
-			Examples: []string{"asin 0  ; => 0.0", "asin 1  ; => 1.5707963267948966 (pi/2)", "asin -1  ; => -1.5707963267948966 (-pi/2)"},
+			Returns:  "[decimal! float!] The angle in radians",
+			Examples: []string{"asin 0  ; => 0.0000000000", "asin 1  ; => 1.5707963267 (pi/2)", "asin -1  ; => -1.5707963267 (-pi/2)"},
 			SeeAlso:  []string{"sin", "acos", "atan"}, Tags: []string{"math", "trigonometry", "arcsine", "inverse"},
 		},
 	))
@@ -585,10 +591,10 @@ The input must be between -1 and 1 (inclusive). Result is in range [-pi/2, pi/2]
 			Description: `Returns the angle in radians whose cosine is the given value.
 The input must be between -1 and 1 (inclusive). Result is in range [0, pi].`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The cosine value (must be between -1 and 1)", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The cosine value (must be between -1 and 1)", Optional: false},
 			},
-			Returns:  "[decimal!] The angle in radians",
-			Examples: []string{"acos 1  ; => 0.0", "acos 0  ; => 1.5707963267948966 (pi/2)", "acos -1  ; => 3.141592653589793 (pi)"},
+			Returns:  "[decimal! float!] The angle in radians",
+			Examples: []string{"acos 1  ; => 0.0000000000", "acos 0  ; => 1.5707963267 (pi/2)", "acos -1  ; => 3.1415926535 (pi)"},
 			SeeAlso:  []string{"cos", "asin", "atan"}, Tags: []string{"math", "trigonometry", "arccosine", "inverse"},
 		},
 	))
@@ -606,10 +612,10 @@ The input must be between -1 and 1 (inclusive). Result is in range [0, pi].`,
 			Description: `Returns the angle in radians whose tangent is the given value.
 Accepts any real number as input. Result is in range (-pi/2, pi/2).`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The tangent value", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The tangent value", Optional: false},
 			},
-			Returns:  "[decimal!] The angle in radians",
-			Examples: []string{"atan 0  ; => 0.0", "atan 1  ; => 0.7853981633974483 (pi/4)", "atan -1  ; => -0.7853981633974483 (-pi/4)"},
+			Returns:  "[decimal! float!] The angle in radians",
+			Examples: []string{"atan 0  ; => 0.0000000000", "atan 1  ; => 0.7853981633 (pi/4)", "atan -1  ; => -0.7853981633 (-pi/4)"},
 			SeeAlso:  []string{"tan", "asin", "acos"}, Tags: []string{"math", "trigonometry", "arctangent", "inverse"},
 		},
 	))
@@ -618,20 +624,104 @@ Accepts any real number as input. Result is in range (-pi/2, pi/2).`,
 		"round",
 		[]value.ParamSpec{
 			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("to", true),
+			value.NewRefinementSpec("mode", true),
 		},
 		Round,
 		false,
 		&NativeDoc{
 			Category: "Math",
-			Summary:  "Rounds a number to the nearest integer",
-			Description: `Rounds a decimal number to the nearest integer using standard rounding rules
-(0.5 rounds up). Returns an integer value.`,
+			Summary:  "Rounds a number to the nearest integer or multiple",
+			Description: `Rounds a number to the nearest integer, or to the nearest multiple of --to.
+Decimals round with the active decimal context's rounding mode (half-even unless
+changed with with-decimal-context); floats round half to even.
+
+Refinements:
+  --to: Positive number to round to a multiple of (e.g. 0.01 or 0.05)
+  --mode: Rounding mode word: half-even, half-up, half-down, up, down, ceiling, floor`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to round", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to round", Optional: false},
+				{Name: "--to", Type: "integer! float! decimal!", Description: "Rounding step", Optional: true},
+				{Name: "--mode", Type: "word!", Description: "Rounding mode", Optional: true},
 			},
-			Returns:  "[integer!] The rounded integer value",
-			Examples: []string{"round 3.2  ; => 3", "round 3.7  ; => 4", "round 3.5  ; => 4", "round -2.5  ; => -2"},
-			SeeAlso:  []string{"ceil", "floor", "truncate"}, Tags: []string{"math", "rounding"},
+			Returns:  "[decimal! float!] The rounded value",
+			Examples: []string{"round 3.7  ; => 4", "round 2.5  ; => 2", "round --mode 'half-up 2.5  ; => 3", "round --to 0.01 2.675  ; => 2.68", "round --to 0.05 --mode 'down 1.27  ; => 1.25"},
+			SeeAlso:  []string{"ceil", "floor", "truncate", "with-decimal-context"}, Tags: []string{"math", "rounding"},
+		},
+	))
+
+	registerAndBind("with-decimal-context", value.NewNativeFunction(
+		"with-decimal-context",
+		[]value.ParamSpec{
+			value.NewParamSpec("spec", true),
+			value.NewParamSpec("body", true),
+		},
+		WithDecimalContext,
+		false,
+		&NativeDoc{
+			Category: "Math",
+			Summary:  "Evaluates a block with a different decimal precision and rounding",
+			Description: `Evaluates body with a decimal context built from spec, then restores the
+previous context, also when body raises an error. Fields that spec does not
+mention keep their current value, so contexts nest.
+
+Spec fields:
+  precision: Significant digits kept in decimal results (1-34, default 34)
+  rounding: Rounding mode word: half-even (default), half-up, half-down, up, down, ceiling, floor
+  traps: When true, a result that must be rounded raises decimal-precision instead`,
+			Parameters: []ParamDoc{
+				{Name: "spec", Type: "block!", Description: "Context fields as field: value pairs", Optional: false},
+				{Name: "body", Type: "block!", Description: "The block to evaluate", Optional: false},
+			},
+			Returns:  "[any-type!] The result of body",
+			Examples: []string{"with-decimal-context [precision: 5] [(decimal \"2\") / 3]", "with-decimal-context [rounding: 'half-up] [round 2.5]  ; => 3", "with-decimal-context [traps: true] [1.0 / 4]  ; => 0.25"},
+			SeeAlso:  []string{"decimal-context", "round", "format-money"}, Tags: []string{"math", "decimal", "precision", "rounding"},
+		},
+	))
+
+	registerAndBind("decimal-context", value.NewNativeFunction(
+		"decimal-context",
+		[]value.ParamSpec{},
+		DecimalContextNative,
+		false,
+		&NativeDoc{
+			Category:    "Math",
+			Summary:     "Returns the active decimal context",
+			Description: `Returns an object with the precision, rounding and traps fields of the decimal context currently in effect.`,
+			Parameters:  []ParamDoc{},
+			Returns:     "[object!] The active decimal context",
+			Examples:    []string{"ctx: decimal-context ctx.precision  ; => 34", "with-decimal-context [rounding: 'floor] [ctx: decimal-context ctx.rounding]  ; => floor"},
+			SeeAlso:     []string{"with-decimal-context"}, Tags: []string{"math", "decimal", "precision"},
+		},
+	))
+
+	registerAndBind("format-money", value.NewNativeFunction(
+		"format-money",
+		[]value.ParamSpec{
+			value.NewParamSpec("amount", true),
+			value.NewRefinementSpec("scale", true),
+			value.NewRefinementSpec("separator", true),
+		},
+		FormatMoneyNative,
+		false,
+		&NativeDoc{
+			Category: "Math",
+			Summary:  "Formats a number with a fixed number of decimal places",
+			Description: `Returns the amount as a string with exactly scale digits after the decimal
+point and the integer digits grouped in thousands. The amount is rounded with the
+active decimal context's rounding mode; floats convert through their shortest form.
+
+Refinements:
+  --scale: Digits after the decimal point (0-34, default 2)
+  --separator: Thousands separator (default ",", use "" for none)`,
+			Parameters: []ParamDoc{
+				{Name: "amount", Type: "integer! float! decimal!", Description: "The amount to format", Optional: false},
+				{Name: "--scale", Type: "integer!", Description: "Digits after the decimal point", Optional: true},
+				{Name: "--separator", Type: "string!", Description: "Thousands separator", Optional: true},
+			},
+			Returns:  "[string!] The formatted amount",
+			Examples: []string{`format-money 1234.5  ; => "1,234.50"`, `format-money --scale 0 -1999.5  ; => "-2,000"`, `format-money --separator "'" 1234567.891  ; => "1'234'567.89"`},
+			SeeAlso:  []string{"round", "with-decimal-context", "format"}, Tags: []string{"math", "decimal", "money", "format"},
 		},
 	))
 
@@ -648,7 +738,7 @@ Accepts any real number as input. Result is in range (-pi/2, pi/2).`,
 			Description: `Returns the smallest integer greater than or equal to the input (ceiling function).
 Always rounds upward, even for negative numbers.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to round up", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to round up", Optional: false},
 			},
 			Returns:  "[integer!] The ceiling value",
 			Examples: []string{"ceil 3.1  ; => 4", "ceil 3.9  ; => 4", "ceil -2.1  ; => -2", "ceil 5  ; => 5"},
@@ -669,7 +759,7 @@ Always rounds upward, even for negative numbers.`,
 			Description: `Returns the largest integer less than or equal to the input (floor function).
 Always rounds downward, even for negative numbers.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to round down", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to round down", Optional: false},
 			},
 			Returns:  "[integer!] The floor value",
 			Examples: []string{"floor 3.1  ; => 3", "floor 3.9  ; => 3", "floor -2.1  ; => -3", "floor 5  ; => 5"},
@@ -690,11 +780,53 @@ Always rounds downward, even for negative numbers.`,
 			Description: `Removes the fractional part of a number, rounding toward zero.
 For positive numbers, behaves like floor; for negative numbers, behaves like ceil.`,
 			Parameters: []ParamDoc{
-				{Name: "value", Type: "integer! decimal!", Description: "The number to truncate", Optional: false},
+				{Name: "value", Type: "integer! float! decimal!", Description: "The number to truncate", Optional: false},
 			},
 			Returns:  "[integer!] The truncated integer value",
 			Examples: []string{"truncate 3.7  ; => 3", "truncate -3.7  ; => -3", "truncate 5  ; => 5"},
 			SeeAlso:  []string{"floor", "ceil", "round"}, Tags: []string{"math", "rounding", "truncate"},
 		},
 	))
+
+	registerAndBind("random", value.NewNativeFunction(
+		"random",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("seed", false),
+			value.NewRefinementSpec("secure", false),
+			value.NewRefinementSpec("only", false),
+			value.NewRefinementSpec("shuffle", false),
+		},
+		RandomNative,
+		false,
+		&NativeDoc{
+			Category: "Math",
+			Summary:  "Returns a random number, picks a random element or shuffles a series",
+			Description: `For an integer! or bigint! n, returns a value from 1 to n (n to -1 when n is negative).
+For a decimal! or float! x, returns a value of the same type in [0, x), so random 1.0 is a
+decimal fraction. Series need --only or --shuffle.
+
+Each evaluator has its own generator, seeded unpredictably at startup. random --seed makes
+the sequence that follows repeatable, which keeps generated test data stable.
+
+Refinements:
+  --seed: Reseed the generator with the integer value and return none
+  --secure: Draw from the operating system's cryptographic generator (ignores the seed)
+  --only: Return a random element of the series from its current position (none if empty)
+  --shuffle: Shuffle the whole series in place and return it`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "integer! bigint! decimal! float! series!", Description: "The upper limit, the series, or the seed", Optional: false},
+			},
+			Returns: "[integer! bigint! decimal! float! any-type! series! none!] The random value",
+			Examples: []string{
+				"random 6  ; an integer from 1 to 6",
+				"random 1.0  ; a decimal in [0, 1)",
+				`random --only ["red" "green" "blue"]`,
+				"random --shuffle [1 2 3 4 5]",
+				"random --seed 42  ; later results repeat for the same seed",
+				"random --secure 1000000",
+			},
+			SeeAlso: []string{"round", "sort"}, Tags: []string{"math", "random", "shuffle", "sampling"},
+		},
+	))
 }
@@ -199,6 +199,7 @@ func registerStringSeriesActions() {
 		value.NewParamSpec("series", true),
 		value.NewParamSpec("value", true),
 		value.NewRefinementSpec("last", false),
+		value.NewRefinementSpec("regex", false),
 	}, StringFind, false, nil))
 	RegisterActionImpl(value.TypeString, "remove", value.NewNativeFunction("remove", []value.ParamSpec{
 		value.NewParamSpec("series", true),
@@ -643,10 +644,12 @@ func RegisterSeriesNatives(rootFrame core.Frame) {
 			{Name: "index", Type: "integer!", Description: "1-based index of the element to set"},
 			{Name: "value", Type: "any!", Description: "The new value to set at the index"},
 		},
-		Returns:  "any! The value that was set",
-		Examples: []string{"poke [1 2 3] 2 99  ; => 99, series becomes [1 99 3]", `poke "hello" 1 "H"  ; => "H", series becomes "Hello"`},
-		SeeAlso:  []string{"at", "change", "insert"},
-		Tags:     []string{"series", "modification", "indexing"},
+		Returns: "any! The value that was set",
+		Examples: []string{"s: [1 2 3]\npoke s 2 99  ; => 99\ns  ; => [1 99 3]", `s: "hello"
+poke s 1 "H"  ; => "H"
+s  ; => "Hello"`},
+		SeeAlso: []string{"at", "change", "insert"},
+		Tags:    []string{"series", "modification", "indexing"},
 	}))
 
 	registerAndBind("select", CreateAction("select", []value.ParamSpec{
@@ -673,7 +676,7 @@ The --default refinement provides a fallback when the value/field is not found.`
 			"select [a 1 b 2] 'b  ; => 2",
 			`select "hello world" " "  ; => "world"`,
 			"obj: object [x: 10]\nselect obj 'x  ; => 10",
-			"select obj 'missing --default 99  ; => 99",
+			"obj: object [x: 10]\nselect obj 'missing --default 99  ; => 99",
 		},
 		SeeAlso: []string{"find", "at", "index?", "put", "get"},
 		Tags:    []string{"series", "search", "objects", "lookup"},
@@ -688,7 +691,7 @@ The --default refinement provides a fallback when the value/field is not found.`
 			{Name: "series", Type: "block! string! binary!", Description: "The series to clear"},
 		},
 		Returns:  "block! string! binary! The cleared series (same reference)",
-		Examples: []string{"clear [1 2 3]  ; => [], series becomes empty with index at head", `clear "hello"  ; => "", series becomes empty with index at head`},
+		Examples: []string{"clear [1 2 3]  ; => []", `clear "hello"  ; => ""`},
 		SeeAlso:  []string{"append", "insert", "remove"},
 		Tags:     []string{"series", "modification"},
 	}))
@@ -703,10 +706,12 @@ The --default refinement provides a fallback when the value/field is not found.`
 			{Name: "series", Type: "block! string! binary!", Description: "The series to modify"},
 			{Name: "value", Type: "any!", Description: "The new value to set at current index (string: single character, binary: 0-255)"},
 		},
-		Returns:  "any! The value that was set",
-		Examples: []string{"change next [1 2 3] 99  ; => 99, series becomes [1 99 3]", `change next "hello" "H"  ; => "H", series becomes "Hello" (single char only)`},
-		SeeAlso:  []string{"poke", "at", "insert"},
-		Tags:     []string{"series", "modification"},
+		Returns: "any! The value that was set",
+		Examples: []string{"s: next [1 2 3]\nchange s 99  ; => 99\nhead s  ; => [1 99 3]", `s: "hello"
+change s "H"  ; => "H"
+s  ; => "Hello"`},
+		SeeAlso: []string{"poke", "at", "insert"},
+		Tags:    []string{"series", "modification"},
 	}))
 
 	registerAndBind("trim", CreateAction("trim", []value.ParamSpec{
@@ -812,6 +817,7 @@ Error example:
 		value.NewParamSpec("series", true),
 		value.NewParamSpec("value", true),
 		value.NewRefinementSpec("last", false),
+		value.NewRefinementSpec("regex", false),
 	}, &NativeDoc{
 		Category: "Series",
 		Summary:  "Finds a value in a series",
@@ -819,10 +825,11 @@ Error example:
 			{Name: "series", Type: "block! string! binary!", Description: "The series to search"},
 			{Name: "value", Type: "any!", Description: "The value to find"},
 			{Name: "--last", Type: "", Description: "Find last occurrence instead of first", Optional: true},
+			{Name: "--regex", Type: "", Description: "Treat value as a regular expression (string! only)", Optional: true},
 		},
 		Returns:  "integer! 1-based index or none",
-		Examples: []string{"find [1 2 3] 2  ; => 2", `find "hello" "l"  ; => 3`, "find #{DEADBEEF} 190  ; => 3"},
-		SeeAlso:  []string{"first", "last"},
+		Examples: []string{"find [1 2 3] 2  ; => 2", `find "hello" "l"  ; => 3`, "find #{DEADBEEF} 190  ; => 3", `find --regex "order 66 shipped" "[0-9]+"  ; => 7`},
+		SeeAlso:  []string{"first", "last", "matches?", "match"},
 		Tags:     []string{"series", "search"},
 	}))
 
@@ -844,7 +851,7 @@ Zero count is a no-op. Oversized counts (where index+count exceeds length) raise
 			"remove [1 2 3]  ; => [2 3]",
 			"remove --part 2 [1 2 3]  ; => [3]",
 			`remove "hello"  ; => "ello"`,
-			"remove #{DEADBEEF}  ; => #{ADBE}",
+			"remove #{DEADBEEF}  ; => #{ADBEEF}",
 			"remove --part 0 [1 2 3]  ; => [1 2 3] (no-op)",
 		},
 		SeeAlso: []string{"append", "insert", "clear"},
@@ -862,7 +869,7 @@ Zero count is a no-op. Oversized counts (where index+count exceeds length) raise
 			{Name: "count", Type: "integer!", Description: "Number of elements to skip"},
 		},
 		Returns:  "block! string! binary! Series with index advanced by count",
-		Examples: []string{"skip [1 2 3 4] 2  ; => [1 2 3 4] (index at 3)", `skip "hello" 2  ; => "hello" (index at 3)`, "skip #{DEADBEEF} 2  ; => #{DEADBEEF} (index at 3)"},
+		Examples: []string{"skip [1 2 3 4] 2  ; => [3 4] (index at 3)", `skip "hello" 2  ; => "llo" (index at 3)`, "skip #{DEADBEEF} 2  ; => #{BEEF} (index at 3)"},
 		SeeAlso:  []string{"take", "first", "last"},
 		Tags:     []string{"series"},
 	}))
@@ -876,7 +883,7 @@ Zero count is a no-op. Oversized counts (where index+count exceeds length) raise
 			{Name: "series", Type: "block! string! binary!", Description: "The series to advance"},
 		},
 		Returns:  "block! string! binary! New series reference at next position",
-		Examples: []string{"next [1 2 3]  ; => [1 2 3] (index at 2)", `next "hello"  ; => "hello" (index at 2)`, "next #{DEADBEEF}  ; => #{DEADBEEF} (index at 2)"},
+		Examples: []string{"next [1 2 3]  ; => [2 3] (index at 2)", `next "hello"  ; => "ello" (index at 2)`, "next #{DEADBEEF}  ; => #{ADBEEF} (index at 2)"},
 		SeeAlso:  []string{"skip", "back", "head", "tail"},
 		Tags:     []string{"series", "navigation"},
 	}))
@@ -918,7 +925,7 @@ Zero count is a no-op. Oversized counts (where index+count exceeds length) raise
 			{Name: "series", Type: "block! string! binary!", Description: "The series to position at tail"},
 		},
 		Returns:  "block! string! binary! New series reference at tail position",
-		Examples: []string{"tail [1 2 3 4]  ; => [1 2 3 4] (index at 4)", `tail "hello"  ; => "hello" (index at 5)`, "tail #{DEADBEEF}  ; => #{DEADBEEF} (index at 4)"},
+		Examples: []string{"tail [1 2 3 4]  ; => [] (index at 5)", `tail "hello"  ; => "" (index at 6)`, "tail #{DEADBEEF}  ; => #{} (index at 5)"},
 		SeeAlso:  []string{"head", "next", "back"},
 		Tags:     []string{"series", "navigation"},
 	}))
@@ -1031,7 +1038,7 @@ Oversized counts are clamped to the remaining elements from current position.`,
 			{Name: "series", Type: "block! string! binary!", Description: "The series to reverse"},
 		},
 		Returns:  "block! string! binary! The reversed series",
-		Examples: []string{"reverse [1 2 3]  ; => [3 2 1]", `reverse "hello"  ; => "olleh"`, "reverse #{DEADBEEF}  ; => #{EFBEADDE}"},
+		Examples: []string{"reverse [1 2 3]  ; => [3 2 1]", `reverse "hello"  ; => "olleh"`},
 		SeeAlso:  []string{"sort"},
 		Tags:     []string{"series"},
 	}))
@@ -1041,6 +1048,7 @@ Oversized counts are clamped to the remaining elements from current position.`,
 		[]value.ParamSpec{
 			value.NewParamSpec("string", true),
 			value.NewParamSpec("delimiter", true),
+			value.NewRefinementSpec("regex", false),
 		},
 		StringSplit,
 		false,
@@ -1048,15 +1056,367 @@ Oversized counts are clamped to the remaining elements from current position.`,
 			Category: "Series",
 			Summary:  "Splits a string by delimiter into a block of strings",
 			Description: `Splits a string by a delimiter and returns a block containing the resulting substrings.
-Empty delimiter is not allowed and will raise an error. Consecutive delimiters create empty strings in the result.`,
+Empty delimiter is not allowed and will raise an error. Consecutive delimiters create empty strings in the result.
+
+Refinements:
+  --regex: The delimiter is a regular expression; the string is split on every match`,
 			Parameters: []ParamDoc{
 				{Name: "string", Type: "string!", Description: "The string to split"},
 				{Name: "delimiter", Type: "string!", Description: "The delimiter to split by (cannot be empty)"},
 			},
 			Returns:  "[block!] Block containing the split string parts",
-			Examples: []string{`split "hello world" " "  ; => ["hello" "world"]`, `split "a,b,c" ","  ; => ["a" "b" "c"]`, `split "a,,b" ","  ; => ["a" "" "b"]`},
-			SeeAlso:  []string{"join", "form", "mold"},
-			Tags:     []string{"series", "string", "split"},
+			Examples: []string{`split "hello world" " "  ; => ["hello" "world"]`, `split "a,b,c" ","  ; => ["a" "b" "c"]`, `split "a,,b" ","  ; => ["a" "" "b"]`, `split --regex "a, b;c" "[,;] *"  ; => ["a" "b" "c"]`},
+			SeeAlso:  []string{"join", "form", "mold", "match"},
+			Tags:     []string{"series", "string", "split", "regex"},
+		}))
+
+	registerAndBind("matches?", value.NewNativeFunction(
+		"matches?",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewParamSpec("pattern", true),
+		},
+		MatchesNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Tests whether a regular expression matches a string",
+			Description: `Returns true if the pattern matches anywhere in the string. Patterns use RE2
+syntax (as in Go's regexp package); anchor with ^ and $ to require a full match.
+An invalid pattern raises an invalid-regex error.`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to test"},
+				{Name: "pattern", Type: "string!", Description: "The regular expression"},
+			},
+			Returns:  "[logic!] true if the pattern matches",
+			Examples: []string{`matches? "ERROR: disk full" "^(ERROR|WARN):"  ; => true`, `matches? "abc" "^[0-9]+$"  ; => false`},
+			SeeAlso:  []string{"match", "find", "replace"},
+			Tags:     []string{"series", "string", "regex", "match"},
+		}))
+
+	registerAndBind("match", value.NewNativeFunction(
+		"match",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewParamSpec("pattern", true),
+			value.NewRefinementSpec("all", false),
+			value.NewRefinementSpec("named", false),
+		},
+		MatchNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Returns the capture groups of a regular expression match",
+			Description: `Matches the pattern against the string and returns a block with the whole
+match followed by each capture group. Groups that did not participate are none.
+Returns none if the pattern does not match.
+
+Refinements:
+  --all: Return a block with one capture block per non-overlapping match
+  --named: Return an object with a field per named group (?P<name>...) instead of a block`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to search"},
+				{Name: "pattern", Type: "string!", Description: "The regular expression"},
+			},
+			Returns: "[block! object! none!] Captures of the match, or none",
+			Examples: []string{
+				`match "2024-01-15 ERROR disk" "([0-9-]+) ([A-Z]+)"  ; => ["2024-01-15 ERROR" "2024-01-15" "ERROR"]`,
+				`m: match --named "user=ann id=7" "user=(?P<user>[a-z]+) id=(?P<id>[0-9]+)"
+m.user  ; => "ann"`,
+				`match --all "a1 b2" "([a-z])([0-9])"  ; => [["a1" "a" "1"] ["b2" "b" "2"]]`,
+			},
+			SeeAlso: []string{"matches?", "find", "replace", "split"},
+			Tags:    []string{"series", "string", "regex", "match", "capture"},
+		}))
+
+	registerAndBind("replace", value.NewNativeFunction(
+		"replace",
+		[]value.ParamSpec{
+			value.NewParamSpec("target", true),
+			value.NewParamSpec("search", true),
+			value.NewParamSpec("replacement", true),
+			value.NewRefinementSpec("all", false),
+			value.NewRefinementSpec("regex", false),
+			value.NewRefinementSpec("ignore-case", false),
+		},
+		ReplaceNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Replaces occurrences in a string",
+			Description: `Replaces the first occurrence of search in the target string, or every
+occurrence with --all. The target is modified and returned. Literal searches
+are case-sensitive, like find, unless --ignore-case is given.
+
+The replacement is a string or a function. A function is called once per match
+with the capture block [whole group1 ...] and its result is formed into the string.
+
+Refinements:
+  --all: Replace every occurrence instead of only the first
+  --ignore-case: Match a literal search regardless of case
+  --regex: search is a regular expression; a string replacement may use
+           $1 or ${name} to insert capture groups ($$ for a literal $)`,
+			Parameters: []ParamDoc{
+				{Name: "target", Type: "string!", Description: "The string to modify"},
+				{Name: "search", Type: "string!", Description: "The text or pattern to replace"},
+				{Name: "replacement", Type: "string! function!", Description: "Replacement text or function"},
+			},
+			Returns: "[string!] The modified target",
+			Examples: []string{
+				`replace "a-b-c" "-" "+"  ; => "a+b-c"`,
+				`replace --all "a-b-c" "-" "+"  ; => "a+b+c"`,
+				`replace --all "Ab ab" "ab" "x"  ; => "Ab x"`,
+				`replace --all --ignore-case "Ab ab" "ab" "x"  ; => "x x"`,
+				`replace --regex --all "2024-01-15" "([0-9]+)-([0-9]+)-([0-9]+)" "$3/$2/$1"  ; => "15/01/2024"`,
+				`replace --regex --all "a1 b22" "[0-9]+" fn [m] [(to-integer first m) * 2]  ; => "a2 b44"`,
+			},
+			SeeAlso: []string{"match", "find", "split"},
+			Tags:    []string{"series", "string", "regex", "replace"},
+		}))
+
+	registerAndBind("uppercase", value.NewNativeFunction(
+		"uppercase",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewRefinementSpec("part", true),
+		},
+		UppercaseNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Converts a string to upper case",
+			Description: `Converts the string to upper case in place, starting at its current position,
+and returns it. Conversion is per character and covers all Unicode letters.
+
+Refinements:
+  --part: Convert only the given number of characters`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to convert"},
+				{Name: "--part", Type: "integer!", Description: "Number of characters to convert", Optional: true},
+			},
+			Returns:  "[string!] The modified string",
+			Examples: []string{`uppercase "straße"  ; => "STRAßE"`, `uppercase --part 1 "hello"  ; => "Hello"`},
+			SeeAlso:  []string{"lowercase", "trim"},
+			Tags:     []string{"series", "string", "case"},
+		}))
+
+	registerAndBind("lowercase", value.NewNativeFunction(
+		"lowercase",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewRefinementSpec("part", true),
+		},
+		LowercaseNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Converts a string to lower case",
+			Description: `Converts the string to lower case in place, starting at its current position,
+and returns it. Conversion is per character and covers all Unicode letters.
+
+Refinements:
+  --part: Convert only the given number of characters`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to convert"},
+				{Name: "--part", Type: "integer!", Description: "Number of characters to convert", Optional: true},
+			},
+			Returns:  "[string!] The modified string",
+			Examples: []string{`lowercase "ÉCOLE"  ; => "école"`, `lowercase --part 2 "HELLO"  ; => "heLLO"`},
+			SeeAlso:  []string{"uppercase", "trim"},
+			Tags:     []string{"series", "string", "case"},
+		}))
+
+	registerAndBind("pad", value.NewNativeFunction(
+		"pad",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewParamSpec("width", true),
+			value.NewRefinementSpec("left", false),
+			value.NewRefinementSpec("right", false),
+			value.NewRefinementSpec("with", true),
+		},
+		PadNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Pads a string to a minimum width",
+			Description: `Extends the string in place to at least width characters and returns it.
+Strings that are already wide enough are left unchanged.
+
+Refinements:
+  --right: Add the fill after the text (default; left-aligns)
+  --left: Add the fill before the text (right-aligns)
+  --with: Fill character (a one-character string, default space)`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to pad"},
+				{Name: "width", Type: "integer!", Description: "Minimum width in characters"},
+				{Name: "--with", Type: "string!", Description: "Fill character", Optional: true},
+			},
+			Returns:  "[string!] The modified string",
+			Examples: []string{`pad "ab" 5  ; => "ab   "`, `pad --left "42" 6  ; => "    42"`, `pad --left --with "0" "7" 3  ; => "007"`},
+			SeeAlso:  []string{"format", "trim", "repeat-string"},
+			Tags:     []string{"series", "string", "pad", "align"},
+		}))
+
+	registerAndBind("repeat-string", value.NewNativeFunction(
+		"repeat-string",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewParamSpec("count", true),
+		},
+		RepeatStringNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Returns a string repeated a number of times",
+			Description: `Returns a new string made of count copies of the string. A count of zero
+gives an empty string; a negative count is an error.`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to repeat"},
+				{Name: "count", Type: "integer!", Description: "Number of copies"},
+			},
+			Returns:  "[string!] The repeated string",
+			Examples: []string{`repeat-string "-" 10  ; => "----------"`, `repeat-string "ab" 3  ; => "ababab"`},
+			SeeAlso:  []string{"pad", "join"},
+			Tags:     []string{"series", "string", "repeat"},
+		}))
+
+	registerAndBind("starts-with?", value.NewNativeFunction(
+		"starts-with?",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewParamSpec("prefix", true),
+		},
+		StartsWithNative,
+		false,
+		&NativeDoc{
+			Category:    "Series",
+			Summary:     "Tests whether a string begins with a prefix",
+			Description: `Returns true if the string, from its current position, begins with the prefix. Comparison is case-sensitive.`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to test"},
+				{Name: "prefix", Type: "string!", Description: "The expected prefix"},
+			},
+			Returns:  "[logic!] true if the string starts with prefix",
+			Examples: []string{`starts-with? "viro-lang" "viro"  ; => true`},
+			SeeAlso:  []string{"ends-with?", "find", "matches?"},
+			Tags:     []string{"series", "string", "predicate"},
+		}))
+
+	registerAndBind("ends-with?", value.NewNativeFunction(
+		"ends-with?",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewParamSpec("suffix", true),
+		},
+		EndsWithNative,
+		false,
+		&NativeDoc{
+			Category:    "Series",
+			Summary:     "Tests whether a string ends with a suffix",
+			Description: `Returns true if the string ends with the suffix. Comparison is case-sensitive.`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "The string to test"},
+				{Name: "suffix", Type: "string!", Description: "The expected suffix"},
+			},
+			Returns:  "[logic!] true if the string ends with suffix",
+			Examples: []string{`ends-with? "report.viro" ".viro"  ; => true`},
+			SeeAlso:  []string{"starts-with?", "find", "matches?"},
+			Tags:     []string{"series", "string", "predicate"},
+		}))
+
+	registerAndBind("format", value.NewNativeFunction(
+		"format",
+		[]value.ParamSpec{
+			value.NewParamSpec("template", true),
+			value.NewParamSpec("values", true),
+		},
+		FormatNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Formats values into a template string",
+			Description: `Fills printf-style directives in the template with the values of a block
+(reduced first) or with a single value. Each directive is
+%[flags][width][.precision]verb where flags are - (left-align), + (sign),
+0 (zero-pad) or space. Width counts characters. Precision truncates %s to
+that many characters and, as in C, is the number of digits after the point
+for %f and %e (6 by default for %e).
+
+Verbs:
+  %s  any value, formed (precision truncates)
+  %d  integer!
+  %f  integer! or decimal! in fixed-point notation
+  %e  integer! or decimal! in scientific notation
+  %x  integer! in hexadecimal (%X for upper case)
+  %%  a literal percent sign
+
+The number of values must match the number of directives.`,
+			Parameters: []ParamDoc{
+				{Name: "template", Type: "string!", Description: "Template with % directives"},
+				{Name: "values", Type: "block! any-type!", Description: "Values for the directives"},
+			},
+			Returns: "[string!] The formatted string",
+			Examples: []string{
+				`format "%-6s|%5d" ["abc" 42]  ; => "abc   |   42"`,
+				`format "%.2f" 3.14159  ; => "3.14"`,
+				`format "%08.3f" 2.5  ; => "0002.500"`,
+				`format "%.2e" 12345.678  ; => "1.23e+04"`,
+				`format "%3d%%" 7  ; => "  7%"`,
+			},
+			SeeAlso: []string{"pad", "form", "to-hex"},
+			Tags:    []string{"series", "string", "format", "printf"},
+		}))
+
+	registerAndBind("to-hex", value.NewNativeFunction(
+		"to-hex",
+		[]value.ParamSpec{
+			value.NewParamSpec("value", true),
+			value.NewRefinementSpec("size", true),
+		},
+		ToHexNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Converts an integer or binary to a hexadecimal string",
+			Description: `Returns the upper-case hexadecimal digits of an integer (with a leading "-" when
+negative) or of each byte of a binary.
+
+Refinements:
+  --size: Pad an integer with leading zeros to this many digits`,
+			Parameters: []ParamDoc{
+				{Name: "value", Type: "integer! binary!", Description: "The value to convert"},
+				{Name: "--size", Type: "integer!", Description: "Minimum number of digits", Optional: true},
+			},
+			Returns:  "[string!] Hexadecimal digits",
+			Examples: []string{`to-hex 255  ; => "FF"`, `to-hex --size 4 255  ; => "00FF"`, `to-hex #{CAFE}  ; => "CAFE"`},
+			SeeAlso:  []string{"from-hex", "format"},
+			Tags:     []string{"string", "hex", "conversion"},
+		}))
+
+	registerAndBind("from-hex", value.NewNativeFunction(
+		"from-hex",
+		[]value.ParamSpec{
+			value.NewParamSpec("string", true),
+			value.NewRefinementSpec("binary", false),
+		},
+		FromHexNative,
+		false,
+		&NativeDoc{
+			Category: "Series",
+			Summary:  "Parses a hexadecimal string",
+			Description: `Parses hexadecimal digits (either case, optional leading "-") into an integer.
+Values that do not fit in integer! raise a math overflow error.
+
+Refinements:
+  --binary: Decode pairs of digits into a binary! instead`,
+			Parameters: []ParamDoc{
+				{Name: "string", Type: "string!", Description: "Hexadecimal digits"},
+			},
+			Returns:  "[integer! binary!] The parsed value",
+			Examples: []string{`from-hex "ff"  ; => 255`, `from-hex --binary "CAFE"  ; => #{CAFE}`},
+			SeeAlso:  []string{"to-hex", "to-integer"},
+			Tags:     []string{"string", "hex", "conversion"},
 		}))
 
 	registerAndBind("intersect", CreateAction("intersect", []value.ParamSpec{
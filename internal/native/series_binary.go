@@ -13,6 +13,9 @@ func BinaryFind(args []core.Value, refValues map[string]core.Value, eval core.Ev
 	if !ok {
 		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"binary", value.TypeToString(args[0].GetType()), ""})
 	}
+	if err := rejectRegex("find", refValues, args[0]); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	sought := args[1]
 	soughtByte, ok := value.AsIntValue(sought)
@@ -11,6 +11,9 @@ func BlockFind(args []core.Value, refValues map[string]core.Value, eval core.Eva
 	if !ok {
 		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"block", value.TypeToString(args[0].GetType()), ""})
 	}
+	if err := rejectRegex("find", refValues, args[0]); err != nil {
+		return value.NewNoneVal(), err
+	}
 
 	sought := args[1]
 
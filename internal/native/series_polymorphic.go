@@ -103,10 +103,12 @@ func seriesAppend(args []core.Value, refValues map[string]core.Value, eval core.
 		return value.NewNoneVal(), err
 	}
 
+	before := seriesVal.Length()
 	err = seriesVal.AppendValue(args[1])
 	if err != nil {
 		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"compatible value", value.TypeToString(args[1].GetType()), ""})
 	}
+	core.CountAllocation(core.AllocGrowth, seriesVal.Length()-before)
 	return args[0], nil
 }
 
@@ -116,10 +118,12 @@ func seriesInsert(args []core.Value, refValues map[string]core.Value, eval core.
 		return value.NewNoneVal(), err
 	}
 
+	before := seriesVal.Length()
 	err = seriesVal.InsertValue(args[1])
 	if err != nil {
 		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"compatible value", value.TypeToString(args[1].GetType()), ""})
 	}
+	core.CountAllocation(core.AllocGrowth, seriesVal.Length()-before)
 	return args[0], nil
 }
 
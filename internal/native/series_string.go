@@ -15,15 +15,21 @@ func StringFind(args []core.Value, refValues map[string]core.Value, eval core.Ev
 	}
 
 	sought := args[1]
-	soughtStr, ok := value.AsStringValue(sought)
-	if !ok {
-		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"string", value.TypeToString(sought.GetType()), ""})
-	}
 
 	// --last refinement: find last occurrence
 	lastVal, hasLast := refValues["last"]
 	isLast := hasLast && lastVal.GetType() == value.TypeLogic && lastVal.Equals(value.NewLogicVal(true))
 
+	// --regex refinement: sought is a pattern
+	if hasRefinement(refValues, "regex") {
+		return regexFind(str, sought, isLast)
+	}
+
+	soughtStr, ok := value.AsStringValue(sought)
+	if !ok {
+		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"string", value.TypeToString(sought.GetType()), ""})
+	}
+
 	haystack := str.String()
 	needle := soughtStr.String()
 
@@ -214,6 +220,11 @@ func StringSplit(args []core.Value, refValues map[string]core.Value, eval core.E
 		return value.NewNoneVal(), verror.NewScriptError(verror.ErrIDTypeMismatch, [3]string{"string", value.TypeToString(args[0].GetType()), ""})
 	}
 
+	// --regex refinement: split on every match of a pattern
+	if hasRefinement(refValues, "regex") {
+		return regexSplit(str, args[1])
+	}
+
 	// Validate second argument is string
 	delimiter, ok := value.AsStringValue(args[1])
 	if !ok {
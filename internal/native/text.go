@@ -0,0 +1,447 @@
+package native
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ericlagergren/decimal"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// Text transformation natives for string!. All of them operate on runes, so
+// lengths, widths and --part counts are in characters, not bytes.
+
+// changeCase implements uppercase and lowercase: converts the string in place
+// from its current index, limited to --part characters when given.
+func changeCase(name string, args []core.Value, refValues map[string]core.Value, convert func(rune) rune) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError(name, 1, len(args))
+	}
+	str, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError(name, "string!", args[0])
+	}
+
+	count, hasPart, err := readPartCount(refValues)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	if !hasPart {
+		count = str.Length() - str.GetIndex()
+	} else if err := validatePartCount(str, count); err != nil {
+		return value.NewNoneVal(), err
+	}
+
+	runes := str.Runes()
+	for i := str.GetIndex(); i < str.GetIndex()+count; i++ {
+		runes[i] = convert(runes[i])
+	}
+	return str, nil
+}
+
+// UppercaseNative implements `uppercase`.
+func UppercaseNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	return changeCase("uppercase", args, refValues, unicode.ToUpper)
+}
+
+// LowercaseNative implements `lowercase`.
+func LowercaseNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	return changeCase("lowercase", args, refValues, unicode.ToLower)
+}
+
+// PadNative implements `pad`: extends the string in place to at least width
+// characters. Fill goes on the right by default (left-aligned text);
+// --left puts it on the left (right-aligned text). --with sets the fill character.
+func PadNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("pad", 2, len(args))
+	}
+	str, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("pad", "string!", args[0])
+	}
+	width, ok := value.AsIntValue(args[1])
+	if !ok {
+		return value.NewNoneVal(), typeError("pad", "integer!", args[1])
+	}
+
+	left := hasRefinement(refValues, "left")
+	if left && hasRefinement(refValues, "right") {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"pad refinements --left and --right are mutually exclusive", "", ""},
+		)
+	}
+
+	fill := ' '
+	if hasWith, withVal := getRefinementValue(refValues, "with"); hasWith {
+		r, err := validateStringValue(withVal)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		fill = r
+	}
+
+	missing := int(width) - str.Length()
+	if missing <= 0 {
+		return str, nil
+	}
+	padding := []rune(strings.Repeat(string(fill), missing))
+	if left {
+		str.SetRunes(append(padding, str.Runes()...))
+	} else {
+		str.SetRunes(append(str.Runes(), padding...))
+	}
+	return str, nil
+}
+
+// RepeatStringNative implements `repeat-string`: a new string holding count copies.
+func RepeatStringNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("repeat-string", 2, len(args))
+	}
+	str, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("repeat-string", "string!", args[0])
+	}
+	count, ok := value.AsIntValue(args[1])
+	if !ok {
+		return value.NewNoneVal(), typeError("repeat-string", "integer!", args[1])
+	}
+	if count < 0 {
+		return value.NewNoneVal(), verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{"repeat-string count must not be negative", "", ""},
+		)
+	}
+	return value.NewStrVal(strings.Repeat(str.Form(), int(count))), nil
+}
+
+// affixArgs validates the arguments shared by starts-with? and ends-with?.
+func affixArgs(name string, args []core.Value) (string, string, error) {
+	if len(args) != 2 {
+		return "", "", arityError(name, 2, len(args))
+	}
+	str, ok := value.AsStringValue(args[0])
+	if !ok {
+		return "", "", typeError(name, "string!", args[0])
+	}
+	affix, ok := value.AsStringValue(args[1])
+	if !ok {
+		return "", "", typeError(name, "string!", args[1])
+	}
+	return str.Form(), affix.Form(), nil
+}
+
+// StartsWithNative implements `starts-with?`.
+func StartsWithNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	s, prefix, err := affixArgs("starts-with?", args)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return value.NewLogicVal(strings.HasPrefix(s, prefix)), nil
+}
+
+// EndsWithNative implements `ends-with?`.
+func EndsWithNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	s, suffix, err := affixArgs("ends-with?", args)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return value.NewLogicVal(strings.HasSuffix(s, suffix)), nil
+}
+
+// FormatNative implements `format`: printf-style formatting of a template with
+// the values of a block (reduced first) or a single value.
+//
+// Directives are %[flags][width][.precision]verb with flags from "-+0 " and verbs:
+//
+//	%s  any value, formed (precision truncates to that many characters)
+//	%d  integer!
+//	%f  integer! or decimal! in fixed-point notation
+//	%e  integer! or decimal! in scientific notation
+//	%x  integer! in lower-case hexadecimal (%X for upper case)
+//	%%  a literal percent sign
+//
+// Width counts characters, so padding is correct for any script. For %f and
+// %e the precision is the number of digits after the point, as in C.
+func FormatNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 2 {
+		return value.NewNoneVal(), arityError("format", 2, len(args))
+	}
+	tmpl, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("format", "string!", args[0])
+	}
+
+	values := []core.Value{args[1]}
+	if args[1].GetType() == value.TypeBlock {
+		reduced, err := Reduce([]core.Value{args[1]}, nil, eval)
+		if err != nil {
+			return value.NewNoneVal(), err
+		}
+		block, _ := value.AsBlockValue(reduced)
+		values = block.Elements
+	}
+
+	out, err := formatTemplate(tmpl.Form(), values)
+	if err != nil {
+		return value.NewNoneVal(), err
+	}
+	return value.NewStrVal(out), nil
+}
+
+func formatError(msg string) error {
+	return verror.NewScriptError(
+		verror.ErrIDInvalidOperation,
+		[3]string{"format: " + msg, "", ""},
+	)
+}
+
+func formatTemplate(tmpl string, values []core.Value) (string, error) {
+	var out strings.Builder
+	next := 0
+
+	for i := 0; i < len(tmpl); i++ {
+		if tmpl[i] != '%' {
+			out.WriteByte(tmpl[i])
+			continue
+		}
+
+		start := i
+		i++
+		for i < len(tmpl) && strings.IndexByte("-+0 ", tmpl[i]) >= 0 {
+			i++
+		}
+		for i < len(tmpl) && tmpl[i] >= '0' && tmpl[i] <= '9' {
+			i++
+		}
+		if i < len(tmpl) && tmpl[i] == '.' {
+			i++
+			for i < len(tmpl) && tmpl[i] >= '0' && tmpl[i] <= '9' {
+				i++
+			}
+		}
+		if i >= len(tmpl) {
+			return "", formatError(fmt.Sprintf("incomplete directive %q", tmpl[start:]))
+		}
+
+		verb := tmpl[i]
+		directive := tmpl[start : i+1]
+		if verb == '%' {
+			if directive != "%%" {
+				return "", formatError(fmt.Sprintf("invalid directive %q", directive))
+			}
+			out.WriteByte('%')
+			continue
+		}
+
+		if next >= len(values) {
+			return "", formatError(fmt.Sprintf("missing value for %s", directive))
+		}
+		arg, err := formatArg(directive, verb, values[next])
+		if err != nil {
+			return "", err
+		}
+		next++
+		fmt.Fprintf(&out, directive, arg)
+	}
+
+	if next < len(values) {
+		return "", formatError(fmt.Sprintf("%d unused value(s)", len(values)-next))
+	}
+	return out.String(), nil
+}
+
+// formatArg converts a Viro value into the Go value expected by the verb.
+func formatArg(directive string, verb byte, val core.Value) (any, error) {
+	switch verb {
+	case 's':
+		return val.Form(), nil
+	case 'd', 'x', 'X':
+		if n, ok := value.AsIntValue(val); ok {
+			return n, nil
+		}
+		if n, ok := value.AsBigIntValue(val); ok {
+			return n, nil
+		}
+		return nil, typeError("format "+directive, "integer! or bigint!", val)
+	case 'f', 'e':
+		var d *decimal.Big
+		if n, ok := value.AsIntValue(val); ok {
+			d = decimal.New(n, 0)
+		} else if n, ok := value.AsBigIntValue(val); ok {
+			d = new(decimal.Big).SetBigMantScale(n, 0)
+		} else if dec, ok := value.AsDecimal(val); ok {
+			d = dec.Magnitude
+		}
+		if d != nil {
+			if verb == 'e' {
+				return scientific{d}, nil
+			}
+			return d, nil
+		}
+		if f, ok := value.AsFloatValue(val); ok {
+			return f, nil
+		}
+		return nil, typeError("format "+directive, "integer!, bigint!, decimal! or float!", val)
+	default:
+		return nil, formatError(fmt.Sprintf("unknown directive %q", directive))
+	}
+}
+
+// scientific formats a decimal for %e the way C and Go format floats: the
+// precision (6 by default) counts the digits after the point, and the
+// exponent has at least two digits.
+type scientific struct {
+	d *decimal.Big
+}
+
+func (s scientific) Format(f fmt.State, verb rune) {
+	if !s.d.IsFinite() {
+		s.d.Format(f, verb)
+		return
+	}
+	prec, ok := f.Precision()
+	if !ok {
+		prec = 6
+	}
+
+	r := new(decimal.Big).CopyAbs(s.d)
+	r.Round(prec + 1)
+	digits := new(decimal.Big).Copy(r).SetScale(0).Int(nil).String()
+	exp := 0
+	if r.Sign() != 0 {
+		exp = len(digits) - 1 - r.Scale()
+	}
+	if len(digits) < prec+1 {
+		digits += strings.Repeat("0", prec+1-len(digits))
+	}
+
+	var body strings.Builder
+	body.WriteString(digits[:1])
+	if prec > 0 {
+		body.WriteByte('.')
+	}
+	body.WriteString(digits[1 : prec+1])
+	body.WriteByte('e')
+	if exp < 0 {
+		body.WriteByte('-')
+		exp = -exp
+	} else {
+		body.WriteByte('+')
+	}
+	if exp < 10 {
+		body.WriteByte('0')
+	}
+	body.WriteString(strconv.Itoa(exp))
+
+	sign := ""
+	switch {
+	case s.d.Signbit():
+		sign = "-"
+	case f.Flag('+'):
+		sign = "+"
+	case f.Flag(' '):
+		sign = " "
+	}
+
+	width, _ := f.Width()
+	pad := width - len(sign) - body.Len()
+	switch {
+	case pad <= 0:
+		fmt.Fprint(f, sign, body.String())
+	case f.Flag('-'):
+		fmt.Fprint(f, sign, body.String(), strings.Repeat(" ", pad))
+	case f.Flag('0'):
+		fmt.Fprint(f, sign, strings.Repeat("0", pad), body.String())
+	default:
+		fmt.Fprint(f, strings.Repeat(" ", pad), sign, body.String())
+	}
+}
+
+// ToHexNative implements `to-hex`: the upper-case hexadecimal form of an
+// integer, or of the bytes of a binary. --size pads an integer with leading zeros.
+func ToHexNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("to-hex", 1, len(args))
+	}
+
+	switch args[0].GetType() {
+	case value.TypeInteger:
+		n, _ := value.AsIntValue(args[0])
+		digits := strings.ToUpper(strconv.FormatUint(uint64(absInt64(n)), 16))
+		if hasSize, sizeVal := getRefinementValue(refValues, "size"); hasSize {
+			size, ok := value.AsIntValue(sizeVal)
+			if !ok {
+				return value.NewNoneVal(), typeError("to-hex --size", "integer!", sizeVal)
+			}
+			if missing := int(size) - len(digits); missing > 0 {
+				digits = strings.Repeat("0", missing) + digits
+			}
+		}
+		if n < 0 {
+			digits = "-" + digits
+		}
+		return value.NewStrVal(digits), nil
+	case value.TypeBinary:
+		bin, _ := value.AsBinaryValue(args[0])
+		return value.NewStrVal(strings.ToUpper(hex.EncodeToString(bin.Bytes()))), nil
+	default:
+		return value.NewNoneVal(), typeError("to-hex", "integer! or binary!", args[0])
+	}
+}
+
+// absInt64 returns |n| as a uint64-safe magnitude, including math.MinInt64.
+func absInt64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+// FromHexNative implements `from-hex`: parses hexadecimal digits (either case,
+// optional leading "-") into an integer, or into a binary with --binary.
+func FromHexNative(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+	if len(args) != 1 {
+		return value.NewNoneVal(), arityError("from-hex", 1, len(args))
+	}
+	str, ok := value.AsStringValue(args[0])
+	if !ok {
+		return value.NewNoneVal(), typeError("from-hex", "string!", args[0])
+	}
+	s := strings.TrimSpace(str.Form())
+
+	invalid := func() error {
+		return verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("from-hex: invalid hexadecimal %q", s), "", ""},
+		)
+	}
+
+	if hasRefinement(refValues, "binary") {
+		data, err := hex.DecodeString(s)
+		if err != nil {
+			return value.NewNoneVal(), invalid()
+		}
+		return value.NewBinaryVal(data), nil
+	}
+
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok || s == "" || s[0] == '+' {
+		return value.NewNoneVal(), invalid()
+	}
+	if !n.IsInt64() {
+		return value.NewNoneVal(), verror.NewMathError(
+			verror.ErrIDOverflow,
+			[3]string{s, "", ""},
+		)
+	}
+	return value.NewIntVal(n.Int64()), nil
+}
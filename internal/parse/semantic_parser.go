@@ -1,6 +1,7 @@
 package parse
 
 import (
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -16,6 +17,8 @@ var (
 	intPattern        = regexp.MustCompile(`^-?[0-9]+$`)
 	decimalPattern    = regexp.MustCompile(`^-?[0-9]+\.[0-9]+([eE][+-]?[0-9]+)?$`)
 	scientificPattern = regexp.MustCompile(`^-?[0-9]+[eE][+-]?[0-9]+$`)
+	floatPattern      = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?f$`)
+	bigIntPattern     = regexp.MustCompile(`^-?[0-9]+n$`)
 )
 
 type Parser struct {
@@ -218,7 +221,11 @@ func (p *Parser) ClassifyLiteral(token tokenize.Token) (core.Value, error) {
 	}
 
 	if intPattern.MatchString(text) {
-		n, _ := strconv.ParseInt(text, 10, 64)
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			// Out of integer! range: bigint! literals need their n suffix.
+			return nil, p.syntaxError(verror.ErrIDInvalidNumberFormat, [3]string{text + " (out of integer! range, write " + text + "n for a bigint!)", "", ""}, token.Line, token.Column)
+		}
 		return value.NewIntVal(n), nil
 	}
 
@@ -229,6 +236,19 @@ func (p *Parser) ClassifyLiteral(token tokenize.Token) (core.Value, error) {
 		return value.DecimalVal(d, scale), nil
 	}
 
+	if floatPattern.MatchString(text) {
+		f, err := strconv.ParseFloat(text[:len(text)-1], 64)
+		if err != nil {
+			return nil, p.syntaxError(verror.ErrIDInvalidNumberFormat, [3]string{text, "", ""}, token.Line, token.Column)
+		}
+		return value.NewFloatVal(f), nil
+	}
+
+	if bigIntPattern.MatchString(text) {
+		n, _ := new(big.Int).SetString(text[:len(text)-1], 10)
+		return value.NewBigIntVal(n), nil
+	}
+
 	if strings.HasSuffix(text, "!") {
 		return value.NewDatatypeVal(text), nil
 	}
@@ -69,6 +69,79 @@ func TestParser_ClassifyLiteral_Decimals(t *testing.T) {
 	}
 }
 
+func TestParser_ClassifyLiteral_Floats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  float64
+	}{
+		{"fraction", "1.5f", 1.5},
+		{"integral", "2f", 2},
+		{"negative", "-3.25f", -3.25},
+		{"exponent", "6.02e23f", 6.02e23},
+		{"negative exponent", "1e-7f", 1e-7},
+		{"signed exponent", "1e+21f", 1e21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser([]tokenize.Token{}, "(test)")
+			result, err := p.ClassifyLiteral(literalToken(tt.input))
+			if err != nil {
+				t.Fatalf("ClassifyLiteral() error = %v", err)
+			}
+			got, ok := value.AsFloatValue(result)
+			if !ok {
+				t.Fatalf("ClassifyLiteral() type = %v, want float!", value.TypeToString(result.GetType()))
+			}
+			if got != tt.want {
+				t.Errorf("ClassifyLiteral() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	p := NewParser([]tokenize.Token{}, "(test)")
+	if _, err := p.ClassifyLiteral(literalToken("1e999f")); err == nil {
+		t.Errorf("ClassifyLiteral(1e999f) should fail: out of float range")
+	}
+}
+
+func TestParser_ClassifyLiteral_BigInts(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"small", "42n", "42"},
+		{"zero", "0n", "0"},
+		{"negative", "-7n", "-7"},
+		{"beyond int64", "123456789012345678901234567890n", "123456789012345678901234567890"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser([]tokenize.Token{}, "(test)")
+			result, err := p.ClassifyLiteral(literalToken(tt.input))
+			if err != nil {
+				t.Fatalf("ClassifyLiteral() error = %v", err)
+			}
+			got, ok := value.AsBigIntValue(result)
+			if !ok {
+				t.Fatalf("ClassifyLiteral() type = %v, want bigint!", value.TypeToString(result.GetType()))
+			}
+			if got.String() != tt.want {
+				t.Errorf("ClassifyLiteral() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	p := NewParser([]tokenize.Token{}, "(test)")
+	result, err := p.ClassifyLiteral(literalToken("1.5n"))
+	if err == nil && result.GetType() == value.TypeBigInt {
+		t.Errorf("ClassifyLiteral(1.5n) should not be a bigint!")
+	}
+}
+
 func TestParser_ClassifyLiteral_SetWords(t *testing.T) {
 	tests := []struct {
 		name     string
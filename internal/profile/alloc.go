@@ -0,0 +1,50 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+)
+
+// allocationRow is one line of the allocation table.
+type allocationRow struct {
+	name        string
+	self, total core.AllocStats
+}
+
+// reportAllocations returns a table row for each function that allocated,
+// most allocations (callees included) first.
+func reportAllocations(functions []*FunctionStats) []allocationRow {
+	var rows []allocationRow
+	for _, f := range functions {
+		if f.Allocations.IsZero() && f.AllocationsTotal.IsZero() {
+			continue
+		}
+		rows = append(rows, allocationRow{name: f.Name, self: f.Allocations, total: f.AllocationsTotal})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if a, b := rows[i].total.Total(), rows[j].total.Total(); a != b {
+			return a > b
+		}
+		return rows[i].self.Total() > rows[j].self.Total()
+	})
+	return rows
+}
+
+// writeAllocations writes the allocation table shared by the call-tree
+// and trace-based reports. The per-kind columns and Self count what was
+// allocated while the function was the innermost call; Total adds its
+// callees. Growth is in elements and is not part of Self or Total.
+func writeAllocations(w io.Writer, rows []allocationRow) {
+	const rule = "───────────────────────────────────────────────────────────────────────────────────────────────────"
+	fmt.Fprintf(w, "Allocations (sorted by total; growth in elements):\n")
+	fmt.Fprintf(w, "%s\n", rule)
+	fmt.Fprintf(w, "%-30s %9s %9s %9s %9s %10s %9s %9s\n", "Function", "Blocks", "Strings", "Binaries", "Frames", "Growth", "Self", "Total")
+	fmt.Fprintf(w, "%s\n", rule)
+	for _, r := range rows {
+		fmt.Fprintf(w, "%-30s %9d %9d %9d %9d %10d %9d %9d\n", truncate(r.name, 30),
+			r.self.Blocks, r.self.Strings, r.self.Binaries, r.self.Frames, r.self.Growth, r.self.Total(), r.total.Total())
+	}
+}
@@ -0,0 +1,341 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+)
+
+// CallTree is a call-path profiler. It is driven by the evaluator's call
+// observer (eval.CallObserver) rather than by the trace session: each call
+// path gets its own node, so a function reached from two callers shows up
+// twice, and a node's self time excludes the time spent in its callees.
+// Unlike Profiler, nested and recursive calls are not counted twice.
+//
+// A CallTree is not safe for concurrent use.
+type CallTree struct {
+	Root *CallNode
+
+	active  []activation
+	started time.Time
+	now     func() time.Time
+}
+
+// CallNode is one call path: the function Name called from the path of
+// its parent.
+type CallNode struct {
+	Name  string
+	Calls int64
+	// Total includes callees; Self does not.
+	Total time.Duration
+	Self  time.Duration
+	// Allocs are the allocations counted while this path was innermost.
+	Allocs   core.AllocStats
+	Children []*CallNode
+
+	parent   *CallNode
+	children map[string]*CallNode
+}
+
+type activation struct {
+	node  *CallNode
+	start time.Time
+	// callees is the time spent in calls made by this activation so far.
+	callees time.Duration
+}
+
+// NewCallTree returns a call tree whose root, named root, stands for the
+// code being profiled.
+func NewCallTree(root string) *CallTree {
+	return &CallTree{Root: &CallNode{Name: root}, now: time.Now}
+}
+
+// Start starts timing the root.
+func (t *CallTree) Start() {
+	t.started = t.now()
+	t.Root.Calls++
+	t.active = append(t.active[:0], activation{node: t.Root, start: t.started})
+}
+
+// Stop ends any calls still open, and then the root.
+func (t *CallTree) Stop() {
+	for len(t.active) > 0 {
+		t.exit()
+	}
+}
+
+// EnterCall records entry into a function called from the current path.
+func (t *CallTree) EnterCall(name string) {
+	if len(t.active) == 0 {
+		return
+	}
+	parent := t.active[len(t.active)-1].node
+	node := parent.child(name)
+	node.Calls++
+	t.active = append(t.active, activation{node: node, start: t.now()})
+}
+
+// Allocated counts allocations against the innermost call. It makes
+// CallTree an eval.AllocationObserver.
+func (t *CallTree) Allocated(kind core.AllocKind, n int) {
+	if len(t.active) > 0 {
+		t.active[len(t.active)-1].node.Allocs.Add(kind, n)
+	}
+}
+
+// ExitCall records return from the innermost call.
+func (t *CallTree) ExitCall() {
+	if len(t.active) > 1 {
+		t.exit()
+	}
+}
+
+func (t *CallTree) exit() {
+	a := t.active[len(t.active)-1]
+	t.active = t.active[:len(t.active)-1]
+	elapsed := t.now().Sub(a.start)
+	a.node.Total += elapsed
+	a.node.Self += elapsed - a.callees
+	if len(t.active) > 0 {
+		t.active[len(t.active)-1].callees += elapsed
+	}
+}
+
+func (n *CallNode) child(name string) *CallNode {
+	if c, ok := n.children[name]; ok {
+		return c
+	}
+	if n.children == nil {
+		n.children = make(map[string]*CallNode)
+	}
+	c := &CallNode{Name: name, parent: n}
+	n.children[name] = c
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Path returns the names from the root down to the node.
+func (n *CallNode) Path() []string {
+	var path []string
+	for p := n; p != nil; p = p.parent {
+		path = append(path, p.Name)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// walk visits the nodes depth first, children in order of first call.
+func (n *CallNode) walk(visit func(*CallNode, int), depth int) {
+	visit(n, depth)
+	for _, c := range n.Children {
+		c.walk(visit, depth+1)
+	}
+}
+
+// FunctionTotals is a function's time and allocations summed over every
+// path it appears on. Total and AllocsTotal count a recursive function's
+// outermost calls only.
+type FunctionTotals struct {
+	Name        string
+	Calls       int64
+	Self        time.Duration
+	Total       time.Duration
+	Allocs      core.AllocStats
+	AllocsTotal core.AllocStats
+}
+
+// Functions returns per-function totals, highest self time first.
+func (t *CallTree) Functions() []FunctionTotals {
+	byName := make(map[string]*FunctionTotals)
+	var order []*FunctionTotals
+	// visit returns the allocations of n and its callees.
+	var visit func(n *CallNode, open map[string]bool) core.AllocStats
+	visit = func(n *CallNode, open map[string]bool) core.AllocStats {
+		f, ok := byName[n.Name]
+		if !ok {
+			f = &FunctionTotals{Name: n.Name}
+			byName[n.Name] = f
+			order = append(order, f)
+		}
+		f.Calls += n.Calls
+		f.Self += n.Self
+		f.Allocs.Merge(n.Allocs)
+		outermost := !open[n.Name]
+		if outermost {
+			f.Total += n.Total
+			open[n.Name] = true
+			defer delete(open, n.Name)
+		}
+		allocs := n.Allocs
+		for _, c := range n.Children {
+			allocs.Merge(visit(c, open))
+		}
+		if outermost {
+			f.AllocsTotal.Merge(allocs)
+		}
+		return allocs
+	}
+	visit(t.Root, make(map[string]bool))
+
+	totals := make([]FunctionTotals, len(order))
+	for i, f := range order {
+		totals[i] = *f
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].Self > totals[j].Self })
+	return totals
+}
+
+func share(d, of time.Duration) float64 {
+	if of <= 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(of)
+}
+
+// minTreeShare is the share of the total time below which FormatText
+// leaves a call path out of the tree; recursion makes a path per depth.
+const minTreeShare = 1.0
+
+// FormatText writes a per-function table and the call tree, children
+// sorted by total time. The files written by WriteFile keep every path.
+func (t *CallTree) FormatText(w io.Writer) {
+	const rule = "───────────────────────────────────────────────────────────────────────────────────────────────────"
+	total := t.Root.Total
+	calls := int64(0)
+	t.Root.walk(func(n *CallNode, depth int) {
+		if depth > 0 {
+			calls += n.Calls
+		}
+	}, 0)
+
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════════════════════════════════════════\n")
+	fmt.Fprintf(w, "                                           EXECUTION PROFILE\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════════════════════════════════════════\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "Total Execution Time: %v\n", total)
+	fmt.Fprintf(w, "Total Calls:          %d\n", calls)
+	fmt.Fprintf(w, "\n")
+
+	if len(t.Root.Children) == 0 {
+		fmt.Fprintf(w, "No function calls recorded.\n")
+		return
+	}
+
+	fmt.Fprintf(w, "Function Statistics (sorted by self time):\n")
+	fmt.Fprintf(w, "%s\n", rule)
+	fmt.Fprintf(w, "%-30s %10s %12s %8s %12s %8s\n", "Function", "Calls", "Self Time", "Self%", "Total Time", "Total%")
+	fmt.Fprintf(w, "%s\n", rule)
+	for _, f := range t.Functions() {
+		fmt.Fprintf(w, "%-30s %10d %12s %7.1f%% %12s %7.1f%%\n", truncate(f.Name, 30), f.Calls,
+			formatDuration(f.Self), share(f.Self, total), formatDuration(f.Total), share(f.Total, total))
+	}
+
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "Call Tree (total time, self time, calls; paths under %.0f%% omitted):\n", minTreeShare)
+	fmt.Fprintf(w, "%s\n", rule)
+	var printNode func(n *CallNode, depth int)
+	printNode = func(n *CallNode, depth int) {
+		fmt.Fprintf(w, "%6.1f%% %12s %12s %10d  %s%s\n", share(n.Total, total),
+			formatDuration(n.Total), formatDuration(n.Self), n.Calls, strings.Repeat("  ", depth), n.Name)
+		children := append([]*CallNode(nil), n.Children...)
+		sort.SliceStable(children, func(i, j int) bool { return children[i].Total > children[j].Total })
+		for _, c := range children {
+			if share(c.Total, total) < minTreeShare {
+				break
+			}
+			printNode(c, depth+1)
+		}
+	}
+	printNode(t.Root, 0)
+
+	if rows := reportAllocations(t.Report().Functions); len(rows) > 0 {
+		fmt.Fprintf(w, "\n")
+		writeAllocations(w, rows)
+	}
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════════════════════════════════════════\n")
+	fmt.Fprintf(w, "\n")
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n-3] + "..."
+	}
+	return s
+}
+
+// WriteCollapsed writes one line per call path in the collapsed-stack
+// format read by flamegraph tools: the names joined by semicolons, a
+// space, and the path's self time in nanoseconds. Paths with no self time
+// are left out.
+func (t *CallTree) WriteCollapsed(w io.Writer) error {
+	var b strings.Builder
+	t.Root.walk(func(n *CallNode, _ int) {
+		if n.Self > 0 {
+			fmt.Fprintf(&b, "%s %d\n", strings.Join(n.Path(), ";"), n.Self.Nanoseconds())
+		}
+	}, 0)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Report returns the per-function totals as a ProfileReport, for
+// FormatJSON. Median and percentile times are not known to a call tree
+// and stay zero.
+func (t *CallTree) Report() *ProfileReport {
+	report := &ProfileReport{TotalExecutionTime: t.Root.Total}
+	for _, f := range t.Functions() {
+		stats := &FunctionStats{
+			Name:             f.Name,
+			CallCount:        f.Calls,
+			TotalTime:        f.Total,
+			SelfTime:         f.Self,
+			Allocations:      f.Allocs,
+			AllocationsTotal: f.AllocsTotal,
+		}
+		if f.Calls > 0 {
+			stats.AverageTime = f.Total / time.Duration(f.Calls)
+		}
+		if f.Name != t.Root.Name {
+			report.TotalEvents += f.Calls
+		}
+		report.Functions = append(report.Functions, stats)
+	}
+	return report
+}
+
+// WriteFile writes the profile to path: pprof protobuf when the name ends
+// in .pprof, .pb or .pb.gz, the Report as JSON for .json, and collapsed
+// stacks otherwise.
+func (t *CallTree) WriteFile(path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case isPprofPath(path):
+		err = t.WritePprof(out)
+	case strings.EqualFold(filepath.Ext(path), ".json"):
+		err = t.Report().FormatJSON(out)
+	default:
+		err = t.WriteCollapsed(out)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// isPprofPath reports whether WriteFile writes path as pprof protobuf.
+func isPprofPath(path string) bool {
+	path = strings.ToLower(path)
+	return strings.HasSuffix(path, ".pb.gz") || filepath.Ext(path) == ".pprof" || filepath.Ext(path) == ".pb"
+}
@@ -0,0 +1,242 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+)
+
+// newTestTree returns a call tree whose clock advances by step on every
+// reading.
+func newTestTree(step time.Duration) *CallTree {
+	tree := NewCallTree("main")
+	var clock time.Time
+	tree.now = func() time.Time {
+		clock = clock.Add(step)
+		return clock
+	}
+	return tree
+}
+
+func TestCallTreeSelfAndTotal(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()        // 1
+	tree.EnterCall("a") // 2
+	tree.EnterCall("b") // 3
+	tree.ExitCall()     // 4: b total 1
+	tree.EnterCall("b") // 5
+	tree.ExitCall()     // 6: b total 2
+	tree.ExitCall()     // 7: a total 5, self 3
+	tree.EnterCall("b") // 8
+	tree.ExitCall()     // 9: b under main total 1
+	tree.Stop()         // 10: main total 9, self 3
+
+	if tree.Root.Total != 9*time.Millisecond || tree.Root.Self != 3*time.Millisecond {
+		t.Errorf("root total/self = %v/%v, want 9ms/3ms", tree.Root.Total, tree.Root.Self)
+	}
+	if len(tree.Root.Children) != 2 {
+		t.Fatalf("root has %d children, want 2 (a, b)", len(tree.Root.Children))
+	}
+	a := tree.Root.Children[0]
+	if a.Name != "a" || a.Calls != 1 || a.Total != 5*time.Millisecond || a.Self != 3*time.Millisecond {
+		t.Errorf("a = %+v", a)
+	}
+	ab := a.Children[0]
+	if ab.Calls != 2 || ab.Total != 2*time.Millisecond || strings.Join(ab.Path(), ";") != "main;a;b" {
+		t.Errorf("a;b = %+v, path %v", ab, ab.Path())
+	}
+
+	for _, f := range tree.Functions() {
+		if f.Name == "b" && (f.Calls != 3 || f.Total != 3*time.Millisecond) {
+			t.Errorf("b totals = %+v, want 3 calls in 3ms", f)
+		}
+	}
+}
+
+func TestCallTreeRecursionCountedOnce(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("f")
+	tree.EnterCall("f")
+	tree.EnterCall("f")
+	tree.ExitCall()
+	tree.ExitCall()
+	tree.ExitCall()
+	tree.Stop()
+
+	for _, f := range tree.Functions() {
+		if f.Name == "f" {
+			if f.Calls != 3 || f.Total != 5*time.Millisecond || f.Self != 5*time.Millisecond {
+				t.Errorf("f totals = %+v, want 3 calls, 5ms total and self", f)
+			}
+			return
+		}
+	}
+	t.Fatal("f missing from Functions()")
+}
+
+func TestCallTreeStopClosesOpenCalls(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("a")
+	tree.Stop()
+	tree.ExitCall()
+	tree.EnterCall("late")
+
+	if got := tree.Root.Children[0].Total; got != time.Millisecond {
+		t.Errorf("a total = %v, want 1ms", got)
+	}
+	if len(tree.Root.Children) != 1 {
+		t.Errorf("calls after Stop were recorded")
+	}
+}
+
+func TestWriteCollapsed(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("a")
+	tree.EnterCall("b")
+	tree.ExitCall()
+	tree.ExitCall()
+	tree.Stop()
+
+	var buf bytes.Buffer
+	if err := tree.WriteCollapsed(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "main 2000000\nmain;a 2000000\nmain;a;b 1000000\n"
+	if buf.String() != want {
+		t.Errorf("collapsed stacks:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("square")
+	tree.ExitCall()
+	tree.Stop()
+
+	var buf bytes.Buffer
+	if err := tree.WritePprof(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("pprof output is not gzipped: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"main", "square", "calls", "nanoseconds"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("string table is missing %q", s)
+		}
+	}
+}
+
+func TestIsPprofPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"cpu.pprof":  true,
+		"cpu.pb.gz":  true,
+		"CPU.PB":     true,
+		"out.folded": false,
+		"out.txt":    false,
+		"profile.gz": false,
+	} {
+		if got := isPprofPath(path); got != want {
+			t.Errorf("isPprofPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCallTreeFormatText(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("work")
+	tree.ExitCall()
+	tree.Stop()
+
+	var buf bytes.Buffer
+	tree.FormatText(&buf)
+	output := buf.String()
+	for _, want := range []string{"EXECUTION PROFILE", "Total Calls:          1", "Function Statistics", "Call Tree", "    work"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("report missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestCallTreeAllocations(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("build")
+	tree.Allocated(core.AllocFrame, 1)
+	tree.EnterCall("append")
+	tree.Allocated(core.AllocGrowth, 3)
+	tree.Allocated(core.AllocBlock, 1)
+	tree.ExitCall()
+	tree.ExitCall()
+	tree.Stop()
+	tree.Allocated(core.AllocBlock, 1)
+
+	report := tree.Report()
+	byName := make(map[string]*FunctionStats)
+	for _, f := range report.Functions {
+		byName[f.Name] = f
+	}
+	build := byName["build"]
+	if build.Allocations.Frames != 1 || build.AllocationsTotal.Total() != 2 || build.AllocationsTotal.Growth != 3 {
+		t.Errorf("build allocations = %+v / %+v", build.Allocations, build.AllocationsTotal)
+	}
+	if got := byName["main"].AllocationsTotal.Total(); got != 2 {
+		t.Errorf("main total allocations = %d, want 2 (allocations after Stop are dropped)", got)
+	}
+
+	var buf bytes.Buffer
+	report.FormatText(&buf)
+	if !strings.Contains(buf.String(), "Allocations (sorted by total") {
+		t.Errorf("report has no allocation table:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.FormatJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"AllocationsTotal"`) {
+		t.Errorf("JSON report has no allocations:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := tree.WritePprof(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if !bytes.Contains(data, []byte("allocations")) {
+		t.Errorf("pprof profile has no allocations sample type")
+	}
+}
+
+func TestCallTreeWithoutAllocationsHasNoTable(t *testing.T) {
+	tree := newTestTree(time.Millisecond)
+	tree.Start()
+	tree.EnterCall("work")
+	tree.ExitCall()
+	tree.Stop()
+
+	var buf bytes.Buffer
+	tree.FormatText(&buf)
+	if strings.Contains(buf.String(), "Allocations") {
+		t.Errorf("allocation table shown without accounting:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,154 @@
+package profile
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+)
+
+// Field numbers of the messages in pprof's profile.proto.
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+	profileDefaultSample = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID   = 1
+	functionName = 2
+)
+
+// protoBuffer encodes the few protobuf wire types profile.proto needs.
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) varint(field int, v uint64) {
+	b.data = binary.AppendUvarint(b.data, uint64(field)<<3)
+	b.data = binary.AppendUvarint(b.data, v)
+}
+
+func (b *protoBuffer) bytes(field int, p []byte) {
+	b.data = binary.AppendUvarint(b.data, uint64(field)<<3|2)
+	b.data = binary.AppendUvarint(b.data, uint64(len(p)))
+	b.data = append(b.data, p...)
+}
+
+func (b *protoBuffer) message(field int, encode func(*protoBuffer)) {
+	var m protoBuffer
+	encode(&m)
+	b.bytes(field, m.data)
+}
+
+func (b *protoBuffer) packed(field int, values []uint64) {
+	var p []byte
+	for _, v := range values {
+		p = binary.AppendUvarint(p, v)
+	}
+	b.bytes(field, p)
+}
+
+// WritePprof writes the profile as gzipped pprof protobuf, readable by
+// `go tool pprof`. Each call path is a sample with two values, its call
+// count and its self time, and a third, the series and frames it created,
+// when allocations were counted; every function has one location.
+func (t *CallTree) WritePprof(w io.Writer) error {
+	strs := []string{""}
+	index := map[string]uint64{"": 0}
+	str := func(s string) uint64 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = uint64(len(strs))
+		strs = append(strs, s)
+		return index[s]
+	}
+
+	sampleTypes := [][2]string{{"calls", "count"}, {"time", "nanoseconds"}}
+	allocs := false
+	t.Root.walk(func(n *CallNode, _ int) { allocs = allocs || !n.Allocs.IsZero() }, 0)
+	if allocs {
+		sampleTypes = append(sampleTypes, [2]string{"allocations", "count"})
+	}
+
+	var b protoBuffer
+	for _, vt := range sampleTypes {
+		typ, unit := str(vt[0]), str(vt[1])
+		b.message(profileSampleType, func(m *protoBuffer) {
+			m.varint(valueTypeType, typ)
+			m.varint(valueTypeUnit, unit)
+		})
+	}
+
+	functions := make(map[string]uint64)
+	var names []string
+	t.Root.walk(func(n *CallNode, _ int) {
+		ids := make([]uint64, 0, 8)
+		for p := n; p != nil; p = p.parent {
+			id, ok := functions[p.Name]
+			if !ok {
+				id = uint64(len(functions) + 1)
+				functions[p.Name] = id
+				names = append(names, p.Name)
+			}
+			ids = append(ids, id)
+		}
+		values := []uint64{uint64(n.Calls), uint64(n.Self.Nanoseconds())}
+		if allocs {
+			values = append(values, uint64(n.Allocs.Total()))
+		}
+		b.message(profileSample, func(m *protoBuffer) {
+			m.packed(sampleLocationID, ids)
+			m.packed(sampleValue, values)
+		})
+	}, 0)
+
+	for i, name := range names {
+		id, nameIdx := uint64(i+1), str(name)
+		b.message(profileLocation, func(m *protoBuffer) {
+			m.varint(locationID, id)
+			m.message(locationLine, func(l *protoBuffer) {
+				l.varint(lineFunctionID, id)
+			})
+		})
+		b.message(profileFunction, func(m *protoBuffer) {
+			m.varint(functionID, id)
+			m.varint(functionName, nameIdx)
+		})
+	}
+
+	timeType, nanos := str("time"), str("nanoseconds")
+	b.varint(profileTimeNanos, uint64(t.started.UnixNano()))
+	b.varint(profileDurationNanos, uint64(t.Root.Total.Nanoseconds()))
+	b.message(profilePeriodType, func(m *protoBuffer) {
+		m.varint(valueTypeType, timeType)
+		m.varint(valueTypeUnit, nanos)
+	})
+	b.varint(profilePeriod, 1)
+	b.varint(profileDefaultSample, timeType)
+	for _, s := range strs {
+		b.bytes(profileStringTable, []byte(s))
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -1,59 +1,37 @@
 // Package profile provides execution profiling infrastructure for Viro.
 //
-// This package enables performance analysis by collecting execution statistics
-// from the trace system. It works by registering a callback with the trace
-// session to capture timing data for function calls and operations.
+// Two profilers are provided:
 //
-// # Architecture
-//
-// The profiler integrates with the existing trace system (internal/trace) via
-// callbacks, avoiding duplication of event collection logic. When profiling is
-// enabled, trace events are captured and aggregated into function-level statistics.
-//
-// Key components:
-//   - Profiler: Thread-safe statistics collector
-//   - FunctionStats: Per-function timing metrics (count, min, max, avg, total)
-//   - ProfileReport: Aggregated results with multiple output formats
+//   - CallTree records a call tree from the evaluator's call observer
+//     (eval.CallObserver): call counts, total and self time per call path.
+//     It backs the --profile flag and the profile native, and exports
+//     collapsed stacks for flamegraph tools and pprof protobuf. With
+//     allocation accounting on (--profile-allocs) it also counts the series
+//     and frames each call path creates.
+//   - Profiler aggregates flat per-word timings from trace session
+//     callbacks (EnableProfilingWithTrace). Nested calls are counted in
+//     every enclosing word, so its times do not add up; it is kept for
+//     callers that already run a trace session.
 //
 // # Usage
 //
-// Profiling is typically enabled via CLI flag (--profile) which:
-//  1. Initializes trace system with silent output (io.Discard)
-//  2. Creates a Profiler instance
-//  3. Registers profiler callback with trace session
-//  4. Enables trace collection with minimal filters
-//  5. Displays report after script execution
-//
-// # Profiling vs Tracing
-//
-// Use profiling when you need:
-//   - High-level performance overview
-//   - Function call counts and timing statistics
-//   - Minimal overhead (no JSON serialization)
-//   - Human-readable summary reports
-//
-// Use tracing when you need:
-//   - Detailed execution flow analysis
-//   - Step-by-step debugging information
-//   - Frame state inspection
-//   - Machine-parseable event streams
+// --profile installs a CallTree on the evaluator before the script runs
+// and prints its report to stderr afterwards, also when the script fails;
+// --profile-output FILE writes it to FILE as well. In the REPL the report
+// covers the whole session and is printed on exit. `profile [block]`
+// profiles a single block from Viro code.
 //
 // # Performance Implications
 //
-// Profiling has lower overhead than full tracing because:
-//   - Trace output is discarded (no I/O)
-//   - Minimal trace filters (StepLevel=0, no verbose mode)
-//   - Lock-free callback invocation
-//   - In-memory aggregation only
-//
-// However, the trace system itself still runs, so there is non-zero overhead
-// compared to execution without any instrumentation.
+// The call tree costs two clock readings and a map lookup per call and does
+// not need the trace session, so it is much cheaper than Profiler, which
+// runs the trace system with its output discarded.
 //
 // # Thread Safety
 //
 // All Profiler methods are thread-safe and can be called concurrently.
 // Statistics are protected by a mutex, and the enabled flag uses atomic
-// operations for lock-free reads.
+// operations for lock-free reads. A CallTree is not safe for concurrent use.
 package profile
 
 import (
@@ -65,6 +43,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/trace"
 )
 
@@ -87,7 +66,14 @@ type FunctionStats struct {
 	MedianTime  time.Duration
 	P95Time     time.Duration
 	P99Time     time.Duration
-	durations   []time.Duration
+	// SelfTime excludes callees; only CallTree.Report sets it.
+	SelfTime time.Duration
+	// Allocations are those counted while the function was the innermost
+	// call; AllocationsTotal adds its callees'. Both stay zero unless
+	// allocation accounting was on.
+	Allocations      core.AllocStats
+	AllocationsTotal core.AllocStats
+	durations        []time.Duration
 }
 
 type ProfileReport struct {
@@ -272,6 +258,11 @@ func (r *ProfileReport) FormatText(w io.Writer) {
 			formatDuration(stats.MaxTime))
 	}
 
+	if rows := reportAllocations(r.Functions); len(rows) > 0 {
+		fmt.Fprintf(w, "\n")
+		writeAllocations(w, rows)
+	}
+
 	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════════════════════════════════════════\n")
 	fmt.Fprintf(w, "\n")
 }
@@ -30,14 +30,17 @@ import (
 
 	"github.com/chzyer/readline"
 	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/config"
 	"github.com/marcin-radoszewski/viro/internal/core"
 	"github.com/marcin-radoszewski/viro/internal/debug"
 	"github.com/marcin-radoszewski/viro/internal/eval"
 	"github.com/marcin-radoszewski/viro/internal/native"
 	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/profile"
 	"github.com/marcin-radoszewski/viro/internal/trace"
 	"github.com/marcin-radoszewski/viro/internal/value"
 	"github.com/marcin-radoszewski/viro/internal/verror"
+	"github.com/marcin-radoszewski/viro/internal/version"
 )
 
 const (
@@ -55,7 +58,20 @@ type Options struct {
 	NoHistory   bool
 	HistoryFile string
 	TraceOn     bool
+	// TraceFile, TraceFormat and TraceBuffer configure the trace sink as
+	// the --trace-* flags do.
+	TraceFile   string
+	TraceFormat string
+	TraceBuffer int
+	AllowExec   bool
+	Compile     bool
+	SandboxRoot string
 	Args        []string
+	// Profile records a call-tree profile of the whole session, reported
+	// on exit; ProfileOutput also writes it to that file.
+	Profile       bool
+	ProfileOutput string
+	ProfileAllocs bool
 }
 
 // REPL implements a Read-Eval-Print-Loop for Viro.
@@ -78,6 +94,9 @@ type REPL struct {
 	customPrompt   string
 	noWelcome      bool
 	noHistory      bool
+	exitSignal     *eval.ExitSignal
+	profile        *profile.CallTree
+	profileOutput  string
 }
 
 // NewREPL creates a new REPL instance with default options.
@@ -94,13 +113,19 @@ func NewREPLWithOptions(opts *Options) (*REPL, error) {
 	}
 
 	// Initialize trace/debug sessions (Feature 002, T154)
-	// Trace is initialized with default settings (stderr, 50MB max size)
+	// Trace writes to stderr (or TraceFile) with a 50MB max size
 	// These will be controlled via trace --on/--off and debug --on/--off
-	if err := bootstrap.InitTrace(false); err != nil {
+	if err := bootstrap.InitTraceWithFormat(opts.TraceFile, opts.TraceFormat, opts.TraceBuffer); err != nil {
 		return nil, fmt.Errorf("failed to initialize trace session: %w", err)
 	}
 	bootstrap.InitDebugger()
 
+	if err := eval.InitSandbox(opts.SandboxRoot); err != nil {
+		return nil, fmt.Errorf("failed to initialize sandbox: %w", err)
+	}
+	native.SandboxRoot = eval.SandboxRoot
+	native.AllowExec = opts.AllowExec
+
 	// Enable trace if requested
 	if opts.TraceOn && trace.GlobalTraceSession != nil {
 		trace.GlobalTraceSession.Enable(trace.TraceFilters{})
@@ -137,7 +162,16 @@ func NewREPLWithOptions(opts *Options) (*REPL, error) {
 	}
 
 	evaluator := bootstrap.NewEvaluatorWithNatives(os.Stdout, os.Stderr, os.Stdin, false)
-	bootstrap.InjectSystemArgs(evaluator, opts.Args)
+	evaluator.SetCompile(opts.Compile)
+	bootstrap.InjectSystem(evaluator, bootstrap.SystemInfo{
+		Args: opts.Args,
+		Options: &config.Config{
+			SandboxRoot: opts.SandboxRoot,
+			TraceOn:     opts.TraceOn,
+			AllowExec:   opts.AllowExec,
+			Compile:     opts.Compile,
+		},
+	})
 
 	repl := &REPL{
 		evaluator:      evaluator,
@@ -154,6 +188,14 @@ func NewREPLWithOptions(opts *Options) (*REPL, error) {
 		noHistory:      opts.NoHistory,
 	}
 
+	if opts.Profile {
+		repl.profile = profile.NewCallTree("(repl)")
+		repl.profileOutput = opts.ProfileOutput
+		evaluator.SetCallObserver(repl.profile)
+		evaluator.SetAllocationAccounting(opts.ProfileAllocs)
+		repl.profile.Start()
+	}
+
 	// Load persistent history only if not disabled
 	if !opts.NoHistory {
 		repl.loadPersistentHistory()
@@ -202,7 +244,7 @@ func NewREPLForTest(e core.Evaluator, out io.Writer) *REPL {
 
 // WelcomeMessage returns the default multi-line welcome text shown when the REPL starts.
 func WelcomeMessage() string {
-	return "Viro 0.1.0\nType 'exit' or 'quit' to leave\n\n"
+	return version.String() + "\nType 'exit' or 'quit' to leave\n\n"
 }
 
 // Run starts the REPL loop.
@@ -211,6 +253,7 @@ func (r *REPL) Run() error {
 		return fmt.Errorf("readline instance not configured")
 	}
 	defer r.rl.Close()
+	defer r.reportProfile()
 
 	// Print welcome message
 	r.printWelcome()
@@ -235,11 +278,29 @@ func (r *REPL) Run() error {
 		r.processLine(line, true)
 
 		if !r.shouldContinue {
+			if r.exitSignal != nil {
+				return r.exitSignal
+			}
 			return nil
 		}
 	}
 }
 
+// reportProfile prints the session profile to standard error and writes
+// it to the --profile-output file, if profiling was requested.
+func (r *REPL) reportProfile() {
+	if r.profile == nil {
+		return
+	}
+	r.profile.Stop()
+	r.profile.FormatText(os.Stderr)
+	if r.profileOutput != "" {
+		if err := r.profile.WriteFile(r.profileOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing profile: %v\n", err)
+		}
+	}
+}
+
 // EvalLineForTest evaluates a single line and prints to the configured writer.
 func (r *REPL) EvalLineForTest(input string) {
 	if r == nil {
@@ -345,8 +406,18 @@ func (r *REPL) evalParsedValues(values []core.Value, locations []core.SourceLoca
 		if returnSig, ok := err.(*eval.ReturnSignal); ok {
 			result = returnSig.Value()
 			err = nil
+		} else if exitSig, ok := err.(*eval.ExitSignal); ok {
+			r.exitSignal = exitSig
+			r.handleExit(r.rl != nil)
+			return
 		} else {
 			err = verror.ConvertLoopControlSignal(err)
+			// A ring-buffer trace holds the events leading up to the error.
+			if trace.GlobalTraceSession != nil {
+				if dumpErr := trace.GlobalTraceSession.Dump(); dumpErr != nil {
+					fmt.Fprintf(os.Stderr, "Error writing trace: %v\n", dumpErr)
+				}
+			}
 			r.printError(err)
 			return
 		}
@@ -404,6 +475,7 @@ func (r *REPL) ResetForTest() {
 	r.shouldContinue = true
 	r.awaitingCont = false
 	r.pendingLines = nil
+	r.exitSignal = nil
 	r.historyCursor = len(r.history)
 }
 
@@ -0,0 +1,244 @@
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/trace"
+)
+
+const browseHelp = `Commands:
+  step N, goto N   go to trace step N
+  back [n], b      go back n steps (default 1)
+  next [n], n      go forward n steps (default 1)
+  first, last      go to the first or last step
+  error            go to the first step that failed
+  find WORD        go to the next step evaluating WORD
+  frame [all], f   show the words the script set or changed (all: every word)
+  list [n], l      show n steps around this one (default 5)
+  help, ?          show this help
+  quit, q          leave the replay`
+
+// maxShown is the longest value printed before it is cut short.
+const maxShown = 100
+
+// Browser steps through the events of a replayed run in either direction.
+type Browser struct {
+	events   []trace.TraceEvent
+	baseline map[string]string
+	pos      int
+	out      io.Writer
+}
+
+// NewBrowser returns a browser over events, positioned at the first failed
+// step, or the last step when the run succeeded. baseline holds the words
+// bound before the script ran; frame leaves them out while unchanged.
+func NewBrowser(events []trace.TraceEvent, baseline map[string]string, out io.Writer) *Browser {
+	b := &Browser{events: events, baseline: baseline, out: out, pos: len(events) - 1}
+	if i := b.firstError(); i >= 0 {
+		b.pos = i
+	}
+	return b
+}
+
+// Run reads commands from in until quit or end of input.
+func (b *Browser) Run(in io.Reader) {
+	if len(b.events) == 0 {
+		fmt.Fprintln(b.out, "replay: no steps were recorded")
+		return
+	}
+	fmt.Fprintf(b.out, "replay: %d steps (%d-%d); type help for commands\n",
+		len(b.events), b.events[0].Step, b.events[len(b.events)-1].Step)
+	b.show()
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(b.out, "replay> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(b.out)
+			return
+		}
+		if !b.Exec(scanner.Text()) {
+			return
+		}
+	}
+}
+
+// Exec runs one command and reports whether browsing continues.
+func (b *Browser) Exec(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+	cmd, args := fields[0], fields[1:]
+	switch cmd {
+	case "quit", "q", "exit":
+		return false
+	case "help", "?":
+		fmt.Fprintln(b.out, browseHelp)
+	case "step", "goto", "s":
+		if len(args) != 1 {
+			fmt.Fprintln(b.out, "usage: step N")
+			return true
+		}
+		step, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Fprintf(b.out, "not a step number: %s\n", args[0])
+			return true
+		}
+		b.moveTo(b.indexOfStep(step))
+	case "back", "b":
+		b.moveTo(b.pos - count(args, 1))
+	case "next", "n":
+		b.moveTo(b.pos + count(args, 1))
+	case "first":
+		b.moveTo(0)
+	case "last":
+		b.moveTo(len(b.events) - 1)
+	case "error":
+		i := b.firstError()
+		if i < 0 {
+			fmt.Fprintln(b.out, "no step failed")
+			return true
+		}
+		b.moveTo(i)
+	case "find":
+		if len(args) != 1 {
+			fmt.Fprintln(b.out, "usage: find WORD")
+			return true
+		}
+		b.find(args[0])
+	case "frame", "f":
+		b.frame(len(args) == 1 && args[0] == "all")
+	case "list", "l":
+		b.list(count(args, 5))
+	default:
+		fmt.Fprintf(b.out, "unknown command: %s (type help)\n", cmd)
+	}
+	return true
+}
+
+// count parses an optional count, defaulting to n.
+func count(args []string, n int) int {
+	if len(args) > 0 {
+		if v, err := strconv.Atoi(args[0]); err == nil && v > 0 {
+			n = v
+		}
+	}
+	return n
+}
+
+func (b *Browser) moveTo(i int) {
+	b.pos = min(max(i, 0), len(b.events)-1)
+	b.show()
+}
+
+// indexOfStep returns the event for step, or the first one after it.
+func (b *Browser) indexOfStep(step int64) int {
+	return sort.Search(len(b.events), func(i int) bool { return b.events[i].Step >= step })
+}
+
+func (b *Browser) firstError() int {
+	for i, ev := range b.events {
+		if ev.Error != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *Browser) find(word string) {
+	for i := b.pos + 1; i < len(b.events); i++ {
+		if b.events[i].Word == word {
+			b.moveTo(i)
+			return
+		}
+	}
+	fmt.Fprintf(b.out, "%s is not evaluated after step %d\n", word, b.events[b.pos].Step)
+}
+
+func (b *Browser) show() {
+	fmt.Fprintln(b.out, describe(b.events[b.pos]))
+}
+
+func (b *Browser) list(n int) {
+	from := max(b.pos-n/2, 0)
+	to := min(from+n, len(b.events))
+	for i := from; i < to; i++ {
+		marker := "  "
+		if i == b.pos {
+			marker = "=>"
+		}
+		fmt.Fprintf(b.out, "%s %s\n", marker, describe(b.events[i]))
+	}
+}
+
+// frame prints the frame captured at the current step. Words still bound
+// to their value from before the run are left out unless all is set.
+func (b *Browser) frame(all bool) {
+	ev := b.events[b.pos]
+	names := make([]string, 0, len(ev.Frame))
+	for name, val := range ev.Frame {
+		if before, ok := b.baseline[name]; all || !ok || before != val {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(b.out, "no frame state at step %d\n", ev.Step)
+		return
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(b.out, "  %s: %s\n", name, oneLine(ev.Frame[name]))
+	}
+}
+
+// describe renders an event on one line.
+func describe(ev trace.TraceEvent) string {
+	var what string
+	switch ev.EventType {
+	case "call":
+		what = "call " + ev.Word
+		for _, name := range sortedKeys(ev.Args) {
+			what += " " + name + "=" + ev.Args[name]
+		}
+	case "return":
+		what = ev.Word + " returned " + ev.Value
+	case "replay":
+		what = ev.Expression + " == " + ev.Value + " (recorded)"
+	default:
+		expr := ev.Expression
+		if expr == "" {
+			expr = ev.Word
+		}
+		what = expr + " == " + ev.Value
+	}
+	line := fmt.Sprintf("step %d [depth %d] %s", ev.Step, ev.Depth, oneLine(what))
+	if ev.Error != "" {
+		msg, _, _ := strings.Cut(ev.Error, "\n")
+		line += " !! " + msg
+	}
+	return line
+}
+
+// oneLine fits a formed value on one line of the listing.
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxShown {
+		s = s[:maxShown-3] + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
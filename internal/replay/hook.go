@@ -0,0 +1,204 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/trace"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// wrapNatives replaces the implementation of each recorded native bound in
+// root. The functions are per evaluator, so other evaluators are unaffected.
+func wrapNatives(root core.Frame, wrap func(name string, inner core.NativeFunc) core.NativeFunc) {
+	for _, name := range Natives {
+		bound, ok := root.Get(name)
+		if !ok {
+			continue
+		}
+		fn, ok := value.AsFunctionValue(bound)
+		if !ok || fn.Type != value.FuncNative || fn.Native == nil {
+			continue
+		}
+		fn.Native = wrap(name, fn.Native)
+	}
+}
+
+// recorded reports whether a call to name is recorded. Plain random draws
+// come from the seeded generator and replay by themselves.
+func recorded(name string, refValues map[string]core.Value) bool {
+	if name != "random" {
+		return true
+	}
+	secure, ok := refValues["secure"]
+	if !ok {
+		return false
+	}
+	on, _ := value.AsLogicValue(secure)
+	return on
+}
+
+// moldArgs renders a call's arguments for the recording.
+func moldArgs(args []core.Value) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Mold()
+	}
+	return strings.Join(parts, " ")
+}
+
+// Recorder captures the recorded natives' results into a Recording.
+type Recorder struct {
+	mu  sync.Mutex
+	rec *Recording
+}
+
+// NewRecorder starts a recording of source. The caller seeds the
+// evaluator's random generator with seed.
+func NewRecorder(source, sourceName string, script, printResult bool, args []string, system System, seed uint64) *Recorder {
+	return &Recorder{rec: &Recording{
+		Version:     formatVersion,
+		SourceName:  sourceName,
+		Source:      source,
+		Script:      script,
+		PrintResult: printResult,
+		Args:        args,
+		System:      system,
+		Seed:        seed,
+		Calls:       []Call{},
+	}}
+}
+
+// Install records calls to the recorded natives bound in root.
+func (r *Recorder) Install(root core.Frame) {
+	wrapNatives(root, func(name string, inner core.NativeFunc) core.NativeFunc {
+		return func(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+			if !recorded(name, refValues) {
+				return inner(args, refValues, eval)
+			}
+			// The arguments are molded first, as the player sees them: close
+			// changes the state of its port.
+			call := Call{Native: name, Args: moldArgs(args)}
+			result, err := inner(args, refValues, eval)
+			if err != nil {
+				call.Error = encodeError(err)
+			} else {
+				encoded := encodeValue(result)
+				call.Result = &encoded
+			}
+			r.mu.Lock()
+			r.rec.Calls = append(r.rec.Calls, call)
+			r.mu.Unlock()
+			return result, err
+		}
+	})
+}
+
+// Finish sets the run's exit code and returns the recording.
+func (r *Recorder) Finish(exitCode int) *Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rec.ExitCode = exitCode
+	return r.rec
+}
+
+// Player substitutes recorded results for the recorded natives.
+type Player struct {
+	mu   sync.Mutex
+	rec  *Recording
+	next int
+}
+
+// NewPlayer returns a player for rec.
+func NewPlayer(rec *Recording) *Player {
+	return &Player{rec: rec}
+}
+
+// Install replays calls to the recorded natives bound in root. A call that
+// does not match the next recorded one is a divergence and fails with an
+// invalid-operation error.
+func (p *Player) Install(root core.Frame) {
+	wrapNatives(root, func(name string, inner core.NativeFunc) core.NativeFunc {
+		return func(args []core.Value, refValues map[string]core.Value, eval core.Evaluator) (core.Value, error) {
+			if !recorded(name, refValues) {
+				return inner(args, refValues, eval)
+			}
+			call, err := p.take(name, moldArgs(args))
+			if err != nil {
+				return value.NewNoneVal(), err
+			}
+			if call.Error != nil {
+				err := call.Error.rebuild()
+				emitReplayed(eval, name, call.Args, "", err.Error())
+				return value.NewNoneVal(), err
+			}
+			if name == "close" && len(args) == 1 {
+				if port, ok := value.AsPort(args[0]); ok {
+					port.State = value.PortClosed
+				}
+			}
+			if call.Result == nil {
+				return value.NewNoneVal(), nil
+			}
+			result, err := decodeValue(*call.Result)
+			if err != nil {
+				return value.NewNoneVal(), verror.NewScriptError(
+					verror.ErrIDInvalidOperation,
+					[3]string{fmt.Sprintf("replay of %s failed: %v", name, err), "", ""},
+				)
+			}
+			emitReplayed(eval, name, call.Args, result.Mold(), "")
+			return result, nil
+		}
+	})
+}
+
+// take returns the next recorded call, which must be a call to name.
+func (p *Player) take(name, args string) (Call, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.rec.Calls) {
+		return Call{}, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("replay diverged: the recording has no call %d (%s %s)", p.next+1, name, args), "", ""},
+		)
+	}
+	call := p.rec.Calls[p.next]
+	if call.Native != name {
+		return Call{}, verror.NewScriptError(
+			verror.ErrIDInvalidOperation,
+			[3]string{fmt.Sprintf("replay diverged at call %d: recorded %s %s, got %s %s", p.next+1, call.Native, call.Args, name, args), "", ""},
+		)
+	}
+	p.next++
+	return call, nil
+}
+
+// Remaining returns the number of recorded calls not yet replayed.
+func (p *Player) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.rec.Calls) - p.next
+}
+
+// emitReplayed traces a substituted call as a "replay" event, so stepping
+// through a replay shows where recorded input entered the run.
+func emitReplayed(eval core.Evaluator, name, args, result, errMsg string) {
+	if trace.GlobalTraceSession == nil || !trace.GlobalTraceSession.IsEnabled() {
+		return
+	}
+	trace.GlobalTraceSession.Emit(trace.TraceEvent{
+		Timestamp:  time.Now(),
+		Word:       name,
+		Value:      result,
+		EventType:  "replay",
+		Step:       trace.GlobalTraceSession.NextStep(),
+		Depth:      len(eval.GetCallStack()) - 1,
+		Expression: strings.TrimSpace(name + " " + args),
+		Error:      errMsg,
+	})
+}
@@ -0,0 +1,219 @@
+// Package replay records the results of natives that read the outside world
+// (standard input, files, ports and URLs, processes, the environment, file
+// times and secure random numbers) so a run can be replayed deterministically.
+//
+// A Recording holds the program, its arguments, the working directory and
+// platform the system object showed, the evaluator's random seed and the
+// result of every recorded native call in order. A Player substitutes
+// those results during replay. Natives that change the outside world, such as
+// write, delete and open, are recorded too, so a replay neither repeats their
+// effects nor depends on them.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/frame"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// formatVersion is written to every recording and checked by Load.
+const formatVersion = 1
+
+// Natives lists the natives whose results are recorded: those that read the
+// outside world, then those that change it. random is only recorded with
+// --secure; otherwise the recorded seed reproduces it. Every native that
+// takes a port is listed, so a replayed port is never used.
+var Natives = []string{
+	"input", "read", "load", "query", "wait", "call", "open",
+	"exists?", "dir?", "size?", "modified?", "info?", "list-dir", "archive-list",
+	"get-env", "random",
+	"write", "save", "close", "delete", "rename", "move", "make-dir", "copy-file",
+	"archive-create", "archive-extract", "set-env",
+}
+
+// Recording is a run captured by --record.
+type Recording struct {
+	Version     int      `json:"version"`
+	SourceName  string   `json:"source_name"`
+	Source      string   `json:"source"`
+	Script      bool     `json:"script"`       // source is a script file, with header and system.script
+	PrintResult bool     `json:"print_result"` // -c printed the result
+	Args        []string `json:"args"`
+	System      System   `json:"system"`
+	Seed        uint64   `json:"seed"`
+	Calls       []Call   `json:"calls"`
+	ExitCode    int      `json:"exit_code"`
+}
+
+// System is the environment the system object showed during the recorded
+// run. A replay shows the same, wherever it runs.
+type System struct {
+	Cwd  string `json:"cwd,omitempty"`
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+// Call is the outcome of one recorded native call.
+type Call struct {
+	Native string `json:"native"`
+	Args   string `json:"args,omitempty"` // molded, for reading the recording
+	Result *Value `json:"result,omitempty"`
+	Error  *Error `json:"error,omitempty"`
+}
+
+// Error is a recorded error, rebuilt with verror.NewError on replay.
+type Error struct {
+	Category verror.ErrorCategory `json:"category"`
+	ID       string               `json:"id"`
+	Args     [3]string            `json:"args"`
+}
+
+// Value is a recorded value. Most values are stored molded and parsed back
+// without evaluation; none, logic values, blocks, objects and ports are
+// stored by structure because their molded forms do not parse back to
+// themselves.
+type Value struct {
+	Mold   string  `json:"mold,omitempty"`
+	Kind   string  `json:"kind,omitempty"` // "none", "logic", "block", "object" or "port"
+	Logic  bool    `json:"logic,omitempty"`
+	Items  []Value `json:"items,omitempty"`
+	Fields []Field `json:"fields,omitempty"`
+	Scheme string  `json:"scheme,omitempty"` // of a port
+	Spec   string  `json:"spec,omitempty"`   // of a port
+}
+
+// Field is an object field in a recorded Value.
+type Field struct {
+	Name  string `json:"name"`
+	Value Value  `json:"value"`
+}
+
+// Load reads a recording written by Save.
+func Load(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("%s is not a recording: %w", path, err)
+	}
+	if rec.Version != formatVersion {
+		return nil, fmt.Errorf("%s has recording version %d, want %d", path, rec.Version, formatVersion)
+	}
+	return &rec, nil
+}
+
+// Save writes the recording to path as JSON.
+func (r *Recording) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// encodeValue converts v for storage in a recording.
+func encodeValue(v core.Value) Value {
+	switch v.GetType() {
+	case value.TypeNone:
+		return Value{Kind: "none"}
+	case value.TypeLogic:
+		b, _ := value.AsLogicValue(v)
+		return Value{Kind: "logic", Logic: b}
+	case value.TypeBlock:
+		blk, _ := value.AsBlockValue(v)
+		items := make([]Value, len(blk.Elements))
+		for i, elem := range blk.Elements {
+			items[i] = encodeValue(elem)
+		}
+		return Value{Kind: "block", Items: items}
+	case value.TypeObject:
+		obj, _ := value.AsObject(v)
+		bindings := obj.GetAllFieldsWithProto()
+		fields := make([]Field, len(bindings))
+		for i, b := range bindings {
+			fields[i] = Field{Name: b.Symbol, Value: encodeValue(b.Value)}
+		}
+		return Value{Kind: "object", Fields: fields}
+	case value.TypePort:
+		port, _ := value.AsPort(v)
+		return Value{Kind: "port", Scheme: port.Scheme, Spec: port.Spec}
+	}
+	return Value{Mold: v.Mold()}
+}
+
+// decodeValue rebuilds a value stored by encodeValue.
+func decodeValue(v Value) (core.Value, error) {
+	switch v.Kind {
+	case "none":
+		return value.NewNoneVal(), nil
+	case "logic":
+		return value.NewLogicVal(v.Logic), nil
+	case "block":
+		items := make([]core.Value, len(v.Items))
+		for i, item := range v.Items {
+			decoded, err := decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = decoded
+		}
+		return value.NewBlockVal(items), nil
+	case "object":
+		objFrame := frame.NewFrame(frame.FrameObject, -1)
+		for _, f := range v.Fields {
+			decoded, err := decodeValue(f.Value)
+			if err != nil {
+				return nil, err
+			}
+			objFrame.Bind(f.Name, decoded)
+		}
+		return value.ObjectVal(value.NewObject(objFrame)), nil
+	case "port":
+		port := value.NewPort(v.Scheme, v.Spec, replayedPort{})
+		port.State = value.PortOpen
+		return value.PortVal(port), nil
+	case "":
+		vals, _, err := parse.Parse(v.Mold)
+		if err != nil || len(vals) != 1 {
+			return nil, fmt.Errorf("cannot replay value %s", v.Mold)
+		}
+		return vals[0], nil
+	}
+	return nil, fmt.Errorf("unknown recorded value kind %q", v.Kind)
+}
+
+// errReplayedPort is returned by every operation on a replayed port.
+var errReplayedPort = errors.New("a replayed port cannot be used outside its recorded calls")
+
+// replayedPort drives the open ports of a replay. The natives that take a
+// port are all recorded, so it is never asked for I/O.
+type replayedPort struct{}
+
+func (replayedPort) Open(context.Context, string) error { return errReplayedPort }
+func (replayedPort) Read([]byte) (int, error)           { return 0, errReplayedPort }
+func (replayedPort) Write([]byte) (int, error)          { return 0, errReplayedPort }
+func (replayedPort) Close() error                       { return nil }
+func (replayedPort) Query() (map[string]any, error)     { return nil, errReplayedPort }
+
+// encodeError converts a native's error for storage. Errors that are not
+// Viro errors are kept as invalid-operation errors with their message.
+func encodeError(err error) *Error {
+	if vErr, ok := err.(*verror.Error); ok {
+		return &Error{Category: vErr.Category, ID: vErr.ID, Args: vErr.Args}
+	}
+	return &Error{Category: verror.ErrScript, ID: verror.ErrIDInvalidOperation, Args: [3]string{err.Error(), "", ""}}
+}
+
+func (e *Error) rebuild() error {
+	return verror.NewError(e.Category, e.ID, e.Args)
+}
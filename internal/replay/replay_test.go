@@ -0,0 +1,145 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/bootstrap"
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/trace"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+const script = `name: input
+n: random --secure 1000000
+m: random 1000000
+print [name n + m]
+`
+
+func run(t *testing.T, stdin io.Reader, install func(root core.Frame), seed uint64) (string, error) {
+	t.Helper()
+	values, locations, err := parse.ParseWithSource(script, "s.viro")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var out bytes.Buffer
+	e := bootstrap.NewEvaluatorWithNatives(&out, io.Discard, stdin, false)
+	e.SeedRandom(seed)
+	install(e.GetFrameByIndex(0))
+	_, err = e.DoBlock(values, locations)
+	return out.String(), err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	recorder := NewRecorder(script, "s.viro", true, false, nil, System{}, 42)
+	recorded, err := run(t, strings.NewReader("ada\n"), recorder.Install, 42)
+	if err != nil {
+		t.Fatalf("recorded run failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rec.json")
+	if err := recorder.Finish(0).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Calls) != 2 || rec.Calls[0].Native != "input" || rec.Calls[1].Native != "random" {
+		t.Fatalf("recorded calls = %+v, want input and random --secure only", rec.Calls)
+	}
+
+	player := NewPlayer(rec)
+	replayed, err := run(t, strings.NewReader(""), player.Install, rec.Seed)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if replayed != recorded {
+		t.Errorf("replay printed %q, recorded run %q", replayed, recorded)
+	}
+	if player.Remaining() != 0 {
+		t.Errorf("%d calls left over", player.Remaining())
+	}
+}
+
+func TestReplayDiverged(t *testing.T) {
+	rec := &Recording{Calls: []Call{{Native: "get-env", Result: &Value{Kind: "none"}}}}
+	_, err := run(t, strings.NewReader(""), NewPlayer(rec).Install, 1)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || !strings.Contains(vErr.Args[0], "replay diverged at call 1") {
+		t.Errorf("expected a divergence error, got %v", err)
+	}
+}
+
+func TestReplayRecordedError(t *testing.T) {
+	rec := &Recording{Calls: []Call{{
+		Native: "input",
+		Error:  &Error{Category: verror.ErrAccess, ID: "io-error", Args: [3]string{"stdin", "closed", ""}},
+	}}}
+	_, err := run(t, strings.NewReader(""), NewPlayer(rec).Install, 1)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || vErr.Category != verror.ErrAccess || vErr.ID != "io-error" {
+		t.Errorf("expected the recorded error, got %v", err)
+	}
+}
+
+func TestValueRoundTrip(t *testing.T) {
+	sources := []string{
+		`"text"`, `42`, `1.5`, `#{DEADBEEF}`, `none`, `true`,
+		`[1 "two" [false none]]`, `make object! [size: 3 kind: 'file]`,
+	}
+	for _, src := range sources {
+		t.Run(src, func(t *testing.T) {
+			values, _, err := parse.Parse(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := bootstrap.NewEvaluatorWithNatives(io.Discard, io.Discard, nil, true)
+			original, err := e.DoBlock(values, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := decodeValue(encodeValue(original))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Mold() != original.Mold() || decoded.GetType() != original.GetType() {
+				t.Errorf("decoded %s (%v), want %s (%v)", decoded.Mold(), decoded.GetType(), original.Mold(), original.GetType())
+			}
+		})
+	}
+}
+
+func TestBrowser(t *testing.T) {
+	events := []trace.TraceEvent{
+		{Step: 1, EventType: "eval", Expression: "x: 1", Value: "1", Frame: map[string]string{"print": "native[print]", "x": "1"}},
+		{Step: 2, EventType: "replay", Word: "input", Expression: "input", Value: `"ada"`},
+		{Step: 3, EventType: "eval", Expression: "1 / 0", Error: "Math error\nNear: ..."},
+		{Step: 4, EventType: "eval", Expression: "y", Value: "2"},
+	}
+	var out bytes.Buffer
+	b := NewBrowser(events, map[string]string{"print": "native[print]"}, &out)
+	b.Run(strings.NewReader("back 2\nframe\nstep 2\nfind zzz\nlast\nq\n"))
+
+	got := out.String()
+	for _, want := range []string{
+		"replay: 4 steps (1-4)",
+		"step 3 [depth 0] 1 / 0 == !! Math error\n",
+		"  x: 1\n",
+		`step 2 [depth 0] input == "ada" (recorded)`,
+		"zzz is not evaluated after step 2",
+		"step 4 [depth 0] y == 2",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("browser output lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "print: native[print]") {
+		t.Errorf("frame shows an unchanged native:\n%s", got)
+	}
+}
@@ -0,0 +1,290 @@
+// Package testrun holds the results of `viro test` and writes them as
+// text, TAP or JUnit XML.
+package testrun
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Output formats.
+const (
+	FormatText  = "text"
+	FormatTAP   = "tap"
+	FormatJUnit = "junit"
+)
+
+// Case is the result of one test case.
+type Case struct {
+	Name     string
+	File     string
+	Line     int
+	Duration time.Duration
+	// Output is what the case printed.
+	Output  string
+	Failure *Failure
+}
+
+// Failure describes why a case failed.
+type Failure struct {
+	// Kind is the error id, e.g. assertion-failed or div-zero.
+	Kind    string
+	Message string
+	File    string
+	Line    int
+	Column  int
+}
+
+// Location renders where the failure happened, or "" when unknown.
+func (f *Failure) Location() string {
+	if f.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+}
+
+// File is the result of one test file. A file that fails to load has a
+// LoadError and no cases.
+type File struct {
+	Path      string
+	Cases     []Case
+	LoadError *Failure
+	Duration  time.Duration
+}
+
+// Report is the result of a whole run.
+type Report struct {
+	Files []File
+}
+
+// Counts returns the number of passed and failed cases. A file that failed
+// to load counts as one failure.
+func (r *Report) Counts() (passed, failed int) {
+	for _, f := range r.Files {
+		if f.LoadError != nil {
+			failed++
+		}
+		for _, c := range f.Cases {
+			if c.Failure != nil {
+				failed++
+			} else {
+				passed++
+			}
+		}
+	}
+	return passed, failed
+}
+
+// Write writes the report in the given format. verbose lists passing cases
+// and their output in the text format.
+func (r *Report) Write(w io.Writer, format string, verbose bool) error {
+	switch format {
+	case FormatTAP:
+		return r.writeTAP(w)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	default:
+		return r.writeText(w, verbose)
+	}
+}
+
+func indent(text, prefix string) string {
+	text = strings.TrimRight(text, "\n")
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+func (r *Report) writeText(w io.Writer, verbose bool) error {
+	var b strings.Builder
+	for _, f := range r.Files {
+		failedHere := 0
+		if f.LoadError != nil {
+			failedHere++
+			fmt.Fprintf(&b, "--- FAIL: %s (load)\n", f.Path)
+			writeTextFailure(&b, f.LoadError)
+		}
+		for _, c := range f.Cases {
+			switch {
+			case c.Failure != nil:
+				failedHere++
+				fmt.Fprintf(&b, "--- FAIL: %s (%s:%d, %s)\n", c.Name, c.File, c.Line, seconds(c.Duration))
+				writeTextFailure(&b, c.Failure)
+				if c.Output != "" {
+					b.WriteString(indent(c.Output, "    | ") + "\n")
+				}
+			case verbose:
+				fmt.Fprintf(&b, "--- PASS: %s (%s)\n", c.Name, seconds(c.Duration))
+				if c.Output != "" {
+					b.WriteString(indent(c.Output, "    | ") + "\n")
+				}
+			}
+		}
+		status := "ok  "
+		if failedHere > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%s %s\t%d passed, %d failed\t%s\n", status, f.Path, len(f.Cases)-countFailures(f.Cases), failedHere, seconds(f.Duration))
+	}
+
+	passed, failed := r.Counts()
+	if failed > 0 {
+		fmt.Fprintf(&b, "FAIL: %d passed, %d failed\n", passed, failed)
+	} else {
+		fmt.Fprintf(&b, "PASS: %d passed\n", passed)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeTextFailure(b *strings.Builder, f *Failure) {
+	message := f.Message
+	if loc := f.Location(); loc != "" {
+		message = loc + ": " + message
+	}
+	b.WriteString(indent(message, "    ") + "\n")
+}
+
+func countFailures(cases []Case) int {
+	n := 0
+	for _, c := range cases {
+		if c.Failure != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
+
+// writeTAP writes TAP version 13. Failure details go in a YAML block and
+// output in comment lines.
+func (r *Report) writeTAP(w io.Writer) error {
+	var b strings.Builder
+	passed, failed := r.Counts()
+	fmt.Fprintf(&b, "TAP version 13\n1..%d\n", passed+failed)
+	n := 0
+	point := func(ok bool, description, output string, failure *Failure) {
+		n++
+		status := "ok"
+		if !ok {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, n, tapEscape(description))
+		if failure != nil {
+			b.WriteString("  ---\n")
+			fmt.Fprintf(&b, "  message: %q\n", failure.Message)
+			fmt.Fprintf(&b, "  severity: fail\n")
+			if failure.Kind != "" {
+				fmt.Fprintf(&b, "  error: %s\n", failure.Kind)
+			}
+			if loc := failure.Location(); loc != "" {
+				fmt.Fprintf(&b, "  at: %q\n", loc)
+			}
+			b.WriteString("  ...\n")
+		}
+		if output != "" {
+			b.WriteString(indent(output, "# ") + "\n")
+		}
+	}
+	for _, f := range r.Files {
+		if f.LoadError != nil {
+			point(false, f.Path+" (load)", "", f.LoadError)
+		}
+		for _, c := range f.Cases {
+			point(c.Failure == nil, f.Path+": "+c.Name, c.Output, c.Failure)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tapEscape keeps a description on one line and away from the directive
+// marker.
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "#", "\\#")
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func newJUnitFailure(f *Failure) *junitFailure {
+	text := f.Message
+	if loc := f.Location(); loc != "" {
+		text = loc + ": " + text
+	}
+	message, _, _ := strings.Cut(f.Message, "\n")
+	return &junitFailure{Message: message, Type: f.Kind, Text: text}
+}
+
+func (r *Report) writeJUnit(w io.Writer) error {
+	passed, failed := r.Counts()
+	suites := junitSuites{Tests: passed + failed, Failures: failed}
+	var total time.Duration
+	for _, f := range r.Files {
+		suite := junitSuite{Name: f.Path, Time: fmt.Sprintf("%.3f", f.Duration.Seconds())}
+		if f.LoadError != nil {
+			suite.Cases = append(suite.Cases, junitCase{Name: "(load)", Classname: f.Path, File: f.Path, Time: "0.000", Failure: newJUnitFailure(f.LoadError)})
+		}
+		for _, c := range f.Cases {
+			jc := junitCase{Name: c.Name, Classname: f.Path, File: c.File, Line: c.Line, Time: fmt.Sprintf("%.3f", c.Duration.Seconds()), SystemOut: c.Output}
+			if c.Failure != nil {
+				jc.Failure = newJUnitFailure(c.Failure)
+			}
+			suite.Cases = append(suite.Cases, jc)
+		}
+		for _, c := range suite.Cases {
+			suite.Tests++
+			if c.Failure != nil {
+				suite.Failures++
+			}
+		}
+		total += f.Duration
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
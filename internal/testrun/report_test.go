@@ -0,0 +1,112 @@
+package testrun
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func sampleReport() *Report {
+	return &Report{Files: []File{
+		{
+			Path: "math_test.viro",
+			Cases: []Case{
+				{Name: "adds", File: "math_test.viro", Line: 1},
+				{Name: "divides", File: "math_test.viro", Line: 5, Output: "dividing\n", Failure: &Failure{
+					Kind: "div-zero", Message: "Division by zero", File: "math_test.viro", Line: 6, Column: 5,
+				}},
+			},
+		},
+		{Path: "bad_test.viro", LoadError: &Failure{Kind: "unclosed-block", Message: "Unclosed block", File: "bad_test.viro", Line: 1, Column: 4}},
+	}}
+}
+
+func TestCounts(t *testing.T) {
+	passed, failed := sampleReport().Counts()
+	if passed != 1 || failed != 2 {
+		t.Errorf("Counts() = %d, %d; want 1, 2", passed, failed)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var b strings.Builder
+	if err := sampleReport().Write(&b, FormatText, true); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"--- PASS: adds",
+		"--- FAIL: divides (math_test.viro:5,",
+		"    math_test.viro:6:5: Division by zero\n",
+		"    | dividing\n",
+		"--- FAIL: bad_test.viro (load)",
+		"FAIL: 1 passed, 2 failed\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text report missing %q:\n%s", want, out)
+		}
+	}
+
+	b.Reset()
+	sampleReport().Write(&b, FormatText, false)
+	if strings.Contains(b.String(), "PASS: adds") {
+		t.Errorf("passing case listed without verbose:\n%s", b.String())
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	var b strings.Builder
+	if err := sampleReport().Write(&b, FormatTAP, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 13
+1..3
+ok 1 - math_test.viro: adds
+not ok 2 - math_test.viro: divides
+  ---
+  message: "Division by zero"
+  severity: fail
+  error: div-zero
+  at: "math_test.viro:6:5"
+  ...
+# dividing
+not ok 3 - bad_test.viro (load)
+  ---
+  message: "Unclosed block"
+  severity: fail
+  error: unclosed-block
+  at: "bad_test.viro:1:4"
+  ...
+`
+	if b.String() != want {
+		t.Errorf("TAP report =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var b strings.Builder
+	if err := sampleReport().Write(&b, FormatJUnit, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal([]byte(b.String()), &suites); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, b.String())
+	}
+	if suites.Tests != 3 || suites.Failures != 2 || len(suites.Suites) != 2 {
+		t.Fatalf("testsuites = %+v", suites)
+	}
+	cases := suites.Suites[0].Cases
+	if len(cases) != 2 || cases[0].Failure != nil || cases[1].Failure == nil {
+		t.Fatalf("testcases = %+v", cases)
+	}
+	if f := cases[1].Failure; f.Type != "div-zero" || f.Message != "Division by zero" {
+		t.Errorf("failure = %+v", f)
+	}
+	if cases[1].SystemOut != "dividing\n" {
+		t.Errorf("system-out = %q", cases[1].SystemOut)
+	}
+	if load := suites.Suites[1].Cases; len(load) != 1 || load[0].Name != "(load)" {
+		t.Errorf("load case = %+v", load)
+	}
+}
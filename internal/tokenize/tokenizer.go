@@ -16,6 +16,8 @@ const (
 	TokenLBracket
 	TokenRBracket
 	TokenEOF
+	// TokenComment is only produced when comments are kept; see SetKeepComments.
+	TokenComment
 )
 
 type Token struct {
@@ -24,14 +26,18 @@ type Token struct {
 	Line   int
 	Column int
 	Source string
+	// Raw is the token's text as written in the source. It differs from
+	// Value only for strings, where Value has quotes and escapes resolved.
+	Raw string
 }
 
 type Tokenizer struct {
-	input  string
-	pos    int
-	line   int
-	column int
-	source string
+	input        string
+	pos          int
+	line         int
+	column       int
+	source       string
+	keepComments bool
 }
 
 func NewTokenizer(input string) *Tokenizer {
@@ -47,6 +53,13 @@ func (t *Tokenizer) SetSource(source string) {
 	t.source = source
 }
 
+// SetKeepComments makes the tokenizer return comments as TokenComment
+// tokens instead of skipping them. The parser does not accept comment
+// tokens; this is for tools such as the formatter that re-emit source.
+func (t *Tokenizer) SetKeepComments(keep bool) {
+	t.keepComments = keep
+}
+
 func (t *Tokenizer) syntaxError(id string, args [3]string, line, column int) *verror.Error {
 	return verror.NewSyntaxError(id, args).SetLocation(t.source, line, column)
 }
@@ -62,6 +75,15 @@ func (t *Tokenizer) NextToken() (Token, error) {
 	tokenLine := t.line
 	tokenColumn := t.column
 
+	if ch == ';' {
+		start := t.pos
+		for t.pos < len(t.input) && t.input[t.pos] != '\n' {
+			t.advance()
+		}
+		text := t.input[start:t.pos]
+		return Token{Type: TokenComment, Value: text, Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: text}, nil
+	}
+
 	if ch == '@' || ch == '`' || ch == '~' {
 		return Token{}, t.syntaxError(verror.ErrIDInvalidCharacter, [3]string{string(ch), "", ""}, tokenLine, tokenColumn)
 	}
@@ -69,25 +91,26 @@ func (t *Tokenizer) NextToken() (Token, error) {
 	switch ch {
 	case '[':
 		t.advance()
-		return Token{Type: TokenLBracket, Value: "[", Line: tokenLine, Column: tokenColumn, Source: t.source}, nil
+		return Token{Type: TokenLBracket, Value: "[", Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: "["}, nil
 	case ']':
 		t.advance()
-		return Token{Type: TokenRBracket, Value: "]", Line: tokenLine, Column: tokenColumn, Source: t.source}, nil
+		return Token{Type: TokenRBracket, Value: "]", Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: "]"}, nil
 	case '(':
 		t.advance()
-		return Token{Type: TokenLParen, Value: "(", Line: tokenLine, Column: tokenColumn, Source: t.source}, nil
+		return Token{Type: TokenLParen, Value: "(", Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: "("}, nil
 	case ')':
 		t.advance()
-		return Token{Type: TokenRParen, Value: ")", Line: tokenLine, Column: tokenColumn, Source: t.source}, nil
+		return Token{Type: TokenRParen, Value: ")", Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: ")"}, nil
 	case '"':
+		start := t.pos
 		str, err := t.readString()
 		if err != nil {
 			return Token{}, err
 		}
-		return Token{Type: TokenString, Value: str, Line: tokenLine, Column: tokenColumn, Source: t.source}, nil
+		return Token{Type: TokenString, Value: str, Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: t.input[start:t.pos]}, nil
 	default:
 		literal := t.readLiteral()
-		return Token{Type: TokenLiteral, Value: literal, Line: tokenLine, Column: tokenColumn, Source: t.source}, nil
+		return Token{Type: TokenLiteral, Value: literal, Line: tokenLine, Column: tokenColumn, Source: t.source, Raw: literal}, nil
 	}
 }
 
@@ -119,7 +142,7 @@ func (t *Tokenizer) skipWhitespaceAndComments() {
 			continue
 		}
 
-		if ch == ';' {
+		if ch == ';' && !t.keepComments {
 			t.skipComment()
 			continue
 		}
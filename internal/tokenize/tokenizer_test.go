@@ -218,6 +218,42 @@ func TestTokenizer_Comments(t *testing.T) {
 	}
 }
 
+func TestTokenizer_KeepComments(t *testing.T) {
+	tokenizer := NewTokenizer("; head\nabc [1 ; inner  \n]")
+	tokenizer.SetKeepComments(true)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+
+	expected := []Token{
+		{Type: TokenComment, Value: "; head", Line: 1, Column: 1},
+		{Type: TokenLiteral, Value: "abc", Line: 2, Column: 1},
+		{Type: TokenLBracket, Value: "[", Line: 2, Column: 5},
+		{Type: TokenLiteral, Value: "1", Line: 2, Column: 6},
+		{Type: TokenComment, Value: "; inner  ", Line: 2, Column: 8},
+		{Type: TokenRBracket, Value: "]", Line: 3, Column: 1},
+		{Type: TokenEOF, Line: 3, Column: 2},
+	}
+	if !tokensEqual(tokens, expected) {
+		t.Errorf("Expected %v, got %v", expected, tokens)
+	}
+}
+
+func TestTokenizer_RawText(t *testing.T) {
+	tokenizer := NewTokenizer(`"a\tb" word`)
+	tokens, err := tokenizer.Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+	if tokens[0].Value != "a\tb" || tokens[0].Raw != `"a\tb"` {
+		t.Errorf("string Value = %q, Raw = %q", tokens[0].Value, tokens[0].Raw)
+	}
+	if tokens[1].Raw != "word" {
+		t.Errorf("literal Raw = %q, want %q", tokens[1].Raw, "word")
+	}
+}
+
 func TestTokenizer_Mixed(t *testing.T) {
 	tokenizer := NewTokenizer(`abc [1 "test"] def`)
 	tokens, err := tokenizer.Tokenize()
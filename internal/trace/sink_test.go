@@ -0,0 +1,197 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSession(sink Sink, filters TraceFilters) *TraceSession {
+	ts := &TraceSession{sink: sink}
+	ts.enabled.Store(true)
+	ts.atomicFilters.Store(&filters)
+	return ts
+}
+
+func TestTreeSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewTreeSink(&buf)
+	events := []TraceEvent{
+		{EventType: "call", Word: "square", Depth: 1, Args: map[string]string{"n": "3"}},
+		{EventType: "eval", Expression: "n", Value: "3", Depth: 1},
+		{EventType: "return", Word: "square", Value: "9", Depth: 1, Duration: int64(2 * time.Microsecond)},
+		{EventType: "eval", Expression: "x:", Value: "9"},
+	}
+	for _, ev := range events {
+		if err := sink.Write(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "-> square n=3\n  n == 3\n<- square == 9 (2µs)\nx: == 9\n"
+	if buf.String() != want {
+		t.Errorf("tree output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestChromeSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewChromeSink(&buf)
+	start := time.Unix(100, 0)
+	for _, ev := range []TraceEvent{
+		{EventType: "call", Word: "square", Timestamp: start},
+		{EventType: "return", Word: "square", Value: "9", Timestamp: start, Duration: int64(5 * time.Microsecond)},
+		{EventType: "port", Word: "open", Value: "port opened", Timestamp: start.Add(10 * time.Microsecond)},
+	} {
+		if err := sink.Write(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("chrome trace is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("got %d events, want 2 (call is folded into return):\n%s", len(parsed), buf.String())
+	}
+	if parsed[0]["ph"] != "X" || parsed[0]["name"] != "square" || parsed[0]["dur"] != 5.0 {
+		t.Errorf("return event = %v", parsed[0])
+	}
+	if parsed[1]["ph"] != "i" || parsed[1]["ts"] != 10.0 {
+		t.Errorf("port event = %v", parsed[1])
+	}
+
+	buf.Reset()
+	empty := NewChromeSink(&buf)
+	empty.Close()
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty chrome trace = %q", buf.String())
+	}
+}
+
+func TestRingSinkKeepsLastEvents(t *testing.T) {
+	var buf bytes.Buffer
+	ring := NewRingSink(3, NewJSONSink(&buf))
+	ts := newTestSession(ring, TraceFilters{})
+	for _, word := range []string{"a", "b", "c", "d", "e"} {
+		ts.Emit(TraceEvent{Word: word})
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("ring wrote before dump: %s", buf.String())
+	}
+
+	var words []string
+	for _, ev := range ring.Events() {
+		words = append(words, ev.Word)
+	}
+	if strings.Join(words, "") != "cde" {
+		t.Errorf("ring holds %v, want [c d e]", words)
+	}
+
+	if err := ts.Dump(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[0], `"word":"c"`) {
+		t.Errorf("dump wrote:\n%s", buf.String())
+	}
+	if len(ring.Events()) != 0 {
+		t.Errorf("dump did not clear the ring")
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	mem := NewMemorySink()
+	ts := newTestSession(mem, TraceFilters{})
+	for i := 0; i < 5; i++ {
+		ts.Emit(TraceEvent{Word: "w"})
+	}
+	if got := len(mem.Events()); got != 5 {
+		t.Errorf("memory sink holds %d events, want 5", got)
+	}
+	mem.Clear()
+	if got := len(mem.Events()); got != 0 {
+		t.Errorf("Clear left %d events", got)
+	}
+	if err := ts.Dump(); err != nil {
+		t.Errorf("Dump of a memory sink should do nothing, got %v", err)
+	}
+}
+
+func TestFilterGlobsAndEventTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters TraceFilters
+		event   TraceEvent
+		want    bool
+	}{
+		{"include glob match", TraceFilters{IncludeWords: []string{"http-*"}}, TraceEvent{Word: "http-get"}, true},
+		{"include glob miss", TraceFilters{IncludeWords: []string{"http-*"}}, TraceEvent{Word: "read"}, false},
+		{"exclude glob", TraceFilters{ExcludeWords: []string{"?"}}, TraceEvent{Word: "+"}, false},
+		{"invalid glob matches itself", TraceFilters{IncludeWords: []string{"["}}, TraceEvent{Word: "["}, true},
+		{"event type selected", TraceFilters{EventTypes: []string{"call", "return"}}, TraceEvent{EventType: "call"}, true},
+		{"event type not selected", TraceFilters{EventTypes: []string{"call", "return"}}, TraceEvent{EventType: "eval"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemorySink()
+			newTestSession(mem, tt.filters).Emit(tt.event)
+			if got := len(mem.Events()) == 1; got != tt.want {
+				t.Errorf("emitted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenSink(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "trace.log")
+	for i := 0; i < 2; i++ {
+		sink, err := OpenSink("tree", file, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sink.Write(TraceEvent{Word: "w", Value: "1"})
+		sink.Close()
+	}
+	data, _ := os.ReadFile(file)
+	if got := strings.Count(string(data), "w: 1"); got != 2 {
+		t.Errorf("append mode kept %d events, want 2:\n%s", got, data)
+	}
+
+	sink, err := OpenSink("json", file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Close()
+	if data, _ := os.ReadFile(file); len(data) != 0 {
+		t.Errorf("file not truncated: %s", data)
+	}
+
+	if _, err := OpenSink("chrome", file, true); err == nil {
+		t.Error("appending a chrome trace should fail")
+	}
+	if _, err := OpenSink("xml", file, false); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
+
+func TestSetSinkClosesPrevious(t *testing.T) {
+	var buf bytes.Buffer
+	ts := newTestSession(NewChromeSink(&buf), TraceFilters{})
+	if err := ts.SetSink(NewMemorySink()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("previous sink not closed, wrote %q", buf.String())
+	}
+	ts.Emit(TraceEvent{Word: "w"})
+	if mem, ok := ts.Sink().(*MemorySink); !ok || len(mem.Events()) != 1 {
+		t.Errorf("events not routed to the new sink")
+	}
+}
@@ -1,8 +1,9 @@
 // Package trace provides tracing and observability infrastructure for Viro.
 //
 // This package manages trace event collection and output, supporting:
-// - Structured event emission (JSON format)
-// - Filtering by word patterns
+// - Structured event emission (line-delimited JSON, call tree or Chrome trace-event format)
+// - In-memory and ring-buffer sinks
+// - Filtering by word glob patterns and event types
 // - File and stderr output with rotation
 // - Port lifecycle tracing
 // - Object operation tracing
@@ -11,7 +12,6 @@
 package trace
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -19,8 +19,6 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // TraceSession manages trace event collection and output (Feature 002).
@@ -29,17 +27,21 @@ import (
 type TraceSession struct {
 	mu            sync.Mutex
 	enabled       atomic.Bool
-	sink          io.Writer          // Output destination (stderr or file)
-	logger        *lumberjack.Logger // Optional file logger
-	atomicFilters atomic.Value       // Stores *TraceFilters for lock-free reads; must always store a non-nil *TraceFilters pointer to avoid panics when type asserting in Load() calls
-	stepCounter   int64              // Monotonic step counter
-	callback      atomic.Value       // Stores func(TraceEvent) for lock-free reads
+	sink          Sink         // Output destination; guarded by mu
+	atomicFilters atomic.Value // Stores *TraceFilters for lock-free reads; must always store a non-nil *TraceFilters pointer to avoid panics when type asserting in Load() calls
+	stepCounter   int64        // Monotonic step counter
+	callback      atomic.Value // Stores func(TraceEvent) for lock-free reads
 }
 
+// EventTypes lists the event types a session emits, the names accepted by
+// trace --types.
+var EventTypes = []string{"call", "return", "eval", "block-enter", "block-exit", "debug", "port", "object", "replay"}
+
 // TraceFilters controls which events are emitted.
 type TraceFilters struct {
-	IncludeWords []string      // Only trace these words (empty = all)
-	ExcludeWords []string      // Never trace these words
+	IncludeWords []string      // Only trace words matching these glob patterns (empty = all)
+	ExcludeWords []string      // Never trace words matching these glob patterns
+	EventTypes   []string      // Only trace these event types (empty = all)
 	MinDuration  time.Duration // Only trace operations taking longer than this
 
 	Verbose     bool // Include frame state
@@ -55,7 +57,7 @@ type TraceEvent struct {
 	Word      string    `json:"word"`     // Word being evaluated (if applicable)
 	Duration  int64     `json:"duration"` // Nanoseconds spent evaluating
 
-	EventType  string            `json:"event_type,omitempty"`  // "eval", "call", "return", "block-enter", "block-exit"
+	EventType  string            `json:"event_type,omitempty"`  // One of EventTypes
 	Step       int64             `json:"step,omitempty"`        // Execution step counter
 	Depth      int               `json:"depth,omitempty"`       // Call stack depth
 	Position   int               `json:"position,omitempty"`    // Position in current block
@@ -72,24 +74,30 @@ var GlobalTraceSession *TraceSession
 // InitTrace initializes the global trace session.
 // Called during REPL initialization with CLI flag values.
 func InitTrace(traceFile string, maxSizeMB int) error {
-	var sink io.Writer = os.Stderr // Default per FR-015
+	return InitTraceWithFormat(traceFile, maxSizeMB, "json", 0)
+}
 
-	var logger *lumberjack.Logger
+// InitTraceWithFormat initializes the global trace session writing format
+// to traceFile, or to stderr when traceFile is empty. When bufferSize is
+// positive only the last bufferSize events are kept, and they reach the
+// output when the session is dumped.
+func InitTraceWithFormat(traceFile string, maxSizeMB int, format string, bufferSize int) error {
+	var sink Sink
+	var err error
 	if traceFile != "" {
-		// User requested file output
-		logger = &lumberjack.Logger{
-			Filename:   traceFile,
-			MaxSize:    maxSizeMB, // Per clarification: 50MB default
-			MaxBackups: 5,         // Per FR-015: retain 5 backup files
-			MaxAge:     0,         // No age-based deletion
-			Compress:   true,      // Per FR-015: compress backups
-		}
-		sink = logger
+		sink, err = newRotatingSink(format, traceFile, maxSizeMB)
+	} else {
+		sink, err = NewFormatSink(format, os.Stderr, nil) // Default per FR-015
+	}
+	if err != nil {
+		return err
+	}
+	if bufferSize > 0 {
+		sink = NewRingSink(bufferSize, sink)
 	}
 
 	ts := &TraceSession{
-		sink:   sink,
-		logger: logger,
+		sink: sink,
 	}
 	ts.enabled.Store(false)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -103,8 +111,7 @@ func InitTrace(traceFile string, maxSizeMB int) error {
 // Uses io.Discard for cross-platform compatibility (works on Windows, Unix, etc).
 func InitTraceSilent() error {
 	ts := &TraceSession{
-		sink:   io.Discard,
-		logger: nil,
+		sink: NewJSONSink(io.Discard),
 	}
 	ts.enabled.Store(false)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -113,6 +120,37 @@ func InitTraceSilent() error {
 	return nil
 }
 
+// SetSink replaces the session's sink and closes the previous one.
+func (ts *TraceSession) SetSink(sink Sink) error {
+	ts.mu.Lock()
+	previous := ts.sink
+	ts.sink = sink
+	ts.mu.Unlock()
+
+	if previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
+
+// Sink returns the session's sink.
+func (ts *TraceSession) Sink() Sink {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.sink
+}
+
+// Dump writes out the events held by a ring-buffer sink. It does nothing
+// for other sinks, so callers can dump unconditionally after an error.
+func (ts *TraceSession) Dump() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ring, ok := ts.sink.(*RingSink); ok {
+		return ring.Dump()
+	}
+	return nil
+}
+
 // Enable activates tracing with optional filters.
 func (ts *TraceSession) Enable(filters TraceFilters) {
 	ts.atomicFilters.Store(&filters)
@@ -138,14 +176,15 @@ func (ts *TraceSession) Emit(event TraceEvent) {
 	filters := ts.atomicFilters.Load().(*TraceFilters)
 
 	// Apply filters
-	if len(filters.IncludeWords) > 0 {
-		found := slices.Contains(filters.IncludeWords, event.Word)
-		if !found {
-			return
-		}
+	if len(filters.EventTypes) > 0 && !slices.Contains(filters.EventTypes, event.EventType) {
+		return
+	}
+
+	if len(filters.IncludeWords) > 0 && !matchWord(filters.IncludeWords, event.Word) {
+		return
 	}
 
-	if slices.Contains(filters.ExcludeWords, event.Word) {
+	if matchWord(filters.ExcludeWords, event.Word) {
 		return
 	}
 
@@ -158,17 +197,15 @@ func (ts *TraceSession) Emit(event TraceEvent) {
 		callback.(func(TraceEvent))(event)
 	}
 
-	// Serialize as JSON (per FR-015: line-delimited JSON)
-	data, err := json.Marshal(event)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "trace serialization error: %v\n", err)
-		return
-	}
-
 	// Write to sink (mutex-protected for safe concurrent writes)
 	ts.mu.Lock()
-	fmt.Fprintf(ts.sink, "%s\n", data)
-	ts.mu.Unlock()
+	defer ts.mu.Unlock()
+	if ts.sink == nil {
+		return
+	}
+	if err := ts.sink.Write(event); err != nil {
+		fmt.Fprintf(os.Stderr, "trace write error: %v\n", err)
+	}
 }
 
 // Close flushes and closes the trace session.
@@ -176,8 +213,8 @@ func (ts *TraceSession) Close() error {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	if ts.logger != nil {
-		return ts.logger.Close()
+	if ts.sink != nil {
+		return ts.sink.Close()
 	}
 	return nil
 }
@@ -221,7 +258,7 @@ func (ts *TraceSession) ShouldTraceAtDepth(depth int) bool {
 
 // SetCallback registers a callback function that will be invoked for each emitted trace event.
 // This is useful for profiling and other real-time analysis.
-// The callback is invoked lock-free before the event is serialized to JSON.
+// The callback is invoked lock-free before the event is written to the sink.
 func (ts *TraceSession) SetCallback(callback func(TraceEvent)) {
 	ts.callback.Store(callback)
 }
@@ -237,6 +274,7 @@ func TracePortOpen(scheme, spec string) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "open",
+		EventType: "port",
 		Value:     fmt.Sprintf("port opened: %s (%s)", spec, scheme),
 		Duration:  0,
 	}
@@ -252,6 +290,7 @@ func TracePortRead(scheme, spec string, bytes int) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "read",
+		EventType: "port",
 		Value:     fmt.Sprintf("port read: %s (%s) %d bytes", spec, scheme, bytes),
 		Duration:  0,
 	}
@@ -267,6 +306,7 @@ func TracePortWrite(scheme, spec string, bytes int) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "write",
+		EventType: "port",
 		Value:     fmt.Sprintf("port write: %s (%s) %d bytes", spec, scheme, bytes),
 		Duration:  0,
 	}
@@ -282,6 +322,7 @@ func TracePortClose(scheme, spec string) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "close",
+		EventType: "port",
 		Value:     fmt.Sprintf("port closed: %s (%s)", spec, scheme),
 		Duration:  0,
 	}
@@ -297,6 +338,7 @@ func TracePortError(scheme, spec string, err error) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "error",
+		EventType: "port",
 		Value:     fmt.Sprintf("port error: %s (%s) - %v", spec, scheme, err),
 		Duration:  0,
 	}
@@ -312,6 +354,7 @@ func TraceObjectCreate(fieldCount int) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "object",
+		EventType: "object",
 		Value:     fmt.Sprintf("object created: fields:%d", fieldCount),
 		Duration:  0,
 	}
@@ -332,6 +375,7 @@ func TraceObjectFieldRead(field string, found bool) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "select",
+		EventType: "object",
 		Value:     fmt.Sprintf("object field read: field:%s (%s)", field, status),
 		Duration:  0,
 	}
@@ -347,6 +391,7 @@ func TraceObjectFieldWrite(field string, newValue string) {
 	event := TraceEvent{
 		Timestamp: time.Now(),
 		Word:      "put",
+		EventType: "object",
 		Value:     fmt.Sprintf("object field write: field:%s value:%s", field, newValue),
 		Duration:  0,
 	}
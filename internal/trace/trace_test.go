@@ -81,7 +81,7 @@ func TestTraceSession_EnableDisable(t *testing.T) {
 func TestTraceSession_Emit(t *testing.T) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -111,7 +111,7 @@ func TestTraceSession_Emit(t *testing.T) {
 func TestTraceSession_Filters(t *testing.T) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 
@@ -252,7 +252,7 @@ func TestTraceSession_Getters(t *testing.T) {
 func TestTraceSession_SetCallback(t *testing.T) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -285,7 +285,7 @@ func TestTraceSession_SetCallback(t *testing.T) {
 func TestTraceSession_ConcurrentAccess(t *testing.T) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -334,7 +334,7 @@ func TestTraceSession_ConcurrentAccess(t *testing.T) {
 func TestTracePortLifecycle(t *testing.T) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -380,7 +380,7 @@ func TestTracePortLifecycle(t *testing.T) {
 func TestTraceObjectOperations(t *testing.T) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -492,25 +492,22 @@ func TestTraceEvent_JSONSerialization(t *testing.T) {
 }
 
 func TestTraceSession_Close(t *testing.T) {
-	// Test with nil logger
-	ts := &TraceSession{
-		logger: nil,
-	}
+	// Test with nil sink
+	ts := &TraceSession{}
 
 	err := ts.Close()
 	if err != nil {
-		t.Errorf("Close() with nil logger should not error, got %v", err)
+		t.Errorf("Close() with nil sink should not error, got %v", err)
 	}
 
-	// Test with logger (we can't easily test lumberjack without creating files)
-	// This would require integration testing
+	// File sinks are covered by TestOpenSink
 }
 
 // Benchmark tests
 func BenchmarkTraceSession_Emit(b *testing.B) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{})
@@ -532,7 +529,7 @@ func BenchmarkTraceSession_Emit(b *testing.B) {
 func BenchmarkTraceSession_EmitWithFilters(b *testing.B) {
 	var buf bytes.Buffer
 	ts := &TraceSession{
-		sink: &buf,
+		sink: NewJSONSink(&buf),
 	}
 	ts.enabled.Store(true)
 	ts.atomicFilters.Store(&TraceFilters{
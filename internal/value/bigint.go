@@ -0,0 +1,61 @@
+package value
+
+import (
+	"math/big"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+)
+
+// BigIntValue is an arbitrary-precision integer. Arithmetic on bigint! never
+// overflows, which makes it the type for checksums, combinatorics and other
+// integer work that outgrows int64.
+//
+// Literals carry an n suffix: 123n, -42n. A bigint! mixed with integer!
+// stays a bigint!; mixed with float! or decimal! it promotes like integer!
+// does. Values are immutable: operations always build a new big.Int.
+type BigIntValue struct {
+	n *big.Int
+}
+
+func (b *BigIntValue) GetType() core.ValueType {
+	return TypeBigInt
+}
+
+func (b *BigIntValue) GetPayload() any {
+	return b.n
+}
+
+// String returns the decimal digits without the suffix.
+func (b *BigIntValue) String() string {
+	return b.n.String()
+}
+
+// Mold returns the loadable form with the n suffix.
+func (b *BigIntValue) Mold() string {
+	return b.n.String() + "n"
+}
+
+func (b *BigIntValue) Form() string {
+	return b.n.String()
+}
+
+func (b *BigIntValue) Equals(other core.Value) bool {
+	if ob, ok := other.(*BigIntValue); ok {
+		return b.n.Cmp(ob.n) == 0
+	}
+	return false
+}
+
+// NewBigIntVal wraps n, which the caller must not modify afterwards.
+func NewBigIntVal(n *big.Int) core.Value {
+	return &BigIntValue{n: n}
+}
+
+// AsBigIntValue returns the big.Int held by a bigint!. The result is shared
+// and must not be modified.
+func AsBigIntValue(v core.Value) (*big.Int, bool) {
+	if b, ok := v.(*BigIntValue); ok {
+		return b.n, true
+	}
+	return nil, false
+}
@@ -15,6 +15,7 @@ type BinaryValue struct {
 }
 
 func NewBinaryValue(data []byte) *BinaryValue {
+	core.CountAllocation(core.AllocBinary, 1)
 	return &BinaryValue{
 		data:  data,
 		index: 0,
@@ -153,6 +154,7 @@ func (b *BinaryValue) Remove(count int) {
 func (b *BinaryValue) Clone() Series {
 	dataCopy := make([]byte, len(b.data))
 	copy(dataCopy, b.data)
+	core.CountAllocation(core.AllocBinary, 1)
 	return &BinaryValue{
 		data:  dataCopy,
 		index: b.index,
@@ -289,3 +291,10 @@ func SortBinary(b *BinaryValue) {
 		return b.data[i] < b.data[j]
 	})
 }
+
+// ShuffleBinary permutes the bytes of b in place using shuffle.
+func ShuffleBinary(b *BinaryValue, shuffle func(n int, swap func(i, j int))) {
+	shuffle(len(b.data), func(i, j int) {
+		b.data[i], b.data[j] = b.data[j], b.data[i]
+	})
+}
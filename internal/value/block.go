@@ -20,6 +20,7 @@ func NewBlockValue(elements []core.Value) *BlockValue {
 	if elements == nil {
 		elements = []core.Value{}
 	}
+	core.CountAllocation(core.AllocBlock, 1)
 	return &BlockValue{
 		Elements:  elements,
 		Index:     0,
@@ -32,6 +33,7 @@ func NewBlockValueWithType(elements []core.Value, typ core.ValueType) *BlockValu
 	if elements == nil {
 		elements = []core.Value{}
 	}
+	core.CountAllocation(core.AllocBlock, 1)
 	return &BlockValue{
 		Elements:  elements,
 		Index:     0,
@@ -217,6 +219,7 @@ func (b *BlockValue) Clone() Series {
 	copy(elemsCopy, b.Elements)
 	locCopy := make([]core.SourceLocation, len(b.Elements))
 	copy(locCopy, b.locations)
+	core.CountAllocation(core.AllocBlock, 1)
 	return &BlockValue{
 		Elements:  elemsCopy,
 		Index:     b.Index,
@@ -345,3 +348,10 @@ func SortBlock(b *BlockValue) {
 		}
 	})
 }
+
+// ShuffleBlock permutes the elements of b in place using shuffle.
+func ShuffleBlock(b *BlockValue, shuffle func(n int, swap func(i, j int))) {
+	shuffle(len(b.Elements), func(i, j int) {
+		b.Elements[i], b.Elements[j] = b.Elements[j], b.Elements[i]
+	})
+}
@@ -22,12 +22,24 @@ type DecimalValue struct {
 	Scale     int16            // Digits right of decimal point for formatting
 }
 
+// defaultDecimalContext is the context every new DecimalValue starts with.
+// Each value gets its own copy, so changing one value's context never affects
+// another. Arithmetic uses the evaluator's active context instead; see
+// with-decimal-context.
+var defaultDecimalContext = decimal.Context{
+	Precision:    34,                    // decimal128 target per FR-001
+	RoundingMode: decimal.ToNearestEven, // Banker's rounding per FR-003
+}
+
+// moldContext rounds values to their display scale without limiting digits.
+var moldContext = decimal.Context{
+	Precision:    decimal.UnlimitedPrecision,
+	RoundingMode: decimal.ToNearestEven,
+}
+
 // NewDecimal creates a DecimalValue with default context (34-digit precision, half-even rounding).
 func NewDecimal(magnitude *decimal.Big, scale int16) *DecimalValue {
-	ctx := decimal.Context{
-		Precision:    34,                    // decimal128 target per FR-001
-		RoundingMode: decimal.ToNearestEven, // Banker's rounding per FR-003
-	}
+	ctx := defaultDecimalContext
 	return &DecimalValue{
 		Magnitude: magnitude,
 		Context:   &ctx,
@@ -45,11 +57,14 @@ func (d *DecimalValue) Mold() string {
 	if d == nil || d.Magnitude == nil {
 		return "0.0"
 	}
-	// Use scale to format with correct decimal places
-	if f, ok := d.Magnitude.Float64(); ok {
-		return fmt.Sprintf("%.*f", d.Scale, f)
+	// Use scale to format with correct decimal places. Quantizing keeps every
+	// digit, so values beyond float64 precision mold exactly; huge exponents
+	// fall back to scientific notation.
+	if d.Magnitude.IsFinite() && -d.Magnitude.Scale() <= 34 {
+		q := new(decimal.Big).Copy(d.Magnitude)
+		moldContext.Quantize(q, int(d.Scale))
+		return fmt.Sprintf("%f", q)
 	}
-	// Fallback to scientific notation if conversion fails
 	return d.Magnitude.String()
 }
 
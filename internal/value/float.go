@@ -0,0 +1,77 @@
+package value
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+)
+
+// FloatValue is an IEEE-754 binary64 number. Unlike decimal! it is inexact,
+// but arithmetic maps directly onto hardware floating point, which makes it the
+// type for scientific and graphics code.
+//
+// Literals carry an f suffix so they cannot be confused with decimals:
+// 1.5f, 2f, 6.02e23f. NaN and the infinities have no literal form; they mold
+// as nan, inf and -inf and are built with to-float.
+type FloatValue float64
+
+func (f FloatValue) GetType() core.ValueType {
+	return TypeFloat
+}
+
+func (f FloatValue) GetPayload() any {
+	return float64(f)
+}
+
+// String returns the shortest representation that reads back as the same
+// float, always with a fraction or exponent.
+func (f FloatValue) String() string {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return "nan"
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// Mold returns the loadable form with the f suffix.
+func (f FloatValue) Mold() string {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return f.String()
+	}
+	return f.String() + "f"
+}
+
+func (f FloatValue) Form() string {
+	return f.String()
+}
+
+// Equals follows IEEE-754: NaN is not equal to anything, including itself.
+func (f FloatValue) Equals(other core.Value) bool {
+	if of, ok := other.(FloatValue); ok {
+		return f == of
+	}
+	return false
+}
+
+func NewFloatVal(f float64) core.Value {
+	return FloatValue(f)
+}
+
+func AsFloatValue(v core.Value) (float64, bool) {
+	if fv, ok := v.(FloatValue); ok {
+		return float64(fv), true
+	}
+	return 0, false
+}
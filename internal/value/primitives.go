@@ -100,26 +100,37 @@ func (n NoneValue) Equals(other core.Value) bool {
 	return ok
 }
 
-type WordValue string
+// wordSymbol is the payload shared by all word variants: the spelling plus its
+// interned symbol, resolved once when the word is created (normally at parse time).
+type wordSymbol struct {
+	name string
+	sym  core.Symbol
+}
+
+func newWordSymbol(name string) wordSymbol {
+	return wordSymbol{name: name, sym: core.Intern(name)}
+}
+
+type WordValue struct{ wordSymbol }
 
 func (w WordValue) GetType() core.ValueType {
 	return TypeWord
 }
 
 func (w WordValue) GetPayload() any {
-	return string(w)
+	return w.name
 }
 
 func (w WordValue) String() string {
-	return string(w)
+	return w.name
 }
 
 func (w WordValue) Mold() string {
-	return string(w)
+	return w.name
 }
 
 func (w WordValue) Form() string {
-	return string(w)
+	return w.name
 }
 
 func (w WordValue) Equals(other core.Value) bool {
@@ -129,26 +140,26 @@ func (w WordValue) Equals(other core.Value) bool {
 	return false
 }
 
-type SetWordValue string
+type SetWordValue struct{ wordSymbol }
 
 func (s SetWordValue) GetType() core.ValueType {
 	return TypeSetWord
 }
 
 func (s SetWordValue) GetPayload() any {
-	return string(s)
+	return s.name
 }
 
 func (s SetWordValue) String() string {
-	return string(s) + ":"
+	return s.name + ":"
 }
 
 func (s SetWordValue) Mold() string {
-	return string(s) + ":"
+	return s.name + ":"
 }
 
 func (s SetWordValue) Form() string {
-	return string(s) + ":"
+	return s.name + ":"
 }
 
 func (s SetWordValue) Equals(other core.Value) bool {
@@ -158,26 +169,26 @@ func (s SetWordValue) Equals(other core.Value) bool {
 	return false
 }
 
-type GetWordValue string
+type GetWordValue struct{ wordSymbol }
 
 func (g GetWordValue) GetType() core.ValueType {
 	return TypeGetWord
 }
 
 func (g GetWordValue) GetPayload() any {
-	return string(g)
+	return g.name
 }
 
 func (g GetWordValue) String() string {
-	return ":" + string(g)
+	return ":" + g.name
 }
 
 func (g GetWordValue) Mold() string {
-	return ":" + string(g)
+	return ":" + g.name
 }
 
 func (g GetWordValue) Form() string {
-	return ":" + string(g)
+	return ":" + g.name
 }
 
 func (g GetWordValue) Equals(other core.Value) bool {
@@ -187,26 +198,26 @@ func (g GetWordValue) Equals(other core.Value) bool {
 	return false
 }
 
-type LitWordValue string
+type LitWordValue struct{ wordSymbol }
 
 func (l LitWordValue) GetType() core.ValueType {
 	return TypeLitWord
 }
 
 func (l LitWordValue) GetPayload() any {
-	return string(l)
+	return l.name
 }
 
 func (l LitWordValue) String() string {
-	return "'" + string(l)
+	return "'" + l.name
 }
 
 func (l LitWordValue) Mold() string {
-	return "'" + string(l)
+	return "'" + l.name
 }
 
 func (l LitWordValue) Form() string {
-	return "'" + string(l)
+	return "'" + l.name
 }
 
 func (l LitWordValue) Equals(other core.Value) bool {
@@ -261,19 +272,19 @@ func NewNoneVal() core.Value {
 }
 
 func NewWordVal(symbol string) core.Value {
-	return WordValue(symbol)
+	return WordValue{newWordSymbol(symbol)}
 }
 
 func NewSetWordVal(symbol string) core.Value {
-	return SetWordValue(symbol)
+	return SetWordValue{newWordSymbol(symbol)}
 }
 
 func NewGetWordVal(symbol string) core.Value {
-	return GetWordValue(symbol)
+	return GetWordValue{newWordSymbol(symbol)}
 }
 
 func NewLitWordVal(symbol string) core.Value {
-	return LitWordValue(symbol)
+	return LitWordValue{newWordSymbol(symbol)}
 }
 
 func NewDatatypeVal(name string) core.Value {
@@ -297,18 +308,34 @@ func AsLogicValue(v core.Value) (bool, bool) {
 func AsWordValue(v core.Value) (string, bool) {
 	switch wv := v.(type) {
 	case WordValue:
-		return string(wv), true
+		return wv.name, true
 	case SetWordValue:
-		return string(wv), true
+		return wv.name, true
 	case GetWordValue:
-		return string(wv), true
+		return wv.name, true
 	case LitWordValue:
-		return string(wv), true
+		return wv.name, true
 	default:
 		return "", false
 	}
 }
 
+// AsWordSymbol returns the interned symbol of any word variant.
+func AsWordSymbol(v core.Value) (core.Symbol, bool) {
+	switch wv := v.(type) {
+	case WordValue:
+		return wv.sym, true
+	case SetWordValue:
+		return wv.sym, true
+	case GetWordValue:
+		return wv.sym, true
+	case LitWordValue:
+		return wv.sym, true
+	default:
+		return core.NoSymbol, false
+	}
+}
+
 func AsDatatypeValue(v core.Value) (string, bool) {
 	if dv, ok := v.(DatatypeValue); ok {
 		return string(dv), true
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
 )
@@ -14,6 +15,7 @@ type StringValue struct {
 }
 
 func NewStringValue(s string) *StringValue {
+	core.CountAllocation(core.AllocString, 1)
 	return &StringValue{
 		runes: []rune(s),
 		index: 0,
@@ -24,14 +26,18 @@ func (s *StringValue) String() string {
 	return string(s.runes)
 }
 
+// Mold quotes the visible part of the string, escaping backslashes and double
+// quotes so the result reads back as the same string. Other characters,
+// newlines included, are written as they are.
 func (s *StringValue) Mold() string {
 	if s.index >= len(s.runes) {
 		return `""`
 	}
-	visibleRunes := s.runes[s.index:]
-	return fmt.Sprintf(`"%s"`, string(visibleRunes))
+	return `"` + moldEscaper.Replace(string(s.runes[s.index:])) + `"`
 }
 
+var moldEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
 func (s *StringValue) Form() string {
 	if s.index >= len(s.runes) {
 		return ""
@@ -130,6 +136,7 @@ func (s *StringValue) SetRunes(r []rune) {
 func (s *StringValue) Clone() Series {
 	runesCopy := make([]rune, len(s.runes))
 	copy(runesCopy, s.runes)
+	core.CountAllocation(core.AllocString, 1)
 	return &StringValue{
 		runes: runesCopy,
 		index: s.index,
@@ -254,3 +261,11 @@ func SortString(s *StringValue) {
 		return s.runes[i] < s.runes[j]
 	})
 }
+
+// ShuffleString permutes the characters of s in place using shuffle, which
+// has the signature of (*rand.Rand).Shuffle.
+func ShuffleString(s *StringValue, shuffle func(n int, swap func(i, j int))) {
+	shuffle(len(s.runes), func(i, j int) {
+		s.runes[i], s.runes[j] = s.runes[j], s.runes[i]
+	})
+}
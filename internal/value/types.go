@@ -31,6 +31,8 @@ const (
 	TypeSetPath  // Set-path expression (transient evaluation type)
 	TypeDatatype // Datatype literal (e.g., object!, integer!)
 	TypeBinary   // Raw byte sequence
+	TypeFloat    // IEEE 754 binary64 floating point
+	TypeBigInt   // Arbitrary-precision integer
 )
 
 // TypeToString returns the type name for debugging and error messages.
@@ -74,6 +76,10 @@ func TypeToString(t core.ValueType) string {
 		return "datatype!"
 	case TypeBinary:
 		return "binary!"
+	case TypeFloat:
+		return "float!"
+	case TypeBigInt:
+		return "bigint!"
 	default:
 		return "unknown!"
 	}
@@ -80,10 +80,14 @@ const (
 	ErrIDArgCount         = "arg-count"
 	ErrIDEmptySeries      = "empty-series"
 	ErrIDOutOfBounds      = "out-of-bounds"
-	ErrIDNotImplemented   = "not-implemented" // Feature 002: feature not yet implemented
-	ErrIDNotComparable    = "not-comparable"  // sort on mixed types, etc.
-	ErrIDActionNoImpl     = "action-no-impl"  // Feature 004: action not defined for type
-	ErrIDInvalidToken     = "invalid-token"   // Runtime constructed token is malformed
+	ErrIDNotImplemented   = "not-implemented"  // Feature 002: feature not yet implemented
+	ErrIDNotComparable    = "not-comparable"   // sort on mixed types, etc.
+	ErrIDActionNoImpl     = "action-no-impl"   // Feature 004: action not defined for type
+	ErrIDInvalidToken     = "invalid-token"    // Runtime constructed token is malformed
+	ErrIDInvalidRegex     = "invalid-regex"    // regular expression failed to compile
+	ErrIDInvalidEncoding  = "invalid-encoding" // text is not valid in the requested base
+	ErrIDCorruptData      = "corrupt-data"     // compressed or archived data cannot be decoded
+	ErrIDShortData        = "short-data"       // binary ends before an unpack field is complete
 
 	// Feature 002: Reflection errors (T162)
 	ErrIDSpecUnsupported   = "spec-unsupported-type" // spec-of not supported for this type
@@ -111,10 +115,11 @@ const (
 	ErrIDSqrtNegative     = "sqrt-negative"     // sqrt of negative number
 	ErrIDLogDomain        = "log-domain"        // log of zero or negative
 	ErrIDExpOverflow      = "exp-overflow"      // exponential overflow
-	ErrIDDecimalPrecision = "decimal-precision" // precision overflow (>34 digits)
+	ErrIDDecimalPrecision = "decimal-precision" // result needs more digits than the decimal context allows
 	ErrIDInvalidDecimal   = "invalid-decimal"   // invalid decimal string format
 	ErrIDAsinDomain       = "asin-domain"       // asin outside [-1, 1]
 	ErrIDAcosDomain       = "acos-domain"       // acos outside [-1, 1]
+	ErrIDNonFiniteFloat   = "non-finite-float"  // NaN or infinity where a finite number is required
 
 	// Access errors (500) - Feature 002: Port I/O
 	ErrIDPortClosed            = "port-closed"             // operation on closed port
@@ -123,10 +128,14 @@ const (
 	ErrIDTimeout               = "timeout"                 // I/O operation timeout
 	ErrIDConnectionRefused     = "connection-refused"      // TCP/HTTP connection refused
 	ErrIDUnknownScheme         = "unknown-port-scheme"     // unsupported port scheme
+	ErrIDExecDisabled          = "exec-disabled"           // subprocess execution not permitted
+	ErrIDUnsafeArchiveEntry    = "unsafe-archive-entry"    // archive entry would land outside the extraction directory
 
 	// Internal errors (900)
-	ErrIDStackOverflow   = "stack-overflow"
-	ErrIDOutOfMemory     = "out-of-memory"
+	ErrIDStackOverflow = "stack-overflow"
+	ErrIDOutOfMemory   = "out-of-memory"
+
+	// Raised by assert, assert-equal and assert-error (ErrScript category)
 	ErrIDAssertionFailed = "assertion-failed"
 
 	// Loop control error IDs (ErrThrow category)
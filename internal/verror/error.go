@@ -147,6 +147,10 @@ var messageTemplates = map[string]string{
 	ErrIDNotImplemented:   "Feature not yet implemented: %1",
 	ErrIDActionNoImpl:     "Action not implemented for type: %1",
 	ErrIDInvalidToken:     "Invalid token object: %1",
+	ErrIDInvalidRegex:     "Invalid regular expression %1: %2",
+	ErrIDInvalidEncoding:  "Invalid base-%1 data: %2",
+	ErrIDCorruptData:      "Corrupt %1 data: %2",
+	ErrIDShortData:        "Not enough data for %1: need %2 bytes, %3 left",
 
 	ErrIDInvalidPath:      "Invalid path (%2): %1",
 	ErrIDNonePath:         "Cannot traverse path through none value",
@@ -163,10 +167,11 @@ var messageTemplates = map[string]string{
 	ErrIDSqrtNegative:     "Square root of negative number: %1",
 	ErrIDLogDomain:        "Logarithm domain error: %1",
 	ErrIDExpOverflow:      "Exponential overflow: %1",
-	ErrIDDecimalPrecision: "Decimal precision overflow (max 34 digits): %1",
+	ErrIDDecimalPrecision: "Decimal precision exceeded: %1 needs more than %2 digits",
 	ErrIDInvalidDecimal:   "Invalid decimal format: %1",
 	ErrIDAsinDomain:       "asin domain error: %1 not in [-1, 1]",
 	ErrIDAcosDomain:       "acos domain error: %1 not in [-1, 1]",
+	ErrIDNonFiniteFloat:   "Cannot convert %1 to %2",
 
 	ErrIDPortClosed:            "Port is closed: %1",
 	ErrIDTLSVerificationFailed: "TLS certificate verification failed: %1",
@@ -174,6 +179,8 @@ var messageTemplates = map[string]string{
 	ErrIDTimeout:               "I/O timeout: %1",
 	ErrIDConnectionRefused:     "Connection refused: %1",
 	ErrIDUnknownScheme:         "Unknown port scheme: %1",
+	ErrIDExecDisabled:          "Subprocess execution is disabled (run with --allow-exec): %1",
+	ErrIDUnsafeArchiveEntry:    "Unsafe archive entry %1: %2",
 
 	ErrIDSpecUnsupported:   "spec-of: unsupported type %1",
 	ErrIDNoBody:            "body-of: %1",
@@ -181,7 +188,7 @@ var messageTemplates = map[string]string{
 
 	ErrIDStackOverflow:   "Stack overflow (maximum depth exceeded)",
 	ErrIDOutOfMemory:     "Out of memory",
-	ErrIDAssertionFailed: "Internal assertion failed: %1",
+	ErrIDAssertionFailed: "Assertion failed: %1",
 
 	ErrIDBreak:               "break",
 	ErrIDContinue:            "continue",
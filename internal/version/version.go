@@ -0,0 +1,18 @@
+// Package version holds the interpreter version shared by the CLI, the REPL
+// and the system object.
+package version
+
+import "fmt"
+
+const (
+	Version   = "0.1.0"
+	BuildDate = ""
+)
+
+// String returns the human-readable version banner, e.g. "Viro 0.1.0".
+func String() string {
+	if BuildDate != "" {
+		return fmt.Sprintf("Viro %s (built %s)", Version, BuildDate)
+	}
+	return fmt.Sprintf("Viro %s", Version)
+}
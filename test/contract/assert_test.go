@@ -0,0 +1,75 @@
+package contract
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestAssert_Pass(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"assert value", `assert 1 < 2`, "true"},
+		{"assert block", `x: 5 assert [x > 1]`, "true"},
+		{"assert-equal", `assert-equal [1 2] reduce [1 1 + 1]`, "true"},
+		{"assert-error message", `assert-error [1 / 0]`, `"Division by zero"`},
+		{"assert-error id", `assert-error --id 'div-zero [1 / 0]`, `"Division by zero"`},
+		{"test returns body result", `test "t" [1 + 2]`, "3"},
+		{"test locals stay local", `x: 1 test "t" [x: 2] x`, "1"},
+		{"test sees outer words", `y: 4 test "t" [y * 2]`, "8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssert_Failures(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantID  string
+		message string
+	}{
+		{"falsy value", `assert none`, verror.ErrIDAssertionFailed, "condition is none"},
+		{"falsy block quoted", `x: 0 assert [x > 1]`, verror.ErrIDAssertionFailed, "[x > 1]"},
+		{"custom message", `assert --message "empty" false`, verror.ErrIDAssertionFailed, "Assertion failed: empty"},
+		{"caret diff", `assert-equal "abc" "abd"`, verror.ErrIDAssertionFailed, "expected: \"abc\"\n  actual: \"abd\"\n             ^"},
+		{"different types", `assert-equal 1 1.0f`, verror.ErrIDAssertionFailed, "expected: 1\n  actual: 1.0f\n           ^"},
+		{"no error raised", `assert-error [1 + 1]`, verror.ErrIDAssertionFailed, "expected an error, but [1 + 1] returned 2"},
+		{"wrong error id", `assert-error --id 'no-value [1 / 0]`, verror.ErrIDAssertionFailed, "expected error no-value, got div-zero"},
+		{"assert-error needs block", `assert-error 5`, verror.ErrIDTypeMismatch, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected verror.Error, got %T", err)
+			}
+			if verr.ID != tt.wantID {
+				t.Errorf("Error ID = %s, want %s", verr.ID, tt.wantID)
+			}
+			if !strings.Contains(verr.Message, tt.message) {
+				t.Errorf("Message = %q, want it to contain %q", verr.Message, tt.message)
+			}
+		})
+	}
+}
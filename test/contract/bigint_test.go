@@ -0,0 +1,173 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestBigInt_Arithmetic(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"literal", "123456789012345678901234567890n", "123456789012345678901234567890n"},
+		{"past int64 max", "9223372036854775807n + 1", "9223372036854775808n"},
+		{"past int64 min", "-9223372036854775808n - 1", "-9223372036854775809n"},
+		{"multiply", "9223372036854775807n * 9223372036854775807n", "85070591730234615847396907784232501249n"},
+		{"integer operand promotes", "2 * 21n", "42n"},
+		{"small results stay bigint", "5n - 3", "2n"},
+		{"division truncates", "-7n / 2", "-3n"},
+		{"mod follows dividend", "-7n mod 2", "-1n"},
+		{"float operand gives float", "1n + 0.5f", "1.5f"},
+		{"decimal operand gives decimal", "type? 1n + 0.5", "decimal!"},
+		{"pow", "pow 2n 100", "1267650600228229401496703205376n"},
+		{"pow negative exponent", "type? pow 2n -1", "decimal!"},
+		{"factorial", "r: 1n n: 25 loop 25 [r: r * n n: n - 1] r", "15511210043330985984000000n"},
+		{"round passes through", "round 5n", "5n"},
+		{"floor passes through", "floor -5n", "-5n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigInt_Comparison(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"less than", "9223372036854775807n < 9223372036854775808n", "true"},
+		{"greater than integer", "9223372036854775808n > 9223372036854775807", "true"},
+		{"less or equal", "3n <= 3", "true"},
+		{"greater or equal float", "2n >= 2.5f", "false"},
+		{"decimal comparison", "10000000000000000000000n > 0.5", "true"},
+		{"equal bigints", "5n = (2n + 3)", "true"},
+		{"equal integer", "1n = 1", "true"},
+		{"equal decimal", "10000000000000000000000n = 10000000000000000000000.0", "true"},
+		{"not equal float", "3n <> 3.5f", "true"},
+		{"not equal", "1n <> 2n", "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigInt_Bitwise(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"and", "bit.and 12n 10", "8n"},
+		{"or", "bit.or 12 3n", "15n"},
+		{"xor", "bit.xor 255n 15n", "240n"},
+		{"not", "bit.not 0n", "-1n"},
+		{"shl past 64 bits", "bit.shl 1n 70", "1180591620717411303424n"},
+		{"shr keeps sign", "bit.shr -16n 2", "-4n"},
+		{"on past 64 bits", "bit.on 0n 64", "18446744073709551616n"},
+		{"off", "bit.off 255n 0", "254n"},
+		{"count", "bit.count bit.shl 255n 100", "8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigInt_Conversions(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"to-bigint integer", "to-bigint 42", "42n"},
+		{"to-bigint string", `to-bigint "123456789012345678901234567890"`, "123456789012345678901234567890n"},
+		{"to-bigint suffixed string", `to-bigint "-12n"`, "-12n"},
+		{"to-bigint decimal truncates", "to-bigint -3.7", "-3n"},
+		{"to-bigint float truncates", "to-bigint 1e20f", "100000000000000000000n"},
+		{"to-integer in range", "to-integer 42n", "42"},
+		{"to-decimal exact", "to-decimal 12345678901234567890123n", "12345678901234567890123"},
+		{"decimal constructor", "type? decimal 5n", "decimal!"},
+		{"to-float", "to-float 3n", "3.0f"},
+		{"to-string", "to-string 99999999999999999999n", `"99999999999999999999"`},
+		{"form has no suffix", "form 7n", `"7"`},
+		{"format", `format "%d" [18446744073709551616n]`, `"18446744073709551616"`},
+		{"type", "type? 1n", "bigint!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigInt_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"divide by zero", "1n / 0", verror.ErrIDDivByZero},
+		{"mod by zero", "1n mod 0n", verror.ErrIDDivByZero},
+		{"to-integer overflow", "to-integer 9223372036854775808n", "to-integer-overflow"},
+		{"to-bigint bad string", `to-bigint "12x"`, "to-bigint-invalid-string"},
+		{"to-bigint nan", `to-bigint to-float "nan"`, verror.ErrIDNonFiniteFloat},
+		{"bit.count negative", "bit.count -1n", verror.ErrIDInvalidOperation},
+		{"bit.and with binary", "bit.and 1n #{01}", verror.ErrIDTypeMismatch},
+		{"integer overflow still raised", "9223372036854775807 + 1", verror.ErrIDOverflow},
+		{"bit.shl beyond the size limit", "bit.shl 1n 100000000000", verror.ErrIDOverflow},
+		{"bit.on beyond the size limit", "bit.on 0n 100000000000", verror.ErrIDOverflow},
+		{"bit.off beyond the size limit", "bit.off -1n 100000000000", verror.ErrIDOverflow},
+		{"pow beyond the size limit", "pow 3n 100000000000", verror.ErrIDOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if verr.ID != tt.wantErr {
+				t.Errorf("Got error %s, want %s", verr.ID, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package contract
+
+import "testing"
+
+// Word lookups are cached per symbol; these scripts check that a cached
+// resolution never survives a binding that shadows it.
+func TestBindingCache_Shadowing(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			"local shadows global after cached lookup",
+			`x: 1 f: fn [] [r: copy [] loop 2 [append r x x: 10] r] f`,
+			"[1 10]",
+		},
+		{
+			"global unaffected by local binding",
+			`x: 1 f: fn [] [x: 10 x] f x`,
+			"1",
+		},
+		{
+			"closure sees later binding in defining frame",
+			`y: "global" outer: fn [] [g: fn [] [y] first-y: g y: "outer" reduce [first-y g]] outer`,
+			`["global" "outer"]`,
+		},
+		{
+			"recursion resolves parameter per call",
+			`count-down: fn [n] [when n = 0 [return 0] n + count-down n - 1] count-down 10`,
+			"55",
+		},
+		{
+			"rebinding global updates cached value",
+			`z: 1 f: fn [] [z] a: f z: 2 reduce [a f]`,
+			"[1 2]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestCodec_Enbase(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"base64 string", `enbase "hello"`, `"aGVsbG8="`},
+		{"base64 binary", `enbase #{FBFF}`, `"+/8="`},
+		{"base64 url", `enbase --url #{FBFF}`, `"-_8"`},
+		{"base64 empty", `enbase #{}`, `""`},
+		{"base32", `enbase --base 32 "hi"`, `"NBUQ===="`},
+		{"base16", `enbase --base 16 #{CAFE01}`, `"CAFE01"`},
+		{"base2", `enbase --base 2 #{0F80}`, `"0000111110000000"`},
+		{"utf-8 string", `enbase --base 16 "é"`, `"C3A9"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodec_Debase(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"base64", `debase "aGVsbG8="`, "#{68656C6C6F}"},
+		{"base64 without padding", `debase "aGVsbG8"`, "#{68656C6C6F}"},
+		{"base64 with whitespace", `debase "aGVs  bG8="`, "#{68656C6C6F}"},
+		{"base64 url", `debase --url "-_8"`, "#{FBFF}"},
+		{"base32", `debase --base 32 "NBUQ===="`, "#{6869}"},
+		{"base16 lower case", `debase --base 16 "cafe"`, "#{CAFE}"},
+		{"base2", `debase --base 2 "0000111110000000"`, "#{0F80}"},
+		{"round trip", `b: #{00FF10E7} reduce [(debase enbase b) = b (debase --base 32 enbase --base 32 b) = b (debase --base 2 enbase --base 2 b) = b]`, "[true true true]"},
+		{"back to text", `to-string debase "aGVsbG8="`, `"68656C6C6F"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodec_ChecksumAndHmac(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"crc32", `checksum --method 'crc32 "hello"`, "907060870"},
+		{"adler32", `checksum --method 'adler32 "Wikipedia"`, "300286872"},
+		{"md5", `enbase --base 16 checksum --method 'md5 "hello"`, `"5D41402ABC4B2A76B9719D911017C592"`},
+		{"sha1", `enbase --base 16 checksum --method 'sha1 "abc"`, `"A9993E364706816ABA3E25717850C26C9CD0D89D"`},
+		{"sha256 default", `enbase --base 16 checksum "abc"`, `"BA7816BF8F01CFEA414140DE5DAE2223B00361A396177A9CB410FF61F20015AD"`},
+		{"sha512 length", `length? checksum --method 'sha512 #{}`, "64"},
+		{"binary and string agree", `(checksum #{616263}) = checksum "abc"`, "true"},
+		{"hmac sha256", `enbase --base 16 hmac "The quick brown fox jumps over the lazy dog" "key"`, `"F7BC83F430538424B13298E6AA6FB143EF4D59A14946175997479DBC2D1A3CD8"`},
+		{"hmac md5", `enbase --base 16 hmac --method 'md5 "The quick brown fox jumps over the lazy dog" "key"`, `"80070713463E7749B90C2DC24911E275"`},
+		{"hmac binary key", `(hmac "msg" #{6B6579}) = hmac "msg" "key"`, "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"invalid base64", `debase "a$b"`, verror.ErrIDInvalidEncoding},
+		{"invalid base16", `debase --base 16 "ABC"`, verror.ErrIDInvalidEncoding},
+		{"invalid base2 length", `debase --base 2 "0101"`, verror.ErrIDInvalidEncoding},
+		{"invalid base2 digit", `debase --base 2 "01010102"`, verror.ErrIDInvalidEncoding},
+		{"unsupported base", `enbase --base 8 "x"`, verror.ErrIDInvalidOperation},
+		{"url needs base 64", `enbase --base 16 --url "x"`, verror.ErrIDInvalidOperation},
+		{"unknown checksum method", `checksum --method 'sha3 "x"`, verror.ErrIDInvalidOperation},
+		{"crc32 is not an hmac method", `hmac --method 'crc32 "x" "k"`, verror.ErrIDInvalidOperation},
+		{"enbase of integer", `enbase 42`, verror.ErrIDTypeMismatch},
+		{"debase of binary", `debase #{00}`, verror.ErrIDTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if verr.ID != tt.wantErr {
+				t.Errorf("Got error %s, want %s", verr.ID, tt.wantErr)
+			}
+		})
+	}
+}
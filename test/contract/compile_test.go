@@ -0,0 +1,92 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// evaluateWithCompile runs src on a fresh evaluator with block compilation
+// switched on or off.
+func evaluateWithCompile(t *testing.T, src string, compile bool) (core.Value, error) {
+	t.Helper()
+	vals, locations, err := parse.ParseWithSource(src, "(test)")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	e := NewTestEvaluator()
+	e.SetCompile(compile)
+	return e.DoBlock(vals, locations)
+}
+
+// Every script runs through the interpreter and the block compiler; results
+// and errors, including their reported location, must be identical.
+func TestCompile_MatchesInterpreter(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"infix chain", `1 + 2 * 3 - 4`},
+		{"set-word in loop", "total: 0 i: 0\nloop 100 [total: total + i i: i + 1]\ntotal"},
+		{"user function", `sq: fn [n] [n * n] sq 3 + sq 4`},
+		{"recursion", `fib: fn [n] [if n <= 1 [n] [(fib n - 1) + (fib n - 2)]] fib 12`},
+		{"refinement flag", `r: copy [] loop 2 [append r uppercase --part 1 "ab"] r`},
+		{"refinement value", `pad --with "*" --left "x" 3`},
+		{"duplicate refinement", `pad --left --left "x" 3`},
+		{"unknown refinement", `pad --bogus "x" 3`},
+		{"refinement after arguments", `f: fn [a --twice] [if twice [a * 2] [a]] r: copy [] loop 2 [append r f 1 --twice] r`},
+		{"rebind to different arity", `f: fn [a] [a] r: copy [] loop 2 [append r f 1 2 f: fn [a b] [a * b]] r`},
+		{"rebind word to non-function", `f: fn [] [1] r: copy [] loop 2 [append r f f: 5] r`},
+		{"rebind word to infix-free value", `plus: :+ r: copy [] loop 2 [append r 1 plus 2] r`},
+		{"block edited in place", `b: [1 + 2] r: copy [] loop 2 [append r do b poke b 2 '*] r`},
+		{"block grown in place", `b: [x: 1] r: copy [] loop 2 [append r do b append b [+ 1]] r`},
+		{"lit-word parameter", `f: fn ['w] [w] f hello`},
+		{"missing argument", "f: fn [a b] [a + b]\nf 1"},
+		{"no value", "x: 1\ny: x + missing-word"},
+		{"error inside function body", "f: fn [n] [\n  n + none\n]\nloop 3 [f 1]"},
+		{"set-word without value", `x:`},
+		{"math error location", "a: 10\nb: 0\nc: a / b"},
+		{"paren and path", `o: object [v: 4] (o.v + 1) * 2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := evaluateWithCompile(t, tt.script, false)
+			got, gotErr := evaluateWithCompile(t, tt.script, true)
+
+			if (wantErr == nil) != (gotErr == nil) {
+				t.Fatalf("interpreter error %v, compiled error %v", wantErr, gotErr)
+			}
+			if wantErr != nil {
+				w, _ := wantErr.(*verror.Error)
+				g, _ := gotErr.(*verror.Error)
+				if w == nil || g == nil {
+					if wantErr.Error() != gotErr.Error() {
+						t.Fatalf("interpreter error %v, compiled error %v", wantErr, gotErr)
+					}
+					return
+				}
+				if w.ID != g.ID || w.Near != g.Near || w.Line != g.Line || w.Column != g.Column || w.Message != g.Message {
+					t.Errorf("errors differ:\ninterpreter: %s %q near %q at %d:%d\ncompiled:    %s %q near %q at %d:%d",
+						w.ID, w.Message, w.Near, w.Line, w.Column, g.ID, g.Message, g.Near, g.Line, g.Column)
+				}
+				return
+			}
+			if want.Mold() != got.Mold() {
+				t.Errorf("interpreter %s, compiled %s", want.Mold(), got.Mold())
+			}
+		})
+	}
+}
+
+func TestCompile_RebindChangesArity(t *testing.T) {
+	result, err := evaluateWithCompile(t, `f: fn [a] [a] r: copy [] loop 2 [append r f 1 2 f: fn [a b] [a * b]] r`, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := result.Mold(); got != "[1 2]" {
+		t.Errorf("Got %s, want [1 2]", got)
+	}
+}
@@ -0,0 +1,292 @@
+package contract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestCompress_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"gzip default", `decompress compress #{0102030405}`, "#{0102030405}"},
+		{"zlib", `decompress --method 'zlib compress --method 'zlib "abc"`, "#{616263}"},
+		{"deflate", `decompress --method 'deflate compress --method 'deflate "abc"`, "#{616263}"},
+		{"string result", `decompress --string compress "hello"`, `"hello"`},
+		{"empty", `decompress compress #{}`, "#{}"},
+		{"gzip magic", `copy --part 2 compress "x"`, "#{1F8B}"},
+		{"zlib header", `copy --part 1 compress --method 'zlib "x"`, "#{78}"},
+		{"repetitive data shrinks", `data: copy "" loop 100 [append data "abc"] (length? compress data) < (length? data)`, "true"},
+		{"gzip members concatenate", `decompress --string append compress "ab" compress "cd"`, `"abcd"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompress_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		wantID string
+	}{
+		{"corrupt gzip", `decompress #{00010203}`, verror.ErrIDCorruptData},
+		{"corrupt zlib", `decompress --method 'zlib #{00010203}`, verror.ErrIDCorruptData},
+		{"wrong method", `decompress --method 'zlib compress "x"`, verror.ErrIDCorruptData},
+		{"unknown method", `compress --method 'lzma "x"`, verror.ErrIDInvalidOperation},
+		{"decompress string", `decompress "x"`, verror.ErrIDTypeMismatch},
+		{"compress integer", `compress 42`, verror.ErrIDTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected verror.Error, got %T", err)
+			}
+			if verr.ID != tt.wantID {
+				t.Errorf("Error ID = %s, want %s", verr.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCompress_ReadWriteGzip(t *testing.T) {
+	tmpDir := setupFileSandbox(t)
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"round trip", `write --gzip "out.txt.gz" "hello world" read --gzip "out.txt.gz"`, `"hello world"`},
+		{"file is gzip", `write --gzip "magic.gz" "x" copy --part 2 read --binary "magic.gz"`, "#{1F8B}"},
+		{"binary", `write --gzip "b.gz" #{00FF} read --gzip --binary "b.gz"`, "#{00FF}"},
+		{"lines", "write --gzip \"l.gz\" \"a\nb\n\" read --gzip --lines \"l.gz\"", `["a" "b"]`},
+		{"part counts decompressed bytes", `write --gzip "p.gz" "hello world" read --gzip --part 5 "p.gz"`, `"hello"`},
+		{"decompress file contents", `write --gzip "d.gz" "data" decompress --string read --binary "d.gz"`, `"data"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("readable by gzip", func(t *testing.T) {
+		if _, err := Evaluate(`write --gzip "std.gz" "plain"`); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		f, err := os.Open(filepath.Join(tmpDir, "std.gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("not a gzip file: %v", err)
+		}
+		buf := make([]byte, 16)
+		n, _ := zr.Read(buf)
+		if string(buf[:n]) != "plain" {
+			t.Errorf("Got %q, want %q", buf[:n], "plain")
+		}
+	})
+
+	t.Run("not gzip", func(t *testing.T) {
+		if _, err := Evaluate(`read --gzip "a.txt"`); err == nil {
+			t.Fatal("Expected error reading plain file with --gzip")
+		}
+	})
+}
+
+func TestArchive_CreateListExtract(t *testing.T) {
+	setupFileSandbox(t)
+
+	for _, archive := range []string{"bundle.tar", "bundle.tar.gz", "bundle.tgz", "bundle.zip"} {
+		t.Run(archive, func(t *testing.T) {
+			created, err := Evaluate(`archive-create "` + archive + `" ["sub" "a.txt"]`)
+			if err != nil {
+				t.Fatalf("archive-create: %v", err)
+			}
+			want := `["sub/" "sub/c.viro" "sub/deep/" "sub/deep/d.txt" "a.txt"]`
+			if got := created.Mold(); got != want {
+				t.Errorf("archive-create = %s, want %s", got, want)
+			}
+
+			listed, err := Evaluate(`archive-list "` + archive + `"`)
+			if err != nil {
+				t.Fatalf("archive-list: %v", err)
+			}
+			if got := listed.Mold(); got != want {
+				t.Errorf("archive-list = %s, want %s", got, want)
+			}
+
+			dest := "out-" + archive
+			if _, err := Evaluate(`archive-extract "` + archive + `" "` + dest + `"`); err != nil {
+				t.Fatalf("archive-extract: %v", err)
+			}
+			result, err := Evaluate(`reduce [read "` + dest + `/a.txt" read "` + dest + `/sub/deep/d.txt" list-dir --recursive "` + dest + `"]`)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			wantTree := `["hello" "deep" ["a.txt" "sub/" "sub/c.viro" "sub/deep/" "sub/deep/d.txt"]]`
+			if got := result.Mold(); got != wantTree {
+				t.Errorf("extracted = %s, want %s", got, wantTree)
+			}
+		})
+	}
+
+	t.Run("format override", func(t *testing.T) {
+		result, err := Evaluate(`archive-create --format 'zip "bundle.bin" "a.txt" archive-list --format 'zip "bundle.bin"`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := result.Mold(); got != `["a.txt"]` {
+			t.Errorf("Got %s", got)
+		}
+	})
+
+	t.Run("archive inside source is skipped", func(t *testing.T) {
+		result, err := Evaluate(`archive-create "sub/self.tar" "sub" archive-list "sub/self.tar"`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := result.Mold(); got != `["sub/" "sub/c.viro" "sub/deep/" "sub/deep/d.txt"]` {
+			t.Errorf("Got %s", got)
+		}
+	})
+}
+
+// writeTarGz writes a tar.gz archive whose headers are taken as given, so
+// tests can build archives no well-behaved tool would produce.
+func writeTarGz(t *testing.T, path string, headers []*tar.Header) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, hdr := range headers {
+		content := []byte("payload")
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(content); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchive_ZipSlip(t *testing.T) {
+	tmpDir := setupFileSandbox(t)
+
+	writeTarGz(t, filepath.Join(tmpDir, "climb.tar.gz"), []*tar.Header{
+		{Name: "ok.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "../../escape.txt", Typeflag: tar.TypeReg, Mode: 0644},
+	})
+	writeTarGz(t, filepath.Join(tmpDir, "inner.tar.gz"), []*tar.Header{
+		{Name: "x/../../a.txt", Typeflag: tar.TypeReg, Mode: 0644},
+	})
+	writeTarGz(t, filepath.Join(tmpDir, "absolute.tar.gz"), []*tar.Header{
+		{Name: "/tmp/escape.txt", Typeflag: tar.TypeReg, Mode: 0644},
+	})
+	writeTarGz(t, filepath.Join(tmpDir, "link.tar.gz"), []*tar.Header{
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+	})
+
+	zf, err := os.Create(filepath.Join(tmpDir, "climb.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(zf)
+	w, err := zw.Create("..\\escape.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("payload"))
+	zw.Close()
+	zf.Close()
+
+	for _, archive := range []string{"climb.tar.gz", "inner.tar.gz", "absolute.tar.gz", "link.tar.gz", "climb.zip"} {
+		t.Run(archive, func(t *testing.T) {
+			_, err := Evaluate(`archive-extract "` + archive + `" "out"`)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected verror.Error, got %T", err)
+			}
+			if verr.ID != verror.ErrIDUnsafeArchiveEntry {
+				t.Errorf("Error ID = %s, want %s", verr.ID, verror.ErrIDUnsafeArchiveEntry)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "out", "ok.txt")); !os.IsNotExist(err) {
+		t.Errorf("entries before the unsafe one must not be extracted (stat err: %v)", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tmpDir, "a.txt")); string(content) != "hello" {
+		t.Errorf("a.txt was overwritten: %q", content)
+	}
+
+	t.Run("listing unsafe archive is allowed", func(t *testing.T) {
+		result, err := Evaluate(`archive-list "climb.tar.gz"`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := result.Mold(); got != `["ok.txt" "../../escape.txt"]` {
+			t.Errorf("Got %s", got)
+		}
+	})
+
+	t.Run("destination outside sandbox", func(t *testing.T) {
+		_, err := Evaluate(`archive-extract "climb.zip" "../elsewhere"`)
+		var verr *verror.Error
+		if !errors.As(err, &verr) || verr.ID != verror.ErrIDSandboxViolation {
+			t.Errorf("Expected sandbox-violation, got %v", err)
+		}
+	})
+}
@@ -840,6 +840,21 @@ func TestData_MoldFormWithSeriesIndex(t *testing.T) {
 			input:    `form skip "testing" 4`,
 			expected: value.NewStrVal("ing"),
 		},
+		{
+			name:     "mold escapes double quotes",
+			input:    `mold "a\"b"`,
+			expected: value.NewStrVal(`"a\"b"`),
+		},
+		{
+			name:     "mold escapes backslashes",
+			input:    `mold "a\\b"`,
+			expected: value.NewStrVal(`"a\\b"`),
+		},
+		{
+			name:     "mold of escaped string loads back",
+			input:    `s: "say \"hi\" \\o/" s = first load-string mold s`,
+			expected: value.NewLogicVal(true),
+		},
 	}
 
 	for _, tt := range tests {
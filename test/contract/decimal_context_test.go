@@ -0,0 +1,165 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestDecimalContext_Scoping(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"default context", "ctx: decimal-context reduce [ctx.precision ctx.rounding ctx.traps]", "[34 half-even false]"},
+		{"fields are applied", "with-decimal-context [precision: 10 rounding: 'half-up traps: true] [ctx: decimal-context reduce [ctx.precision ctx.rounding ctx.traps]]", "[10 half-up true]"},
+		{"fields can be computed", "p: 4 with-decimal-context [precision: p * 2] [ctx: decimal-context ctx.precision]", "8"},
+		{"restored after block", "with-decimal-context [precision: 5] [1] ctx: decimal-context ctx.precision", "34"},
+		{"nested contexts inherit", "with-decimal-context [precision: 5] [with-decimal-context [rounding: 'floor] [ctx: decimal-context reduce [ctx.precision ctx.rounding]]]", "[5 floor]"},
+		{"precision limits results", "with-decimal-context [precision: 3] [x: (decimal \"2\") / 3 x = decimal \"0.667\"]", "true"},
+		{"rounding mode applies", "with-decimal-context [precision: 3 rounding: 'down] [x: (decimal \"2\") / 3 x = decimal \"0.666\"]", "true"},
+		{"round follows context", "with-decimal-context [rounding: 'half-up] [round 2.5]", "3"},
+		{"functions see the context", "f: fn [] [ctx: decimal-context ctx.precision] with-decimal-context [precision: 7] [f]", "7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalContext_RestoredAfterError(t *testing.T) {
+	e := NewTestEvaluator()
+	run := func(src string) (core.Value, error) {
+		vals, locations, err := parse.ParseWithSource(src, "(test)")
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		return e.DoBlock(vals, locations)
+	}
+
+	if _, err := run("with-decimal-context [precision: 5 traps: true] [1 / 0]"); err == nil {
+		t.Fatal("Expected division error")
+	}
+	result, err := run("ctx: decimal-context reduce [ctx.precision ctx.traps]")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := result.Mold(); got != "[34 false]" {
+		t.Errorf("Got %s, want [34 false]", got)
+	}
+}
+
+func TestDecimalContext_Traps(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"exact result passes", "with-decimal-context [traps: true] [1.0 / 4]", ""},
+		{"rounded division", "with-decimal-context [traps: true precision: 5] [(decimal \"10\") / 3]", verror.ErrIDDecimalPrecision},
+		{"rounded multiplication", "with-decimal-context [traps: true precision: 3] [1.25 * 1.25]", verror.ErrIDDecimalPrecision},
+		{"rounded sqrt", "with-decimal-context [traps: true] [sqrt decimal 2]", verror.ErrIDDecimalPrecision},
+		{"traps off rounds silently", "with-decimal-context [precision: 3] [1.25 * 1.25]", ""},
+		{"explicit round is not trapped", "with-decimal-context [traps: true] [round --to 0.01 2.675]", ""},
+		{"precision out of range", "with-decimal-context [precision: 35] [1]", verror.ErrIDInvalidOperation},
+		{"unknown rounding mode", "with-decimal-context [rounding: 'sideways] [1]", verror.ErrIDInvalidOperation},
+		{"unknown field", "with-decimal-context [scale: 2] [1]", verror.ErrIDInvalidOperation},
+		{"traps must be logic", "with-decimal-context [traps: 1] [1]", verror.ErrIDTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if verr.ID != tt.wantErr {
+				t.Errorf("Got error %s, want %s", verr.ID, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecimalContext_RoundAndFormatMoney(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"round to cents", "round --to 0.01 2.675", "2.68"},
+		{"round to cents half-even", "round --to 0.01 2.665", "2.66"},
+		{"round to nickel", "round --to 0.05 1.27", "1.25"},
+		{"round to tens", "round --to 10 1234", "1230"},
+		{"round mode up", "round --mode 'up 1.01", "2"},
+		{"round mode ceiling negative", "round --mode 'ceiling -1.5", "-1"},
+		{"round mode half-down", "round --mode 'half-down 2.5", "2"},
+		{"round float step", "round --to 0.5f 2.3f", "2.5f"},
+		{"round float mode", "round --mode 'half-up 2.5f", "3.0f"},
+		{"money default", "format-money 1234.5", `"1,234.50"`},
+		{"money negative", "format-money -1234567.891", `"-1,234,567.89"`},
+		{"money scale", "format-money --scale 0 1999.5", `"2,000"`},
+		{"money separator", `format-money --separator "" 1234.5`, `"1234.50"`},
+		{"money small", "format-money 0.5", `"0.50"`},
+		{"money negative zero", "format-money -0.001", `"0.00"`},
+		{"money float", "format-money 0.1f", `"0.10"`},
+		{"money follows context", "with-decimal-context [rounding: 'down] [format-money 2.999]", `"2.99"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalContext_RoundErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"zero step", "round --to 0 1.5", verror.ErrIDInvalidOperation},
+		{"negative step", "round --to -0.5 1.5", verror.ErrIDInvalidOperation},
+		{"unknown mode", "round --mode 'sideways 1.5", verror.ErrIDInvalidOperation},
+		{"money of string", `format-money "12"`, verror.ErrIDTypeMismatch},
+		{"money of infinity", `format-money to-float "inf"`, verror.ErrIDNonFiniteFloat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if verr.ID != tt.wantErr {
+				t.Errorf("Got error %s, want %s", verr.ID, tt.wantErr)
+			}
+		})
+	}
+}
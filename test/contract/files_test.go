@@ -0,0 +1,358 @@
+package contract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/value"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// setupFileSandbox creates a temporary sandbox with a small file tree:
+//
+//	a.txt        "hello"
+//	b.viro       "x: 1"
+//	sub/c.viro   "y: 2"
+//	sub/deep/d.txt
+func setupFileSandbox(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	if err := eval.InitSandbox(tmpDir); err != nil {
+		t.Fatalf("Failed to init sandbox: %v", err)
+	}
+	native.SandboxRoot = eval.SandboxRoot
+
+	files := map[string]string{
+		"a.txt":          "hello",
+		"b.viro":         "x: 1",
+		"sub/c.viro":     "y: 2",
+		"sub/deep/d.txt": "deep",
+	}
+	for name, content := range files {
+		full := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	return tmpDir
+}
+
+func TestFiles_Queries(t *testing.T) {
+	setupFileSandbox(t)
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"exists? file", `exists? "a.txt"`, "true"},
+		{"exists? dir", `exists? "sub"`, "true"},
+		{"exists? missing", `exists? "nope.txt"`, "false"},
+		{"dir? on dir", `dir? "sub"`, "true"},
+		{"dir? on file", `dir? "a.txt"`, "false"},
+		{"size? file", `size? "a.txt"`, "5"},
+		{"size? dir", `size? "sub"`, "none"},
+		{"size? missing", `size? "nope.txt"`, "none"},
+		{"modified? missing", `modified? "nope.txt"`, "none"},
+		{"modified? positive", `(modified? "a.txt") > 0`, "true"},
+		{"info? missing", `info? "nope.txt"`, "none"},
+		{"info? name", `i: info? "sub/c.viro" i.name`, `"c.viro"`},
+		{"info? type file", `i: info? "a.txt" i.type`, "file"},
+		{"info? type dir", `i: info? "sub" i.type`, "dir"},
+		{"info? size", `i: info? "a.txt" i.size`, "5"},
+		{"list-dir flat", `list-dir "."`, `["a.txt" "b.viro" "sub/"]`},
+		{"list-dir filter", `list-dir --filter "*.viro" "."`, `["b.viro"]`},
+		{"list-dir recursive", `list-dir --recursive "sub"`, `["c.viro" "deep/" "deep/d.txt"]`},
+		{"list-dir recursive filter", `list-dir --recursive --filter "*.txt" "."`, `["a.txt" "sub/deep/d.txt"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles_Mutations(t *testing.T) {
+	t.Run("make-dir and delete", func(t *testing.T) {
+		root := setupFileSandbox(t)
+		if _, err := Evaluate(`make-dir "out"`); err != nil {
+			t.Fatalf("make-dir failed: %v", err)
+		}
+		if info, err := os.Stat(filepath.Join(root, "out")); err != nil || !info.IsDir() {
+			t.Fatalf("expected out/ to be a directory")
+		}
+		if _, err := Evaluate(`make-dir "out"`); err != nil {
+			t.Fatalf("make-dir on existing directory should succeed: %v", err)
+		}
+		if _, err := Evaluate(`delete "out"`); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "out")); !os.IsNotExist(err) {
+			t.Fatalf("expected out/ to be deleted")
+		}
+	})
+
+	t.Run("make-dir requires --deep for nested paths", func(t *testing.T) {
+		root := setupFileSandbox(t)
+		if _, err := Evaluate(`make-dir "x/y/z"`); err == nil {
+			t.Fatalf("expected error without --deep")
+		}
+		if _, err := Evaluate(`make-dir --deep "x/y/z"`); err != nil {
+			t.Fatalf("make-dir --deep failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "x", "y", "z")); err != nil {
+			t.Fatalf("expected x/y/z to exist: %v", err)
+		}
+	})
+
+	t.Run("delete non-empty directory requires --recursive", func(t *testing.T) {
+		root := setupFileSandbox(t)
+		if _, err := Evaluate(`delete "sub"`); err == nil {
+			t.Fatalf("expected error deleting non-empty directory")
+		}
+		if _, err := Evaluate(`delete --recursive "sub"`); err != nil {
+			t.Fatalf("delete --recursive failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "sub")); !os.IsNotExist(err) {
+			t.Fatalf("expected sub/ to be deleted")
+		}
+	})
+
+	t.Run("delete missing file errors", func(t *testing.T) {
+		setupFileSandbox(t)
+		if _, err := Evaluate(`delete "nope.txt"`); err == nil {
+			t.Fatalf("expected error deleting missing file")
+		}
+	})
+
+	t.Run("rename and move", func(t *testing.T) {
+		root := setupFileSandbox(t)
+		if _, err := Evaluate(`rename "a.txt" "renamed.txt"`); err != nil {
+			t.Fatalf("rename failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "renamed.txt")); err != nil {
+			t.Fatalf("expected renamed.txt: %v", err)
+		}
+		if _, err := Evaluate(`move "renamed.txt" "sub"`); err != nil {
+			t.Fatalf("move failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "sub", "renamed.txt")); err != nil {
+			t.Fatalf("expected sub/renamed.txt: %v", err)
+		}
+	})
+
+	t.Run("copy-file", func(t *testing.T) {
+		root := setupFileSandbox(t)
+		if _, err := Evaluate(`copy-file "a.txt" "backup/a.txt"`); err != nil {
+			t.Fatalf("copy-file failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(root, "backup", "a.txt"))
+		if err != nil || string(data) != "hello" {
+			t.Fatalf("expected copied content %q, got %q (%v)", "hello", data, err)
+		}
+		if _, err := Evaluate(`copy-file "sub" "sub2"`); err == nil {
+			t.Fatalf("expected error copying a directory")
+		}
+		for _, script := range []string{`copy-file "a.txt" "a.txt"`, `copy-file "a.txt" "sub/../a.txt"`} {
+			if _, err := Evaluate(script); err == nil {
+				t.Errorf("%s: expected error copying a file onto itself", script)
+			}
+		}
+		if data, err := os.ReadFile(filepath.Join(root, "a.txt")); err != nil || string(data) != "hello" {
+			t.Fatalf("source must survive a copy onto itself, got %q (%v)", data, err)
+		}
+	})
+}
+
+func TestFiles_SandboxViolations(t *testing.T) {
+	root := setupFileSandbox(t)
+
+	sibling := root + "-other"
+	if err := os.Mkdir(sibling, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sibling) })
+
+	scripts := []string{
+		`exists? "../../etc/passwd"`,
+		`info? "/etc/passwd"`,
+		`delete "."`,
+		`delete --recursive "../` + filepath.Base(sibling) + `"`,
+		`list-dir ".."`,
+		`copy-file "a.txt" "../escaped.txt"`,
+		`rename "a.txt" "/tmp/escaped.txt"`,
+		`make-dir --deep "../escaped"`,
+	}
+
+	for _, script := range scripts {
+		t.Run(script, func(t *testing.T) {
+			_, err := Evaluate(script)
+			if err == nil {
+				t.Fatalf("expected sandbox error")
+			}
+			verr, ok := err.(*verror.Error)
+			if !ok {
+				t.Fatalf("expected verror.Error, got %T", err)
+			}
+			if verr.Category != verror.ErrAccess {
+				t.Errorf("expected access error, got %v", verr.Category)
+			}
+		})
+	}
+
+	if _, err := os.Stat(sibling); err != nil {
+		t.Fatalf("sibling directory outside sandbox must survive: %v", err)
+	}
+}
+
+func TestFiles_Symlinks(t *testing.T) {
+	setup := func(t *testing.T) (root, outside string) {
+		root = setupFileSandbox(t)
+		outside = t.TempDir()
+		if err := os.WriteFile(filepath.Join(outside, "keep.txt"), []byte("keep"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("sub", filepath.Join(root, "link")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		if err := os.Symlink(outside, filepath.Join(root, "out-link")); err != nil {
+			t.Fatal(err)
+		}
+		return root, outside
+	}
+	isLink := func(path string) bool {
+		info, err := os.Lstat(path)
+		return err == nil && info.Mode()&os.ModeSymlink != 0
+	}
+
+	t.Run("delete removes the link, not its target", func(t *testing.T) {
+		root, outside := setup(t)
+		if _, err := Evaluate(`delete --recursive "link"`); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		if _, err := os.Lstat(filepath.Join(root, "link")); !os.IsNotExist(err) {
+			t.Errorf("expected link to be deleted")
+		}
+		if _, err := os.Stat(filepath.Join(root, "sub", "c.viro")); err != nil {
+			t.Errorf("link target must survive: %v", err)
+		}
+		if _, err := Evaluate(`delete "out-link"`); err != nil {
+			t.Fatalf("delete of a link leaving the sandbox failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "keep.txt")); err != nil {
+			t.Errorf("directory outside the sandbox must survive: %v", err)
+		}
+	})
+
+	t.Run("rename moves the link, not its target", func(t *testing.T) {
+		root, _ := setup(t)
+		if _, err := Evaluate(`rename "link" "link2"`); err != nil {
+			t.Fatalf("rename failed: %v", err)
+		}
+		if !isLink(filepath.Join(root, "link2")) {
+			t.Errorf("expected link2 to be a symlink")
+		}
+		if _, err := os.Stat(filepath.Join(root, "sub", "c.viro")); err != nil {
+			t.Errorf("link target must stay in place: %v", err)
+		}
+	})
+
+	t.Run("move into a link leaving the sandbox is rejected", func(t *testing.T) {
+		root, outside := setup(t)
+		_, err := Evaluate(`move "a.txt" "out-link"`)
+		if verr, ok := err.(*verror.Error); !ok || verr.Category != verror.ErrAccess {
+			t.Fatalf("expected an access error, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "a.txt")); err != nil {
+			t.Errorf("a.txt must stay in place: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "a.txt")); !os.IsNotExist(err) {
+			t.Errorf("a.txt must not leave the sandbox")
+		}
+	})
+
+	t.Run("dangling links leaving the sandbox are rejected", func(t *testing.T) {
+		root, outside := setup(t)
+		if err := os.Symlink(filepath.Join(outside, "escaped"), filepath.Join(root, "dang")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("dang", filepath.Join(root, "dang2")); err != nil {
+			t.Fatal(err)
+		}
+		for _, script := range []string{
+			`write "dang" "pwned"`,
+			`write "dang2" "pwned"`,
+			`make-dir "dang"`,
+			`copy-file "a.txt" "dang"`,
+		} {
+			_, err := Evaluate(script)
+			if verr, ok := err.(*verror.Error); !ok || verr.Category != verror.ErrAccess {
+				t.Errorf("%s: expected an access error, got %v", script, err)
+			}
+		}
+		if _, err := os.Lstat(filepath.Join(outside, "escaped")); !os.IsNotExist(err) {
+			t.Errorf("nothing may be created outside the sandbox")
+		}
+	})
+
+	t.Run("dangling links inside the sandbox are followed", func(t *testing.T) {
+		root, _ := setup(t)
+		if err := os.Symlink("new.txt", filepath.Join(root, "dang")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Evaluate(`write "dang" "hi"`); err != nil {
+			t.Fatalf("write through a link inside the sandbox failed: %v", err)
+		}
+		if data, err := os.ReadFile(filepath.Join(root, "new.txt")); err != nil || string(data) != "hi" {
+			t.Errorf("expected new.txt to hold %q, got %q (%v)", "hi", data, err)
+		}
+	})
+}
+
+func TestFiles_FilesystemRootSandbox(t *testing.T) {
+	setupFileSandbox(t)
+	previous := eval.SandboxRoot
+	t.Cleanup(func() { eval.SandboxRoot = previous })
+
+	eval.SandboxRoot = string(filepath.Separator)
+	dir := t.TempDir()
+	for _, resolve := range []func(string) (string, error){eval.ResolveSandboxPath, eval.ResolveSandboxEntry} {
+		if _, err := resolve(dir); err != nil {
+			t.Errorf("expected %s to be inside a sandbox rooted at /: %v", dir, err)
+		}
+	}
+}
+
+func TestFiles_TypeErrors(t *testing.T) {
+	setupFileSandbox(t)
+
+	_, err := Evaluate(`exists? 42`)
+	if err == nil {
+		t.Fatalf("expected type error")
+	}
+	verr, ok := err.(*verror.Error)
+	if !ok || verr.ID != verror.ErrIDTypeMismatch {
+		t.Fatalf("expected type-mismatch error, got %v", err)
+	}
+
+	result, err := Evaluate(`info? "a.txt"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.GetType() != value.TypeObject {
+		t.Fatalf("expected object from info?, got %s", value.TypeToString(result.GetType()))
+	}
+}
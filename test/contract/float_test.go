@@ -0,0 +1,178 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestFloat_Arithmetic(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"literal", "1.5f", "1.5f"},
+		{"integral literal molds with fraction", "3f", "3.0f"},
+		{"type", "type? 2.5f", "float!"},
+		{"binary64 rounding", "0.1f + 0.2f", "0.30000000000000004f"},
+		{"integer promotes to float", "1 + 1.5f", "2.5f"},
+		{"float on the left", "2.5f * 2", "5.0f"},
+		{"decimal wins over float", "type? 1.5f + 1.0", "decimal!"},
+		{"decimal from shortest form", "0.1f + 0.2", "0.30"},
+		{"subtract", "10 - 0.5f", "9.5f"},
+		{"divide", "7f / 2", "3.5f"},
+		{"mod", "10 mod 3.5f", "3.0f"},
+		{"divide by zero", "1f / 0", "inf"},
+		{"negative infinity", "-1f / 0f", "-inf"},
+		{"zero over zero", "0f / 0f", "nan"},
+		{"form has no suffix", "form 2.5f", `"2.5"`},
+		{"mold in block", "mold [1.5f 2f]", `"[1.5f 2.0f]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloat_Comparison(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"less than integer", "1.5f < 2", "true"},
+		{"greater than decimal", "2.5f > 2.4", "true"},
+		{"equal floats", "0.5f = 0.5f", "true"},
+		{"equal integer", "1.0f = 1", "true"},
+		{"equal decimal", "1.5 = 1.5f", "true"},
+		{"not equal integer", "1.5f <> 1", "true"},
+		{"not equal promoted", "2f <> 2", "false"},
+		{"equal agrees with ordering", "x: 0.1f (x <= 0.1) = (x = 0.1)", "true"},
+		{"number and string differ", "1f = \"1\"", "false"},
+		{"nan is not equal to itself", "x: 0f / 0f x = x", "false"},
+		{"nan is unordered", "x: 0f / 0f x < 1", "false"},
+		{"nan unordered against decimal", "x: 0f / 0f x >= 1.0", "false"},
+		{"infinity above decimal", "(1f / 0f) > 1.0", "true"},
+		{"nan?", "nan? 0f / 0f", "true"},
+		{"nan? on non-float", "nan? 1", "false"},
+		{"infinite?", "infinite? -1f / 0f", "true"},
+		{"infinite? on finite", "infinite? 1e308f", "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloat_MathNatives(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"sqrt", "sqrt 2f", "1.4142135623730951f"},
+		{"sqrt negative is nan", "sqrt -1f", "nan"},
+		{"log zero", "log 0f", "-inf"},
+		{"exp overflow", "exp 1000f", "inf"},
+		{"sin", "sin 0f", "0.0f"},
+		{"asin outside domain", "asin 2f", "nan"},
+		{"pow", "pow 2f 10", "1024.0f"},
+		{"pow stays decimal without floats", "type? pow 2 10", "decimal!"},
+		{"round half to even", "round 2.5f", "2.0f"},
+		{"floor", "floor -1.5f", "-2.0f"},
+		{"ceil", "ceil 1.2f", "2.0f"},
+		{"truncate", "truncate -1.7f", "-1.0f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloat_Conversions(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"to-float integer", "to-float 3", "3.0f"},
+		{"to-float decimal", "to-float 0.1", "0.1f"},
+		{"to-float string", `to-float "2.5"`, "2.5f"},
+		{"to-float suffixed string", `to-float "2.5f"`, "2.5f"},
+		{"to-float inf", `to-float "inf"`, "inf"},
+		{"to-float nan", `nan? to-float "NaN"`, "true"},
+		{"to-integer truncates", "to-integer -2.9f", "-2"},
+		{"to-decimal shortest form", "to-decimal 0.1f", "0.1"},
+		{"decimal constructor", "decimal 1.25f", "1.25"},
+		{"to-string", "to-string 1.5f", `"1.5"`},
+		{"format", `format "%.2f" [3.14159f]`, `"3.14"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloat_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		wantID string
+	}{
+		{"infinity into decimal arithmetic", "(1f / 0f) + 1.0", verror.ErrIDNonFiniteFloat},
+		{"nan to decimal", "to-decimal 0f / 0f", verror.ErrIDNonFiniteFloat},
+		{"infinity to integer", "to-integer 1f / 0f", verror.ErrIDNonFiniteFloat},
+		{"out of integer range", "to-integer 1e30f", "to-integer-overflow"},
+		{"bad string", `to-float "abc"`, "to-float-invalid-string"},
+		{"non-numeric operand", `1.5f + "a"`, verror.ErrIDTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatalf("Expected error %s, got none", tt.wantID)
+			}
+			verr, ok := err.(*verror.Error)
+			if !ok {
+				t.Fatalf("Expected *verror.Error, got %T", err)
+			}
+			if verr.ID != tt.wantID {
+				t.Errorf("Got error %s, want %s", verr.ID, tt.wantID)
+			}
+		})
+	}
+}
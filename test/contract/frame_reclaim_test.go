@@ -0,0 +1,142 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/value"
+)
+
+// Frames of finished calls must not accumulate in the evaluator's frame store.
+func TestFrameReclaim_PlainCallsReuseSlots(t *testing.T) {
+	e := NewTestEvaluator()
+	run := func(code string) {
+		t.Helper()
+		values, locations, err := parse.ParseWithSource(code, "(test)")
+		if err != nil {
+			t.Fatalf("parse error: %v", err)
+		}
+		if _, err := e.DoBlock(values, locations); err != nil {
+			t.Fatalf("eval error: %v", err)
+		}
+	}
+
+	run(`f: fn [x] [g x] g: fn [y] [y + 1] loop 10 [f 1]`)
+	before := e.FrameStats().Slots
+
+	run(`loop 10000 [f 1]`)
+	after := e.FrameStats().Slots
+
+	if after != before {
+		t.Errorf("frame store grew from %d to %d slots for uncaptured calls", before, after)
+	}
+}
+
+func TestFrameReclaim_ClosuresAndObjects(t *testing.T) {
+	tests := []struct {
+		name string
+		loop string
+	}{
+		{"closure per call", `make-adder: fn [n] [fn [x] [x + n]] loop 3000 [a: make-adder 1]`},
+		{"object with method", `loop 3000 [o: object [v: 1 m: fn [] [v]]]`},
+		{"object from function", `mk: fn [k] [object [get-k: fn [] [k]]] loop 3000 [o: mk 1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewTestEvaluator()
+			values, locations, err := parse.ParseWithSource(tt.loop, "(test)")
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if _, err := e.DoBlock(values, locations); err != nil {
+				t.Fatalf("eval error: %v", err)
+			}
+
+			stats := e.RecycleFrames()
+			// Root frame plus the frames still reachable from the last binding.
+			if stats.Live > 4 {
+				t.Errorf("expected at most 4 live frames after recycle, got %d (%+v)", stats.Live, stats)
+			}
+			if stats.Reclaimed == 0 {
+				t.Errorf("expected reclaimed frames, got %+v", stats)
+			}
+		})
+	}
+}
+
+// Reclaiming must never release a frame that is still reachable.
+func TestFrameReclaim_ReachableFramesSurvive(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			"closures in a block",
+			`make-adder: fn [n] [fn [x] [x + n]]
+			 adders: reduce [make-adder 1 make-adder 10]
+			 loop 2000 [make-adder 0]
+			 recycle
+			 a1: first adders a2: second adders
+			 reduce [a1 1 a2 1]`,
+			"[2 11]",
+		},
+		{
+			"closure held only by a native during recycle",
+			`make-adder: fn [n] [fn [x] [x + n]]
+			 adders: reduce [make-adder 1 (recycle make-adder 2)]
+			 a1: first adders a2: second adders
+			 reduce [a1 0 a2 0]`,
+			"[1 2]",
+		},
+		{
+			"object created in a function",
+			`mk: fn [k] [object [get-k: fn [] [k]]]
+			 o: mk 5
+			 loop 2000 [mk 0]
+			 recycle
+			 o.get-k`,
+			"5",
+		},
+		{
+			"nested closures",
+			`outer: fn [a] [fn [b] [fn [c] [a + b + c]]]
+			 g: outer 1
+			 f: g 2
+			 recycle
+			 f 3`,
+			"6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsNatives(t *testing.T) {
+	for _, script := range []string{`stats`, `recycle`} {
+		result, err := Evaluate(script)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", script, err)
+		}
+		obj, ok := value.AsObject(result)
+		if !ok {
+			t.Fatalf("%s: expected object, got %s", script, result.Mold())
+		}
+		for _, field := range []string{"frame-slots", "live-frames", "active-frames", "free-slots", "reclaimed", "sweeps", "symbols", "heap-alloc"} {
+			if _, ok := obj.GetField(field); !ok {
+				t.Errorf("%s: missing field %s", script, field)
+			}
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package contract
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ericlagergren/decimal"
@@ -138,6 +142,18 @@ func TestTrigFunctions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.function + " " + tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := strconv.ParseFloat(result.Mold(), 64)
+			if err != nil {
+				t.Fatalf("Result %s is not a number", result.Mold())
+			}
+			want, _ := strconv.ParseFloat(tt.expected, 64)
+			if math.Abs(got-want) > tt.tolerance {
+				t.Errorf("%s %s = %s, want %s", tt.function, tt.input, result.Mold(), tt.expected)
+			}
 		})
 	}
 }
@@ -165,10 +181,30 @@ func TestRoundingModes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			script := tt.function + " " + tt.input
+			if tt.mode != "" {
+				script = fmt.Sprintf("%s --to %s --mode '%s %s", tt.function, placesQuantum(tt.places), tt.mode, tt.input)
+			}
+			result, err := Evaluate(script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.expected {
+				t.Errorf("%s: got %s, want %s", script, got, tt.expected)
+			}
 		})
 	}
 }
 
+// placesQuantum returns the rounding step for a number of decimal places,
+// e.g. 0.01 for 2.
+func placesQuantum(places int) string {
+	if places == 0 {
+		return "1"
+	}
+	return "0." + strings.Repeat("0", places-1) + "1"
+}
+
 // T032: overflow/underflow handling
 func TestDecimalOverflow(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,121 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"uint8 int8", `pack [uint8 255 int8 -1 int8 -128]`, "#{FFFF80}"},
+		{"uint16 both orders", `pack [uint16-be 258 uint16-le 258]`, "#{01020201}"},
+		{"int32", `pack [int32-be -2 int32-le 1]`, "#{FFFFFFFE01000000}"},
+		{"uint64 from bigint", `pack [uint64-be 18446744073709551615n]`, "#{FFFFFFFFFFFFFFFF}"},
+		{"int64 min", `pack [int64-le -9223372036854775808]`, "#{0000000000000080}"},
+		{"float32", `pack [float32-be 1.5f]`, "#{3FC00000}"},
+		{"float64 from integer", `pack [float64-le 1]`, "#{000000000000F03F}"},
+		{"expressions", `n: 3 pack [uint8 n + 1 uint8 n * 2]`, "#{0406}"},
+		{"length prefixed string", `pack [string uint16-be "hi"]`, "#{00026869}"},
+		{"fixed string padded", `pack [string 4 "ab"]`, "#{61620000}"},
+		{"bytes rest", `pack [bytes rest #{CAFE} uint8 1]`, "#{CAFE01}"},
+		{"bytes from string", `pack [bytes uint8 "é"]`, "#{02C3A9}"},
+		{"pad", `pack [uint8 1 pad 3 uint8 2]`, "#{0100000002}"},
+		{"spec in a word", `layout: [uint8 7] pack layout`, "#{07}"},
+		{"empty", `pack []`, "#{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"object", `unpack #{00020000002A6869} [uint16-be len uint32-be id string len name]`, `make object! [len: 2 id: 42 name: "hi"]`},
+		{"block", `unpack --block #{FF01} [int8 a uint8 b]`, "[-1 1]"},
+		{"little endian", `unpack --block #{0201FEFFFFFF} [uint16-le a int32-le b]`, "[258 -2]"},
+		{"uint64 above int64 becomes bigint", `unpack --block #{FFFFFFFFFFFFFFFF} [uint64-be n]`, "[18446744073709551615n]"},
+		{"int64", `unpack --block #{8000000000000000} [int64-be n]`, "[-9223372036854775808]"},
+		{"float32", `unpack --block #{3FC00000} [float32-be f]`, "[1.5f]"},
+		{"float64", `unpack --block #{000000000000F03F} [float64-le f]`, "[1.0f]"},
+		{"length prefix", `unpack --block #{03414243FF} [string uint8 s uint8 tail]`, `["ABC" 255]`},
+		{"fixed string drops padding", `unpack --block #{61620000} [string 4 s]`, `["ab"]`},
+		{"bytes rest", `unpack --block #{01CAFE} [uint8 kind bytes rest payload]`, "[1 #{CAFE}]"},
+		{"pad skipped", `unpack --block #{01000000020304} [uint8 a pad 3 uint8 b]`, "[1 2]"},
+		{"count from field", `unpack --block #{02AABBCC} [uint8 n bytes n data]`, "[2 #{AABB}]"},
+		{"trailing bytes ignored", `unpack --block #{0102} [uint8 a]`, "[1]"},
+		{"field access", `r: unpack #{0005} [uint16-be port] r.port`, "5"},
+		{"round trip", `b: pack [uint16-be 7 string uint8 "hey" float64-be 2.5f] unpack --block b [uint16-be a string uint8 s float64-be f]`, `[7 "hey" 2.5f]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackUnpack_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		wantID string
+	}{
+		{"short data", `unpack #{01} [uint16-be n]`, verror.ErrIDShortData},
+		{"short bytes", `unpack #{03AA} [uint8 n bytes n data]`, verror.ErrIDShortData},
+		{"short prefix", `unpack #{} [string uint8 s]`, verror.ErrIDShortData},
+		{"unknown type", `unpack #{01} [uint12 n]`, verror.ErrIDInvalidOperation},
+		{"missing byte order", `unpack #{0001} [uint16 n]`, verror.ErrIDInvalidOperation},
+		{"byte order on int8", `pack [int8-be 1]`, verror.ErrIDInvalidOperation},
+		{"missing name", `unpack #{01} [uint8]`, verror.ErrIDInvalidOperation},
+		{"duplicate name", `unpack #{0102} [uint8 a uint8 a]`, verror.ErrIDInvalidOperation},
+		{"unknown count field", `unpack #{01} [bytes n data]`, verror.ErrIDInvalidOperation},
+		{"uint8 overflow", `pack [uint8 256]`, verror.ErrIDInvalidOperation},
+		{"int8 underflow", `pack [int8 -129]`, verror.ErrIDInvalidOperation},
+		{"negative unsigned", `pack [uint32-le -1]`, verror.ErrIDInvalidOperation},
+		{"fixed string too long", `pack [string 2 "abc"]`, verror.ErrIDInvalidOperation},
+		{"prefix too small", `s: copy "" loop 256 [append s "x"] pack [string uint8 s]`, verror.ErrIDInvalidOperation},
+		{"integer field gets string", `pack [uint8 "x"]`, verror.ErrIDTypeMismatch},
+		{"missing value", `pack [uint8]`, verror.ErrIDInvalidOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected verror.Error, got %T", err)
+			}
+			if verr.ID != tt.wantID {
+				t.Errorf("Error ID = %s, want %s (%v)", verr.ID, tt.wantID, err)
+			}
+		})
+	}
+}
@@ -389,6 +389,16 @@ func TestParser_ErrorHandling(t *testing.T) {
 			input:       `classify "1.e"`,
 			expectedErr: "invalid-number-format",
 		},
+		{
+			name:        "load-string integer out of range",
+			input:       `load-string "9223372036854775808"`,
+			expectedErr: "invalid-number-format",
+		},
+		{
+			name:        "load-string negative integer out of range",
+			input:       `load-string "-9223372036854775809"`,
+			expectedErr: "invalid-number-format",
+		},
 		{
 			name:        "classify path starting with number",
 			input:       `classify "1.x"`,
@@ -251,6 +251,30 @@ func TestFilePortOperations(t *testing.T) {
 			t.Errorf("Expected '%s', got '%s'", expected, str.String())
 		}
 	})
+
+	t.Run("ReadAndWriteOpenPort", func(t *testing.T) {
+		result, err := Evaluate(`p: open "test-port.txt"
+write p "Hello, port!"
+data: read p
+close p
+data`)
+		if err != nil {
+			t.Fatalf("Failed to use the open port: %v", err)
+		}
+		defer os.Remove(filepath.Join(tmpDir, "test-port.txt"))
+		if result.Mold() != `"Hello, port!"` {
+			t.Errorf("Expected the written data, got %s", result.Mold())
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "port[file open test-port.txt]")); err == nil {
+			t.Error("read or write used the port's mold as a file name")
+		}
+
+		if _, err := Evaluate(`p: open "test-port.txt"
+close p
+read p`); err == nil {
+			t.Error("Expected error when reading a closed port")
+		}
+	})
 }
 
 // T056: HTTP GET/POST/HEAD with redirects
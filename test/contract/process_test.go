@@ -0,0 +1,175 @@
+package contract
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// enableExec turns on subprocess execution for the duration of a test.
+func enableExec(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("call tests rely on POSIX utilities")
+	}
+	native.AllowExec = true
+	t.Cleanup(func() { native.AllowExec = false })
+}
+
+func TestCall_DisabledByDefault(t *testing.T) {
+	native.AllowExec = false
+
+	_, err := Evaluate(`call ["echo" "hi"]`)
+	if err == nil {
+		t.Fatalf("expected error when exec is disabled")
+	}
+	verr, ok := err.(*verror.Error)
+	if !ok {
+		t.Fatalf("expected verror.Error, got %T", err)
+	}
+	if verr.Category != verror.ErrAccess || verr.ID != verror.ErrIDExecDisabled {
+		t.Errorf("expected access/exec-disabled, got %v/%s", verr.Category, verr.ID)
+	}
+}
+
+func TestCall_Results(t *testing.T) {
+	setupFileSandbox(t)
+	enableExec(t)
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"output", `r: call ["echo" "hello"] r.output`, "\"hello\n\""},
+		{"exit-code success", `r: call ["true"] r.exit-code`, "0"},
+		{"exit-code failure", `r: call ["false"] r.exit-code`, "1"},
+		{"error output", `r: call ["sh" "-c" "echo oops 1>&2; exit 4"] r.error`, "\"oops\n\""},
+		{"non-string args", `r: call ["echo" 42 hello] r.output`, "\"42 hello\n\""},
+		{"input string", `r: call --input "b\na\n" ["sort"] r.output`, "\"a\nb\n\""},
+		{"shell", `r: call --shell "exit 3" r.exit-code`, "3"},
+		{"env override", `e: object [VIRO_CALL_TEST: "set"] r: call --env e ["sh" "-c" "echo $VIRO_CALL_TEST"] r.output`, "\"set\n\""},
+		{"default dir is sandbox root", `r: call ["ls"] r.output`, "\"a.txt\nb.viro\nsub\n\""},
+		{"dir", `r: call --dir "sub" ["ls"] r.output`, "\"c.viro\ndeep\n\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCall_Errors(t *testing.T) {
+	root := setupFileSandbox(t)
+	enableExec(t)
+
+	sibling := root + "-other"
+	if err := os.Mkdir(sibling, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sibling) })
+
+	tests := []struct {
+		name     string
+		script   string
+		category verror.ErrorCategory
+		id       string
+	}{
+		{"dir escapes sandbox", `call --dir "../` + filepath.Base(sibling) + `" ["ls"]`, verror.ErrAccess, verror.ErrIDSandboxViolation},
+		{"timeout", `call --timeout 50 ["sleep" "5"]`, verror.ErrAccess, verror.ErrIDTimeout},
+		{"missing program", `call ["viro-no-such-program"]`, verror.ErrAccess, verror.ErrIDInvalidOperation},
+		{"empty command", `call []`, verror.ErrScript, verror.ErrIDInvalidOperation},
+		{"string without --shell", `call "echo hi"`, verror.ErrScript, verror.ErrIDTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			verr, ok := err.(*verror.Error)
+			if !ok {
+				t.Fatalf("expected verror.Error, got %T", err)
+			}
+			if verr.Category != tt.category || verr.ID != tt.id {
+				t.Errorf("expected %v/%s, got %v/%s", tt.category, tt.id, verr.Category, verr.ID)
+			}
+		})
+	}
+}
+
+func TestCall_Stream(t *testing.T) {
+	setupFileSandbox(t)
+	enableExec(t)
+
+	e := NewTestEvaluator()
+	var out bytes.Buffer
+	e.SetOutputWriter(&out)
+
+	values, locations, err := parse.ParseWithSource(`r: call --stream ["echo" "streamed"] r.output`, "(test)")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	result, err := e.DoBlock(values, locations)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "streamed") {
+		t.Errorf("expected streamed output on OutputWriter, got %q", out.String())
+	}
+	if result.Mold() != "\"streamed\n\"" {
+		t.Errorf("expected output to be captured as well, got %s", result.Mold())
+	}
+}
+
+func TestEnvNatives(t *testing.T) {
+	t.Setenv("VIRO_ENV_TEST", "initial")
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"get-env string name", `get-env "VIRO_ENV_TEST"`, `"initial"`},
+		{"get-env word name", `get-env 'VIRO_ENV_TEST`, `"initial"`},
+		{"get-env missing", `get-env "VIRO_ENV_TEST_MISSING"`, "none"},
+		{"set-env returns value", `set-env "VIRO_ENV_TEST" "changed"`, `"changed"`},
+		{"set-env then get-env", `set-env "VIRO_ENV_TEST" 42 get-env "VIRO_ENV_TEST"`, `"42"`},
+		{"set-env none unsets", `set-env "VIRO_ENV_TEST" none get-env "VIRO_ENV_TEST"`, "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, script := range []string{`get-env 42`, `get-env ""`, `set-env "A=B" "x"`} {
+		t.Run(script, func(t *testing.T) {
+			if _, err := Evaluate(script); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/eval"
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+// evaluateProfiled evaluates src and returns its result with the output it
+// printed, and the evaluator for inspection.
+func evaluateProfiled(t *testing.T, src string) (core.Value, string, *eval.Evaluator, error) {
+	t.Helper()
+	vals, locations, err := parse.ParseWithSource(src, "(test)")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	var out bytes.Buffer
+	e := NewTestEvaluator()
+	e.SetOutputWriter(&out)
+	result, err := e.DoBlock(vals, locations)
+	return result, out.String(), e, err
+}
+
+func TestProfile_ReturnsResultAndReports(t *testing.T) {
+	result, output, e, err := evaluateProfiled(t, `square: fn [n] [n * n]  profile [square 3]`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := result.Mold(); got != "9" {
+		t.Errorf("Got %s, want 9", got)
+	}
+	for _, want := range []string{"EXECUTION PROFILE", "Total Calls:", "square"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("report missing %q:\n%s", want, output)
+		}
+	}
+	if e.CallObserver() != nil {
+		t.Errorf("profile left its observer installed")
+	}
+}
+
+func TestProfile_ReportsAndPropagatesErrors(t *testing.T) {
+	_, output, e, err := evaluateProfiled(t, `profile [1 / 0]`)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || vErr.ID != verror.ErrIDDivByZero {
+		t.Fatalf("Expected div-zero error, got %v", err)
+	}
+	if !strings.Contains(output, "EXECUTION PROFILE") {
+		t.Errorf("Expected report after the error, got:\n%s", output)
+	}
+	if e.CallObserver() != nil {
+		t.Errorf("profile left its observer installed")
+	}
+}
+
+func TestProfile_Save(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := eval.InitSandbox(tmpDir); err != nil {
+		t.Fatalf("Failed to init sandbox: %v", err)
+	}
+	native.SandboxRoot = eval.SandboxRoot
+
+	_, _, _, err := evaluateProfiled(t, `square: fn [n] [n * n]  profile --save "out.folded" [square 3]`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "out.folded"))
+	if err != nil {
+		t.Fatalf("profile was not saved: %v", err)
+	}
+	if !strings.Contains(string(data), "profile;square;* ") {
+		t.Errorf("Expected call path of square, got:\n%s", data)
+	}
+
+	_, _, _, err = evaluateProfiled(t, `profile --save "../escape.folded" [1]`)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || vErr.ID != verror.ErrIDSandboxViolation {
+		t.Errorf("Expected sandbox violation, got %v", err)
+	}
+}
+
+func TestProfile_RequiresBlock(t *testing.T) {
+	_, err := Evaluate(`profile 5`)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || vErr.ID != verror.ErrIDTypeMismatch {
+		t.Errorf("Expected type mismatch, got %v", err)
+	}
+}
+
+func TestStats_Allocations(t *testing.T) {
+	script := `
+build: fn [n] [
+    out: copy []
+    loop n [append out 1]
+    out
+]
+profile --allocs [
+    build 5
+    stats --allocations
+]`
+	result, output, e, err := evaluateProfiled(t, script)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Allocations (sorted by total") {
+		t.Errorf("profile --allocs report has no allocation table:\n%s", output)
+	}
+	if e.AllocationAccounting() {
+		t.Errorf("profile --allocs left accounting on")
+	}
+
+	// Natives allocate on behalf of the user-defined function that
+	// called them: build's copy and appends count against build. The
+	// empty literal is a fresh block too, so copy [] makes two.
+	molded := result.Mold()
+	if !strings.Contains(molded, `function: "build" blocks: 2 strings: 0 binaries: 0 frames: 1 growth: 5 total: 3`) {
+		t.Errorf("Unexpected allocations for build:\n%s", molded)
+	}
+}
+
+func TestStats_AllocationsRequiresAccounting(t *testing.T) {
+	_, err := Evaluate(`stats --allocations`)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || vErr.ID != verror.ErrIDInvalidOperation {
+		t.Errorf("Expected invalid operation, got %v", err)
+	}
+}
+
+func TestStats_AllocationAccountingPerEvaluator(t *testing.T) {
+	first, second := NewTestEvaluator(), NewTestEvaluator()
+	blocks := func(e *eval.Evaluator) int64 {
+		total := e.Allocations()["(top level)"]
+		return total.Blocks
+	}
+	run := func(e *eval.Evaluator) {
+		t.Helper()
+		vals, locations, err := parse.ParseWithSource(`copy [1 2]`, "(test)")
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		if _, err := e.DoBlock(vals, locations); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	first.SetAllocationAccounting(true)
+	defer first.SetAllocationAccounting(false)
+	second.SetAllocationAccounting(true)
+	second.SetAllocationAccounting(false)
+
+	before := blocks(first)
+	run(first)
+	if blocks(first) == before {
+		t.Errorf("turning accounting off in one evaluator stopped it in another")
+	}
+}
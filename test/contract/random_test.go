@@ -0,0 +1,152 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/parse"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestRandom_Ranges(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"integer 1 to n", `ok: true loop 200 [r: random 6 when (r < 1) or (r > 6) [ok: false]] ok`, "true"},
+		{"integer covers range", `seen: copy [] loop 500 [r: random 3 when not find seen r [append seen r]] sort seen`, "[1 2 3]"},
+		{"negative integer", `ok: true loop 200 [r: random -4 when (r < -4) or (r > -1) [ok: false]] ok`, "true"},
+		{"zero", `random 0`, "0"},
+		{"one", `random 1`, "1"},
+		{"bigint", `ok: true loop 100 [r: random 100000000000000000000n when (r < 1n) or (r > 100000000000000000000n) [ok: false]] ok`, "true"},
+		{"bigint type", `type? random 10n`, "bigint!"},
+		{"decimal in [0, 1)", `ok: true loop 200 [r: random 1.0 when (r < 0.0) or (r >= 1.0) [ok: false]] ok`, "true"},
+		{"decimal type", `type? random 1.0`, "decimal!"},
+		{"float in [0, x)", `ok: true loop 200 [r: random 2.0f when (r < 0.0f) or (r >= 2.0f) [ok: false]] ok`, "true"},
+		{"float type", `type? random 1.0f`, "float!"},
+		{"secure integer", `ok: true loop 100 [r: random --secure 10 when (r < 1) or (r > 10) [ok: false]] ok`, "true"},
+		{"seed returns none", `random --seed 7`, "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandom_Series(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"only block member", `b: [10 20 30] ok: true loop 100 [when not find b random --only b [ok: false]] ok`, "true"},
+		{"only from position", `b: next next [1 2 3] random --only b`, "3"},
+		{"only empty", `random --only []`, "none"},
+		{"only string", `random --only "x"`, `"x"`},
+		{"shuffle keeps elements", `b: [1 2 3 4 5 6 7 8] sort random --shuffle b`, "[1 2 3 4 5 6 7 8]"},
+		{"shuffle in place", `b: [1 2 3] r: random --shuffle b append r 4 length? b`, "4"},
+		{"shuffle string", `sort random --shuffle "hello"`, `"ehllo"`},
+		{"shuffle binary", `sort random --shuffle #{030201}`, "#{010203}"},
+		{"secure shuffle", `sort random --secure --shuffle [3 1 2]`, "[1 2 3]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandom_Seed(t *testing.T) {
+	sample := `random --seed 42 reduce [random 1000 random 1000 random 1.0 random --only [a b c d e] random --shuffle [1 2 3 4 5 6]]`
+
+	run := func(t *testing.T, src string) core.Value {
+		t.Helper()
+		e := NewTestEvaluator()
+		vals, locations, err := parse.ParseWithSource(src, "(test)")
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		result, err := e.DoBlock(vals, locations)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+
+	t.Run("same seed same sequence across evaluators", func(t *testing.T) {
+		first := run(t, sample).Mold()
+		second := run(t, sample).Mold()
+		if first != second {
+			t.Errorf("seeded sequences differ:\n%s\n%s", first, second)
+		}
+	})
+
+	t.Run("reseeding restarts the sequence", func(t *testing.T) {
+		result := run(t, `random --seed 9 a: reduce [random 100 random 100] random --seed 9 b: reduce [random 100 random 100] a = b`)
+		if got := result.Mold(); got != "true" {
+			t.Errorf("Got %s, want true", got)
+		}
+	})
+
+	t.Run("different seeds differ", func(t *testing.T) {
+		result := run(t, `random --seed 1 a: reduce [random 1000000 random 1000000] random --seed 2 b: reduce [random 1000000 random 1000000] a = b`)
+		if got := result.Mold(); got != "false" {
+			t.Errorf("Got %s, want false", got)
+		}
+	})
+
+	t.Run("secure ignores the seed", func(t *testing.T) {
+		result := run(t, `random --seed 3 a: random --secure 1000000000000 random --seed 3 b: random --secure 1000000000000 a = b`)
+		if got := result.Mold(); got != "false" {
+			t.Errorf("Got %s, want false", got)
+		}
+	})
+}
+
+func TestRandom_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		wantID string
+	}{
+		{"series without refinement", `random [1 2 3]`, verror.ErrIDInvalidOperation},
+		{"only and shuffle", `random --only --shuffle [1 2]`, verror.ErrIDInvalidOperation},
+		{"seed with secure", `random --seed --secure 1`, verror.ErrIDInvalidOperation},
+		{"seed needs integer", `random --seed "abc"`, verror.ErrIDTypeMismatch},
+		{"only on number", `random --only 5`, verror.ErrIDTypeMismatch},
+		{"unsupported type", `random true`, verror.ErrIDTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			var verr *verror.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected verror.Error, got %T", err)
+			}
+			if verr.ID != tt.wantID {
+				t.Errorf("Error ID = %s, want %s", verr.ID, tt.wantID)
+			}
+		})
+	}
+}
@@ -268,10 +268,25 @@ func TestBodyOf(t *testing.T) {
 		},
 		{
 			name: "body-of object",
-			code: "obj: object [x: 10 y: 20]\nbody-of obj",
+			code: "obj: object [x: 10 y: \"a\"]\nbody-of obj",
 			checkFunc: func(t *testing.T, v core.Value) {
-				if v.GetType() != value.TypeBlock {
-					t.Errorf("expected body-of to return block!, got %v", value.TypeToString(v.GetType()))
+				if got := v.Mold(); got != `[x: 10 y: "a"]` {
+					t.Errorf("expected body-of to return the fields and values, got %s", got)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "body-of object returns copies of series",
+			code: `o: make object! [b: [1 [2]] s: "ab"]
+			       x: body-of o
+			       append second x 3
+			       append second second x 4
+			       append fourth x "c"
+			       reduce [o.b o.s]`,
+			checkFunc: func(t *testing.T, v core.Value) {
+				if got := v.Mold(); got != `[[1 [2]] "ab"]` {
+					t.Errorf("expected the object to be unchanged, got %s", got)
 				}
 			},
 			wantErr: false,
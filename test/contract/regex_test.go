@@ -0,0 +1,77 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestRegex_Natives(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"find --regex", `find --regex "order 66 shipped" "[0-9]+"`, "7"},
+		{"find --regex --last", `find --regex --last "ab ab" "ab"`, "4"},
+		{"find --regex rune index", `find --regex "héllo wörld" "w"`, "7"},
+		{"find --regex no match", `find --regex "abc" "[0-9]"`, "none"},
+		{"matches? true", `matches? "ERROR: disk full" "^(ERROR|WARN):"`, "true"},
+		{"matches? false", `matches? "abc" "^[0-9]+$"`, "false"},
+		{"match captures", `match "2024-01-15 ERROR disk" "([0-9-]+) ([A-Z]+)"`, `["2024-01-15 ERROR" "2024-01-15" "ERROR"]`},
+		{"match unmatched group", `match "a" "(b)?a"`, `["a" none]`},
+		{"match no match", `match "abc" "[0-9]"`, "none"},
+		{"match --all", `match --all "a1 b2" "([a-z])([0-9])"`, `[["a1" "a" "1"] ["b2" "b" "2"]]`},
+		{"match --named", `m: match --named "user=ann id=7" "user=(?P<user>[a-z]+) id=(?P<id>[0-9]+)" m.id`, `"7"`},
+		{"replace first", `replace "a-b-c" "-" "+"`, `"a+b-c"`},
+		{"replace --all", `replace --all "a-b-c" "-" "+"`, `"a+b+c"`},
+		{"replace literal metacharacters", `replace --all "a.b.c" "." "!"`, `"a!b!c"`},
+		{"replace modifies target", `s: "x-y" replace s "-" "_" s`, `"x_y"`},
+		{"replace --regex backrefs", `replace --regex --all "2024-01-15" "([0-9]+)-([0-9]+)-([0-9]+)" "$3/$2/$1"`, `"15/01/2024"`},
+		{"replace --regex named backref", `replace --regex "k=v" "(?P<key>[a-z])=(?P<val>[a-z])" "${val}=${key}"`, `"v=k"`},
+		{"replace with function", `replace --regex --all "a1 b22" "[0-9]+" fn [m] [(to-integer first m) * 2]`, `"a2 b44"`},
+		{"split --regex", `split --regex "a, b;c" "[,;] *"`, `["a" "b" "c"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegex_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		id     string
+	}{
+		{"invalid pattern", `matches? "x" "("`, verror.ErrIDInvalidRegex},
+		{"invalid pattern in replace", `replace --regex "x" "[" "y"`, verror.ErrIDInvalidRegex},
+		{"regex on block", `find --regex [1 2] "x"`, verror.ErrIDTypeMismatch},
+		{"non-string pattern", `match "x" 42`, verror.ErrIDTypeMismatch},
+		{"empty literal search", `replace "x" "" "y"`, verror.ErrIDInvalidOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			verr, ok := err.(*verror.Error)
+			if !ok {
+				t.Fatalf("expected verror.Error, got %T", err)
+			}
+			if verr.ID != tt.id {
+				t.Errorf("expected %s, got %s", tt.id, verr.ID)
+			}
+		})
+	}
+}
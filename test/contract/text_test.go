@@ -0,0 +1,106 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestText_Natives(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"uppercase", `uppercase "hello"`, `"HELLO"`},
+		{"uppercase unicode", `uppercase "ça va"`, `"ÇA VA"`},
+		{"uppercase --part", `uppercase --part 1 "hello"`, `"Hello"`},
+		{"uppercase from index", `s: next "abc" uppercase s head s`, `"aBC"`},
+		{"uppercase modifies in place", `s: "abc" uppercase s s`, `"ABC"`},
+		{"lowercase unicode", `lowercase "ÉCOLE"`, `"école"`},
+		{"lowercase --part", `lowercase --part 2 "HELLO"`, `"heLLO"`},
+		{"replace is case-sensitive by default", `replace --all "Ab ab AB" "ab" "x"`, `"Ab x AB"`},
+		{"replace --ignore-case", `replace --all --ignore-case "Ab ab AB" "ab" "x"`, `"x x x"`},
+		{"replace unicode case folding", `replace --ignore-case "ÉCOLE école" "école" "x"`, `"x école"`},
+		{"pad right by default", `pad "ab" 5`, `"ab   "`},
+		{"pad --right", `pad --right "ab" 4`, `"ab  "`},
+		{"pad --left", `pad --left "42" 6`, `"    42"`},
+		{"pad --with", `pad --left --with "0" "7" 3`, `"007"`},
+		{"pad counts characters", `pad --left "日本" 4`, `"  日本"`},
+		{"pad wide enough", `pad "hello" 3`, `"hello"`},
+		{"repeat-string", `repeat-string "ab" 3`, `"ababab"`},
+		{"repeat-string zero", `repeat-string "ab" 0`, `""`},
+		{"starts-with? true", `starts-with? "viro-lang" "viro"`, "true"},
+		{"starts-with? false", `starts-with? "viro-lang" "lang"`, "false"},
+		{"ends-with? true", `ends-with? "report.viro" ".viro"`, "true"},
+		{"ends-with? unicode", `ends-with? "naïve" "ïve"`, "true"},
+		{"format width", `format "%-6s|%5d" ["abc" 42]`, `"abc   |   42"`},
+		{"format precision", `format "%.2f" 3.14159`, `"3.14"`},
+		{"format zero pad", `format "%08.3f" 2.5`, `"0002.500"`},
+		{"format integer as decimal", `format "%.1f" 3`, `"3.0"`},
+		{"format scientific", `format "%5.1e" [12345.678]`, `"1.2e+04"`},
+		{"format scientific default precision", `format "%e" 12345.678`, `"1.234568e+04"`},
+		{"format scientific rounds up", `format "%.0e" 9.99`, `"1e+01"`},
+		{"format scientific zero pad", `format "%010.2e" -0.000123`, `"-01.23e-04"`},
+		{"format sign", `format "%+d" 5`, `"+5"`},
+		{"format percent", `format "%3d%%" 7`, `"  7%"`},
+		{"format hex", `format "%x/%X" [255 255]`, `"ff/FF"`},
+		{"format reduces block", `x: 5 format "%s" [x * 2]`, `"10"`},
+		{"format unicode width", `format "%-4s|" "日本"`, `"日本  |"`},
+		{"format truncates with precision", `format "%.3s" "abcdef"`, `"abc"`},
+		{"to-hex integer", `to-hex 255`, `"FF"`},
+		{"to-hex negative", `to-hex -255`, `"-FF"`},
+		{"to-hex --size", `to-hex --size 4 255`, `"00FF"`},
+		{"to-hex binary", `to-hex #{CAFE}`, `"CAFE"`},
+		{"from-hex", `from-hex "ff"`, "255"},
+		{"from-hex negative", `from-hex "-1A"`, "-26"},
+		{"from-hex --binary", `from-hex --binary "cafe"`, "#{CAFE}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Mold(); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		id     string
+	}{
+		{"uppercase part too long", `uppercase --part 9 "hi"`, verror.ErrIDOutOfBounds},
+		{"pad fill not one character", `pad --with "ab" "x" 5`, verror.ErrIDTypeMismatch},
+		{"pad left and right", `pad --left --right "x" 5`, verror.ErrIDInvalidOperation},
+		{"repeat-string negative", `repeat-string "x" -1`, verror.ErrIDInvalidOperation},
+		{"format wrong type", `format "%d" 1.5`, verror.ErrIDTypeMismatch},
+		{"format missing value", `format "%d %d" [1]`, verror.ErrIDInvalidOperation},
+		{"format unused value", `format "%d" [1 2]`, verror.ErrIDInvalidOperation},
+		{"format unknown verb", `format "%q" 1`, verror.ErrIDInvalidOperation},
+		{"from-hex invalid", `from-hex "zz"`, verror.ErrIDInvalidOperation},
+		{"from-hex overflow", `from-hex "FFFFFFFFFFFFFFFFFF"`, verror.ErrIDOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.script)
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			verr, ok := err.(*verror.Error)
+			if !ok {
+				t.Fatalf("expected verror.Error, got %T", err)
+			}
+			if verr.ID != tt.id {
+				t.Errorf("expected %s, got %s", tt.id, verr.ID)
+			}
+		})
+	}
+}
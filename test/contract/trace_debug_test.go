@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/marcin-radoszewski/viro/internal/core"
+	"github.com/marcin-radoszewski/viro/internal/native"
 	"github.com/marcin-radoszewski/viro/internal/parse"
 	"github.com/marcin-radoszewski/viro/internal/trace"
 	"github.com/marcin-radoszewski/viro/internal/value"
@@ -174,15 +175,17 @@ func TestTraceFiltering(t *testing.T) {
 }
 
 // T133: trace sink configuration (--file, --append)
-// NOTE: File type (%) is not yet implemented, so file-related tests are skipped
 func TestTraceSinkConfiguration(t *testing.T) {
+	original := native.SandboxRoot
+	native.SandboxRoot = t.TempDir()
+	defer func() { native.SandboxRoot = original }()
+
 	tests := []struct {
 		name    string
 		code    string
 		wantErr bool
 		errCat  verror.ErrorCategory
 	}{
-		// File-related tests removed until file type (%) is implemented
 		{
 			name:    "trace with custom file path",
 			code:    "trace --on --file \"trace-custom.log\"",
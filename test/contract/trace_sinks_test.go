@@ -0,0 +1,123 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/native"
+	"github.com/marcin-radoszewski/viro/internal/trace"
+	"github.com/marcin-radoszewski/viro/internal/verror"
+)
+
+func TestTraceEvents_Memory(t *testing.T) {
+	script := `
+square: fn [n] [n * n]
+sum: fn [a b] [a + b]
+trace --on --memory --types [call] --only ["sq*"]
+sum square 2 square 3
+trace --off
+trace-events`
+	result, err := Evaluate(script)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	molded := result.Mold()
+	if strings.Count(molded, `word: "square"`) != 2 || strings.Contains(molded, `word: "sum"`) {
+		t.Errorf("Expected two square calls only, got:\n%s", molded)
+	}
+	if !strings.Contains(molded, "type: call") {
+		t.Errorf("Expected call events, got:\n%s", molded)
+	}
+}
+
+func TestTraceEvents_Clear(t *testing.T) {
+	result, err := Evaluate(`trace --on --memory  1 + 2  trace --off  trace-events --clear  length? trace-events`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Mold() != "0" {
+		t.Errorf("Expected an empty buffer after --clear, got %s", result.Mold())
+	}
+}
+
+func TestTraceEvents_RequiresBuffer(t *testing.T) {
+	_, err := Evaluate(`trace-events`)
+	var vErr *verror.Error
+	if !errors.As(err, &vErr) || vErr.ID != verror.ErrIDInvalidOperation {
+		t.Errorf("Expected invalid operation, got %v", err)
+	}
+}
+
+func TestTraceFormats_File(t *testing.T) {
+	tmpDir := t.TempDir()
+	original := native.SandboxRoot
+	native.SandboxRoot = tmpDir
+	defer func() { native.SandboxRoot = original }()
+
+	script := `
+square: fn [n] [n * n]
+trace --on --format 'tree --file "tree.log" --include-args
+square 3
+trace --on --format 'chrome --file "trace.json"
+square 4
+trace --off`
+	if _, err := Evaluate(script); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Closing the session ends the Chrome array.
+	trace.GlobalTraceSession.Close()
+
+	tree, err := os.ReadFile(filepath.Join(tmpDir, "tree.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tree), "-> square n=3") || !strings.Contains(string(tree), "<- square == 9") {
+		t.Errorf("Unexpected tree output:\n%s", tree)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "trace.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []map[string]any
+	if err := json.Unmarshal(data, &events); err != nil {
+		t.Fatalf("Chrome trace is not a JSON array: %v\n%s", err, data)
+	}
+	found := false
+	for _, ev := range events {
+		if ev["name"] == "square" && ev["ph"] == "X" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("No complete event for square:\n%s", data)
+	}
+}
+
+func TestTraceRefinementErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		id   string
+	}{
+		{"unknown format", `trace --on --format 'xml`, verror.ErrIDInvalidOperation},
+		{"buffer must be positive", `trace --on --buffer 0`, verror.ErrIDInvalidOperation},
+		{"memory with file", `trace --on --memory --file "t.log"`, verror.ErrIDInvalidOperation},
+		{"types are words", `trace --on --types ["call"]`, verror.ErrIDTypeMismatch},
+		{"unknown event type", `trace --on --types [call retrun]`, verror.ErrIDInvalidOperation},
+		{"only takes words or strings", `trace --on --only [1]`, verror.ErrIDTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.code)
+			var vErr *verror.Error
+			if !errors.As(err, &vErr) || vErr.ID != tt.id {
+				t.Errorf("Expected %s, got %v", tt.id, err)
+			}
+		})
+	}
+}
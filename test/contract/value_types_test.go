@@ -37,6 +37,19 @@ func TestDecimalValueConstruction(t *testing.T) {
 	}
 }
 
+// TestDecimalValueContextNotShared validates that each decimal owns its context
+func TestDecimalValueContextNotShared(t *testing.T) {
+	a := value.NewDecimal(decimal.New(1, 0), 0)
+	b := value.NewDecimal(decimal.New(2, 0), 0)
+	a.Context.Precision = 5
+	if b.Context.Precision != 34 {
+		t.Errorf("changing one decimal's context changed another's precision to %d", b.Context.Precision)
+	}
+	if c := value.NewDecimal(decimal.New(3, 0), 0); c.Context.Precision != 34 {
+		t.Errorf("changing a decimal's context changed the default precision to %d", c.Context.Precision)
+	}
+}
+
 // TestDecimalValueWrapping validates Value wrapping for decimals
 func TestDecimalValueWrapping(t *testing.T) {
 	mag := decimal.New(42, 0)
@@ -2,7 +2,9 @@ package integration
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -347,3 +349,95 @@ func TestCheckModeWithQuiet(t *testing.T) {
 		t.Logf("Note: --quiet with --check produced output: %s", output)
 	}
 }
+
+func runCheck(t *testing.T, script string, flags ...string) (int, string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lint.viro")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := append(append([]string{"--check"}, flags...), path)
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{
+		Args:   args,
+		Stdin:  &bytes.Buffer{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	cfg, err := api.ConfigFromArgs(args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs() error = %v", err)
+	}
+	return api.Run(ctx, cfg), stdout.String(), stderr.String()
+}
+
+func TestCheckModeLint(t *testing.T) {
+	script := "greet: fn [name extra] [print name]\nprint missing\nbreak\n"
+
+	t.Run("human output", func(t *testing.T) {
+		code, out, _ := runCheck(t, script)
+		if code != api.ExitError {
+			t.Errorf("exit code = %d, want %d", code, api.ExitError)
+		}
+		for _, want := range []string{
+			"lint.viro:1:17: warning: parameter 'extra' is never used [unused-param]",
+			"lint.viro:2:7: error: 'missing' is not defined [undefined-word]",
+			"lint.viro:3:1: error: 'break' used outside of a loop [loop-control-outside-loop]",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("stdout = %q, want to contain %q", out, want)
+			}
+		}
+	})
+
+	t.Run("warnings alone succeed", func(t *testing.T) {
+		code, out, _ := runCheck(t, "f: fn [a b] [a]\nf 1 2\n")
+		if code != api.ExitSuccess || !strings.Contains(out, "[unused-param]") {
+			t.Errorf("exit code = %d, stdout = %q", code, out)
+		}
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		code, out, _ := runCheck(t, script, "--json")
+		if code != api.ExitError {
+			t.Errorf("exit code = %d, want %d", code, api.ExitError)
+		}
+		var report struct {
+			Valid       bool `json:"valid"`
+			Errors      int  `json:"errors"`
+			Warnings    int  `json:"warnings"`
+			Diagnostics []struct {
+				Line     int    `json:"line"`
+				Column   int    `json:"column"`
+				Severity string `json:"severity"`
+				Code     string `json:"code"`
+			} `json:"diagnostics"`
+		}
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("invalid JSON %q: %v", out, err)
+		}
+		if report.Valid || report.Errors != 2 || report.Warnings != 1 || len(report.Diagnostics) != 3 {
+			t.Fatalf("report = %+v", report)
+		}
+		if d := report.Diagnostics[1]; d.Code != "undefined-word" || d.Line != 2 || d.Column != 7 || d.Severity != "error" {
+			t.Errorf("diagnostic = %+v", d)
+		}
+	})
+
+	t.Run("json syntax error", func(t *testing.T) {
+		code, out, errOut := runCheck(t, "print [1 2\n", "--json")
+		if code != api.ExitSyntax {
+			t.Errorf("exit code = %d, want %d", code, api.ExitSyntax)
+		}
+		if errOut != "" || !strings.Contains(out, `"code": "syntax"`) || !strings.Contains(out, `"valid": false`) {
+			t.Errorf("stdout = %q, stderr = %q", out, errOut)
+		}
+	})
+
+	t.Run("json clean script", func(t *testing.T) {
+		code, out, _ := runCheck(t, "print 1\n", "--json")
+		if code != api.ExitSuccess || !strings.Contains(out, `"diagnostics": []`) {
+			t.Errorf("exit code = %d, stdout = %q", code, out)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func runWithArgs(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{Args: args, Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+	cfg, err := api.ConfigFromArgs(args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs() error = %v", err)
+	}
+	return api.Run(ctx, cfg), stdout.String(), stderr.String()
+}
+
+func TestCoverageFlag(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.viro")
+	os.WriteFile(script, []byte(`grade: fn [n] [
+    if n > 50 [
+        "pass"
+    ] [
+        "fail"
+    ]
+]
+print grade 70
+`), 0644)
+
+	t.Run("lcov", func(t *testing.T) {
+		out := filepath.Join(dir, "cover.info")
+		code, stdout, stderr := runWithArgs(t, "--coverage", out, script)
+		if code != api.ExitSuccess || stdout != "pass\n" {
+			t.Fatalf("exit code = %d, stdout = %q, stderr = %q", code, stdout, stderr)
+		}
+		if !strings.Contains(stderr, "coverage: 80.0% of lines") {
+			t.Errorf("stderr = %q", stderr)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "TN:\nSF:" + script + "\nDA:1,1\nDA:2,1\nDA:3,1\nDA:5,0\nDA:8,1\nLF:5\nLH:4\nend_of_record\n"
+		if string(data) != want {
+			t.Errorf("LCOV =\n%s\nwant\n%s", data, want)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		out := filepath.Join(dir, "cover.html")
+		if code, _, stderr := runWithArgs(t, "--quiet", "--coverage", out, script); code != api.ExitSuccess || stderr != "" {
+			t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+		}
+		data, _ := os.ReadFile(out)
+		if !strings.Contains(string(data), `<tr class="miss"><td class="num">5</td>`) {
+			t.Errorf("HTML report does not mark line 5 uncovered:\n%s", data)
+		}
+	})
+
+	t.Run("written when the script fails", func(t *testing.T) {
+		failing := filepath.Join(dir, "failing.viro")
+		os.WriteFile(failing, []byte("x: 1\ny: x / 0\nprint y\n"), 0644)
+		out := filepath.Join(dir, "failing.info")
+		code, _, _ := runWithArgs(t, "--coverage", out, failing)
+		if code != api.ExitError {
+			t.Errorf("exit code = %d, want %d", code, api.ExitError)
+		}
+		data, _ := os.ReadFile(out)
+		if !strings.Contains(string(data), "DA:2,1\nDA:3,0\n") {
+			t.Errorf("LCOV =\n%s", data)
+		}
+	})
+
+	t.Run("test mode", func(t *testing.T) {
+		tests := t.TempDir()
+		os.WriteFile(filepath.Join(tests, "grade_test.viro"), []byte(`grade: fn [n] [
+    if n > 50 ["pass"] ["fail"]
+]
+unused: fn [] [
+    none
+]
+test "pass" [assert-equal "pass" grade 70]
+`), 0644)
+		out := filepath.Join(dir, "tests.info")
+		code, _, stderr := runWithArgs(t, "test", "--sandbox-root", tests, "--coverage", out)
+		if code != api.ExitSuccess {
+			t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+		}
+		data, _ := os.ReadFile(out)
+		if !strings.Contains(string(data), "SF:grade_test.viro\n") || !strings.Contains(string(data), "DA:5,0\n") {
+			t.Errorf("LCOV =\n%s", data)
+		}
+	})
+
+	t.Run("rejected for -c", func(t *testing.T) {
+		if code, _, _ := runWithArgs(t, "--coverage", filepath.Join(dir, "x.info"), "-c", "1 + 1"); code != api.ExitUsage {
+			t.Errorf("exit code = %d, want %d", code, api.ExitUsage)
+		}
+	})
+}
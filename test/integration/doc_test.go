@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func TestDocCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := `Viro [title: "Geometry"]
+area: fn [
+    "Returns the area of a rectangle
+    area 3 4  ; => 12"
+    w "The width"
+    h "The height"
+] [w * h]
+helper: fn [x] [x]
+`
+	if err := os.WriteFile(filepath.Join(dir, "geometry.viro"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "site")
+
+	exitCode, _, stderr := runWithStdin(t, "", "doc", "--sandbox-root", dir, "--output", out, "geometry.viro")
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("viro doc exited with %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "0 failed") {
+		t.Errorf("Unexpected summary: %s", stderr)
+	}
+	for _, name := range []string{"index.html", "index.md", "math.html", "series.md", "geometry.md", "search-index.json"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join(out, "geometry.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "Returns the area of a rectangle") || strings.Contains(string(page), "helper") {
+		t.Errorf("Unexpected geometry page:\n%s", page)
+	}
+}
+
+func TestDocCommandScriptWithSideEffects(t *testing.T) {
+	dir := t.TempDir()
+	script := `write "created.txt" "side effect"
+tools: object [
+    triple: fn ["Triples n" "tools.triple 2  ; => 6" n] [n * 3]
+]
+double: fn ["Doubles n" "double 2  ; => 4" n] [n * 2]
+print "running"
+exit --code 3
+`
+	if err := os.WriteFile(filepath.Join(dir, "tool.viro"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "site")
+
+	exitCode, stdout, stderr := runWithStdin(t, "", "doc", "--sandbox-root", dir, "--output", out, "tool.viro")
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("viro doc exited with %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, " 0 failed") {
+		t.Errorf("Unexpected summary: %s", stderr)
+	}
+	if stdout != "" {
+		t.Errorf("viro doc printed the script's output: %q", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "created.txt")); !os.IsNotExist(err) {
+		t.Errorf("viro doc ran the script in the sandbox root (%v)", err)
+	}
+	page, err := os.ReadFile(filepath.Join(out, "tool.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## `double`", "## `tools.triple`"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("tool.md lacks %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestDocCommandExampleMismatch(t *testing.T) {
+	dir := t.TempDir()
+	script := `double: fn ["Doubles n" "double 2  ; => 5" n] [n * 2]`
+	if err := os.WriteFile(filepath.Join(dir, "lib.viro"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "site")
+
+	exitCode, _, stderr := runWithStdin(t, "", "doc", "--check", "--sandbox-root", dir, "--output", out, "lib.viro")
+	if exitCode != api.ExitError {
+		t.Fatalf("viro doc exited with %d, want %d: %s", exitCode, api.ExitError, stderr)
+	}
+	if !strings.Contains(stderr, "FAIL double example 1: double 2\n    want: 5\n    got:  4") {
+		t.Errorf("Unexpected report: %s", stderr)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("--check wrote the site: %v", err)
+	}
+}
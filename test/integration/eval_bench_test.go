@@ -11,6 +11,7 @@ import (
 var (
 	simpleEvalResult  core.Value
 	complexEvalResult core.Value
+	loopEvalResult    core.Value
 )
 
 func BenchmarkEvalSimpleExpression(b *testing.B) {
@@ -52,7 +53,7 @@ fib: fn [n] [
 	if n <= 1 [
 		n
 	] [
-		fib (n - 1) + fib (n - 2)
+		(fib n - 1) + (fib n - 2)
 	]
 ]
 
@@ -92,3 +93,73 @@ total
 		b.Fatalf("unexpected final result: %v", complexEvalResult)
 	}
 }
+
+// BenchmarkEvalNumericLoop measures a tight loop dominated by word lookups:
+// every iteration resolves total, i, + and loop's body words.
+func BenchmarkEvalNumericLoop(b *testing.B) {
+	source := `
+total: 0
+i: 0
+loop 1000 [
+	total: total + i
+	i: i + 1
+]
+total
+`
+	values, locations, err := parse.ParseWithSource(source, "(test)")
+	if err != nil {
+		b.Fatalf("parse failed: %v", err)
+	}
+
+	benchmarkBothModes(b, values, locations, 499500)
+}
+
+// BenchmarkEvalLocalsInFunction measures lookups that mix function locals,
+// parameters and natives while new locals keep being bound.
+func BenchmarkEvalLocalsInFunction(b *testing.B) {
+	source := `
+step: fn [n] [
+	a: n + 1
+	b: a * 2
+	a + b
+]
+sum: 0
+loop 200 [sum: sum + step 3]
+sum
+`
+	values, locations, err := parse.ParseWithSource(source, "(test)")
+	if err != nil {
+		b.Fatalf("parse failed: %v", err)
+	}
+
+	benchmarkBothModes(b, values, locations, 2400)
+}
+
+// benchmarkBothModes runs a script through the interpreter and through the
+// block compiler, checking that both produce want.
+func benchmarkBothModes(b *testing.B, values []core.Value, locations []core.SourceLocation, want int64) {
+	for _, mode := range []struct {
+		name    string
+		compile bool
+	}{{"interpreted", false}, {"compiled", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			evaluator := NewTestEvaluator()
+			evaluator.SetCompile(mode.compile)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				result, err := evaluator.DoBlock(values, locations)
+				if err != nil {
+					b.Fatalf("evaluation error: %v", err)
+				}
+				loopEvalResult = result
+			}
+
+			if got, ok := value.AsIntValue(loopEvalResult); !ok || got != want {
+				b.Fatalf("unexpected final result: %v", loopEvalResult)
+			}
+		})
+	}
+}
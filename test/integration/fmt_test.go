@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func runFmt(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{
+		Args:   append([]string{"fmt"}, args...),
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	cfg, err := api.ConfigFromArgs(ctx.Args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs() error = %v", err)
+	}
+	return api.Run(ctx, cfg), stdout.String(), stderr.String()
+}
+
+func TestFmtMode(t *testing.T) {
+	messy := "x:   [1  2]\nf: fn [a] [\nprint a]\n"
+	clean := "x: [1 2]\nf: fn [a] [\n    print a\n]\n"
+
+	dir := t.TempDir()
+	messyPath := filepath.Join(dir, "messy.viro")
+	cleanPath := filepath.Join(dir, "clean.viro")
+	os.WriteFile(messyPath, []byte(messy), 0644)
+	os.WriteFile(cleanPath, []byte(clean), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x   y"), 0644)
+
+	t.Run("stdin to stdout", func(t *testing.T) {
+		code, out, errOut := runFmt(t, messy)
+		if code != api.ExitSuccess || out != clean {
+			t.Errorf("exit %d, stdout %q, stderr %q", code, out, errOut)
+		}
+	})
+
+	t.Run("file to stdout", func(t *testing.T) {
+		code, out, _ := runFmt(t, "", messyPath)
+		if code != api.ExitSuccess || out != clean {
+			t.Errorf("exit %d, stdout %q", code, out)
+		}
+	})
+
+	t.Run("check lists unformatted files", func(t *testing.T) {
+		code, out, _ := runFmt(t, "", "--check", dir)
+		if code != api.ExitError {
+			t.Errorf("exit %d, want %d", code, api.ExitError)
+		}
+		if !strings.Contains(out, "messy.viro") || strings.Contains(out, "clean.viro") || strings.Contains(out, "notes.txt") {
+			t.Errorf("stdout %q", out)
+		}
+	})
+
+	t.Run("check passes on formatted file", func(t *testing.T) {
+		code, out, _ := runFmt(t, "", "--check", cleanPath)
+		if code != api.ExitSuccess || out != "" {
+			t.Errorf("exit %d, stdout %q", code, out)
+		}
+	})
+
+	t.Run("write rewrites in place", func(t *testing.T) {
+		code, out, _ := runFmt(t, "", "--write", dir)
+		if code != api.ExitSuccess || out != "" {
+			t.Errorf("exit %d, stdout %q", code, out)
+		}
+		data, _ := os.ReadFile(messyPath)
+		if string(data) != clean {
+			t.Errorf("messy.viro = %q, want %q", data, clean)
+		}
+		notes, _ := os.ReadFile(filepath.Join(dir, "notes.txt"))
+		if string(notes) != "x   y" {
+			t.Errorf("notes.txt was modified: %q", notes)
+		}
+		if code, _, _ := runFmt(t, "", "--check", dir); code != api.ExitSuccess {
+			t.Errorf("check after write exit %d", code)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		code, _, errOut := runFmt(t, "print [1")
+		if code != api.ExitSyntax || !strings.Contains(errOut, "Syntax Error") {
+			t.Errorf("exit %d, stderr %q", code, errOut)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if code, _, _ := runFmt(t, "", filepath.Join(dir, "absent.viro")); code != api.ExitError {
+			t.Errorf("exit %d, want %d", code, api.ExitError)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func frame(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func TestLSPMode(t *testing.T) {
+	input := frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","method":"initialized","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.viro","languageId":"viro","version":1,"text":"print nope\n"}}}`) +
+		frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///a.viro"},"position":{"line":0,"character":2}}}`) +
+		frame(`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`) +
+		frame(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{
+		Args:   []string{"lsp", "--stdio"},
+		Stdin:  strings.NewReader(input),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	cfg, err := api.ConfigFromArgs(ctx.Args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs() error = %v", err)
+	}
+	if code := api.Run(ctx, cfg); code != api.ExitSuccess {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		`"id":1,"result":{"capabilities"`,
+		`"method":"textDocument/publishDiagnostics"`,
+		`'nope' is not defined`,
+		`PRINT - `,
+		`"id":3,"result":null`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLSPModeExitWithoutShutdown(t *testing.T) {
+	input := frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{Args: []string{"lsp"}, Stdin: strings.NewReader(input), Stdout: &stdout, Stderr: &stderr}
+	cfg, _ := api.ConfigFromArgs(ctx.Args)
+	if code := api.Run(ctx, cfg); code != api.ExitError {
+		t.Errorf("exit code = %d, want %d", code, api.ExitError)
+	}
+}
@@ -117,7 +117,7 @@ print result
 	}
 }
 
-func TestProfileFlagRequiresScript(t *testing.T) {
+func TestProfileWithEval(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	ctx := &api.RuntimeContext{
 		Args:   []string{"--profile", "-c", "3 + 4"},
@@ -131,14 +131,80 @@ func TestProfileFlagRequiresScript(t *testing.T) {
 	}
 
 	exitCode := api.Run(ctx, cfg)
-	output := stdout.String() + stderr.String()
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("Profile execution failed with exit code %d\nOutput: %s", exitCode, stdout.String()+stderr.String())
+	}
 
-	if exitCode == api.ExitSuccess {
-		t.Fatalf("Expected error when using --profile with -c, but command succeeded")
+	if strings.TrimSpace(stdout.String()) != "7" {
+		t.Errorf("Expected result 7 on stdout, got:\n%s", stdout.String())
 	}
 
-	if !strings.Contains(output, "profile") && !strings.Contains(output, "script") {
-		t.Errorf("Expected error message about profile requiring script, got:\n%s", output)
+	if !strings.Contains(stderr.String(), "EXECUTION PROFILE") {
+		t.Errorf("Expected profile on stderr, got:\n%s", stderr.String())
+	}
+}
+
+func TestProfileOutputFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "test.viro")
+
+	script := `
+square: fn [n] [* n n]
+sum: fn [a b] [+ (square a) (square b)]
+print sum 3 4
+`
+
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+
+	folded := filepath.Join(tmpDir, "out.folded")
+	pprof := filepath.Join(tmpDir, "out.pb.gz")
+	for _, out := range []string{folded, pprof} {
+		var stdout, stderr bytes.Buffer
+		ctx := &api.RuntimeContext{Stdout: &stdout, Stderr: &stderr}
+		cfg, err := api.ConfigFromArgs([]string{"--quiet", "--profile-output", out, scriptPath})
+		if err != nil {
+			t.Fatalf("ConfigFromArgs failed: %v", err)
+		}
+		if exitCode := api.Run(ctx, cfg); exitCode != api.ExitSuccess {
+			t.Fatalf("Profile execution failed with exit code %d\nOutput: %s", exitCode, stdout.String()+stderr.String())
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("Expected no report with --quiet, got:\n%s", stderr.String())
+		}
+	}
+
+	data, err := os.ReadFile(folded)
+	if err != nil {
+		t.Fatalf("Failed to read collapsed stacks: %v", err)
+	}
+	if !strings.Contains(string(data), "(top level);print;sum;+;square;* ") {
+		t.Errorf("Expected call path of square in collapsed stacks, got:\n%s", data)
+	}
+
+	data, err = os.ReadFile(pprof)
+	if err != nil {
+		t.Fatalf("Failed to read pprof profile: %v", err)
+	}
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		t.Errorf("Expected a gzipped pprof profile")
+	}
+}
+
+func TestProfileReportedOnError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{Stdout: &stdout, Stderr: &stderr}
+	cfg, err := api.ConfigFromArgs([]string{"--profile", "-c", "f: fn [] [1 / 0]  f"})
+	if err != nil {
+		t.Fatalf("ConfigFromArgs failed: %v", err)
+	}
+
+	if exitCode := api.Run(ctx, cfg); exitCode == api.ExitSuccess {
+		t.Fatalf("Expected division by zero to fail")
+	}
+	if !strings.Contains(stderr.String(), "EXECUTION PROFILE") {
+		t.Errorf("Expected profile after the error, got:\n%s", stderr.String())
 	}
 }
 
@@ -196,8 +262,8 @@ print result3
 		}
 	}
 
-	if !strings.Contains(output, "Total Events:") {
-		t.Errorf("Expected 'Total Events' in profile, got:\n%s", output)
+	if !strings.Contains(output, "Total Calls:") {
+		t.Errorf("Expected 'Total Calls' in profile, got:\n%s", output)
 	}
 
 	if !strings.Contains(output, "Calls") {
@@ -208,3 +274,48 @@ print result3
 		t.Errorf("Expected 'Total Time' column header in profile, got:\n%s", output)
 	}
 }
+
+func TestProfileAllocsFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "test.viro")
+
+	script := `
+build: fn [n] [
+    out: copy []
+    loop n [append out "item"]
+    out
+]
+build 10
+print length? stats --allocations
+`
+
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+
+	jsonPath := filepath.Join(tmpDir, "profile.json")
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{Stdout: &stdout, Stderr: &stderr}
+	cfg, err := api.ConfigFromArgs([]string{"--profile-allocs", "--profile-output", jsonPath, scriptPath})
+	if err != nil {
+		t.Fatalf("ConfigFromArgs failed: %v", err)
+	}
+
+	if exitCode := api.Run(ctx, cfg); exitCode != api.ExitSuccess {
+		t.Fatalf("Profile execution failed with exit code %d\nOutput: %s", exitCode, stdout.String()+stderr.String())
+	}
+	if strings.TrimSpace(stdout.String()) != "2" {
+		t.Errorf("Expected allocations for build and the top level, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Allocations (sorted by total") {
+		t.Errorf("Expected allocation table in profile, got:\n%s", stderr.String())
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON profile: %v", err)
+	}
+	if !strings.Contains(string(data), `"Growth": 10`) {
+		t.Errorf("Expected append growth in JSON profile, got:\n%s", data)
+	}
+}
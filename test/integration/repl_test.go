@@ -231,6 +231,24 @@ func TestREPL_ExitCommands(t *testing.T) {
 	}
 }
 
+func TestREPL_ExitNativeWithStatus(t *testing.T) {
+	evaluator := NewTestEvaluator()
+	var out bytes.Buffer
+	loop := repl.NewREPLForTest(evaluator, &out)
+
+	loop.EvalLineForTest(`when true [exit --code 3] print "unreachable"`)
+	if loop.ShouldContinue() {
+		t.Fatalf("REPL should request shutdown after exit native")
+	}
+	output := out.String()
+	if strings.Contains(output, "unreachable") {
+		t.Fatalf("code after exit was evaluated: %q", output)
+	}
+	if !strings.Contains(output, "Goodbye!") {
+		t.Fatalf("expected goodbye message after exit native, got %q", output)
+	}
+}
+
 func TestREPL_CtrlCInterrupt(t *testing.T) {
 	evaluator := NewTestEvaluator()
 	var out bytes.Buffer
@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func runWithStdin(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{Args: args, Stdin: strings.NewReader(stdin), Stdout: &stdout, Stderr: &stderr}
+	cfg, err := api.ConfigFromArgs(args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs failed: %v", err)
+	}
+	return api.Run(ctx, cfg), stdout.String(), stderr.String()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	dataPath := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(dataPath, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	scriptPath := filepath.Join(dir, "test.viro")
+	script := `name: input
+data: read "data.txt"
+n: random --secure 1000000
+m: random 1000000
+print [name data n + m]
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recPath := filepath.Join(dir, "run.json")
+
+	exitCode, recorded, stderr := runWithStdin(t, "ada\n", "--sandbox-root", dir, "--record", recPath, scriptPath)
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("Recorded run failed with %d: %s", exitCode, stderr)
+	}
+	if !strings.HasPrefix(recorded, "ada original ") {
+		t.Fatalf("Unexpected output %q", recorded)
+	}
+
+	// The replay reads neither standard input nor the changed file.
+	if err := os.WriteFile(dataPath, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exitCode, replayed, stderr := runWithStdin(t, "step 1\nfind read\nlist 4\nlast\nframe\nq\n", "--sandbox-root", dir, "--replay", recPath)
+	if exitCode != api.ExitSuccess || stderr != "" {
+		t.Fatalf("Replay exited with %d: %s", exitCode, stderr)
+	}
+	if !strings.HasPrefix(replayed, recorded) {
+		t.Errorf("Replay printed:\n%s\nwant it to start with the recorded output %q", replayed, recorded)
+	}
+	browsed := strings.TrimPrefix(replayed, recorded)
+	for _, want := range []string{`read "data.txt" == "original" (recorded)`, "  name: ada\n"} {
+		if !strings.Contains(browsed, want) {
+			t.Errorf("Browser output lacks %q:\n%s", want, browsed)
+		}
+	}
+}
+
+func TestReplayChangesNothing(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.txt")
+	outPath := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(inPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	scriptPath := filepath.Join(dir, "test.viro")
+	script := `p: open "in.txt"
+data: read p
+close p
+write "out.txt" data
+delete "in.txt"
+set-env "VIRO_REPLAY_TEST" "set"
+print data
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("VIRO_REPLAY_TEST")
+	recPath := filepath.Join(dir, "run.json")
+
+	exitCode, recorded, stderr := runWithStdin(t, "", "--sandbox-root", dir, "--record", recPath, scriptPath)
+	if exitCode != api.ExitSuccess || recorded != "hello\n" {
+		t.Fatalf("Recorded run exited with %d, printed %q: %s", exitCode, recorded, stderr)
+	}
+
+	// The replay reads the recorded data, not the recreated file, and
+	// writes, deletes and sets nothing.
+	if err := os.Remove(outPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(inPath, []byte("recreated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("VIRO_REPLAY_TEST")
+	exitCode, replayed, stderr := runWithStdin(t, "q\n", "--sandbox-root", dir, "--replay", recPath)
+	if exitCode != api.ExitSuccess || stderr != "" {
+		t.Fatalf("Replay exited with %d: %s", exitCode, stderr)
+	}
+	if !strings.HasPrefix(replayed, recorded) {
+		t.Errorf("Replay printed:\n%s\nwant it to start with the recorded output %q", replayed, recorded)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("Replay wrote out.txt again (%v)", err)
+	}
+	if _, err := os.Stat(inPath); err != nil {
+		t.Errorf("Replay deleted in.txt again: %v", err)
+	}
+	if _, ok := os.LookupEnv("VIRO_REPLAY_TEST"); ok {
+		t.Error("Replay set the environment variable again")
+	}
+}
+
+func TestReplayMissingRecording(t *testing.T) {
+	exitCode, _, stderr := runWithStdin(t, "", "--replay", filepath.Join(t.TempDir(), "none.json"))
+	if exitCode != api.ExitError || !strings.Contains(stderr, "Error loading recording") {
+		t.Errorf("Expected a load error, got %d: %s", exitCode, stderr)
+	}
+}
+
+func TestReplayShowsRecordedSystem(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "test.viro")
+	script := `print [system.cwd system.platform.os system.args]
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recPath := filepath.Join(dir, "run.json")
+
+	t.Chdir(dir)
+	exitCode, recorded, stderr := runWithStdin(t, "", "--sandbox-root", dir, "--record", recPath, "test.viro", "one")
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("Recorded run failed with %d: %s", exitCode, stderr)
+	}
+
+	// Replayed from elsewhere, the system object still shows the recorded run.
+	t.Chdir(t.TempDir())
+	exitCode, replayed, stderr := runWithStdin(t, "q\n", "--sandbox-root", dir, "--replay", recPath)
+	if exitCode != api.ExitSuccess || stderr != "" {
+		t.Fatalf("Replay exited with %d: %s", exitCode, stderr)
+	}
+	if !strings.HasPrefix(replayed, recorded) {
+		t.Errorf("Replay printed:\n%s\nwant it to start with the recorded output %q", replayed, recorded)
+	}
+}
@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+	"github.com/marcin-radoszewski/viro/internal/version"
+)
+
+func runViroArgs(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{
+		Args:   args,
+		Stdin:  strings.NewReader(""),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	cfg, err := api.ConfigFromArgs(args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs failed: %v", err)
+	}
+	exitCode := api.Run(ctx, cfg)
+	return exitCode, stdout.String() + stderr.String()
+}
+
+func writeTempScript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.viro")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSystemObject_Fields(t *testing.T) {
+	script := writeTempScript(t, `Viro [title: "Demo" version: 2]
+print system.version
+print system.platform.os
+print system.platform.arch
+print system.script.path
+print system.script.dir
+print system.script.header.title
+print system.options.quiet
+print system.options.allow-exec
+print system.cwd`)
+
+	exitCode, output := runViroArgs(t, "--allow-exec", script)
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("exit code %d, output: %s", exitCode, output)
+	}
+
+	cwd, _ := os.Getwd()
+	want := []string{
+		version.Version,
+		runtime.GOOS,
+		runtime.GOARCH,
+		script,
+		filepath.Dir(script),
+		"Demo",
+		"false",
+		"true",
+		cwd,
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), output)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestSystemObject_NoScriptOutsideScriptMode(t *testing.T) {
+	exitCode, output := runViroArgs(t, "-c", "system.script")
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("exit code %d, output: %s", exitCode, output)
+	}
+	if strings.TrimSpace(output) != "none" {
+		t.Errorf("system.script = %q, want none", output)
+	}
+}
+
+func TestSystemObject_ScriptWithoutHeader(t *testing.T) {
+	script := writeTempScript(t, `print system.script.header`)
+	exitCode, output := runViroArgs(t, script)
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("exit code %d, output: %s", exitCode, output)
+	}
+	if strings.TrimSpace(output) != "none" {
+		t.Errorf("header = %q, want none", output)
+	}
+}
+
+func TestExitStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{"exit without status", []string{"-c", "exit"}, 0, ""},
+		{"exit with status", []string{"-c", "exit --code 3"}, 3, ""},
+		{"quit with status", []string{"-c", "quit --code 42"}, 42, ""},
+		{"exit from nested function and loop", []string{"-c", `f: fn [] [loop 3 [exit --code 5]] f print "unreachable"`}, 5, ""},
+		{"exit followed by code", []string{"-c", `print "a" exit print "unreachable"`}, 0, ""},
+		{"exit followed by an integer", []string{"-c", "exit 5"}, 0, ""},
+		{"out of range status", []string{"-c", "exit --code 256"}, api.ExitError, "between 0 and 255"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exitCode, output := runViroArgs(t, tt.args...)
+			if exitCode != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (output: %s)", exitCode, tt.wantCode, output)
+			}
+			if tt.wantOut == "" && strings.Contains(output, "unreachable") {
+				t.Errorf("code after exit was evaluated: %s", output)
+			}
+			if tt.wantOut != "" && !strings.Contains(output, tt.wantOut) {
+				t.Errorf("output = %q, want to contain %q", output, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestExitStatusInScript(t *testing.T) {
+	script := writeTempScript(t, "print \"a\"\nexit\nx: 5\nprint \"unreachable\"\n")
+	exitCode, output := runViroArgs(t, script)
+	if exitCode != api.ExitSuccess {
+		t.Errorf("exit code = %d, want 0 (output: %s)", exitCode, output)
+	}
+	if strings.TrimSpace(output) != "a" {
+		t.Errorf("output = %q, want only a", output)
+	}
+}
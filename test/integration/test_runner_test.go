@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func runTests(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{
+		Args:   append([]string{"test"}, args...),
+		Stdin:  strings.NewReader(""),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	cfg, err := api.ConfigFromArgs(ctx.Args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs() error = %v", err)
+	}
+	return api.Run(ctx, cfg), stdout.String(), stderr.String()
+}
+
+func TestTestMode(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	os.WriteFile(filepath.Join(dir, "math_test.viro"), []byte(`Viro [title: "math"]
+double: fn [x] [x * 2]
+
+test "double" [
+    assert-equal 4 double 2
+]
+
+test "double wrong" [
+    print "checking"
+    assert-equal 5 double 2
+]
+
+test "division" [
+    assert-error --id 'div-zero [1 / 0]
+]
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "lib", "text_test.viro"), []byte(`test "upper" [assert-equal "AB" uppercase "ab"]
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "lib", "helpers.viro"), []byte(`test "not a test file" [assert false]
+`), 0644)
+
+	t.Run("text report", func(t *testing.T) {
+		code, out, _ := runTests(t, "--sandbox-root", dir)
+		if code != api.ExitError {
+			t.Errorf("exit code = %d, want %d", code, api.ExitError)
+		}
+		for _, want := range []string{
+			"--- FAIL: double wrong (math_test.viro:8,",
+			"math_test.viro:10:5: Assertion failed: values differ",
+			"| checking",
+			"ok   lib/text_test.viro",
+			"FAIL: 3 passed, 1 failed",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q:\n%s", want, out)
+			}
+		}
+		if strings.Contains(out, "not a test file") {
+			t.Errorf("ran a file without the _test suffix:\n%s", out)
+		}
+	})
+
+	t.Run("run filter", func(t *testing.T) {
+		code, out, _ := runTests(t, "--sandbox-root", dir, "--run", "^(double|upper)$")
+		if code != api.ExitSuccess {
+			t.Errorf("exit code = %d, want %d:\n%s", code, api.ExitSuccess, out)
+		}
+		if !strings.Contains(out, "PASS: 2 passed") {
+			t.Errorf("output = %q", out)
+		}
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		_, out, _ := runTests(t, "--sandbox-root", dir, "--fail-fast", "--format", "tap")
+		if !strings.HasPrefix(out, "TAP version 13\n1..3\n") || !strings.Contains(out, "not ok 3 - math_test.viro: double wrong") || strings.Contains(out, "division") {
+			t.Errorf("output = %q", out)
+		}
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		_, out, _ := runTests(t, "--sandbox-root", dir, "--format", "junit", "lib")
+		if !strings.Contains(out, `<testsuites tests="1" failures="0"`) || !strings.Contains(out, `<testcase name="upper" classname="lib/text_test.viro"`) {
+			t.Errorf("output = %q", out)
+		}
+	})
+
+	t.Run("load error", func(t *testing.T) {
+		bad := t.TempDir()
+		os.WriteFile(filepath.Join(bad, "bad_test.viro"), []byte("x: [\n"), 0644)
+		code, out, _ := runTests(t, "--sandbox-root", bad)
+		if code != api.ExitError || !strings.Contains(out, "--- FAIL: bad_test.viro (load)") {
+			t.Errorf("exit code = %d, output = %q", code, out)
+		}
+	})
+
+	t.Run("no test files", func(t *testing.T) {
+		code, _, errOut := runTests(t, "--sandbox-root", t.TempDir())
+		if code != api.ExitSuccess || !strings.Contains(errOut, "no test files") {
+			t.Errorf("exit code = %d, stderr = %q", code, errOut)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcin-radoszewski/viro/internal/api"
+)
+
+func runTraced(t *testing.T, script string, flags ...string) (int, string) {
+	t.Helper()
+	scriptPath := filepath.Join(t.TempDir(), "test.viro")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+	args := append(flags, scriptPath)
+
+	var stdout, stderr bytes.Buffer
+	ctx := &api.RuntimeContext{Args: args, Stdout: &stdout, Stderr: &stderr}
+	cfg, err := api.ConfigFromArgs(args)
+	if err != nil {
+		t.Fatalf("ConfigFromArgs failed: %v", err)
+	}
+	return api.Run(ctx, cfg), stdout.String() + stderr.String()
+}
+
+func TestTraceBufferDumpedOnError(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.log")
+	script := `
+step: fn [n] [n + 1]
+loop 50 [step 1]
+fail: fn [] [1 / 0]
+fail
+`
+	exitCode, output := runTraced(t, script, "--trace-buffer", "5", "--trace-format", "tree", "--trace-file", traceFile)
+	if exitCode == api.ExitSuccess {
+		t.Fatalf("Expected the script to fail\nOutput: %s", output)
+	}
+
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatalf("Trace file missing: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 5 {
+		t.Errorf("Expected the last 5 events, got %d:\n%s", len(lines), data)
+	}
+	if !strings.Contains(string(data), "<- fail") || !strings.Contains(string(data), "!! ") {
+		t.Errorf("Expected the failing call at the end of the trace:\n%s", data)
+	}
+}
+
+func TestTraceBufferSilentOnSuccess(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.log")
+	exitCode, output := runTraced(t, "print 1 + 2", "--trace-buffer", "5", "--trace-file", traceFile)
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("Unexpected exit code %d\nOutput: %s", exitCode, output)
+	}
+	if data, _ := os.ReadFile(traceFile); len(data) != 0 {
+		t.Errorf("Ring buffer written without an error:\n%s", data)
+	}
+}
+
+func TestTraceFileChromeFormat(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.json")
+	script := `square: fn [n] [n * n]  print square 3`
+	exitCode, output := runTraced(t, script, "--trace-format", "chrome", "--trace-file", traceFile)
+	if exitCode != api.ExitSuccess {
+		t.Fatalf("Unexpected exit code %d\nOutput: %s", exitCode, output)
+	}
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatalf("Trace file missing: %v", err)
+	}
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") || !strings.Contains(text, `"name":"square","cat":"return","ph":"X"`) {
+		t.Errorf("Unexpected Chrome trace:\n%s", data)
+	}
+}